@@ -26,6 +26,8 @@ type Config struct {
 	AnalysisType              string            `yaml:"AnalysisType"`
 	AutoRemediationID         string            `yaml:"AutoRemediationID"`
 	AutoRemediationParameters map[string]string `yaml:"AutoRemediationParameters"`
+	Correlation               *Correlation      `yaml:"Correlation"`
+	DedupPeriodMinutes        int64             `yaml:"DedupPeriodMinutes"`
 	Description               string            `yaml:"Description"`
 	DisplayName               string            `yaml:"DisplayName"`
 	Enabled                   bool              `yaml:"Enabled"`
@@ -37,9 +39,18 @@ type Config struct {
 	Severity                  string            `yaml:"Severity"`
 	Suppressions              []string          `yaml:"Suppressions"`
 	Tags                      []string          `yaml:"Tags"`
+	Threshold                 int64             `yaml:"Threshold"`
+	ThresholdWindowMinutes    int64             `yaml:"ThresholdWindowMinutes"`
 	Tests                     []Test            `yaml:"Tests"`
 }
 
+// Correlation is the correlation of a rule when parsing rules in a bulk upload.
+type Correlation struct {
+	Ordered       bool     `yaml:"Ordered"`
+	RuleIDs       []string `yaml:"RuleIDs"`
+	WindowMinutes int64    `yaml:"WindowMinutes"`
+}
+
 // Test is a unit test definition when parsing policies in a bulk upload.
 type Test struct {
 	ExpectedResult bool        `yaml:"ExpectedResult"`
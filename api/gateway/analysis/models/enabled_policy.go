@@ -36,6 +36,12 @@ type EnabledPolicy struct {
 	// body
 	Body Body `json:"body,omitempty"`
 
+	// correlation
+	Correlation *Correlation `json:"correlation,omitempty"`
+
+	// dedup period minutes
+	DedupPeriodMinutes DedupPeriodMinutes `json:"dedupPeriodMinutes,omitempty"`
+
 	// id
 	ID ID `json:"id,omitempty"`
 
@@ -48,6 +54,12 @@ type EnabledPolicy struct {
 	// suppressions
 	Suppressions Suppressions `json:"suppressions,omitempty"`
 
+	// threshold
+	Threshold Threshold `json:"threshold,omitempty"`
+
+	// threshold window minutes
+	ThresholdWindowMinutes ThresholdWindowMinutes `json:"thresholdWindowMinutes,omitempty"`
+
 	// version Id
 	VersionID VersionID `json:"versionId,omitempty"`
 }
@@ -60,6 +72,14 @@ func (m *EnabledPolicy) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateCorrelation(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateDedupPeriodMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -76,6 +96,14 @@ func (m *EnabledPolicy) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateThreshold(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateThresholdWindowMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateVersionID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -102,6 +130,40 @@ func (m *EnabledPolicy) validateBody(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *EnabledPolicy) validateCorrelation(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Correlation) { // not required
+		return nil
+	}
+
+	if m.Correlation != nil {
+		if err := m.Correlation.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("correlation")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *EnabledPolicy) validateDedupPeriodMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.DedupPeriodMinutes) { // not required
+		return nil
+	}
+
+	if err := m.DedupPeriodMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupPeriodMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *EnabledPolicy) validateID(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.ID) { // not required
@@ -166,6 +228,38 @@ func (m *EnabledPolicy) validateSuppressions(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *EnabledPolicy) validateThreshold(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Threshold) { // not required
+		return nil
+	}
+
+	if err := m.Threshold.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("threshold")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *EnabledPolicy) validateThresholdWindowMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ThresholdWindowMinutes) { // not required
+		return nil
+	}
+
+	if err := m.ThresholdWindowMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("thresholdWindowMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *EnabledPolicy) validateVersionID(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.VersionID) { // not required
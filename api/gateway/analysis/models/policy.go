@@ -59,6 +59,9 @@ type Policy struct {
 	// Required: true
 	CreatedBy UserID `json:"createdBy"`
 
+	// dedup period minutes
+	DedupPeriodMinutes DedupPeriodMinutes `json:"dedupPeriodMinutes,omitempty"`
+
 	// description
 	// Required: true
 	Description Description `json:"description"`
@@ -145,6 +148,10 @@ func (m *Policy) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateDedupPeriodMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateDescription(formats); err != nil {
 		res = append(res, err)
 	}
@@ -279,6 +286,22 @@ func (m *Policy) validateCreatedBy(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Policy) validateDedupPeriodMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.DedupPeriodMinutes) { // not required
+		return nil
+	}
+
+	if err := m.DedupPeriodMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupPeriodMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *Policy) validateDescription(formats strfmt.Registry) error {
 
 	if err := m.Description.Validate(formats); err != nil {
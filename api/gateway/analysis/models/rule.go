@@ -38,6 +38,9 @@ type Rule struct {
 	// Required: true
 	Body Body `json:"body"`
 
+	// correlation
+	Correlation *Correlation `json:"correlation,omitempty"`
+
 	// created at
 	// Required: true
 	// Format: date-time
@@ -47,6 +50,9 @@ type Rule struct {
 	// Required: true
 	CreatedBy UserID `json:"createdBy"`
 
+	// dedup period minutes
+	DedupPeriodMinutes DedupPeriodMinutes `json:"dedupPeriodMinutes,omitempty"`
+
 	// description
 	// Required: true
 	Description Description `json:"description"`
@@ -96,6 +102,12 @@ type Rule struct {
 	// Required: true
 	Tests TestSuite `json:"tests"`
 
+	// threshold
+	Threshold Threshold `json:"threshold,omitempty"`
+
+	// threshold window minutes
+	ThresholdWindowMinutes ThresholdWindowMinutes `json:"thresholdWindowMinutes,omitempty"`
+
 	// version Id
 	// Required: true
 	VersionID VersionID `json:"versionId"`
@@ -109,6 +121,10 @@ func (m *Rule) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateCorrelation(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateCreatedAt(formats); err != nil {
 		res = append(res, err)
 	}
@@ -117,6 +133,10 @@ func (m *Rule) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateDedupPeriodMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateDescription(formats); err != nil {
 		res = append(res, err)
 	}
@@ -165,6 +185,14 @@ func (m *Rule) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateThreshold(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateThresholdWindowMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateVersionID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -187,6 +215,24 @@ func (m *Rule) validateBody(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Rule) validateCorrelation(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Correlation) { // not required
+		return nil
+	}
+
+	if m.Correlation != nil {
+		if err := m.Correlation.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("correlation")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *Rule) validateCreatedAt(formats strfmt.Registry) error {
 
 	if err := m.CreatedAt.Validate(formats); err != nil {
@@ -211,6 +257,22 @@ func (m *Rule) validateCreatedBy(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Rule) validateDedupPeriodMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.DedupPeriodMinutes) { // not required
+		return nil
+	}
+
+	if err := m.DedupPeriodMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupPeriodMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *Rule) validateDescription(formats strfmt.Registry) error {
 
 	if err := m.Description.Validate(formats); err != nil {
@@ -367,6 +429,38 @@ func (m *Rule) validateTests(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Rule) validateThreshold(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Threshold) { // not required
+		return nil
+	}
+
+	if err := m.Threshold.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("threshold")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *Rule) validateThresholdWindowMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ThresholdWindowMinutes) { // not required
+		return nil
+	}
+
+	if err := m.ThresholdWindowMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("thresholdWindowMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *Rule) validateVersionID(formats strfmt.Registry) error {
 
 	if err := m.VersionID.Validate(formats); err != nil {
@@ -43,6 +43,9 @@ type UpdatePolicy struct {
 	// Required: true
 	Body Body `json:"body"`
 
+	// dedup period minutes
+	DedupPeriodMinutes DedupPeriodMinutes `json:"dedupPeriodMinutes,omitempty"`
+
 	// description
 	Description Description `json:"description,omitempty"`
 
@@ -100,6 +103,10 @@ func (m *UpdatePolicy) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateDedupPeriodMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateDescription(formats); err != nil {
 		res = append(res, err)
 	}
@@ -198,6 +205,22 @@ func (m *UpdatePolicy) validateBody(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *UpdatePolicy) validateDedupPeriodMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.DedupPeriodMinutes) { // not required
+		return nil
+	}
+
+	if err := m.DedupPeriodMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupPeriodMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *UpdatePolicy) validateDescription(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Description) { // not required
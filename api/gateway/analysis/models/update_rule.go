@@ -37,6 +37,12 @@ type UpdateRule struct {
 	// Required: true
 	Body Body `json:"body"`
 
+	// correlation
+	Correlation *Correlation `json:"correlation,omitempty"`
+
+	// dedup period minutes
+	DedupPeriodMinutes DedupPeriodMinutes `json:"dedupPeriodMinutes,omitempty"`
+
 	// description
 	Description Description `json:"description,omitempty"`
 
@@ -70,6 +76,12 @@ type UpdateRule struct {
 	// tests
 	Tests TestSuite `json:"tests,omitempty"`
 
+	// threshold
+	Threshold Threshold `json:"threshold,omitempty"`
+
+	// threshold window minutes
+	ThresholdWindowMinutes ThresholdWindowMinutes `json:"thresholdWindowMinutes,omitempty"`
+
 	// user Id
 	// Required: true
 	UserID UserID `json:"userId"`
@@ -83,6 +95,14 @@ func (m *UpdateRule) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateCorrelation(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateDedupPeriodMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateDescription(formats); err != nil {
 		res = append(res, err)
 	}
@@ -123,6 +143,14 @@ func (m *UpdateRule) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateThreshold(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateThresholdWindowMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateUserID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -145,6 +173,40 @@ func (m *UpdateRule) validateBody(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *UpdateRule) validateCorrelation(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Correlation) { // not required
+		return nil
+	}
+
+	if m.Correlation != nil {
+		if err := m.Correlation.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("correlation")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *UpdateRule) validateDedupPeriodMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.DedupPeriodMinutes) { // not required
+		return nil
+	}
+
+	if err := m.DedupPeriodMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupPeriodMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *UpdateRule) validateDescription(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Description) { // not required
@@ -293,6 +355,38 @@ func (m *UpdateRule) validateTests(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *UpdateRule) validateThreshold(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Threshold) { // not required
+		return nil
+	}
+
+	if err := m.Threshold.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("threshold")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *UpdateRule) validateThresholdWindowMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ThresholdWindowMinutes) { // not required
+		return nil
+	}
+
+	if err := m.ThresholdWindowMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("thresholdWindowMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *UpdateRule) validateUserID(formats strfmt.Registry) error {
 
 	if err := m.UserID.Validate(formats); err != nil {
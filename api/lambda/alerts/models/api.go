@@ -22,19 +22,96 @@ import "time"
 
 // LambdaInput is the request structure for the alerts-api Lambda function.
 type LambdaInput struct {
-	GetAlert   *GetAlertInput   `json:"getAlert"`
-	GetEvent   *GetEventInput   `json:"getEvent"`
-	ListAlerts *ListAlertsInput `json:"listAlerts"`
+	GetAlert           *GetAlertInput           `json:"getAlert"`
+	GetEvent           *GetEventInput           `json:"getEvent"`
+	ListAlerts         *ListAlertsInput         `json:"listAlerts"`
+	UpdateAlertStatus  *UpdateAlertStatusInput  `json:"updateAlertStatus"`
+	AssignAlert        *AssignAlertInput        `json:"assignAlert"`
+	AddAlertComment    *AddAlertCommentInput    `json:"addAlertComment"`
+	UpdateAlertComment *UpdateAlertCommentInput `json:"updateAlertComment"`
+	DeleteAlertComment *DeleteAlertCommentInput `json:"deleteAlertComment"`
+	ListAlertComments  *ListAlertCommentsInput  `json:"listAlertComments"`
+	GetAlertTimeline   *GetAlertTimelineInput   `json:"getAlertTimeline"`
+	ExportAlerts       *ExportAlertsInput       `json:"exportAlerts"`
+	GetAlertsExport    *GetAlertsExportInput    `json:"getAlertsExport"`
+	RunAlertsExport    *RunAlertsExportInput    `json:"runAlertsExport"`
+	ResendAlert        *ResendAlertInput        `json:"resendAlert"`
+	GetAlertMetrics    *GetAlertMetricsInput    `json:"getAlertMetrics"`
+	GetAlertDeliveries *GetAlertDeliveriesInput `json:"getAlertDeliveries"`
+	AddAlertDeliveries *AddAlertDeliveriesInput `json:"addAlertDeliveries"`
+	// ListOutputDeliveries is used by the outputs-api to check the health of the outputs
+	ListOutputDeliveries *ListOutputDeliveriesInput `json:"listOutputDeliveries"`
 }
 
+// The triage status of an alert
+const (
+	// StatusOpen is the status of new alerts
+	StatusOpen = "OPEN"
+	// StatusTriaged means somebody is looking into the alert
+	StatusTriaged = "TRIAGED"
+	// StatusClosed means the alert was dismissed without action, e.g. a false positive
+	StatusClosed = "CLOSED"
+	// StatusResolved means the issue that triggered the alert has been addressed
+	StatusResolved = "RESOLVED"
+)
+
+// The actions recorded in the history of an alert
+const (
+	ActionStatusChange = "STATUS_CHANGE"
+	ActionAssign       = "ASSIGN"
+	// ActionOutputDelivered is the activity of a successful delivery, it is not stored in the history
+	ActionOutputDelivered = "OUTPUT_DELIVERED"
+	// ActionResend is recorded when a user sends an existing alert to its outputs again
+	ActionResend = "RESEND"
+)
+
+// The outcomes of an attempt to deliver an alert to an output
+const (
+	DeliveryStatusSuccess = "SUCCESS"
+	// DeliveryStatusFailure means the delivery failed and will be retried
+	DeliveryStatusFailure = "FAILURE"
+	// DeliveryStatusPermanentFailure means the delivery failed and will not be retried
+	DeliveryStatusPermanentFailure = "PERMANENT_FAILURE"
+	// DeliveryStatusDigested means the rate limit of the output was exceeded, the alert will be sent in a digest
+	DeliveryStatusDigested = "DIGESTED"
+)
+
+// The types of the entries in the activity timeline of an alert
+const (
+	ActivityAlertCreated    = "ALERT_CREATED"
+	ActivityEventsAdded     = "EVENTS_ADDED"
+	ActivityComment         = "COMMENT"
+	ActivityStatusChange    = ActionStatusChange
+	ActivityAssign          = ActionAssign
+	ActivityOutputDelivered = ActionOutputDelivered
+	ActivityResend          = ActionResend
+)
+
+// The formats of an alerts export
+const (
+	// ExportFormatJSON writes one JSON object per line, each alert with its events
+	ExportFormatJSON = "JSON"
+	// ExportFormatCSV writes one row per event, with the columns of its alert
+	ExportFormatCSV = "CSV"
+)
+
+// The status of an alerts export job
+const (
+	ExportStatusPending   = "PENDING"
+	ExportStatusRunning   = "RUNNING"
+	ExportStatusSucceeded = "SUCCEEDED"
+	ExportStatusFailed    = "FAILED"
+)
+
 // GetAlertInput retrieves details for a single alert.
 //
-// The response will contain by definition all of the events associated with the alert.
-// If `eventPageSize` and `eventPage` are specified, it will returns only the specified events in the response.
+// The response will contain by definition all of the stored events associated with the alert.
+// If `eventsPageSize` is specified, it will return only a page of events in the response,
+// the next page starts after `eventsExclusiveStartKey`.
 // Example:
 // {
 //     "getAlert": {
-// 	    "alertId": "ruleId-2"
+// 	    "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5"
 //     }
 // }
 type GetAlertInput struct {
@@ -48,7 +125,7 @@ type GetAlertInput struct {
 // Example:
 // {
 //     "getAlert": {
-// 	    "alertId": "ruleId-2"
+// 	    "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5"
 //     }
 // }
 type GetAlertOutput = Alert
@@ -63,28 +140,44 @@ type GetEventOutput struct {
 	Event *string `json:"event"`
 }
 
-// ListAlertsInput lists the alerts in reverse-chronological order (newest to oldest)
-// If "ruleId" is not set, we return all the alerts for the organization
-// If the "exclusiveStartKey" is not set, we return alerts starting from the most recent one. If it is set,
-// the output will return alerts starting from the "exclusiveStartKey" exclusive.
+// ListAlertsInput searches the alerts.
+//
+// All of the filters are optional and combined with AND, the values of a list filter are combined with OR:
+// - "ruleId" matches the alerts of a single rule
+// - "severity", "status", "logTypes" and "tags" match the alerts with any of the given values
+// - "titleContains" matches the alerts whose title contains the given (case-sensitive) string
+// - "createdAtAfter" and "createdAtBefore" restrict the creation time of the alerts (inclusive)
 //
+// Alerts are sorted by creation time in "sortDir" order (descending by default).
+// If the "exclusiveStartKey" is not set, we return alerts starting from the first one. If it is set,
+// the output will return alerts starting from the "exclusiveStartKey" exclusive.
 //
 // {
 //     "listAlerts": {
-//         "ruleId": "My.Rule",
+//         "severity": ["HIGH", "CRITICAL"],
+//         "status": ["OPEN"],
+//         "logTypes": ["AWS.CloudTrail"],
+//         "createdAtAfter": "2020-02-01T00:00:00Z",
 //         "pageSize": 25
 //     }
 // }
 type ListAlertsInput struct {
-	RuleID            *string `json:"ruleId,omitempty"`
-	PageSize          *int    `json:"pageSize,omitempty"  validate:"omitempty,min=1,max=50"`
-	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+	RuleID            *string    `json:"ruleId,omitempty"`
+	Severity          []*string  `json:"severity,omitempty" validate:"omitempty,dive,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	Status            []*string  `json:"status,omitempty" validate:"omitempty,dive,oneof=OPEN TRIAGED CLOSED RESOLVED"`
+	LogTypes          []*string  `json:"logTypes,omitempty" validate:"omitempty,dive,required"`
+	Tags              []*string  `json:"tags,omitempty" validate:"omitempty,dive,required"`
+	TitleContains     *string    `json:"titleContains,omitempty" validate:"omitempty,min=1"`
+	CreatedAtAfter    *time.Time `json:"createdAtAfter,omitempty"`
+	CreatedAtBefore   *time.Time `json:"createdAtBefore,omitempty"`
+	SortDir           *string    `json:"sortDir,omitempty" validate:"omitempty,oneof=ascending descending"`
+	PageSize          *int       `json:"pageSize,omitempty"  validate:"omitempty,min=1,max=50"`
+	ExclusiveStartKey *string    `json:"exclusiveStartKey,omitempty"`
 }
 
 // ListAlertsOutput is the returned alert list.
 type ListAlertsOutput struct {
-	// Alerts is a list of alerts sorted by timestamp descending.
-	// Alerts with the same timestamp are returned in ascending order of alert ID.
+	// Alerts is a list of alerts sorted as requested.
 	Alerts []*AlertSummary `json:"alertSummaries"`
 	// LastEvaluatedKey contains the last evaluated alert Id.
 	// If it is populated it means there are more alerts available
@@ -92,23 +185,461 @@ type ListAlertsOutput struct {
 	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
 }
 
+// UpdateAlertStatusInput changes the triage status of an alert.
+//
+// Resolution notes can optionally be set, they are stored along with the user and the time of the change.
+//
+// Example:
+// {
+//     "updateAlertStatus": {
+//         "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5",
+//         "status": "RESOLVED",
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "resolution": "Access keys were rotated"
+//     }
+// }
+type UpdateAlertStatusInput struct {
+	AlertID    *string `json:"alertId" validate:"required"`
+	Status     *string `json:"status" validate:"required,oneof=OPEN TRIAGED CLOSED RESOLVED"`
+	UserID     *string `json:"userId" validate:"required,uuid4"`
+	Resolution *string `json:"resolution,omitempty" validate:"omitempty,max=5000"`
+}
+
+// UpdateAlertStatusOutput returns the updated alert summary
+type UpdateAlertStatusOutput = AlertSummary
+
+// AssignAlertInput assigns an alert to a user.
+//
+// If "assigneeId" is not set, the alert is unassigned.
+//
+// Example:
+// {
+//     "assignAlert": {
+//         "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5",
+//         "assigneeId": "7d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+//     }
+// }
+type AssignAlertInput struct {
+	AlertID    *string `json:"alertId" validate:"required"`
+	AssigneeID *string `json:"assigneeId,omitempty" validate:"omitempty,uuid4"`
+	UserID     *string `json:"userId" validate:"required,uuid4"`
+}
+
+// AssignAlertOutput returns the updated alert summary
+type AssignAlertOutput = AlertSummary
+
+// AddAlertCommentInput adds a comment to an alert.
+//
+// Example:
+// {
+//     "addAlertComment": {
+//         "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5",
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "body": "This is expected, the keys were rotated by the on-call engineer"
+//     }
+// }
+type AddAlertCommentInput struct {
+	AlertID *string `json:"alertId" validate:"required"`
+	UserID  *string `json:"userId" validate:"required,uuid4"`
+	Body    *string `json:"body" validate:"required,min=1,max=10000"`
+}
+
+// AddAlertCommentOutput returns the new comment
+type AddAlertCommentOutput = AlertComment
+
+// UpdateAlertCommentInput edits the body of a comment.
+//
+// Comments can only be edited by their author.
+//
+// Example:
+// {
+//     "updateAlertComment": {
+//         "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5",
+//         "commentId": "3c1d5a0e-8a3f-4ef4-9b6c-2a1e5f0d9c7b",
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "body": "This is expected, the keys were rotated by the on-call engineer"
+//     }
+// }
+type UpdateAlertCommentInput struct {
+	AlertID   *string `json:"alertId" validate:"required"`
+	CommentID *string `json:"commentId" validate:"required,uuid4"`
+	UserID    *string `json:"userId" validate:"required,uuid4"`
+	Body      *string `json:"body" validate:"required,min=1,max=10000"`
+}
+
+// UpdateAlertCommentOutput returns the updated comment
+type UpdateAlertCommentOutput = AlertComment
+
+// DeleteAlertCommentInput deletes a comment.
+//
+// Comments can only be deleted by their author.
+//
+// Example:
+// {
+//     "deleteAlertComment": {
+//         "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5",
+//         "commentId": "3c1d5a0e-8a3f-4ef4-9b6c-2a1e5f0d9c7b",
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+//     }
+// }
+type DeleteAlertCommentInput struct {
+	AlertID   *string `json:"alertId" validate:"required"`
+	CommentID *string `json:"commentId" validate:"required,uuid4"`
+	UserID    *string `json:"userId" validate:"required,uuid4"`
+}
+
+// ListAlertCommentsInput lists the comments of an alert in chronological order (oldest to newest)
+//
+// If the "exclusiveStartKey" is not set, we return comments starting from the oldest one.
+//
+// Example:
+// {
+//     "listAlertComments": {
+//         "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5",
+//         "pageSize": 25
+//     }
+// }
+type ListAlertCommentsInput struct {
+	AlertID           *string `json:"alertId" validate:"required"`
+	PageSize          *int    `json:"pageSize,omitempty"  validate:"omitempty,min=1,max=50"`
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+// ListAlertCommentsOutput is the returned comment list.
+type ListAlertCommentsOutput struct {
+	Comments []*AlertComment `json:"comments"`
+	// LastEvaluatedKey is populated if there are more comments available
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// GetAlertTimelineInput retrieves a page of the activity timeline of an alert.
+//
+// The timeline is paged by its comments: a page ends with its last comment and contains the other
+// activities up to it. If the "exclusiveStartKey" is not set, we return the timeline from its start.
+//
+// Example:
+// {
+//     "getAlertTimeline": {
+//         "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5",
+//         "pageSize": 25
+//     }
+// }
+type GetAlertTimelineInput struct {
+	AlertID           *string `json:"alertId" validate:"required"`
+	PageSize          *int    `json:"pageSize,omitempty" validate:"omitempty,min=1,max=50"`
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+// GetAlertTimelineOutput contains the activities of an alert in chronological order
+type GetAlertTimelineOutput struct {
+	Activities []*AlertActivity `json:"activities"`
+	// LastEvaluatedKey is populated if there are more activities available
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// GetAlertDeliveriesInput retrieves every attempt to deliver an alert to its outputs.
+//
+// Example:
+// {
+//     "getAlertDeliveries": {
+//         "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5"
+//     }
+// }
+type GetAlertDeliveriesInput struct {
+	AlertID *string `json:"alertId" validate:"required"`
+}
+
+// GetAlertDeliveriesOutput contains the delivery attempts of an alert in chronological order
+type GetAlertDeliveriesOutput = []*AlertDelivery
+
+// AddAlertDeliveriesInput records the attempts of the alert delivery to send alerts to their outputs.
+//
+// A random "deliveryId" is generated for the deliveries which do not set it.
+//
+// Example:
+// {
+//     "addAlertDeliveries": {
+//         "deliveries": [
+//             {
+//                 "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5",
+//                 "outputId": "8c2d4b2a-0c6e-4f1a-9b5e-0f5fc1a6e5d6",
+//                 "status": "SUCCESS",
+//                 "attempt": 1,
+//                 "timestamp": "2020-05-01T10:00:00Z"
+//             }
+//         ]
+//     }
+// }
+type AddAlertDeliveriesInput struct {
+	Deliveries []*AlertDelivery `json:"deliveries" validate:"min=1,max=1000,dive,required"`
+}
+
+// ListOutputDeliveriesInput retrieves the latest attempts to deliver alerts to an output.
+//
+// Only the deliveries of rule alerts are recorded.
+//
+// Example:
+// {
+//     "listOutputDeliveries": {
+//         "outputId": "8c2d4b2a-0c6e-4f1a-9b5e-0f5fc1a6e5d6",
+//         "limit": 3
+//     }
+// }
+type ListOutputDeliveriesInput struct {
+	OutputID *string `json:"outputId" validate:"required"`
+	Limit    *int    `json:"limit" validate:"required,min=1,max=100"`
+}
+
+// ListOutputDeliveriesOutput contains the delivery attempts to an output, newest first
+type ListOutputDeliveriesOutput = []*AlertDelivery
+
+// ResendAlertInput sends an existing alert to the alerting queue again.
+//
+// The alert is delivered to the given outputs, or to the default outputs of its severity if "outputIds" is not set.
+// The resend is recorded in the history of the alert.
+//
+// Example:
+// {
+//     "resendAlert": {
+//         "alertId": "0f5fc1a6e5d6e8c2d4b2a0c6e8f1a3b5",
+//         "outputIds": ["5a1c5854-f3ea-491c-8a52-0aa0d58cb456"],
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+//     }
+// }
+type ResendAlertInput struct {
+	AlertID   *string   `json:"alertId" validate:"required"`
+	OutputIDs []*string `json:"outputIds,omitempty" validate:"omitempty,dive,uuid4"`
+	UserID    *string   `json:"userId" validate:"required,uuid4"`
+}
+
+// GetAlertMetricsInput returns the alert metrics of a time range.
+//
+// The metrics are aggregated by the hour, the range is rounded to whole hours. The trend is
+// returned by "DAY" (default) or "HOUR" interval, for at most 366 days.
+//
+// Example:
+// {
+//     "getAlertMetrics": {
+//         "from": "2020-02-01T00:00:00Z",
+//         "to": "2020-02-08T00:00:00Z",
+//         "interval": "DAY"
+//     }
+// }
+type GetAlertMetricsInput struct {
+	From     *time.Time `json:"from" validate:"required"`
+	To       *time.Time `json:"to" validate:"required"`
+	Interval *string    `json:"interval,omitempty" validate:"omitempty,oneof=HOUR DAY"`
+}
+
+// GetAlertMetricsOutput contains the alert metrics grouped by dimension and by interval
+//
+// The groups are sorted by decreasing number of alerts.
+type GetAlertMetricsOutput struct {
+	Total      *AlertMetric   `json:"total"`
+	ByRule     []*AlertMetric `json:"byRule"`
+	BySeverity []*AlertMetric `json:"bySeverity"`
+	ByLogType  []*AlertMetric `json:"byLogType"`
+	// Trend has the totals of each interval in chronological order, keyed by the start of the interval
+	Trend []*AlertMetric `json:"trend"`
+}
+
+// AlertMetric contains the number of alerts and events and the mean time to resolve of a group of alerts
+//
+// Alerts of rules with several log types are counted under each log type.
+type AlertMetric struct {
+	Key                      *string  `json:"key,omitempty"`
+	AlertCount               int64    `json:"alertCount"`
+	EventCount               int64    `json:"eventCount"`
+	ResolvedCount            int64    `json:"resolvedCount"`
+	MeanTimeToResolveSeconds *float64 `json:"meanTimeToResolveSeconds,omitempty"`
+}
+
+// ExportAlertsInput starts an asynchronous export of the alerts created in a time range.
+//
+// The alerts can optionally be filtered by rule and severity. All of their events are exported
+// in the requested format (JSON lines by default). The job can be polled with "getAlertsExport".
+//
+// Example:
+// {
+//     "exportAlerts": {
+//         "createdAtAfter": "2020-01-01T00:00:00Z",
+//         "createdAtBefore": "2020-04-01T00:00:00Z",
+//         "severity": ["HIGH", "CRITICAL"],
+//         "format": "CSV",
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+//     }
+// }
+type ExportAlertsInput struct {
+	CreatedAtAfter  *time.Time `json:"createdAtAfter" validate:"required"`
+	CreatedAtBefore *time.Time `json:"createdAtBefore" validate:"required"`
+	RuleID          *string    `json:"ruleId,omitempty" validate:"omitempty,min=1"`
+	Severity        []*string  `json:"severity,omitempty" validate:"omitempty,dive,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	Format          *string    `json:"format,omitempty" validate:"omitempty,oneof=JSON CSV"`
+	UserID          *string    `json:"userId" validate:"required,uuid4"`
+}
+
+// ExportAlertsOutput is the created export job
+type ExportAlertsOutput = ExportJob
+
+// GetAlertsExportInput retrieves the status of an export job.
+//
+// Once the job succeeded, the output contains a presigned URL to download the export.
+//
+// Example:
+// {
+//     "getAlertsExport": {
+//         "jobId": "5d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+//     }
+// }
+type GetAlertsExportInput struct {
+	JobID *string `json:"jobId" validate:"required"`
+}
+
+// GetAlertsExportOutput is the export job
+type GetAlertsExportOutput = ExportJob
+
+// RunAlertsExportInput runs an export job.
+//
+// This is invoked asynchronously on the panther-alerts-exporter function after "exportAlerts" created the job.
+//
+// Example:
+// {
+//     "runAlertsExport": {
+//         "jobId": "5d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+//     }
+// }
+type RunAlertsExportInput struct {
+	JobID *string `json:"jobId" validate:"required"`
+}
+
+// ExportJob is an asynchronous export of alerts, it is also the item stored in the exports table
+type ExportJob struct {
+	JobID           *string    `json:"jobId"`
+	Status          *string    `json:"status"`
+	Format          *string    `json:"format"`
+	CreatedAtAfter  *time.Time `json:"createdAtAfter"`
+	CreatedAtBefore *time.Time `json:"createdAtBefore"`
+	RuleID          *string    `json:"ruleId,omitempty"`
+	Severity        []*string  `json:"severity,omitempty"`
+	CreatedBy       *string    `json:"createdBy"`
+	CreatedAt       *time.Time `json:"createdAt"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+	AlertCount      *int       `json:"alertCount,omitempty"`
+	EventCount      *int       `json:"eventCount,omitempty"`
+	Error           *string    `json:"error,omitempty"`
+	// ObjectKey is the key of the export in the alert events bucket, it is not returned by the API
+	ObjectKey *string `json:"objectKey,omitempty"`
+	// URL is a presigned URL to download a successful export, it is not stored
+	URL *string `json:"url,omitempty"`
+	// ExpiresAt is when the job is deleted from the table (unix seconds)
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
+
 // AlertSummary contains summary information for an alert
 type AlertSummary struct {
-	AlertID          *string    `json:"alertId"`
-	RuleID           *string    `json:"ruleId"`
-	CreationTime     *time.Time `json:"creationTime"`
-	LastEventMatched *time.Time `json:"lastEventMatched"`
-	EventsMatched    *int       `json:"eventsMatched"`
-	Severity         *string    `json:"severity"`
+	AlertID          *string     `json:"alertId"`
+	RuleID           *string     `json:"ruleId"`
+	RuleDisplayName  *string     `json:"ruleDisplayName,omitempty"`
+	Title            *string     `json:"title"`
+	LogTypes         []*string   `json:"logTypes"`
+	Tags             []*string   `json:"tags"`
+	Dedup            *string     `json:"dedup,omitempty"`
+	CreationTime     *time.Time  `json:"creationTime"`
+	LastEventMatched *time.Time  `json:"lastEventMatched"`
+	EventsMatched    *int        `json:"eventsMatched"`
+	Severity         *string     `json:"severity"`
+	Status           *string     `json:"status"`
+	AssigneeID       *string     `json:"assigneeId,omitempty"`
+	Resolution       *Resolution `json:"resolution,omitempty"`
+	LastUpdatedBy    *string     `json:"lastUpdatedBy,omitempty"`
+	LastUpdatedTime  *time.Time  `json:"lastUpdatedTime,omitempty"`
 }
 
 // Alert contains the details of an alert
+//
+// Only the first "eventLimit" events matched are stored, "eventsTruncated" is set if more events matched.
 type Alert struct {
-	AlertID                *string    `json:"alertId"`
-	RuleID                 *string    `json:"ruleId"`
-	CreationTime           *time.Time `json:"creationTime"`
-	LastEventMatched       *time.Time `json:"lastEventMatched"`
-	MatchedEventNum        *int       `json:"matchedEventNum"`
-	Events                 []*string  `json:"events"`
-	EventsLastEvaluatedKey *string    `json:"eventsLastEvaluatedKey,omitempty"`
+	AlertID                *string          `json:"alertId"`
+	RuleID                 *string          `json:"ruleId"`
+	Dedup                  *string          `json:"dedup,omitempty"`
+	CreationTime           *time.Time       `json:"creationTime"`
+	LastEventMatched       *time.Time       `json:"lastEventMatched"`
+	MatchedEventNum        *int             `json:"matchedEventNum"`
+	EventLimit             *int             `json:"eventLimit,omitempty"`
+	EventsTruncated        *bool            `json:"eventsTruncated"`
+	Events                 []*string        `json:"events"`
+	EventsLastEvaluatedKey *string          `json:"eventsLastEvaluatedKey,omitempty"`
+	Status                 *string          `json:"status"`
+	AssigneeID             *string          `json:"assigneeId,omitempty"`
+	Resolution             *Resolution      `json:"resolution,omitempty"`
+	LastUpdatedBy          *string          `json:"lastUpdatedBy,omitempty"`
+	LastUpdatedTime        *time.Time       `json:"lastUpdatedTime,omitempty"`
+	History                []*AlertChange   `json:"history"`
+	CorrelatedAlertIDs     []*string        `json:"correlatedAlertIds,omitempty"`
+	Title                  *string          `json:"title,omitempty"`
+	Context                []*ContextField  `json:"context,omitempty"`
+	Deliveries             []*AlertDelivery `json:"deliveries"`
+}
+
+// Resolution contains the resolution notes of an alert
+type Resolution struct {
+	Notes     *string    `json:"notes"`
+	UserID    *string    `json:"userId"`
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+// AlertChange is an entry in the audit trail of an alert
+//
+// The changes are stored separately from the alert, the history of older alerts is part of their item.
+type AlertChange struct {
+	AlertID    *string    `json:"alertId,omitempty"`
+	ChangeID   *string    `json:"changeId,omitempty"`
+	Action     *string    `json:"action"`
+	UserID     *string    `json:"userId"`
+	Timestamp  *time.Time `json:"timestamp"`
+	Status     *string    `json:"status,omitempty"`
+	AssigneeID *string    `json:"assigneeId,omitempty"`
+	Resolution *string    `json:"resolution,omitempty"`
+	OutputID   *string    `json:"outputId,omitempty"`
+	// OutputIDs are the outputs an alert was resent to, all of its outputs if empty
+	OutputIDs []*string `json:"outputIds,omitempty"`
+}
+
+// AlertComment is a comment left by a user on an alert
+type AlertComment struct {
+	AlertID      *string    `json:"alertId"`
+	CommentID    *string    `json:"commentId"`
+	UserID       *string    `json:"userId"`
+	Body         *string    `json:"body"`
+	CreatedAt    *time.Time `json:"createdAt"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+}
+
+// AlertActivity is an entry in the activity timeline of an alert
+//
+// Depending on the type of the activity, either the comment, the change or the event count is set.
+type AlertActivity struct {
+	Type       *string       `json:"type"`
+	Timestamp  *time.Time    `json:"timestamp"`
+	UserID     *string       `json:"userId,omitempty"`
+	Comment    *AlertComment `json:"comment,omitempty"`
+	Change     *AlertChange  `json:"change,omitempty"`
+	EventCount *int          `json:"eventCount,omitempty"`
+}
+
+// AlertDelivery records an attempt of the alert delivery to send an alert to one of its outputs
+type AlertDelivery struct {
+	AlertID    *string `json:"alertId" validate:"required"`
+	DeliveryID *string `json:"deliveryId"`
+	OutputID   *string `json:"outputId" validate:"required"`
+	Status     *string `json:"status" validate:"required,oneof=SUCCESS FAILURE PERMANENT_FAILURE DIGESTED"`
+	// HTTPStatusCode is the status of the response of the output, if the delivery failed after it replied
+	HTTPStatusCode *int    `json:"httpStatusCode,omitempty"`
+	Error          *string `json:"error,omitempty"`
+	// Attempt counts the deliveries of the alert, starting from 1, it is incremented on every retry
+	Attempt   *int       `json:"attempt" validate:"required,min=1"`
+	Timestamp *time.Time `json:"timestamp" validate:"required"`
+	// Resolution is set on the attempts to resolve the incident of a closed alert in the output
+	Resolution *bool `json:"resolution,omitempty"`
 }
@@ -18,13 +18,131 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
-import "time"
+import (
+	"fmt"
+	"hash/fnv"
+	"path"
+	"time"
+)
+
+// TimePartitions is the number of partitions of the alerts table indices sorted by time.
+//
+// Alerts are spread over the partitions by ID, so that the updates of the alerts are not all written to the
+// same index partition. Alerts are listed across rules by merging the partitions.
+const TimePartitions = 4
+
+// TimePartition returns the partition of an alert in the indices sorted by time, e.g. "defaultPartition#3"
+func TimePartition(alertID string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(alertID))
+	return timePartition(hash.Sum32() % TimePartitions)
+}
+
+// AllTimePartitions returns the partitions of the alerts table indices sorted by time
+func AllTimePartitions() []string {
+	partitions := make([]string, TimePartitions)
+	for i := range partitions {
+		partitions[i] = timePartition(uint32(i))
+	}
+	return partitions
+}
+
+func timePartition(index uint32) string {
+	return fmt.Sprintf("defaultPartition#%d", index)
+}
 
 // AlertItem is a DDB representation of an Alert
 type AlertItem struct {
 	AlertID          *string    `json:"alertId"`
 	RuleID           *string    `json:"ruleId"`
+	RuleDisplayName  *string    `json:"ruleDisplayName,omitempty"`
+	Title            *string    `json:"title,omitempty"`
+	Severity         *string    `json:"severity,omitempty"`
+	LogTypes         []*string  `json:"logTypes,omitempty"`
+	Tags             []*string  `json:"tags,omitempty"`
+	Dedup            *string    `json:"dedup,omitempty"`
 	CreationTime     *time.Time `json:"creationTime"`
 	LastEventMatched *time.Time `json:"lastEventMatched"`
-	EventHashes      [][]byte   `json:"eventHashes"`
+	EventCount       *int       `json:"eventCount,omitempty"`
+	EventLimit       *int       `json:"eventLimit,omitempty"`
+	// EventHashes reference the events of alerts created before events were stored in S3
+	EventHashes     [][]byte       `json:"eventHashes,omitempty"`
+	Status          *string        `json:"status,omitempty"`
+	AssigneeID      *string        `json:"assigneeId,omitempty"`
+	Resolution      *Resolution    `json:"resolution,omitempty"`
+	LastUpdatedBy   *string        `json:"lastUpdatedBy,omitempty"`
+	LastUpdatedTime *time.Time     `json:"lastUpdatedTime,omitempty"`
+	History         []*AlertChange `json:"history,omitempty"`
+	// ExpiresAt is the end of the retention period, the alert is then archived and deleted
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// ResolvedAt is the first time the alert was resolved, it is kept if the alert is reopened
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	// CorrelatedAlertIDs are the alerts of the referenced rules which triggered an alert of a correlation rule
+	CorrelatedAlertIDs []*string `json:"correlatedAlertIds,omitempty"`
+	// Context is returned by the rule for the match which triggered the alert
+	Context []*ContextField `json:"context,omitempty"`
+}
+
+// ContextField is information returned by a rule about a match, e.g. the user or the source IP
+type ContextField struct {
+	Key   string `json:"key" validate:"required,max=1000"`
+	Value string `json:"value" validate:"max=1000"`
+}
+
+// The dimensions of the alert metrics
+const (
+	MetricTotal    = "total"
+	MetricRule     = "rule"
+	MetricSeverity = "severity"
+	MetricLogType  = "logType"
+)
+
+// MetricDimension identifies an aggregate of the alert metrics, e.g. the alerts of a severity
+type MetricDimension struct {
+	Type  string
+	Value string
+}
+
+// MetricCounts are the values aggregated in the alert metrics
+type MetricCounts struct {
+	AlertCount    int64 `json:"alertCount"`
+	EventCount    int64 `json:"eventCount"`
+	ResolvedCount int64 `json:"resolvedCount"`
+	// ResolveSeconds is the total time to resolve the resolved alerts, from their creation
+	ResolveSeconds int64 `json:"resolveSeconds"`
+}
+
+// AlertMetricItem is a DDB representation of the hourly alert metrics of a dimension
+//
+// Alerts and events are counted in the hour they were matched, resolutions in the hour they happened.
+type AlertMetricItem struct {
+	// Day is the partition of a shard of the metrics of a day (UTC), e.g. "2020-02-01#3"
+	//
+	// The shard is removed from the metrics returned by the alerts table, their counts are summed.
+	Day string `json:"day"`
+	// Key is the hour of the day and the dimension, e.g. "13#severity#HIGH"
+	Key string `json:"key"`
+	MetricCounts
+}
+
+// EventKeyPrefix is the prefix of the S3 objects storing the events of an alert.
+//
+// The object keys sort in the order the events were matched.
+func EventKeyPrefix(alertID string) string {
+	return path.Join("alerts", alertID) + "/"
+}
+
+// MetricDimensions returns the dimensions an alert is counted in
+func MetricDimensions(item *AlertItem) []MetricDimension {
+	dimensions := []MetricDimension{{Type: MetricTotal}}
+	if item.RuleID != nil {
+		dimensions = append(dimensions, MetricDimension{Type: MetricRule, Value: *item.RuleID})
+	}
+	if item.Severity != nil {
+		dimensions = append(dimensions, MetricDimension{Type: MetricSeverity, Value: *item.Severity})
+	}
+	for _, logType := range item.LogTypes {
+		dimensions = append(dimensions, MetricDimension{Type: MetricLogType, Value: *logType})
+	}
+	return dimensions
 }
@@ -39,6 +39,7 @@ type CompleteActionOutput struct {
 // CreateOrganizationInput creates a new Panther customer account.
 type CreateOrganizationInput struct {
 	AlertReportFrequency *string            `json:"alertReportFrequency" validate:"omitempty,oneof=P1D P1W"`
+	AlertRetention       *AlertRetention    `json:"alertRetention,omitempty"`
 	AwsConfig            *AwsConfig         `json:"awsConfig"`
 	DisplayName          *string            `json:"displayName" validate:"required,min=1"`
 	Email                *string            `genericapi:"redact" json:"email" validate:"required,email"`
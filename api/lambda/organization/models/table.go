@@ -18,6 +18,8 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+import "github.com/aws/aws-sdk-go/aws"
+
 // Action defines an action the organization took
 type Action = string
 
@@ -29,6 +31,7 @@ const (
 // Organization defines the fields in the table row.
 type Organization struct {
 	AlertReportFrequency *string            `json:"alertReportFrequency"`
+	AlertRetention       *AlertRetention    `json:"alertRetention,omitempty"`
 	AwsConfig            *AwsConfig         `json:"awsConfig"`
 	CompletedActions     []*Action          `dynamodbav:"completedActions,omitempty,stringset" json:"completedActions"`
 	CreatedAt            *string            `json:"createdAt"`
@@ -51,3 +54,30 @@ type RemediationConfig struct {
 	// This field contains the ARN for that Lambda.
 	AwsRemediationLambdaArn *string `json:"awsRemediationLambdaArn,omitempty"`
 }
+
+// AlertRetention configures how long log analysis alerts and their events are kept before they are archived
+type AlertRetention struct {
+	// RetentionDays applies to alerts of all severities, alerts are kept indefinitely if it is not set
+	RetentionDays *int64 `json:"retentionDays,omitempty" validate:"omitempty,min=1"`
+	// SeverityRetentionDays overrides the retention period of alerts with a given severity
+	SeverityRetentionDays []*SeverityRetention `json:"severityRetentionDays,omitempty" validate:"omitempty,dive"`
+}
+
+// SeverityRetention is the retention period of alerts with a given severity
+type SeverityRetention struct {
+	Severity      *string `json:"severity" validate:"required,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	RetentionDays *int64  `json:"retentionDays" validate:"required,min=1"`
+}
+
+// Days returns the retention period of alerts with the given severity, zero if they are kept indefinitely
+func (r *AlertRetention) Days(severity string) int64 {
+	if r == nil {
+		return 0
+	}
+	for _, override := range r.SeverityRetentionDays {
+		if override != nil && aws.StringValue(override.Severity) == severity {
+			return aws.Int64Value(override.RetentionDays)
+		}
+	}
+	return aws.Int64Value(r.RetentionDays)
+}
@@ -29,6 +29,13 @@ type LambdaInput struct {
 	GetOrganizationOutputs *GetOrganizationOutputsInput `json:"getOrganizationOutputs"`
 	SetDefaultOutputs      *SetDefaultOutputsInput      `json:"setDefaultOutputs"`
 	GetDefaultOutputs      *GetDefaultOutputsInput      `json:"getDefaultOutputs"`
+	PreviewTemplate        *PreviewTemplateInput        `json:"previewTemplate"`
+	AddRoutingRule         *AddRoutingRuleInput         `json:"addRoutingRule"`
+	UpdateRoutingRule      *UpdateRoutingRuleInput      `json:"updateRoutingRule"`
+	DeleteRoutingRule      *DeleteRoutingRuleInput      `json:"deleteRoutingRule"`
+	GetRoutingRules        *GetRoutingRulesInput        `json:"getRoutingRules"`
+	TestOutput             *TestOutputInput             `json:"testOutput"`
+	CheckOutputsHealth     *CheckOutputsHealthInput     `json:"checkOutputsHealth"`
 }
 
 // AddOutputInput adds a new encrypted alert output to DynamoDB.
@@ -146,6 +153,165 @@ type GetDefaultOutputsOutput struct {
 	Defaults []*DefaultOutputs `json:"defaults"`
 }
 
+// PreviewTemplateInput renders a message template against a sample alert.
+//
+// Example:
+// {
+//     "previewTemplate": {
+//         "template": {
+//             "title": "{{.Severity}}: {{.Name}}"
+//         }
+//     }
+// }
+type PreviewTemplateInput struct {
+	Template *MessageTemplate `json:"template" validate:"required"`
+}
+
+// PreviewTemplateOutput is the title and body rendered from the template, they are omitted if not templated.
+//
+// Example:
+// {
+//     "title": "HIGH: AWS Root Account Login"
+// }
+type PreviewTemplateOutput struct {
+	Title *string `json:"title,omitempty"`
+	Body  *string `json:"body,omitempty"`
+}
+
+// TestOutputInput sends a sample alert, marked as a test, to an output.
+//
+// Example:
+// {
+//     "testOutput": {
+//         "outputId": "2b032a16-9a2e-4b0c-b3ad-b4c6d5fc9c82"
+//     }
+// }
+type TestOutputInput struct {
+	OutputID *string `json:"outputId" validate:"required,uuid4"`
+}
+
+// TestOutputOutput is the result of the test, the error of the output is returned if the delivery failed.
+//
+// Example:
+// {
+//     "success": false,
+//     "message": "request failed: 404 Not Found: no_team",
+//     "httpStatusCode": 404
+// }
+type TestOutputOutput struct {
+	Success        *bool   `json:"success"`
+	Message        *string `json:"message,omitempty"`
+	HTTPStatusCode *int    `json:"httpStatusCode,omitempty"`
+}
+
+// CheckOutputsHealthInput updates the health status of every output from its latest deliveries.
+//
+// It is invoked periodically, an output is unhealthy if its latest deliveries all failed.
+//
+// Example:
+// {
+//     "checkOutputsHealth": {}
+// }
+type CheckOutputsHealthInput struct {
+}
+
+// AddRoutingRuleInput adds a rule routing the matching alerts to a set of outputs.
+//
+// Example:
+// {
+//     "addRoutingRule": {
+//         "userId": "f6cfad0a-9bb0-4681-9503-02c54cc979c7",
+//         "displayName": "PCI to Jira",
+//         "priority": 10,
+//         "match": {
+//             "tags": ["pci"]
+//         },
+//         "outputIds": ["7d1c5854-f3ea-491c-8a52-0aa0d58cb456"],
+//         "continue": true
+//     }
+// }
+type AddRoutingRuleInput struct {
+	UserID      *string       `json:"userId" validate:"required,uuid4"`
+	DisplayName *string       `json:"displayName" validate:"required,min=1"`
+	Priority    *int          `json:"priority" validate:"required,min=0"`
+	Match       *RoutingMatch `json:"match" validate:"required"`
+	OutputIDs   []*string     `json:"outputIds" validate:"min=1,dive,required,uuid4"`
+	Continue    *bool         `json:"continue"`
+}
+
+// AddRoutingRuleOutput returns the new routing rule with a randomly generated UUID.
+type AddRoutingRuleOutput = RoutingRule
+
+// UpdateRoutingRuleInput replaces the configuration of a routing rule.
+type UpdateRoutingRuleInput struct {
+	RoutingRuleID *string       `json:"routingRuleId" validate:"required,uuid4"`
+	UserID        *string       `json:"userId" validate:"required,uuid4"`
+	DisplayName   *string       `json:"displayName" validate:"required,min=1"`
+	Priority      *int          `json:"priority" validate:"required,min=0"`
+	Match         *RoutingMatch `json:"match" validate:"required"`
+	OutputIDs     []*string     `json:"outputIds" validate:"min=1,dive,required,uuid4"`
+	Continue      *bool         `json:"continue"`
+}
+
+// UpdateRoutingRuleOutput returns the updated routing rule.
+type UpdateRoutingRuleOutput = RoutingRule
+
+// DeleteRoutingRuleInput permanently deletes a routing rule.
+//
+// Example:
+// {
+//     "deleteRoutingRule": {
+//         "routingRuleId": "3b2ae0c5-9d4c-4a0a-8c1f-3f8f1a3d0c1e"
+//     }
+// }
+type DeleteRoutingRuleInput struct {
+	RoutingRuleID *string `json:"routingRuleId" validate:"required,uuid4"`
+}
+
+// GetRoutingRulesInput lists all the routing rules.
+type GetRoutingRulesInput struct {
+}
+
+// GetRoutingRulesOutput returns the routing rules in the order they are evaluated.
+type GetRoutingRulesOutput = []*RoutingRule
+
+// RoutingRule sends the alerts it matches to its outputs instead of the default outputs for their severity.
+//
+// The routing rules are evaluated by increasing priority. Evaluation stops at the first match
+// unless the rule is set to continue, in which case the alert fans out to every matching rule.
+type RoutingRule struct {
+	RoutingRuleID    *string       `json:"routingRuleId"`
+	DisplayName      *string       `json:"displayName"`
+	Priority         *int          `json:"priority"`
+	Match            *RoutingMatch `json:"match"`
+	OutputIDs        []*string     `json:"outputIds"`
+	Continue         *bool         `json:"continue,omitempty"`
+	CreatedBy        *string       `json:"createdBy"`
+	CreationTime     *string       `json:"creationTime"`
+	LastModifiedBy   *string       `json:"lastModifiedBy"`
+	LastModifiedTime *string       `json:"lastModifiedTime"`
+}
+
+// RoutingMatch contains the criteria an alert must all satisfy to match a routing rule.
+//
+// A list criterion is satisfied if any of its values matches, an empty criterion matches every alert.
+type RoutingMatch struct {
+	// RuleIDs are the IDs of the rules or policies which triggered the alert
+	RuleIDs []*string `json:"ruleIds,omitempty" validate:"omitempty,dive,required"`
+
+	Severities []*string `json:"severities,omitempty" validate:"omitempty,dive,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	Tags       []*string `json:"tags,omitempty" validate:"omitempty,dive,required"`
+
+	// LogTypes only match alerts of rules
+	LogTypes []*string `json:"logTypes,omitempty" validate:"omitempty,dive,required"`
+
+	AlertType *string `json:"alertType,omitempty" validate:"omitempty,oneof=RULE POLICY"`
+
+	// ResourceTypes and IntegrationIDs only match alerts of policies
+	ResourceTypes  []*string `json:"resourceTypes,omitempty" validate:"omitempty,dive,required"`
+	IntegrationIDs []*string `json:"integrationIds,omitempty" validate:"omitempty,dive,uuid4"`
+}
+
 // AlertOutput contains the information for alert output configuration
 type AlertOutput struct {
 
@@ -179,8 +345,25 @@ type AlertOutput struct {
 
 	// DefaultForSeverity defines the alert severities that will be forwarded through this output
 	DefaultForSeverity []*string `json:"defaultForSeverity"`
+
+	// HealthStatus is set by the periodic health check from the latest deliveries, it is empty until it first runs
+	HealthStatus *string `json:"healthStatus,omitempty"`
+
+	// ConsecutiveFailures is the number of failed deliveries since the last successful one
+	ConsecutiveFailures *int `json:"consecutiveFailures,omitempty"`
 }
 
+const (
+	// HealthStatusHealthy shows that the latest delivery to the output succeeded
+	HealthStatusHealthy = "HEALTHY"
+
+	// HealthStatusUnhealthy shows that the latest deliveries to the output repeatedly failed
+	HealthStatusUnhealthy = "UNHEALTHY"
+
+	// HealthStatusUnknown shows that no delivery to the output was recorded, e.g. it only receives policy alerts
+	HealthStatusUnknown = "UNKNOWN"
+)
+
 const (
 	// VerificationStatusNotStarted shows that the verification process hasn't started yet
 	VerificationStatusNotStarted = "NOT_STARTED"
@@ -223,6 +406,24 @@ type OutputConfig struct {
 
 	// SqsConfig contains the configuration for SQS alert output
 	Sqs *SqsConfig `json:"sqs,omitempty"`
+
+	// Webhook contains the configuration for a generic HTTP webhook alert output
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+
+	// ServiceNow contains the configuration for ServiceNow alert output
+	ServiceNow *ServiceNowConfig `json:"serviceNow,omitempty"`
+
+	// Asana contains the configuration for Asana alert output
+	Asana *AsanaConfig `json:"asana,omitempty"`
+
+	// SplunkHEC contains the configuration for Splunk HTTP Event Collector alert output
+	SplunkHEC *SplunkHECConfig `json:"splunkHec,omitempty"`
+
+	// Template optionally replaces the default title and body of the notifications for any output type
+	Template *MessageTemplate `json:"template,omitempty"`
+
+	// RateLimit optionally caps the number of alerts sent to the output, the excess alerts are sent in a digest
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
 }
 
 // SlackConfig defines options for each Slack output.
@@ -275,6 +476,72 @@ type SqsConfig struct {
 	QueueURL *string `json:"queueUrl" validate:"required,url"`
 }
 
+// WebhookConfig defines options for each generic webhook output
+type WebhookConfig struct {
+	URL     *string          `json:"url" validate:"required,url"`
+	Headers []*WebhookHeader `json:"headers,omitempty" validate:"omitempty,max=20,dive,required"`
+
+	// When set, the body is signed with HMAC-SHA256 and the hex digest is sent in the X-Panther-Signature header
+	SigningSecret *string `json:"signingSecret,omitempty" validate:"omitempty,min=1"`
+
+	// Body is a JSON object whose fields are added to every request, e.g. {"source": "panther"}
+	Body *string `json:"body,omitempty" validate:"omitempty,jsonObject"`
+}
+
+// WebhookHeader is a custom HTTP header sent with each webhook request
+type WebhookHeader struct {
+	Key   *string `json:"key" validate:"required,min=1"`
+	Value *string `json:"value" validate:"required"`
+}
+
+// ServiceNowConfig defines options for each ServiceNow output
+type ServiceNowConfig struct {
+	InstanceURL     *string `json:"instanceURL" validate:"required,url"` // https://example.service-now.com
+	UserName        *string `json:"userName" validate:"required"`
+	Password        *string `json:"password" validate:"required"`
+	AssignmentGroup *string `json:"assignmentGroup,omitempty"`
+}
+
+// AsanaConfig defines options for each Asana output
+type AsanaConfig struct {
+	PersonalAccessToken *string   `json:"personalAccessToken" validate:"required"`
+	ProjectGids         []*string `json:"projectGids" validate:"required,min=1,dive,required"`
+}
+
+// SplunkHECConfig defines options for each Splunk HTTP Event Collector output
+type SplunkHECConfig struct {
+	URL        *string `json:"url" validate:"required,url"` // https://splunk.example.com:8088/services/collector/event
+	Token      *string `json:"token" validate:"required"`
+	Index      *string `json:"index,omitempty"`
+	SourceType *string `json:"sourceType,omitempty"`
+}
+
+// MessageTemplate contains Go text/template strings rendered against each alert.
+//
+// Example:
+// {
+//     "title": "{{.Severity}}: {{.Name}}",
+//     "body": "User {{.Context.userName}} triggered {{.PolicyID}}, see {{.Link}}"
+// }
+type MessageTemplate struct {
+	Title *string `json:"title,omitempty" validate:"omitempty,min=1,max=1000"`
+	Body  *string `json:"body,omitempty" validate:"omitempty,min=1,max=10000"`
+}
+
+// RateLimit allows at most MaxAlerts alerts to be sent to an output every WindowMinutes minutes.
+//
+// The alerts exceeding the limit are held back and sent together in a digest message at the end of the window.
+//
+// Example:
+// {
+//     "maxAlerts": 10,
+//     "windowMinutes": 60
+// }
+type RateLimit struct {
+	MaxAlerts     *int `json:"maxAlerts" validate:"required,min=1"`
+	WindowMinutes *int `json:"windowMinutes" validate:"required,min=1,max=1440"`
+}
+
 // DefaultOutputs is the structure holding the information about default outputs for severity
 type DefaultOutputs struct {
 	Severity  *string   `json:"severity"`
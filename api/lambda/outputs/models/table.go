@@ -49,6 +49,12 @@ type AlertOutputItem struct {
 	// VerificationStatus is the current state of the output destination.
 	// When an AlertOutput is not in 'VERIFIED' state it cannot be used to send notifications
 	VerificationStatus *string `json:"verificationStatus"`
+
+	// HealthStatus is updated by the periodic health check of the outputs
+	HealthStatus *string `json:"healthStatus,omitempty"`
+
+	// ConsecutiveFailures is the number of failed deliveries since the last successful one
+	ConsecutiveFailures *int `json:"consecutiveFailures,omitempty"`
 }
 
 // DefaultOutputsItem is the default output configuration stored in DynamoDB.
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
+	jsoniter "github.com/json-iterator/go"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -32,10 +33,16 @@ func Validator() (*validator.Validate, error) {
 	if err := result.RegisterValidation("snsArn", validateAwsArn); err != nil {
 		return nil, err
 	}
+	if err := result.RegisterValidation("jsonObject", validateJSONObject); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
-var outputTypes = []string{"Slack", "Sns", "Email", "PagerDuty", "Github", "Jira", "Opsgenie", "MsTeams", "Sqs"}
+var outputTypes = []string{
+	"Slack", "Sns", "Email", "PagerDuty", "Github", "Jira", "Opsgenie", "MsTeams", "Sqs", "Webhook",
+	"ServiceNow", "Asana", "SplunkHEC",
+}
 
 func ensureOneOutput(sl validator.StructLevel) {
 	input := sl.Current()
@@ -56,3 +63,8 @@ func validateAwsArn(fl validator.FieldLevel) bool {
 	fieldArn, err := arn.Parse(fl.Field().String())
 	return err == nil && fieldArn.Service == "sns"
 }
+
+func validateJSONObject(fl validator.FieldLevel) bool {
+	var object map[string]interface{}
+	return jsoniter.UnmarshalFromString(fl.Field().String(), &object) == nil && object != nil
+}
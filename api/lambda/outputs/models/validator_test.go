@@ -27,7 +27,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-const outputSet = "Slack|Sns|Email|PagerDuty|Github|Jira|Opsgenie|MsTeams|Sqs"
+const outputSet = "Slack|Sns|Email|PagerDuty|Github|Jira|Opsgenie|MsTeams|Sqs|Webhook|ServiceNow|Asana|SplunkHEC"
 
 func expectedMsg(structName string, fieldName string, tagName string) string {
 	return fmt.Sprintf(
@@ -114,3 +114,77 @@ func TestAddNonSnsArn(t *testing.T) {
 	require.Error(t, err)
 	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.Sns", "TopicArn", "snsArn"), err.Error())
 }
+
+func TestAddWebhookValid(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	assert.NoError(t, validator.Struct(&AddOutputInput{
+		UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName: aws.String("soar"),
+		OutputConfig: &OutputConfig{
+			Webhook: &WebhookConfig{
+				URL:           aws.String("https://soar.example.com/alerts"),
+				Headers:       []*WebhookHeader{{Key: aws.String("X-Api-Key"), Value: aws.String("secret")}},
+				SigningSecret: aws.String("signing-secret"),
+				Body:          aws.String(`{"source": "panther"}`),
+			},
+		},
+	}))
+}
+
+func TestAddWebhookInvalidBody(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	for _, body := range []string{`["source"]`, `null`, `{"source"`} {
+		err = validator.Struct(&AddOutputInput{
+			UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+			DisplayName: aws.String("soar"),
+			OutputConfig: &OutputConfig{
+				Webhook: &WebhookConfig{URL: aws.String("https://soar.example.com/alerts"), Body: aws.String(body)},
+			},
+		})
+		require.Error(t, err)
+		assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.Webhook", "Body", "jsonObject"), err.Error())
+	}
+}
+
+func TestAddTicketingOutputsValid(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	for _, config := range []*OutputConfig{
+		{ServiceNow: &ServiceNowConfig{
+			InstanceURL: aws.String("https://example.service-now.com"),
+			UserName:    aws.String("panther"),
+			Password:    aws.String("password"),
+		}},
+		{Asana: &AsanaConfig{
+			PersonalAccessToken: aws.String("token"),
+			ProjectGids:         aws.StringSlice([]string{"1199906407794339"}),
+		}},
+		{SplunkHEC: &SplunkHECConfig{
+			URL:   aws.String("https://splunk.example.com:8088/services/collector/event"),
+			Token: aws.String("token"),
+			Index: aws.String("security"),
+		}},
+	} {
+		assert.NoError(t, validator.Struct(&AddOutputInput{
+			UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+			DisplayName:  aws.String("tickets"),
+			OutputConfig: config,
+		}))
+	}
+}
+
+func TestAddAsanaNoProjects(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	err = validator.Struct(&AddOutputInput{
+		UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName: aws.String("tickets"),
+		OutputConfig: &OutputConfig{
+			Asana: &AsanaConfig{PersonalAccessToken: aws.String("token"), ProjectGids: []*string{}},
+		},
+	})
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.Asana", "ProjectGids", "min"), err.Error())
+}
@@ -39,4 +39,13 @@ type ComplianceNotification struct {
 
 	//Timestamp indicates when the policy was actually evaluated
 	Timestamp *time.Time `json:"timestamp"`
+
+	//ResourceType is the type of the resource, used to route the alert
+	ResourceType *string `json:"resourceType,omitempty"`
+
+	//IntegrationID is the source integration of the resource, used to route the alert
+	IntegrationID *string `json:"integrationId,omitempty"`
+
+	//Resolved indicates that the resource, previously failing, now passes the policy
+	Resolved *bool `json:"resolved,omitempty"`
 }
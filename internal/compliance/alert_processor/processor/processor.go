@@ -28,6 +28,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	jsoniter "github.com/json-iterator/go"
 	"go.uber.org/zap"
 
@@ -44,7 +46,7 @@ import (
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
-const alertSuppressPeriod = 3600 // 1 hour
+const defaultSuppressPeriodMinutes = 60 // 1 hour
 
 var (
 	remediationServiceHost = os.Getenv("REMEDIATION_SERVICE_HOST")
@@ -54,10 +56,12 @@ var (
 	policyServiceHost      = os.Getenv("POLICY_SERVICE_HOST")
 	policyServicePath      = os.Getenv("POLICY_SERVICE_PATH")
 
-	ddbTable = os.Getenv("TABLE_NAME")
+	ddbTable      = os.Getenv("TABLE_NAME")
+	alertQueueURL = os.Getenv("ALERTING_QUEUE_URL")
 
 	awsSession                           = session.Must(session.NewSession())
 	ddbClient  dynamodbiface.DynamoDBAPI = dynamodb.New(awsSession)
+	sqsClient  sqsiface.SQSAPI           = sqs.New(awsSession)
 	httpClient                           = gatewayapi.GatewayClient(awsSession)
 
 	remediationconfig = remediationclient.DefaultTransportConfig().
@@ -80,10 +84,15 @@ var (
 // If the resource is compliant, it will do nothing
 // If the resource is not compliant, it will trigger an auto-remediation action
 // and an alert - if alerting is not suppressed
+// If the resource is compliant again, the incidents opened for it are resolved
 func Handle(event *models.ComplianceNotification) error {
 	zap.L().Info("received new event",
 		zap.String("resourceId", *event.ResourceID))
 
+	if aws.BoolValue(event.Resolved) {
+		return resolveAlert(event)
+	}
+
 	triggerActions, err := shouldTriggerActions(event)
 	if err != nil {
 		return err
@@ -138,14 +147,15 @@ func triggerAlert(event *models.ComplianceNotification) error {
 		return nil
 	}
 	timeNow := time.Now().Unix()
-	expiresAt := int64(alertSuppressPeriod) + timeNow
 
-	alertConfig, err := getAlertConfigPolicy(event)
+	alertConfig, alertSuppressPeriod, err := getAlertConfigPolicy(event)
 	if err != nil {
 		zap.L().Warn("Encountered issue when getting policy",
 			zap.Any("policyId", event.PolicyID))
 		return err
 	}
+	alertConfig.ResourceID = event.ResourceID
+	expiresAt := alertSuppressPeriod + timeNow
 
 	marshalledAlertConfig, err := jsoniter.Marshal(alertConfig)
 	if err != nil {
@@ -160,7 +170,7 @@ func triggerAlert(event *models.ComplianceNotification) error {
 
 	// The Condition will succeed only if `alertSuppressPeriod` has passed since the time the previous
 	// alert was triggered
-	conditionExpression := expression.Name("lastUpdated").LessThan(expression.Value(timeNow - int64(alertSuppressPeriod))).
+	conditionExpression := expression.Name("lastUpdated").LessThan(expression.Value(timeNow - alertSuppressPeriod)).
 		Or(expression.Name("lastUpdated").AttributeNotExists())
 
 	combinedExpression, err := expression.NewBuilder().
@@ -197,6 +207,36 @@ func triggerAlert(event *models.ComplianceNotification) error {
 	return nil
 }
 
+// resolveAlert sends the resolution of the policy/resource pair directly to the alert delivery
+func resolveAlert(event *models.ComplianceNotification) error {
+	alert, _, err := getAlertConfigPolicy(event)
+	if err != nil {
+		zap.L().Warn("Encountered issue when getting policy",
+			zap.Any("policyId", event.PolicyID))
+		return err
+	}
+	alert.ResourceID = event.ResourceID
+	alert.Resolved = aws.Bool(true)
+
+	msgBody, err := jsoniter.MarshalToString(alert)
+	if err != nil {
+		zap.L().Error("failed to marshall resolved alert", zap.Error(err))
+		return err
+	}
+
+	zap.L().Info("resolving alert",
+		zap.String("policyId", *event.PolicyID),
+		zap.String("resourceId", *event.ResourceID))
+	if _, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:    aws.String(alertQueueURL),
+		MessageBody: aws.String(msgBody),
+	}); err != nil {
+		zap.L().Warn("failed to send resolved alert", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 func triggerRemediation(event *models.ComplianceNotification) error {
 	zap.L().Info("Triggering auto-remediation ",
 		zap.String("policyId", *event.PolicyID),
@@ -221,14 +261,26 @@ func triggerRemediation(event *models.ComplianceNotification) error {
 	return nil
 }
 
-func getAlertConfigPolicy(event *models.ComplianceNotification) (*alertmodel.Alert, error) {
-	policy, err := policyClient.Operations.GetPolicy(&analysisoperations.GetPolicyParams{
-		PolicyID:   *event.PolicyID,
-		HTTPClient: httpClient,
-	})
+// getAlertConfigPolicy returns the alert for the policy and the number of seconds during which
+// subsequent alerts for the policy are suppressed
+func getAlertConfigPolicy(event *models.ComplianceNotification) (*alertmodel.Alert, int64, error) {
+	// The params are initialized with the default timeout, a zero timeout expires the request immediately
+	policy, err := policyClient.Operations.GetPolicy(
+		analysisoperations.NewGetPolicyParams().WithPolicyID(*event.PolicyID).WithHTTPClient(httpClient))
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	// The alert is routed on the type of the failing resource if known
+	resourceTypes := aws.StringSlice(policy.Payload.ResourceTypes)
+	if event.ResourceType != nil {
+		resourceTypes = []*string{event.ResourceType}
+	}
+
+	suppressPeriodMinutes := int64(policy.Payload.DedupPeriodMinutes)
+	if suppressPeriodMinutes <= 0 {
+		suppressPeriodMinutes = defaultSuppressPeriodMinutes
 	}
 
 	return &alertmodel.Alert{
@@ -241,5 +293,7 @@ func getAlertConfigPolicy(event *models.ComplianceNotification) (*alertmodel.Ale
 		Severity:          aws.String(string(policy.Payload.Severity)),
 		Tags:              aws.StringSlice(policy.Payload.Tags),
 		Type:              aws.String(alertmodel.PolicyType),
-	}, nil
+		ResourceTypes:     resourceTypes,
+		IntegrationID:     event.IntegrationID,
+	}, suppressPeriodMinutes * 60, nil
 }
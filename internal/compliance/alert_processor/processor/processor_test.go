@@ -21,6 +21,7 @@ package processor
 import (
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -28,6 +29,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -36,6 +39,7 @@ import (
 	analysismodels "github.com/panther-labs/panther/api/gateway/analysis/models"
 	compliancemodels "github.com/panther-labs/panther/api/gateway/compliance/models"
 	"github.com/panther-labs/panther/internal/compliance/alert_processor/models"
+	alertmodel "github.com/panther-labs/panther/internal/core/alert_delivery/models"
 )
 
 type mockDdbClient struct {
@@ -48,6 +52,16 @@ func (m *mockDdbClient) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.U
 	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
 }
 
+type mockSqsClient struct {
+	sqsiface.SQSAPI
+	mock.Mock
+}
+
+func (m *mockSqsClient) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*sqs.SendMessageOutput), args.Error(1)
+}
+
 type mockRoundTripper struct {
 	http.RoundTripper
 	mock.Mock
@@ -101,6 +115,62 @@ func TestHandleEventWithAlert(t *testing.T) {
 	mockRoundTripper.AssertExpectations(t)
 }
 
+func TestHandleEventWithAlertDedupPeriod(t *testing.T) {
+	mockDdbClient := &mockDdbClient{}
+	ddbClient = mockDdbClient
+	mockRoundTripper := &mockRoundTripper{}
+	httpClient = &http.Client{Transport: mockRoundTripper}
+
+	input := &models.ComplianceNotification{
+		ResourceID:      aws.String("test-resource"),
+		PolicyID:        aws.String("test-policy"),
+		PolicyVersionID: aws.String("test-version"),
+		ShouldAlert:     aws.Bool(true),
+	}
+
+	complianceResponse := &compliancemodels.ComplianceStatus{
+		LastUpdated:    compliancemodels.LastUpdated(time.Now()),
+		PolicyID:       "test-policy",
+		PolicySeverity: "INFO",
+		ResourceID:     "test-resource",
+		ResourceType:   "AWS.S3.Test",
+		Status:         compliancemodels.StatusFAIL,
+		Suppressed:     false,
+	}
+
+	policyResponse := &analysismodels.Policy{DedupPeriodMinutes: 5}
+
+	// mock call to compliance-api
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(complianceResponse, http.StatusOK), nil).Once()
+	// mock call to policy-api
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(policyResponse, http.StatusOK), nil).Once()
+	// mock call to remediate-api
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse("", http.StatusOK), nil).Once()
+	mockDdbClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	start := time.Now().Unix()
+	require.NoError(t, Handle(input))
+	end := time.Now().Unix()
+
+	// The alert is suppressed for the dedup period of the policy instead of the default hour
+	updateInput := mockDdbClient.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	var expiresAt int64
+	for _, value := range updateInput.ExpressionAttributeValues {
+		if value.N == nil {
+			continue
+		}
+		number, err := strconv.ParseInt(*value.N, 10, 64)
+		require.NoError(t, err)
+		if number > end {
+			expiresAt = number
+		}
+	}
+	assert.True(t, expiresAt >= start+300 && expiresAt <= end+300)
+
+	mockDdbClient.AssertExpectations(t)
+	mockRoundTripper.AssertExpectations(t)
+}
+
 func TestHandleEventWithoutAlert(t *testing.T) {
 	mockDdbClient := &mockDdbClient{}
 	ddbClient = mockDdbClient
@@ -190,6 +260,45 @@ func TestSkipActionsIfLookupFailed(t *testing.T) {
 	mockRoundTripper.AssertExpectations(t)
 }
 
+func TestHandleResolvedEvent(t *testing.T) {
+	mockDdbClient := &mockDdbClient{}
+	ddbClient = mockDdbClient
+	mockSqsClient := &mockSqsClient{}
+	sqsClient = mockSqsClient
+	alertQueueURL = "alertQueueURL"
+	mockRoundTripper := &mockRoundTripper{}
+	httpClient = &http.Client{Transport: mockRoundTripper}
+
+	input := &models.ComplianceNotification{
+		ResourceID:      aws.String("test-resource"),
+		PolicyID:        aws.String("test-policy"),
+		PolicyVersionID: aws.String("test-version"),
+		ShouldAlert:     aws.Bool(false),
+		Resolved:        aws.Bool(true),
+	}
+
+	policyResponse := &analysismodels.Policy{Severity: "HIGH"}
+
+	// mock call to policy-api, the compliance status is not checked and no remediation is triggered
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(policyResponse, http.StatusOK), nil).Once()
+	mockSqsClient.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).Once()
+
+	require.NoError(t, Handle(input))
+
+	sendInput := mockSqsClient.Calls[0].Arguments[0].(*sqs.SendMessageInput)
+	assert.Equal(t, "alertQueueURL", *sendInput.QueueUrl)
+	var alert alertmodel.Alert
+	require.NoError(t, jsoniter.UnmarshalFromString(*sendInput.MessageBody, &alert))
+	assert.Equal(t, "test-policy", *alert.PolicyID)
+	assert.Equal(t, "test-resource", *alert.ResourceID)
+	assert.Equal(t, "HIGH", *alert.Severity)
+	assert.True(t, *alert.Resolved)
+
+	mockDdbClient.AssertExpectations(t)
+	mockSqsClient.AssertExpectations(t)
+	mockRoundTripper.AssertExpectations(t)
+}
+
 func generateResponse(body interface{}, httpCode int) *http.Response {
 	serializedBody, _ := jsoniter.MarshalToString(body)
 	return &http.Response{StatusCode: httpCode, Body: ioutil.NopCloser(strings.NewReader(serializedBody))}
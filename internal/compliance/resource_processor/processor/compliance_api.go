@@ -0,0 +1,121 @@
+package processor
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/gateway/compliance/client/operations"
+	compliancemodels "github.com/panther-labs/panther/api/gateway/compliance/models"
+)
+
+// The maximum page size of the compliance-api
+const compliancePageSize = 1000
+
+// A policy/resource pair of the analysis
+type statusKey struct {
+	policyID   string
+	resourceID string
+}
+
+// Get the pairs which were failing before the analysis among the pairs which just passed
+//
+// The compliance-api is queried once per policy or once per resource, whichever takes fewer requests.
+// Resolving the incidents is best effort: a failed lookup is logged and the pairs it covers are skipped.
+func getFailingPairs(passed []statusKey) map[statusKey]bool {
+	policies, resources := make(map[string]bool), make(map[string]bool)
+	for _, key := range passed {
+		policies[key.policyID] = true
+		resources[key.resourceID] = true
+	}
+
+	var statuses []*compliancemodels.ComplianceStatus
+	if len(policies) <= len(resources) {
+		for policyID := range policies {
+			statuses = append(statuses, getFailures("policyId", policyID, describePolicyFailures(policyID))...)
+		}
+	} else {
+		for resourceID := range resources {
+			statuses = append(statuses, getFailures("resourceId", resourceID, describeResourceFailures(resourceID))...)
+		}
+	}
+
+	result := make(map[statusKey]bool, len(statuses))
+	for _, status := range statuses {
+		result[statusKey{policyID: string(status.PolicyID), resourceID: string(status.ResourceID)}] = true
+	}
+	return result
+}
+
+// A page of the failing compliance statuses of a policy or a resource
+type describeFailures func(page int64) (*compliancemodels.PolicyResourceDetail, error)
+
+func describePolicyFailures(policyID string) describeFailures {
+	return func(page int64) (*compliancemodels.PolicyResourceDetail, error) {
+		response, err := complianceClient.Operations.DescribePolicy(operations.NewDescribePolicyParams().
+			WithPolicyID(policyID).
+			WithStatus(aws.String(string(compliancemodels.StatusFAIL))).
+			WithPage(aws.Int64(page)).
+			WithPageSize(aws.Int64(compliancePageSize)).
+			WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, err
+		}
+		return response.Payload, nil
+	}
+}
+
+func describeResourceFailures(resourceID string) describeFailures {
+	return func(page int64) (*compliancemodels.PolicyResourceDetail, error) {
+		response, err := complianceClient.Operations.DescribeResource(operations.NewDescribeResourceParams().
+			WithResourceID(resourceID).
+			WithStatus(aws.String(string(compliancemodels.StatusFAIL))).
+			WithPage(aws.Int64(page)).
+			WithPageSize(aws.Int64(compliancePageSize)).
+			WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, err
+		}
+		return response.Payload, nil
+	}
+}
+
+// Read every page of failing compliance statuses, none are returned if a page fails
+func getFailures(field, id string, describe describeFailures) []*compliancemodels.ComplianceStatus {
+	var result []*compliancemodels.ComplianceStatus
+	var totalPages int64 = 1
+	for pageno := int64(1); pageno <= totalPages; pageno++ {
+		page, err := describe(pageno)
+		if err != nil {
+			zap.L().Warn("failed to describe compliance failures, their incidents are not resolved",
+				zap.String(field, id), zap.Error(err))
+			return nil
+		}
+		if page == nil {
+			break
+		}
+		result = append(result, page.Items...)
+		if page.Paging == nil || page.Paging.TotalPages == nil {
+			break // without paging, the page is the only one
+		}
+		totalPages = *page.Paging.TotalPages
+	}
+	return result
+}
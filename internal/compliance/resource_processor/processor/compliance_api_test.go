@@ -0,0 +1,115 @@
+package processor
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	complianceapi "github.com/panther-labs/panther/api/gateway/compliance/client"
+	compliancemodels "github.com/panther-labs/panther/api/gateway/compliance/models"
+)
+
+// Serve the given pages of the compliance-api and count the requests
+func complianceServer(t *testing.T, path, param, id string, pages ...string) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, path, r.URL.Path)
+		assert.Equal(t, id, r.URL.Query().Get(param))
+		assert.Equal(t, "FAIL", r.URL.Query().Get("status"))
+		if requests >= len(pages) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pages[requests]))
+		requests++
+	}))
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	complianceClient = complianceapi.NewHTTPClientWithConfig(nil,
+		complianceapi.DefaultTransportConfig().WithHost(serverURL.Host).WithBasePath("/v1"))
+	httpClient = server.Client()
+	return server, &requests
+}
+
+func TestGetFailingPairsByPolicy(t *testing.T) {
+	server, requests := complianceServer(t, "/v1/describe-policy", "policyId", "policy.a",
+		`{"items": [{"policyId": "policy.a", "resourceId": "bucket.a"}], "paging": {"thisPage": 1, "totalItems": 2, "totalPages": 2}}`,
+		`{"items": [{"policyId": "policy.a", "resourceId": "bucket.c"}], "paging": {"thisPage": 2, "totalItems": 2, "totalPages": 2}}`,
+	)
+	defer server.Close()
+
+	// A single policy passed for several resources, it is looked up once
+	failing := getFailingPairs([]statusKey{
+		{policyID: "policy.a", resourceID: "bucket.a"},
+		{policyID: "policy.a", resourceID: "bucket.b"},
+	})
+	assert.True(t, failing[statusKey{policyID: "policy.a", resourceID: "bucket.a"}])
+	assert.False(t, failing[statusKey{policyID: "policy.a", resourceID: "bucket.b"}])
+	assert.Equal(t, 2, *requests)
+}
+
+func TestGetFailingPairsByResource(t *testing.T) {
+	server, requests := complianceServer(t, "/v1/describe-resource", "resourceId", "bucket.a",
+		`{"items": [{"policyId": "policy.b", "resourceId": "bucket.a"}], "paging": {"thisPage": 1, "totalItems": 1, "totalPages": 1}}`,
+	)
+	defer server.Close()
+
+	// A single resource passed several policies, it is looked up once
+	failing := getFailingPairs([]statusKey{
+		{policyID: "policy.a", resourceID: "bucket.a"},
+		{policyID: "policy.b", resourceID: "bucket.a"},
+	})
+	assert.Equal(t, map[statusKey]bool{{policyID: "policy.b", resourceID: "bucket.a"}: true}, failing)
+	assert.Equal(t, 1, *requests)
+}
+
+func TestGetFailingPairsError(t *testing.T) {
+	server, requests := complianceServer(t, "/v1/describe-policy", "policyId", "policy.a")
+	defer server.Close()
+
+	// The failed lookup is skipped instead of failing the analysis
+	failing := getFailingPairs([]statusKey{{policyID: "policy.a", resourceID: "bucket.a"}})
+	assert.Empty(t, failing)
+	assert.Equal(t, 0, *requests)
+}
+
+func TestGetFailingPairsNone(t *testing.T) {
+	assert.Empty(t, getFailingPairs(nil))
+}
+
+func TestGetFailuresWithoutPaging(t *testing.T) {
+	requests := 0
+	describe := func(page int64) (*compliancemodels.PolicyResourceDetail, error) {
+		requests++
+		return &compliancemodels.PolicyResourceDetail{
+			Items: []*compliancemodels.ComplianceStatus{{PolicyID: "policy.a", ResourceID: "bucket.a"}},
+		}, nil
+	}
+
+	assert.Len(t, getFailures("policyId", "policy.a", describe), 1)
+	assert.Equal(t, 1, requests)
+}
@@ -157,6 +157,7 @@ func (r *batchResults) analyze(resources resourceMap, policies policyMap) error
 	}
 
 	// Add a status entry for every policy/resource pair
+	var passed []statusKey
 	for _, result := range analysis.Resources {
 		for _, policyError := range result.Errored {
 			entry := buildStatus(policies[policyError.ID], resources[result.ID], compliancemodels.StatusERROR)
@@ -201,37 +202,73 @@ func (r *batchResults) analyze(resources resourceMap, policies policyMap) error
 			)
 
 			// Every failed policy, if not suppressed, will trigger the remediation flow
-			complianceNotification := &alertmodels.ComplianceNotification{
-				ResourceID:      aws.String(string(resource.ID)),
-				PolicyID:        aws.String(string(policy.ID)),
-				PolicyVersionID: aws.String(string(policy.VersionID)),
-				Timestamp:       aws.Time(time.Now()),
-
-				// We only need to send an alert to the user if the status is newly FAILing
-				ShouldAlert: aws.Bool(status != compliancemodels.StatusFAIL),
-			}
-			var sqsMessageBody string
-			if sqsMessageBody, err = jsoniter.MarshalToString(complianceNotification); err != nil {
-				zap.L().Error("failed to marshal complianceNotification body", zap.Error(err))
+			complianceNotification := buildNotification(policy, resource)
+			// We only need to send an alert to the user if the status is newly FAILing
+			complianceNotification.ShouldAlert = aws.Bool(status != compliancemodels.StatusFAIL)
+			if err = r.addNotification(complianceNotification); err != nil {
 				return err
 			}
-
-			r.Alerts = append(r.Alerts, &sqs.SendMessageBatchRequestEntry{
-				DelaySeconds: aws.Int64(defaultDelaySeconds),
-				Id:           aws.String(strconv.Itoa(len(r.Alerts))),
-				MessageBody:  aws.String(sqsMessageBody),
-			})
 		}
 
 		for _, policyID := range result.Passed {
 			entry := buildStatus(policies[policyID], resources[result.ID], compliancemodels.StatusPASS)
 			r.StatusEntries = append(r.StatusEntries, entry)
+			if !entry.Suppressed {
+				passed = append(passed, statusKey{policyID: policyID, resourceID: result.ID})
+			}
+		}
+	}
+
+	// The resources compliant again with a policy they were failing resolve the incidents opened for them
+	failing := getFailingPairs(passed)
+	for _, key := range passed {
+		if !failing[key] {
+			continue
+		}
+		complianceNotification := buildNotification(policies[key.policyID], resources[key.resourceID])
+		complianceNotification.ShouldAlert = aws.Bool(false)
+		complianceNotification.Resolved = aws.Bool(true)
+		if err = r.addNotification(complianceNotification); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// Convert a policy/resource pair into the notification sent to the alert-processor
+func buildNotification(
+	policy *analysismodels.EnabledPolicy,
+	resource *resourcemodels.Resource,
+) *alertmodels.ComplianceNotification {
+
+	return &alertmodels.ComplianceNotification{
+		ResourceID:      aws.String(string(resource.ID)),
+		PolicyID:        aws.String(string(policy.ID)),
+		PolicyVersionID: aws.String(string(policy.VersionID)),
+		Timestamp:       aws.Time(time.Now()),
+
+		ResourceType:  aws.String(string(resource.Type)),
+		IntegrationID: aws.String(string(resource.IntegrationID)),
+	}
+}
+
+// Queue a notification for the alert-processor
+func (r *batchResults) addNotification(complianceNotification *alertmodels.ComplianceNotification) error {
+	sqsMessageBody, err := jsoniter.MarshalToString(complianceNotification)
+	if err != nil {
+		zap.L().Error("failed to marshal complianceNotification body", zap.Error(err))
+		return err
+	}
+
+	r.Alerts = append(r.Alerts, &sqs.SendMessageBatchRequestEntry{
+		DelaySeconds: aws.Int64(defaultDelaySeconds),
+		Id:           aws.String(strconv.Itoa(len(r.Alerts))),
+		MessageBody:  aws.String(sqsMessageBody),
+	})
+	return nil
+}
+
 // Invoke the policy engine.
 func evaluatePolicies(policies policyMap, resources resourceMap) (*enginemodels.PolicyEngineOutput, error) {
 	input := enginemodels.PolicyEngineInput{
@@ -38,6 +38,13 @@ func (m *mockOutputsClient) Slack(alert *alertmodels.Alert, config *outputmodels
 	return args.Get(0).(*outputs.AlertDeliveryError)
 }
 
+func (m *mockOutputsClient) PagerDuty(
+	alert *alertmodels.Alert, config *outputmodels.PagerDutyConfig) *outputs.AlertDeliveryError {
+
+	args := m.Called(alert, config)
+	return args.Get(0).(*outputs.AlertDeliveryError)
+}
+
 type mockLambdaClient struct {
 	lambdaiface.LambdaAPI
 	mock.Mock
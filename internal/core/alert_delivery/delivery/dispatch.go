@@ -19,6 +19,9 @@ package delivery
  */
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"go.uber.org/zap"
 
@@ -33,6 +36,8 @@ type outputStatus struct {
 	outputID   string
 	success    bool
 	needsRetry bool
+	// digested is set if the rate limit of the output was exceeded and the alert will be sent in a digest
+	digested bool
 }
 
 // Send an alert to one specific output (run as a child goroutine).
@@ -45,12 +50,20 @@ func send(alert *alertmodels.Alert, outputID string, statusChannel chan outputSt
 	}
 	logger := zap.L()
 
+	// Every attempt is recorded in the delivery history before it is reported back to the channel,
+	// including the attempts to resolve the incident of a closed alert.
+	resolved := aws.BoolValue(alert.Resolved)
+	report := func(status outputStatus, err error) {
+		recordAttempt(alert, status, err)
+		statusChannel <- status
+	}
+
 	defer func() {
 		// If we panic when sending an alert, log an error and report back to the channel.
 		// Otherwise, the main routine will wait forever for this to finish.
 		if r := recover(); r != nil {
 			logger.Error("panic sending alert", append(commonFields, zap.Any("panic", r))...)
-			statusChannel <- outputStatus{outputID: outputID, success: false, needsRetry: false}
+			report(outputStatus{outputID: outputID, success: false, needsRetry: false}, fmt.Errorf("panic: %v", r))
 		}
 	}()
 
@@ -59,58 +72,53 @@ func send(alert *alertmodels.Alert, outputID string, statusChannel chan outputSt
 	if err != nil {
 		logger.Warn("error getting output", append(commonFields, zap.Error(err))...)
 		if lambdaErr, ok := err.(*genericapi.LambdaError); ok && aws.StringValue(lambdaErr.ErrorType) == "DoesNotExistError" {
-			statusChannel <- outputStatus{outputID: outputID, success: false, needsRetry: false}
+			report(outputStatus{outputID: outputID, success: false, needsRetry: false}, err)
 		} else {
-			statusChannel <- outputStatus{outputID: outputID, success: false, needsRetry: true}
+			report(outputStatus{outputID: outputID, success: false, needsRetry: true}, err)
 		}
 		return
 	}
 
 	if aws.StringValue(output.VerificationStatus) != outputmodels.VerificationStatusSuccess {
 		zap.L().Warn("Output is not verified successfully. Will not send notification")
-		statusChannel <- outputStatus{outputID: outputID, success: false, needsRetry: false}
+		report(outputStatus{outputID: outputID, success: false, needsRetry: false}, errors.New("output is not verified"))
+		return
+	}
+
+	if resolved && !outputs.Resolvable(aws.StringValue(output.OutputType)) {
+		// There is no incident to resolve in this output, nothing was attempted
+		statusChannel <- outputStatus{outputID: outputID, success: true, needsRetry: false}
 		return
 	}
 
+	if !resolved {
+		allowed, err := allowDelivery(alert, output)
+		if err != nil {
+			// The alert is held back until it is added to the digest
+			logger.Warn("failed to add alert to the digest", append(commonFields, zap.Error(err))...)
+			report(outputStatus{outputID: outputID, success: false, needsRetry: true}, err)
+			return
+		}
+		if !allowed {
+			logger.Info("rate limit of output exceeded, alert added to the digest", commonFields...)
+			report(outputStatus{outputID: outputID, success: true, needsRetry: false, digested: true}, nil)
+			return
+		}
+	}
+
 	logger.Info(
 		"sending alert",
 		append(commonFields, zap.String("name", *output.DisplayName))...,
 	)
 
-	var alertDeliveryError *outputs.AlertDeliveryError
-	switch *output.OutputType {
-	case "email":
-		alertDeliveryError = outputClient.Email(alert, output.OutputConfig.Email)
-	case "slack":
-		alertDeliveryError = outputClient.Slack(alert, output.OutputConfig.Slack)
-	case "pagerduty":
-		alertDeliveryError = outputClient.PagerDuty(alert, output.OutputConfig.PagerDuty)
-	case "github":
-		alertDeliveryError = outputClient.Github(alert, output.OutputConfig.Github)
-	case "opsgenie":
-		alertDeliveryError = outputClient.Opsgenie(alert, output.OutputConfig.Opsgenie)
-	case "jira":
-		alertDeliveryError = outputClient.Jira(alert, output.OutputConfig.Jira)
-	case "msteams":
-		alertDeliveryError = outputClient.MsTeams(alert, output.OutputConfig.MsTeams)
-	case "sqs":
-		alertDeliveryError = outputClient.Sqs(alert, output.OutputConfig.Sqs)
-	case "sns":
-		alertDeliveryError = outputClient.Sns(alert, output.OutputConfig.Sns)
-	default:
-		logger.Warn("unsupported output type", commonFields...)
-		statusChannel <- outputStatus{outputID: outputID, success: false, needsRetry: false}
-		return
-	}
-	if alertDeliveryError != nil {
+	if alertDeliveryError := outputs.Deliver(outputClient, alert, output); alertDeliveryError != nil {
 		logger.Warn("failed to send alert", append(commonFields, zap.Error(alertDeliveryError))...)
-		statusChannel <- outputStatus{
-			outputID: outputID, success: false, needsRetry: !alertDeliveryError.Permanent}
+		report(outputStatus{outputID: outputID, success: false, needsRetry: !alertDeliveryError.Permanent}, alertDeliveryError)
 		return
 	}
 
 	logger.Info("alert success", commonFields...)
-	statusChannel <- outputStatus{outputID: outputID, success: true, needsRetry: false}
+	report(outputStatus{outputID: outputID, success: true, needsRetry: false}, nil)
 }
 
 // Dispatch sends the alert to each of its designated outputs.
@@ -165,6 +173,7 @@ func dispatch(alert *alertmodels.Alert) bool {
 
 	if len(retryOutputs) > 0 {
 		alert.OutputIDs = retryOutputs // Replace the outputs with the set that failed
+		alert.Attempt = aws.Int(deliveryAttempt(alert) + 1)
 		return false
 	}
 
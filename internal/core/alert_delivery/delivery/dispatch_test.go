@@ -30,6 +30,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	alertsapimodels "github.com/panther-labs/panther/api/lambda/alerts/models"
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
 	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
 	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
@@ -67,6 +68,7 @@ func setCaches() {
 		},
 		Timestamp: time.Now(),
 	}
+	routingRulesCache = &cachedRoutingRules{Timestamp: time.Now()}
 }
 
 func TestFailureToRetrieveOutput(t *testing.T) {
@@ -167,6 +169,90 @@ func TestSendSuccess(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestSendRecordsAttempt(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	mockClient.On("Slack", mock.Anything, mock.Anything).Return(
+		&outputs.AlertDeliveryError{Message: "request failed: 503 Service Unavailable", StatusCode: 503})
+	ch := make(chan outputStatus, 1)
+
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+	alert.Attempt = aws.Int(3)
+	send(alert, "output-id", ch)
+	assert.Equal(t, outputStatus{outputID: "output-id", needsRetry: true}, <-ch)
+
+	delivery := recordedDelivery(t)
+	assert.Equal(t, "alert-id", *delivery.AlertID)
+	assert.Equal(t, "output-id", *delivery.OutputID)
+	assert.Equal(t, alertsapimodels.DeliveryStatusFailure, *delivery.Status)
+	assert.Equal(t, 503, *delivery.HTTPStatusCode)
+	assert.Equal(t, "request failed: 503 Service Unavailable", *delivery.Error)
+	assert.Equal(t, 3, *delivery.Attempt)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSendResolvedAlert(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	output := alertOutputCache[outputCacheKey{OutputID: "output-id"}].Output
+	output.OutputType = aws.String("pagerduty")
+	output.OutputConfig = &outputmodels.OutputConfig{PagerDuty: &outputmodels.PagerDutyConfig{IntegrationKey: aws.String("key")}}
+	mockClient.On("PagerDuty", mock.Anything, mock.Anything).Return((*outputs.AlertDeliveryError)(nil)).Once()
+	ch := make(chan outputStatus, 1)
+
+	// The resolution is recorded in the delivery history of the alert
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+	alert.Type = aws.String(alertmodels.RuleType)
+	alert.Resolved = aws.Bool(true)
+	send(alert, "output-id", ch)
+	assert.Equal(t, outputStatus{outputID: "output-id", success: true}, <-ch)
+	mockClient.AssertExpectations(t)
+	delivery := recordedDelivery(t)
+	assert.Equal(t, alertsapimodels.DeliveryStatusSuccess, *delivery.Status)
+	assert.True(t, *delivery.Resolution)
+}
+
+func TestSendResolvedAlertNotResolvable(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	ch := make(chan outputStatus, 1)
+
+	// Slack messages can not be resolved, nothing is sent or recorded
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+	alert.Resolved = aws.Bool(true)
+	send(alert, "output-id", ch)
+	assert.Equal(t, outputStatus{outputID: "output-id", success: true}, <-ch)
+	mockClient.AssertExpectations(t)
+	assert.Empty(t, recordedDeliveries())
+}
+
+func TestSendTemplatedMessage(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	alertOutputCache[outputCacheKey{OutputID: "output-id"}].Output.OutputConfig.Template = &outputmodels.MessageTemplate{
+		Title: aws.String("{{.Severity}}: {{.Name}}"),
+	}
+	templated := func(alert *alertmodels.Alert) bool {
+		return alert.Message != nil && *alert.Message.Title == "INFO: test_rule_name" && alert.Message.Body == nil
+	}
+	mockClient.On("Slack", mock.MatchedBy(templated), mock.Anything).Return((*outputs.AlertDeliveryError)(nil))
+	ch := make(chan outputStatus, 1)
+
+	// The alert shared by the outputs is not modified
+	alert := sampleAlert()
+	send(alert, "output-id", ch)
+	assert.Equal(t, outputStatus{outputID: "output-id", success: true}, <-ch)
+	assert.Nil(t, alert.Message)
+	mockClient.AssertExpectations(t)
+}
+
 func TestDispatchFailure(t *testing.T) {
 	mockClient := &mockOutputsClient{}
 	outputClient = mockClient
@@ -176,6 +262,7 @@ func TestDispatchFailure(t *testing.T) {
 	alert := sampleAlert()
 	assert.False(t, dispatch(alert))
 	assert.Equal(t, aws.StringSlice([]string{"output-id"}), alert.OutputIDs)
+	assert.Equal(t, aws.Int(2), alert.Attempt)
 	mockClient.AssertExpectations(t)
 }
 
@@ -209,6 +296,7 @@ func TestDispatchUseNonCachedDefault(t *testing.T) {
 	alert := sampleAlert()
 	alert.OutputIDs = nil       //Setting OutputIds in the alert to nil, in order to fetch default outputs
 	defaultOutputIDsCache = nil // Clearing the default output ids cache
+	routingRulesCache = &cachedRoutingRules{Timestamp: time.Now()}
 
 	assert.True(t, dispatch(alert))
 	mockLambdaClient.AssertExpectations(t)
@@ -228,6 +316,7 @@ func TestAllGoRoutinesShouldComplete(t *testing.T) {
 	alert := sampleAlert()
 	alert.OutputIDs = nil       //Setting OutputIds in the alert to nil, in order to fetch default outputs
 	defaultOutputIDsCache = nil // Clearing the default output ids cache
+	routingRulesCache = &cachedRoutingRules{Timestamp: time.Now()}
 
 	assert.True(t, dispatch(alert))
 	mockLambdaClient.AssertExpectations(t)
@@ -248,6 +337,7 @@ func TestDispatchUseDefaultIsEmpty(t *testing.T) {
 	alert.OutputIDs = nil //Setting OutputIds in the alert to nil, in order to fetch default outputs
 	alert.Severity = aws.String("INFO")
 	defaultOutputIDsCache = nil
+	routingRulesCache = &cachedRoutingRules{Timestamp: time.Now()}
 
 	assert.True(t, dispatch(alert))
 	mockLambdaClient.AssertExpectations(t)
@@ -263,6 +353,7 @@ func TestDispatchFailIfFailureToGetDefaults(t *testing.T) {
 	alert := sampleAlert()
 	alert.OutputIDs = nil       //Setting OutputIds in the alert to nil, in order to fetch default outputs
 	defaultOutputIDsCache = nil // Clearing the default output ids cache
+	routingRulesCache = &cachedRoutingRules{Timestamp: time.Now()}
 
 	assert.False(t, dispatch(alert))
 	mockLambdaClient.AssertExpectations(t)
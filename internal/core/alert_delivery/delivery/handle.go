@@ -42,6 +42,8 @@ func getMaxRetryDuration() time.Duration {
 }
 
 // HandleAlerts sends each alert to its outputs and puts failed alerts back on the queue to retry.
+//
+// The delivery attempts of all the alerts are then stored in the delivery history at once.
 func HandleAlerts(alerts []*models.Alert) {
 	var failedAlerts []*models.Alert
 
@@ -67,6 +69,8 @@ func HandleAlerts(alerts []*models.Alert) {
 		}
 	}
 
+	recordHistory()
+
 	if len(failedAlerts) > 0 {
 		retry(failedAlerts)
 	}
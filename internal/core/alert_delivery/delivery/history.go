@@ -0,0 +1,118 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	alertsapimodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The alerts-api stores the delivery history of the alerts
+var alertsAPI = os.Getenv("ALERTS_API")
+
+// maxDeliveriesPerRequest is the max number of deliveries the alerts-api stores in a single request
+const maxDeliveriesPerRequest = 1000
+
+// history collects the delivery attempts of the alerts being handled until they are stored by recordHistory
+var history struct {
+	sync.Mutex
+	deliveries []*alertsapimodels.AlertDelivery
+}
+
+// recordAttempt adds the outcome of an attempt to send an alert to an output to the delivery history.
+//
+// Alerts without an ID can not be looked up and are not recorded. The resolutions of closed alerts are recorded
+// as well, they are marked so that they are not mistaken for deliveries of the alert.
+func recordAttempt(alert *alertmodels.Alert, status outputStatus, err error) {
+	if alert.AlertID == nil {
+		return
+	}
+
+	delivery := &alertsapimodels.AlertDelivery{
+		AlertID:   alert.AlertID,
+		OutputID:  aws.String(status.outputID),
+		Attempt:   aws.Int(deliveryAttempt(alert)),
+		Timestamp: aws.Time(time.Now().UTC()),
+	}
+	switch {
+	case status.digested:
+		delivery.Status = aws.String(alertsapimodels.DeliveryStatusDigested)
+	case status.success:
+		delivery.Status = aws.String(alertsapimodels.DeliveryStatusSuccess)
+	case status.needsRetry:
+		delivery.Status = aws.String(alertsapimodels.DeliveryStatusFailure)
+	default:
+		delivery.Status = aws.String(alertsapimodels.DeliveryStatusPermanentFailure)
+	}
+	if err != nil {
+		delivery.Error = aws.String(err.Error())
+		if deliveryErr, ok := err.(*outputs.AlertDeliveryError); ok && deliveryErr.StatusCode != 0 {
+			delivery.HTTPStatusCode = aws.Int(deliveryErr.StatusCode)
+		}
+	}
+
+	if aws.BoolValue(alert.Resolved) {
+		delivery.Resolution = aws.Bool(true)
+	}
+
+	history.Lock()
+	history.deliveries = append(history.deliveries, delivery)
+	history.Unlock()
+}
+
+// recordHistory stores the delivery attempts collected since it was last called, in as few requests as possible.
+//
+// This is best effort, the alerts are not retried: failures are logged and counted in a metric to alarm on.
+func recordHistory() {
+	history.Lock()
+	deliveries := history.deliveries
+	history.deliveries = nil
+	history.Unlock()
+
+	for start := 0; start < len(deliveries); start += maxDeliveriesPerRequest {
+		end := start + maxDeliveriesPerRequest
+		if end > len(deliveries) {
+			end = len(deliveries)
+		}
+		input := alertsapimodels.LambdaInput{
+			AddAlertDeliveries: &alertsapimodels.AddAlertDeliveriesInput{Deliveries: deliveries[start:end]},
+		}
+		if err := genericapi.Invoke(lambdaClient, alertsAPI, &input, nil); err != nil {
+			zap.L().Error("failed to record alert delivery attempts", zap.Int("deliveries", end-start), zap.Error(err))
+			logFailures(MetricHistoryFailures, end-start)
+		}
+	}
+}
+
+// deliveryAttempt returns the number of the current attempt to deliver the alert, starting from 1
+func deliveryAttempt(alert *alertmodels.Alert) int {
+	if attempt := aws.IntValue(alert.Attempt); attempt > 0 {
+		return attempt
+	}
+	return 1
+}
@@ -0,0 +1,154 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	alertsapimodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+// recordedDeliveries returns the delivery attempts waiting to be stored and clears them
+func recordedDeliveries() []*alertsapimodels.AlertDelivery {
+	history.Lock()
+	defer history.Unlock()
+	deliveries := history.deliveries
+	history.deliveries = nil
+	return deliveries
+}
+
+// recordedDelivery returns the only delivery attempt waiting to be stored and clears it
+func recordedDelivery(t *testing.T) *alertsapimodels.AlertDelivery {
+	deliveries := recordedDeliveries()
+	require.Len(t, deliveries, 1)
+	return deliveries[0]
+}
+
+// storedDeliveries returns the deliveries sent to the alerts-api by an invocation
+func storedDeliveries(t *testing.T, call mock.Call) []*alertsapimodels.AlertDelivery {
+	input := call.Arguments[0].(*lambda.InvokeInput)
+	var lambdaInput alertsapimodels.LambdaInput
+	require.NoError(t, jsoniter.Unmarshal(input.Payload, &lambdaInput))
+	require.NotNil(t, lambdaInput.AddAlertDeliveries)
+	return lambdaInput.AddAlertDeliveries.Deliveries
+}
+
+func TestRecordAttemptSuccess(t *testing.T) {
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+
+	recordAttempt(alert, outputStatus{outputID: "output-id", success: true}, nil)
+	delivery := recordedDelivery(t)
+	assert.Equal(t, alertsapimodels.DeliveryStatusSuccess, *delivery.Status)
+	assert.Equal(t, 1, *delivery.Attempt)
+	assert.Nil(t, delivery.Error)
+	assert.Nil(t, delivery.HTTPStatusCode)
+	assert.Nil(t, delivery.Resolution)
+	assert.NotNil(t, delivery.Timestamp)
+}
+
+func TestRecordAttemptPermanentFailure(t *testing.T) {
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+
+	recordAttempt(alert, outputStatus{outputID: "output-id"}, errors.New("output is not verified"))
+	delivery := recordedDelivery(t)
+	assert.Equal(t, alertsapimodels.DeliveryStatusPermanentFailure, *delivery.Status)
+	assert.Equal(t, "output is not verified", *delivery.Error)
+	assert.Nil(t, delivery.HTTPStatusCode)
+}
+
+func TestRecordAttemptDigested(t *testing.T) {
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+
+	recordAttempt(alert, outputStatus{outputID: "output-id", success: true, digested: true}, nil)
+	delivery := recordedDelivery(t)
+	assert.Equal(t, alertsapimodels.DeliveryStatusDigested, *delivery.Status)
+	assert.Nil(t, delivery.Error)
+}
+
+func TestRecordAttemptResolution(t *testing.T) {
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+	alert.Resolved = aws.Bool(true)
+
+	recordAttempt(alert, outputStatus{outputID: "output-id", success: true}, nil)
+	delivery := recordedDelivery(t)
+	assert.Equal(t, alertsapimodels.DeliveryStatusSuccess, *delivery.Status)
+	assert.True(t, *delivery.Resolution)
+}
+
+func TestRecordAttemptNoAlertID(t *testing.T) {
+	recordAttempt(sampleAlert(), outputStatus{outputID: "output-id", success: true}, nil)
+	assert.Empty(t, recordedDeliveries())
+}
+
+func TestRecordHistory(t *testing.T) {
+	mockLambda := &mockLambdaClient{}
+	lambdaClient = mockLambda
+	mockLambda.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{}, nil).Twice()
+
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+	for i := 0; i < maxDeliveriesPerRequest+1; i++ {
+		recordAttempt(alert, outputStatus{outputID: "output-id", success: true}, nil)
+	}
+
+	// The deliveries are stored in as few requests as possible, then cleared
+	recordHistory()
+	mockLambda.AssertExpectations(t)
+	assert.Len(t, storedDeliveries(t, mockLambda.Calls[0]), maxDeliveriesPerRequest)
+	assert.Len(t, storedDeliveries(t, mockLambda.Calls[1]), 1)
+	assert.Empty(t, recordedDeliveries())
+
+	recordHistory()
+	mockLambda.AssertNumberOfCalls(t, "Invoke", 2)
+}
+
+func TestRecordHistoryFails(t *testing.T) {
+	var buffer bytes.Buffer
+	defer func(logger *metrics.Logger) { MetricsLogger = logger }(MetricsLogger)
+	MetricsLogger = metrics.NewLoggerWithWriter(MetricsNamespace, &buffer)
+	mockLambda := &mockLambdaClient{}
+	lambdaClient = mockLambda
+	mockLambda.On("Invoke", mock.Anything).Return((*lambda.InvokeOutput)(nil), errors.New("failed")).Once()
+
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+	recordAttempt(alert, outputStatus{outputID: "output-id", success: true}, nil)
+	recordAttempt(alert, outputStatus{outputID: "other-output-id", success: true}, nil)
+
+	// Errors are logged and counted, not propagated
+	recordHistory()
+	mockLambda.AssertExpectations(t)
+	assert.Equal(t, float64(2), gjson.Get(buffer.String(), MetricHistoryFailures).Float())
+	assert.Empty(t, recordedDeliveries())
+}
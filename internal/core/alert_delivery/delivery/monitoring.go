@@ -0,0 +1,42 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+// CloudWatch metrics of the alert delivery emitted using the Embedded Metric Format, to alarm on failures that do
+// not fail the delivery
+const (
+	MetricsNamespace = "Panther/AlertDelivery"
+
+	MetricHistoryFailures = "DeliveryHistoryFailures"
+)
+
+var MetricsLogger = metrics.NewLogger(MetricsNamespace)
+
+// logFailures counts failures, failures to write the metric are logged and otherwise ignored
+func logFailures(metric string, count int) {
+	if err := MetricsLogger.Log(nil, metrics.Metric{Name: metric, Unit: metrics.UnitCount, Value: float64(count)}); err != nil {
+		zap.L().Warn("failed to write metrics", zap.Error(err))
+	}
+}
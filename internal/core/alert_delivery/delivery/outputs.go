@@ -66,6 +66,15 @@ func getAlertOutputIds(alert *alertmodels.Alert) ([]*string, error) {
 		return alert.OutputIDs, nil
 	}
 
+	// The routing rules take precedence over the default outputs for the severity
+	rules, err := getRoutingRules()
+	if err != nil {
+		return nil, err
+	}
+	if routed := routeAlert(alert, rules); len(routed) > 0 {
+		return routed, nil
+	}
+
 	if defaultOutputIDsCache != nil && time.Since(defaultOutputIDsCache.Timestamp) < refreshInterval {
 		zap.L().Info("using cached output Ids")
 		return defaultOutputIDsCache.Outputs[*alert.Severity], nil
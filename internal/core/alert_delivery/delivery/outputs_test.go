@@ -21,6 +21,7 @@ package delivery
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/lambda"
@@ -86,6 +87,7 @@ func TestGetAlertOutputIds(t *testing.T) {
 	mockLambdaResponse := &lambda.InvokeOutput{Payload: payload}
 
 	defaultOutputIDsCache = nil // Clear the cache
+	routingRulesCache = &cachedRoutingRules{Timestamp: time.Now()}
 	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil)
 	alert := sampleAlert()
 	alert.OutputIDs = nil
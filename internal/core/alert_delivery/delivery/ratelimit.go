@@ -0,0 +1,431 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"go.uber.org/zap"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+const (
+	// windowRetention is how long the rate limit windows are kept after they end before DynamoDB expires them
+	windowRetention = 24 * time.Hour
+
+	// digestPending is the status of the windows whose digest has not been sent, only they are in the digests index
+	digestPending = "PENDING"
+	digestIndex   = "digests"
+
+	// digestClaimLease is how long a claimed digest is not sent by other invocations, longer than the Lambda timeout
+	// so that a digest claimed by an invocation which crashed is sent once the lease expires.
+	digestClaimLease = 20 * time.Minute
+
+	// Fixed width so that the alerts of a digest sort by time
+	digestKeyTimeFormat = "20060102T150405.000000000Z"
+
+	// maxCountAttempts bounds how often an alert is counted again when its window changes concurrently
+	maxCountAttempts = 3
+)
+
+var (
+	rateLimitsTable = os.Getenv("RATE_LIMITS_TABLE_NAME")
+	digestsTable    = os.Getenv("DIGESTS_TABLE_NAME")
+)
+
+// rateLimitWindow is an item of the rate limits table, counting the alerts sent to an output in a time window.
+type rateLimitWindow struct {
+	WindowID   string `json:"windowId"`
+	OutputID   string `json:"outputId"`
+	WindowEnd  int64  `json:"windowEnd"`
+	AlertCount int    `json:"alertCount"`
+	// DigestStatus is set while alerts held back in the window have not been sent in a digest
+	DigestStatus string     `json:"digestStatus,omitempty"`
+	DigestSentAt *time.Time `json:"digestSentAt,omitempty"`
+	ExpiresAt    int64      `json:"expiresAt"`
+}
+
+// digestEntry is an item of the digests table, an alert counted in a rate limit window.
+//
+// It records whether the alert was sent or held back in the digest of the window, a retried alert is delivered
+// the same way as the first time.
+type digestEntry struct {
+	WindowID string `json:"windowId"`
+	// DigestKey is the creation time and the ID of the alert, the same alert is stored once if it is retried
+	DigestKey string `json:"digestKey"`
+	Digested  bool   `json:"digested,omitempty"`
+	// Alert is only stored for the alerts held back
+	Alert     *alertmodels.Alert `json:"alert,omitempty"`
+	ExpiresAt int64              `json:"expiresAt"`
+}
+
+// allowDelivery counts the alert against the rate limit of the output, if it has one.
+//
+// Returns false if the limit is exceeded, the alert is then added to the digest of the current window. The alert
+// is counted once per window and the choice between sending it and holding it back is made in the same
+// conditional write, so that a retried alert is not counted again and an alert is not held back in a digest
+// which is already being sent. If the alert can not be held back, an error is returned and the alert is retried.
+// The counters are shared by the concurrent invocations, if they can not be updated the alert is sent anyway.
+func allowDelivery(alert *alertmodels.Alert, output *outputmodels.AlertOutput) (bool, error) {
+	rateLimit := output.OutputConfig.RateLimit
+	if rateLimit == nil {
+		return true, nil
+	}
+
+	window := time.Duration(*rateLimit.WindowMinutes) * time.Minute
+	windowEnd := time.Now().Truncate(window).Add(window)
+	windowID := fmt.Sprintf("%s:%d", *output.OutputID, windowEnd.Unix())
+	entry := &digestEntry{
+		WindowID:  windowID,
+		DigestKey: digestKey(alert),
+		ExpiresAt: windowEnd.Add(windowRetention).Unix(),
+	}
+
+	for attempt := 0; attempt < maxCountAttempts; attempt++ {
+		counted, err := countAlert(entry, output, windowEnd, nil)
+		if err != nil {
+			zap.L().Warn("failed to count alert against the rate limit",
+				zap.String("outputID", *output.OutputID), zap.Error(err))
+			return true, nil
+		}
+		if counted {
+			return true, nil
+		}
+
+		previous, err := getDigestEntry(entry)
+		if err != nil {
+			return false, err
+		}
+		if previous != nil {
+			// The alert is retried, it is delivered the same way as the first time
+			return !previous.Digested, nil
+		}
+
+		if counted, err = countAlert(entry, output, windowEnd, alert); err != nil {
+			return false, err
+		}
+		if counted {
+			return false, nil
+		}
+		// The window changed concurrently, for example its digest was claimed when it ended
+	}
+	return false, errors.New("rate limit window " + windowID + " changed while counting the alert")
+}
+
+// countAlert atomically counts the alert in its window and records whether it is sent or held back in the
+// digest of the window, which is the case if the digested alert is not nil.
+//
+// Returns false if the alert was already counted or if the window does not allow the delivery: an alert is sent
+// while the window is under its limit or once its digest is claimed, otherwise it is held back.
+func countAlert(entry *digestEntry, output *outputmodels.AlertOutput, windowEnd time.Time, digested *alertmodels.Alert) (bool, error) {
+	maxAlerts := *output.OutputConfig.RateLimit.MaxAlerts
+	update := expression.
+		Add(expression.Name("alertCount"), expression.Value(1)).
+		Set(expression.Name("outputId"), expression.Value(output.OutputID)).
+		Set(expression.Name("windowEnd"), expression.Value(windowEnd.Unix())).
+		Set(expression.Name("expiresAt"), expression.Value(entry.ExpiresAt))
+	digestClosed := expression.AttributeExists(expression.Name("digestSentAt")).
+		Or(expression.AttributeExists(expression.Name("digestClaimedUntil")))
+
+	record := *entry
+	var condition expression.ConditionBuilder
+	if digested != nil {
+		// The context is not part of the digest
+		alert := *digested
+		alert.Context = nil
+		record.Digested, record.Alert = true, &alert
+		update = update.Set(expression.Name("digestStatus"), expression.Value(digestPending))
+		condition = expression.Name("alertCount").GreaterThanEqual(expression.Value(maxAlerts)).
+			And(expression.Not(digestClosed))
+	} else {
+		condition = expression.AttributeNotExists(expression.Name("alertCount")).
+			Or(expression.Name("alertCount").LessThan(expression.Value(maxAlerts))).
+			Or(digestClosed)
+	}
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return false, err
+	}
+	item, err := dynamodbattribute.MarshalMap(&record)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = getDynamoClient().TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Update: &dynamodb.Update{
+					TableName:                 aws.String(rateLimitsTable),
+					Key:                       rateLimitKey(entry.WindowID),
+					UpdateExpression:          expr.Update(),
+					ConditionExpression:       expr.Condition(),
+					ExpressionAttributeNames:  expr.Names(),
+					ExpressionAttributeValues: expr.Values(),
+				},
+			},
+			{
+				// The alert is counted once per window
+				Put: &dynamodb.Put{
+					TableName:           aws.String(digestsTable),
+					Item:                item,
+					ConditionExpression: aws.String("attribute_not_exists(digestKey)"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeTransactionCanceledException {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// getDigestEntry returns the entry of an alert which was already counted in its window, or nil
+func getDigestEntry(entry *digestEntry) (*digestEntry, error) {
+	result, err := getDynamoClient().GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(digestsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"windowId":  {S: aws.String(entry.WindowID)},
+			"digestKey": {S: aws.String(entry.DigestKey)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil || len(result.Item) == 0 {
+		return nil, err
+	}
+
+	var previous digestEntry
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &previous); err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
+// digestKey sorts the alerts of a digest by creation time
+func digestKey(alert *alertmodels.Alert) string {
+	alertID := aws.StringValue(alert.AlertID)
+	if alertID == "" {
+		alertID = aws.StringValue(alert.PolicyID)
+	}
+	return aws.TimeValue(alert.CreatedAt).UTC().Format(digestKeyTimeFormat) + "#" + alertID
+}
+
+// SendDigests sends the alerts held back by the rate limits of the outputs once their windows have ended.
+func SendDigests() {
+	keyCondition := expression.Key("digestStatus").Equal(expression.Value(digestPending)).
+		And(expression.Key("windowEnd").LessThanEqual(expression.Value(time.Now().Unix())))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		zap.L().Error("failed to build digest key condition", zap.Error(err))
+		return
+	}
+
+	var windows []*rateLimitWindow
+	err = getDynamoClient().QueryPages(&dynamodb.QueryInput{
+		TableName:                 aws.String(rateLimitsTable),
+		IndexName:                 aws.String(digestIndex),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var pageWindows []*rateLimitWindow
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageWindows); err != nil {
+			zap.L().Error("failed to unmarshal rate limit windows", zap.Error(err))
+			return false
+		}
+		windows = append(windows, pageWindows...)
+		return true
+	})
+	if err != nil {
+		zap.L().Error("failed to query rate limit windows", zap.Error(err))
+		return
+	}
+
+	zap.L().Info("sending digests", zap.Int("digests", len(windows)))
+	for _, window := range windows {
+		sendDigest(window)
+	}
+}
+
+// sendDigest sends the digest of a window to its output, it is claimed first so that concurrent invocations
+// do not send it as well.
+//
+// The digest is only marked as sent once it was delivered. The claim is released if the digest can be retried,
+// it is then sent by the next invocation.
+func sendDigest(window *rateLimitWindow) {
+	logger := zap.L().With(zap.String("outputID", window.OutputID), zap.String("windowID", window.WindowID))
+
+	claimed, err := claimDigest(window.WindowID)
+	if err != nil {
+		logger.Warn("failed to claim digest", zap.Error(err))
+		return
+	}
+	if !claimed {
+		logger.Info("digest already claimed")
+		return
+	}
+
+	output, err := getOutput(window.OutputID)
+	if err != nil {
+		logger.Warn("error getting output", zap.Error(err))
+		releaseDigest(window.WindowID)
+		return
+	}
+
+	alerts, err := listDigest(window.WindowID)
+	if err != nil {
+		logger.Warn("failed to list digest", zap.Error(err))
+		releaseDigest(window.WindowID)
+		return
+	}
+	if len(alerts) == 0 {
+		completeDigest(window.WindowID)
+		return
+	}
+
+	digest := outputs.NewDigest(alerts, aws.StringValue(output.DisplayName))
+	if deliveryErr := outputs.Deliver(outputClient, digest, output); deliveryErr != nil {
+		logger.Warn("failed to send digest", zap.Int("alerts", len(alerts)), zap.Error(deliveryErr))
+		if deliveryErr.Permanent {
+			completeDigest(window.WindowID)
+		} else {
+			releaseDigest(window.WindowID)
+		}
+		return
+	}
+	logger.Info("digest success", zap.Int("alerts", len(alerts)))
+	completeDigest(window.WindowID)
+}
+
+// claimDigest leases the pending digest of a window, it returns false if it is sent or leased by another invocation
+func claimDigest(windowID string) (bool, error) {
+	now := time.Now()
+	update := expression.Set(expression.Name("digestClaimedUntil"), expression.Value(now.Add(digestClaimLease).Unix()))
+	condition := expression.Name("digestStatus").Equal(expression.Value(digestPending)).And(
+		expression.AttributeNotExists(expression.Name("digestClaimedUntil")).
+			Or(expression.Name("digestClaimedUntil").LessThan(expression.Value(now.Unix()))))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = getDynamoClient().UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String(rateLimitsTable),
+		Key:                       rateLimitKey(windowID),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// listDigest returns the alerts held back in a window, in the order they were created
+func listDigest(windowID string) ([]*alertmodels.Alert, error) {
+	keyCondition := expression.Key("windowId").Equal(expression.Value(windowID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []*alertmodels.Alert
+	var unmarshalErr error
+	err = getDynamoClient().QueryPages(&dynamodb.QueryInput{
+		TableName: aws.String(digestsTable),
+		// The alerts held back right before the digest was claimed are included
+		ConsistentRead:            aws.Bool(true),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var entries []*digestEntry
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &entries); unmarshalErr != nil {
+			return false
+		}
+		for _, entry := range entries {
+			// The alerts which were sent are counted in the window as well
+			if entry.Digested {
+				alerts = append(alerts, entry.Alert)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alerts, unmarshalErr
+}
+
+// completeDigest marks the digest of a window as sent, which removes it from the digests index
+//
+// If this fails the digest is sent again once its claim expires.
+func completeDigest(windowID string) {
+	update := expression.
+		Set(expression.Name("digestSentAt"), expression.Value(time.Now().UTC())).
+		Remove(expression.Name("digestStatus")).
+		Remove(expression.Name("digestClaimedUntil"))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err == nil {
+		_, err = getDynamoClient().UpdateItem(&dynamodb.UpdateItemInput{
+			TableName:                 aws.String(rateLimitsTable),
+			Key:                       rateLimitKey(windowID),
+			UpdateExpression:          expr.Update(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		})
+	}
+	if err != nil {
+		zap.L().Error("failed to mark digest as sent", zap.String("windowID", windowID), zap.Error(err))
+	}
+}
+
+// releaseDigest removes the claim on a digest which failed to send
+func releaseDigest(windowID string) {
+	_, err := getDynamoClient().UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:        aws.String(rateLimitsTable),
+		Key:              rateLimitKey(windowID),
+		UpdateExpression: aws.String("REMOVE digestClaimedUntil"),
+	})
+	if err != nil {
+		zap.L().Error("failed to release digest", zap.String("windowID", windowID), zap.Error(err))
+	}
+}
+
+func rateLimitKey(windowID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{"windowId": {S: aws.String(windowID)}}
+}
@@ -0,0 +1,379 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+type mockDynamoClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoClient) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.TransactWriteItemsOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) QueryPages(input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool) error {
+	args := m.Called(input, fn)
+	return args.Error(0)
+}
+
+func rateLimitedOutput() *outputmodels.AlertOutput {
+	return &outputmodels.AlertOutput{
+		OutputID:    aws.String("output-id"),
+		OutputType:  aws.String("slack"),
+		DisplayName: aws.String("slack:alerts"),
+		OutputConfig: &outputmodels.OutputConfig{
+			Slack:     &outputmodels.SlackConfig{WebhookURL: aws.String("https://slack.com")},
+			RateLimit: &outputmodels.RateLimit{MaxAlerts: aws.Int(2), WindowMinutes: aws.Int(60)},
+		},
+		VerificationStatus: aws.String(outputmodels.VerificationStatusSuccess),
+	}
+}
+
+func conditionFailed() error {
+	return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+}
+
+func transactionCanceled() error {
+	return awserr.New(dynamodb.ErrCodeTransactionCanceledException, "transaction canceled", nil)
+}
+
+// previousEntry returns the entry of an alert which was already counted
+func previousEntry(digested bool) *dynamodb.GetItemOutput {
+	item, _ := dynamodbattribute.MarshalMap(&digestEntry{WindowID: "output-id:1577840400", Digested: digested})
+	return &dynamodb.GetItemOutput{Item: item}
+}
+
+// countedEntry returns the window update and the entry of a transaction counting an alert
+func countedEntry(t *testing.T, call mock.Call) (*dynamodb.Update, *digestEntry) {
+	input := call.Arguments[0].(*dynamodb.TransactWriteItemsInput)
+	require.Len(t, input.TransactItems, 2)
+	var entry digestEntry
+	require.NoError(t, dynamodbattribute.UnmarshalMap(input.TransactItems[1].Put.Item, &entry))
+	assert.Equal(t, "attribute_not_exists(digestKey)", *input.TransactItems[1].Put.ConditionExpression)
+	return input.TransactItems[0].Update, &entry
+}
+
+// updatedNames returns the attributes named in the update of a window
+func updatedNames(update *dynamodb.Update) []string {
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	return names
+}
+
+func TestAllowDeliveryWithoutRateLimit(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	output := rateLimitedOutput()
+	output.OutputConfig.RateLimit = nil
+
+	allowed, err := allowDelivery(sampleAlert(), output)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAllowDeliveryUnderLimit(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	allowed, err := allowDelivery(sampleAlert(), rateLimitedOutput())
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	mockClient.AssertExpectations(t)
+
+	// The alert is counted in the window once, it is recorded as sent
+	update, entry := countedEntry(t, mockClient.Calls[0])
+	assert.Regexp(t, "^output-id:[0-9]+$", *update.Key["windowId"].S)
+	assert.NotNil(t, update.ConditionExpression)
+	assert.NotContains(t, updatedNames(update), "digestStatus")
+	assert.Equal(t, *update.Key["windowId"].S, entry.WindowID)
+	assert.False(t, entry.Digested)
+	assert.Nil(t, entry.Alert)
+}
+
+func TestAllowDeliveryOverLimit(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	digestsTable = "digests"
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, transactionCanceled()).Once()
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+	alert.CreatedAt = aws.Time(time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC))
+	alert.Context = []*alertmodels.ContextField{{Key: "user", Value: "root"}}
+	allowed, err := allowDelivery(alert, rateLimitedOutput())
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	mockClient.AssertExpectations(t)
+
+	// The alert is held back in its own item of the digest of the window, which is marked as pending in the same write
+	getInput := mockClient.Calls[1].Arguments[0].(*dynamodb.GetItemInput)
+	assert.Equal(t, "digests", *getInput.TableName)
+	assert.True(t, *getInput.ConsistentRead)
+	update, entry := countedEntry(t, mockClient.Calls[2])
+	assert.Contains(t, updatedNames(update), "digestStatus")
+	assert.Equal(t, *update.Key["windowId"].S, entry.WindowID)
+	assert.Equal(t, "20200101T010000.000000000Z#alert-id", entry.DigestKey)
+	assert.Equal(t, "20200101T010000.000000000Z#alert-id", *getInput.Key["digestKey"].S)
+	assert.True(t, entry.Digested)
+	assert.Equal(t, "test-rule-id", *entry.Alert.PolicyID)
+	assert.Nil(t, entry.Alert.Context)
+}
+
+func TestAllowDeliveryRetried(t *testing.T) {
+	for _, digested := range []bool{false, true} {
+		mockClient := &mockDynamoClient{}
+		dynamoClient = mockClient
+		mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, transactionCanceled()).Once()
+		mockClient.On("GetItem", mock.Anything).Return(previousEntry(digested), nil).Once()
+
+		// The alert is not counted again, it is delivered the same way as the first time
+		allowed, err := allowDelivery(sampleAlert(), rateLimitedOutput())
+		require.NoError(t, err)
+		assert.Equal(t, !digested, allowed)
+		mockClient.AssertExpectations(t)
+	}
+}
+
+func TestAllowDeliveryDigestClaimed(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, transactionCanceled()).Once()
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, transactionCanceled()).Once()
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	// The digest of the window was claimed when it ended, the alert is sent on its own
+	allowed, err := allowDelivery(sampleAlert(), rateLimitedOutput())
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	mockClient.AssertExpectations(t)
+
+	_, entry := countedEntry(t, mockClient.Calls[3])
+	assert.False(t, entry.Digested)
+}
+
+func TestAllowDeliveryWindowChanging(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, transactionCanceled())
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	// The alert is retried
+	allowed, err := allowDelivery(sampleAlert(), rateLimitedOutput())
+	assert.Error(t, err)
+	assert.False(t, allowed)
+	mockClient.AssertNumberOfCalls(t, "TransactWriteItems", 2*maxCountAttempts)
+}
+
+func TestAllowDeliveryDigestFails(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, transactionCanceled()).Once()
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, errors.New("dynamo")).Once()
+
+	// The alert is held back
+	allowed, err := allowDelivery(sampleAlert(), rateLimitedOutput())
+	assert.Error(t, err)
+	assert.False(t, allowed)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAllowDeliveryCounterFails(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, errors.New("dynamo")).Once()
+
+	// The alert is sent anyway
+	allowed, err := allowDelivery(sampleAlert(), rateLimitedOutput())
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	mockClient.AssertExpectations(t)
+}
+
+// mockOverLimit counts the alert in the digest of its window
+func mockOverLimit(mockClient *mockDynamoClient, digestErr error) {
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, transactionCanceled()).Once()
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, digestErr).Once()
+}
+
+func TestSendDigested(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockOutputs := &mockOutputsClient{}
+	outputClient = mockOutputs
+	setCaches()
+	alertOutputCache[outputCacheKey{OutputID: "output-id"}] = cachedOutput{Output: rateLimitedOutput(), Timestamp: time.Now()}
+	mockOverLimit(mockClient, nil)
+
+	ch := make(chan outputStatus, 1)
+	send(sampleAlert(), "output-id", ch)
+	assert.Equal(t, outputStatus{outputID: "output-id", success: true, digested: true}, <-ch)
+	mockClient.AssertExpectations(t)
+	mockOutputs.AssertExpectations(t)
+}
+
+func TestSendDigestFails(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockOutputs := &mockOutputsClient{}
+	outputClient = mockOutputs
+	setCaches()
+	alertOutputCache[outputCacheKey{OutputID: "output-id"}] = cachedOutput{Output: rateLimitedOutput(), Timestamp: time.Now()}
+	mockOverLimit(mockClient, errors.New("dynamo"))
+
+	ch := make(chan outputStatus, 1)
+	send(sampleAlert(), "output-id", ch)
+	assert.Equal(t, outputStatus{outputID: "output-id", success: false, needsRetry: true}, <-ch)
+	mockClient.AssertExpectations(t)
+	mockOutputs.AssertExpectations(t)
+}
+
+// mockQueries returns the windows from the digests index and the alerts from the digests table
+func mockQueries(mockClient *mockDynamoClient, window *rateLimitWindow, alerts ...*alertmodels.Alert) {
+	windowItem, _ := dynamodbattribute.MarshalMap(window)
+	// An alert which was sent is counted in the window as well
+	sent, _ := dynamodbattribute.MarshalMap(&digestEntry{WindowID: window.WindowID})
+	entries := []map[string]*dynamodb.AttributeValue{sent}
+	for _, alert := range alerts {
+		entry, _ := dynamodbattribute.MarshalMap(&digestEntry{WindowID: window.WindowID, Digested: true, Alert: alert})
+		entries = append(entries, entry)
+	}
+	mockClient.On("QueryPages", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		items := entries
+		if args.Get(0).(*dynamodb.QueryInput).IndexName != nil {
+			items = []map[string]*dynamodb.AttributeValue{windowItem}
+		}
+		args.Get(1).(func(*dynamodb.QueryOutput, bool) bool)(&dynamodb.QueryOutput{Items: items}, true)
+	})
+}
+
+func digestWindow() *rateLimitWindow {
+	return &rateLimitWindow{
+		WindowID:     "output-id:1577840400",
+		OutputID:     "output-id",
+		WindowEnd:    1577840400,
+		AlertCount:   4,
+		DigestStatus: digestPending,
+	}
+}
+
+func TestSendDigests(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockOutputs := &mockOutputsClient{}
+	outputClient = mockOutputs
+	setCaches()
+
+	mockQueries(mockClient, digestWindow(), sampleAlert(), sampleAlert())
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Twice()
+	mockOutputs.On("Slack", mock.Anything, mock.Anything).Return((*outputs.AlertDeliveryError)(nil)).Once()
+
+	SendDigests()
+	mockClient.AssertExpectations(t)
+	mockOutputs.AssertExpectations(t)
+
+	indexInput := mockClient.Calls[0].Arguments[0].(*dynamodb.QueryInput)
+	assert.Equal(t, digestIndex, *indexInput.IndexName)
+	listInput := mockClient.Calls[2].Arguments[0].(*dynamodb.QueryInput)
+	assert.True(t, *listInput.ConsistentRead)
+	claimInput := mockClient.Calls[1].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "output-id:1577840400", *claimInput.Key["windowId"].S)
+	assert.NotNil(t, claimInput.ConditionExpression)
+	digest := mockOutputs.Calls[0].Arguments[0].(*alertmodels.Alert)
+	require.NotNil(t, digest.Message)
+	assert.Equal(t, "Digest: 2 alerts exceeded the rate limit of slack:alerts", *digest.Message.Title)
+
+	// The digest is only marked as sent once it was delivered
+	completeInput := mockClient.Calls[3].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *completeInput.UpdateExpression, "REMOVE")
+	assert.Contains(t, *completeInput.UpdateExpression, "SET")
+}
+
+func TestSendDigestsAlreadyClaimed(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockOutputs := &mockOutputsClient{}
+	outputClient = mockOutputs
+	setCaches()
+
+	mockQueries(mockClient, digestWindow())
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, conditionFailed()).Once()
+
+	SendDigests()
+	mockClient.AssertExpectations(t)
+	mockOutputs.AssertExpectations(t)
+}
+
+func TestSendDigestsRetryableFailure(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	dynamoClient = mockClient
+	mockOutputs := &mockOutputsClient{}
+	outputClient = mockOutputs
+	setCaches()
+
+	mockQueries(mockClient, digestWindow(), sampleAlert())
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Twice()
+	mockOutputs.On("Slack", mock.Anything, mock.Anything).Return(&outputs.AlertDeliveryError{Message: "timeout"}).Once()
+
+	SendDigests()
+	mockClient.AssertExpectations(t)
+	mockOutputs.AssertExpectations(t)
+
+	// The claim is released so that the digest is sent by the next invocation
+	releaseInput := mockClient.Calls[3].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "REMOVE digestClaimedUntil", *releaseInput.UpdateExpression)
+}
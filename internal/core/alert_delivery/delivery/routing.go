@@ -0,0 +1,114 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type cachedRoutingRules struct {
+	// Sorted by priority
+	Rules     []*outputmodels.RoutingRule
+	Timestamp time.Time
+}
+
+var routingRulesCache *cachedRoutingRules
+
+// Get the routing rules, either from in-memory cache or the outputs-api
+func getRoutingRules() ([]*outputmodels.RoutingRule, error) {
+	if routingRulesCache != nil && time.Since(routingRulesCache.Timestamp) < refreshInterval {
+		zap.L().Info("using cached routing rules")
+		return routingRulesCache.Rules, nil
+	}
+
+	zap.L().Info("getting routing rules")
+	input := outputmodels.LambdaInput{GetRoutingRules: &outputmodels.GetRoutingRulesInput{}}
+	var rules outputmodels.GetRoutingRulesOutput
+	if err := genericapi.Invoke(lambdaClient, outputsAPI, &input, &rules); err != nil {
+		return nil, err
+	}
+
+	routingRulesCache = &cachedRoutingRules{Rules: rules, Timestamp: time.Now()}
+	return rules, nil
+}
+
+// routeAlert returns the outputs of the routing rules matching the alert, nil if no rule matches.
+//
+// Evaluation stops at the first matching rule unless it is set to continue.
+func routeAlert(alert *alertmodels.Alert, rules []*outputmodels.RoutingRule) []*string {
+	var result []*string
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if !routingMatches(rule.Match, alert) {
+			continue
+		}
+
+		zap.L().Debug("alert matches routing rule",
+			zap.String("policyId", aws.StringValue(alert.PolicyID)),
+			zap.String("routingRuleId", aws.StringValue(rule.RoutingRuleID)))
+		for _, outputID := range rule.OutputIDs {
+			if !seen[*outputID] {
+				seen[*outputID] = true
+				result = append(result, outputID)
+			}
+		}
+		if !aws.BoolValue(rule.Continue) {
+			break
+		}
+	}
+	return result
+}
+
+// routingMatches returns true if the alert satisfies every criterion of the match
+func routingMatches(match *outputmodels.RoutingMatch, alert *alertmodels.Alert) bool {
+	if match == nil {
+		return true
+	}
+	if match.AlertType != nil && *match.AlertType != aws.StringValue(alert.Type) {
+		return false
+	}
+	return matchesAny(match.RuleIDs, []*string{alert.PolicyID}) &&
+		matchesAny(match.Severities, []*string{alert.Severity}) &&
+		matchesAny(match.Tags, alert.Tags) &&
+		matchesAny(match.LogTypes, alert.LogTypes) &&
+		matchesAny(match.ResourceTypes, alert.ResourceTypes) &&
+		matchesAny(match.IntegrationIDs, []*string{alert.IntegrationID})
+}
+
+// matchesAny returns true if the criterion is empty or one of its values is in the alert values
+func matchesAny(criterion []*string, values []*string) bool {
+	if len(criterion) == 0 {
+		return true
+	}
+	for _, expected := range criterion {
+		for _, value := range values {
+			if value != nil && *value == *expected {
+				return true
+			}
+		}
+	}
+	return false
+}
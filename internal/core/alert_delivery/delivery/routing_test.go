@@ -0,0 +1,155 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+func TestRouteAlertFirstMatchWins(t *testing.T) {
+	alert := sampleAlert()
+	alert.Tags = aws.StringSlice([]string{"pci"})
+	rules := []*outputmodels.RoutingRule{
+		{
+			RoutingRuleID: aws.String("no-match"),
+			Match:         &outputmodels.RoutingMatch{Severities: aws.StringSlice([]string{"CRITICAL"})},
+			OutputIDs:     aws.StringSlice([]string{"output-1"}),
+		},
+		{
+			RoutingRuleID: aws.String("pci"),
+			Match: &outputmodels.RoutingMatch{
+				Severities: aws.StringSlice([]string{"INFO", "LOW"}),
+				Tags:       aws.StringSlice([]string{"hipaa", "pci"}),
+			},
+			OutputIDs: aws.StringSlice([]string{"output-2"}),
+		},
+		{
+			RoutingRuleID: aws.String("catch-all"),
+			Match:         &outputmodels.RoutingMatch{},
+			OutputIDs:     aws.StringSlice([]string{"output-3"}),
+		},
+	}
+
+	assert.Equal(t, aws.StringSlice([]string{"output-2"}), routeAlert(alert, rules))
+}
+
+func TestRouteAlertContinue(t *testing.T) {
+	alert := sampleAlert()
+	rules := []*outputmodels.RoutingRule{
+		{
+			RoutingRuleID: aws.String("rule-id"),
+			Match:         &outputmodels.RoutingMatch{RuleIDs: aws.StringSlice([]string{"test-rule-id"})},
+			OutputIDs:     aws.StringSlice([]string{"output-1", "output-2"}),
+			Continue:      aws.Bool(true),
+		},
+		{
+			RoutingRuleID: aws.String("catch-all"),
+			Match:         &outputmodels.RoutingMatch{},
+			OutputIDs:     aws.StringSlice([]string{"output-2", "output-3"}),
+		},
+		{
+			RoutingRuleID: aws.String("never-reached"),
+			Match:         &outputmodels.RoutingMatch{},
+			OutputIDs:     aws.StringSlice([]string{"output-4"}),
+		},
+	}
+
+	assert.Equal(t, aws.StringSlice([]string{"output-1", "output-2", "output-3"}), routeAlert(alert, rules))
+}
+
+func TestRouteAlertNoMatch(t *testing.T) {
+	alert := sampleAlert()
+	alert.Type = aws.String(alertmodels.RuleType)
+	rules := []*outputmodels.RoutingRule{
+		{
+			RoutingRuleID: aws.String("policies"),
+			Match:         &outputmodels.RoutingMatch{AlertType: aws.String(alertmodels.PolicyType)},
+			OutputIDs:     aws.StringSlice([]string{"output-1"}),
+		},
+		{
+			RoutingRuleID: aws.String("cloudtrail"),
+			Match:         &outputmodels.RoutingMatch{LogTypes: aws.StringSlice([]string{"AWS.CloudTrail"})},
+			OutputIDs:     aws.StringSlice([]string{"output-2"}),
+		},
+	}
+
+	assert.Nil(t, routeAlert(alert, rules))
+
+	alert.LogTypes = aws.StringSlice([]string{"AWS.S3ServerAccess", "AWS.CloudTrail"})
+	assert.Equal(t, aws.StringSlice([]string{"output-2"}), routeAlert(alert, rules))
+}
+
+func TestRoutingMatchesResource(t *testing.T) {
+	alert := sampleAlert()
+	alert.ResourceTypes = aws.StringSlice([]string{"AWS.S3.Bucket"})
+	alert.IntegrationID = aws.String("integration-id")
+
+	assert.True(t, routingMatches(nil, alert))
+	assert.True(t, routingMatches(&outputmodels.RoutingMatch{
+		ResourceTypes:  aws.StringSlice([]string{"AWS.S3.Bucket"}),
+		IntegrationIDs: aws.StringSlice([]string{"integration-id"}),
+	}, alert))
+	assert.False(t, routingMatches(&outputmodels.RoutingMatch{
+		ResourceTypes:  aws.StringSlice([]string{"AWS.S3.Bucket"}),
+		IntegrationIDs: aws.StringSlice([]string{"other-integration-id"}),
+	}, alert))
+}
+
+func TestGetAlertOutputIdsRouted(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	setCaches()
+
+	mockLambdaResponse := &lambda.InvokeOutput{
+		Payload: []byte(`[{"routingRuleId": "rule-id", "match": {"severities": ["INFO"]}, "outputIds": ["routed-output-id"]}]`),
+	}
+	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil).Once()
+	routingRulesCache = nil
+	alert := sampleAlert()
+	alert.OutputIDs = nil
+
+	result, err := getAlertOutputIds(alert)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"routed-output-id"}), result)
+
+	// The routing rules are now cached
+	require.NotNil(t, routingRulesCache)
+	result, err = getAlertOutputIds(alert)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"routed-output-id"}), result)
+
+	// Alerts not matching any routing rule fall back to the severity defaults
+	routingRulesCache.Timestamp = time.Now()
+	alert.Severity = aws.String("HIGH")
+	defaultOutputIDsCache.Outputs["HIGH"] = aws.StringSlice([]string{"default-output-id"})
+	result, err = getAlertOutputIds(alert)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"default-output-id"}), result)
+	mockClient.AssertExpectations(t)
+}
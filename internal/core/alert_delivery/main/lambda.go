@@ -34,8 +34,19 @@ import (
 
 var validate = validator.New()
 
-func lambdaHandler(ctx context.Context, event events.SQSEvent) {
+// deliveryEvent is either a batch of alerts from the queue or the periodic request to send the digests
+type deliveryEvent struct {
+	events.SQSEvent
+	SendDigests bool `json:"sendDigests"`
+}
+
+func lambdaHandler(ctx context.Context, event deliveryEvent) {
 	_, logger := lambdalogger.ConfigureGlobal(ctx, nil)
+	if event.SendDigests {
+		delivery.SendDigests()
+		return
+	}
+
 	var alerts []*models.Alert
 
 	for _, record := range event.Records {
@@ -18,7 +18,9 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
-import "time"
+import (
+	"time"
+)
 
 // RuleType identifies the Alert to be for a Policy
 const RuleType = "RULE"
@@ -61,4 +63,53 @@ type Alert struct {
 
 	// Type specifies if an alert is for a policy or a rule
 	Type *string `json:"type,omitempty" validate:"omitempty,oneof=RULE POLICY"`
+
+	// Dedup is the string computed by the rule to group matches into this alert.
+	Dedup *string `json:"dedup,omitempty"`
+
+	// EventCount is the number of matches that triggered the alert.
+	EventCount *int64 `json:"eventCount,omitempty"`
+
+	// Title is computed by the rule for the match which triggered the alert, the policy name is used if not set.
+	Title *string `json:"title,omitempty"`
+
+	// Context is the information returned by the rule about the match which triggered the alert, e.g. the user.
+	Context []*ContextField `json:"context,omitempty"`
+
+	// LogTypes are the log types analyzed by the rule which triggered the alert.
+	LogTypes []*string `json:"logTypes,omitempty"`
+
+	// ResourceTypes are the types of the resources failing the policy which triggered the alert.
+	ResourceTypes []*string `json:"resourceTypes,omitempty"`
+
+	// IntegrationID is the source integration of the resource failing the policy which triggered the alert.
+	IntegrationID *string `json:"integrationId,omitempty"`
+
+	// ResourceID is the resource failing the policy which triggered the alert.
+	ResourceID *string `json:"resourceId,omitempty"`
+
+	// Resolved is set when the alert is closed or the resource passes the policy again, the outputs which opened
+	// an incident for the alert resolve it instead of sending a new notification.
+	Resolved *bool `json:"resolved,omitempty"`
+
+	// Attempt counts the deliveries of the alert, starting from 1, it is incremented when it is retried.
+	Attempt *int `json:"attempt,omitempty"`
+
+	// Message replaces the default title and body of the notification, it is rendered from the output template.
+	Message *Message `json:"-"`
+}
+
+// ContextField is information returned by a rule about the match which triggered an alert, e.g. the user
+type ContextField struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Message is the notification content rendered from an output template
+type Message struct {
+	// Title is nil if the template has no title
+	Title *string
+
+	// Body is nil if the template has no body
+	Body *string
 }
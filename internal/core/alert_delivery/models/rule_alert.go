@@ -0,0 +1,48 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	analysismodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+)
+
+// NewRuleAlert builds the alert sent to the outputs for the events matched by a rule.
+//
+// The rule information (name, description, runbook, severity, tags) is copied into the alert.
+func NewRuleAlert(rule *analysismodels.Rule, alertID, dedup *string, createdAt *time.Time, eventCount int64) *Alert {
+	return &Alert{
+		CreatedAt:         createdAt,
+		PolicyDescription: aws.String(string(rule.Description)),
+		PolicyID:          aws.String(string(rule.ID)),
+		PolicyName:        aws.String(string(rule.DisplayName)),
+		PolicyVersionID:   aws.String(string(rule.VersionID)),
+		Runbook:           aws.String(string(rule.Runbook)),
+		Severity:          aws.String(string(rule.Severity)),
+		Tags:              aws.StringSlice(rule.Tags),
+		Type:              aws.String(RuleType),
+		AlertID:           alertID,
+		Dedup:             dedup,
+		EventCount:        aws.Int64(eventCount),
+		LogTypes:          aws.StringSlice(rule.LogTypes),
+	}
+}
@@ -0,0 +1,81 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+var (
+	asanaEndpoint = "https://app.asana.com/api/1.0/tasks"
+)
+
+// asanaTaskRequest is the request creating a task, Asana wraps the task in a data object
+type asanaTaskRequest struct {
+	Data asanaTask `json:"data"`
+}
+
+// asanaTask contains the fields of the task created in Asana
+type asanaTask struct {
+	Name     *string   `json:"name"`
+	Notes    string    `json:"notes"`
+	Projects []*string `json:"projects"`
+}
+
+// Asana alert creates a task in the configured projects.
+func (client *OutputClient) Asana(
+	alert *alertmodels.Alert, config *outputmodels.AsanaConfig) *AlertDeliveryError {
+
+	authorization := "Bearer " + *config.PersonalAccessToken
+	accept := "application/json"
+	requestHeader := map[string]*string{
+		"Accept":        &accept,
+		"Authorization": &authorization,
+	}
+
+	description := "Description: " + aws.StringValue(alert.PolicyDescription)
+	link := "\nLink: " + generateURL(alert)
+	runBook := "\nRunbook: " + aws.StringValue(alert.Runbook)
+	severity := "\nSeverity: " + aws.StringValue(alert.Severity)
+	tags := "\nTags: " + strings.Join(aws.StringValueSlice(alert.Tags), ", ")
+	context := generateContextText(alert, "\n%s: %s", plainText)
+
+	notes := description + link + runBook + severity + tags + context
+	if templatedBody := templateBody(alert); templatedBody != nil {
+		notes = *templatedBody
+	}
+
+	postInput := &PostInput{
+		url: &asanaEndpoint,
+		body: &asanaTaskRequest{
+			Data: asanaTask{
+				Name:     generateAlertTitle(alert),
+				Notes:    notes,
+				Projects: config.ProjectGids,
+			},
+		},
+		headers: requestHeader,
+	}
+	return client.httpWrapper.post(postInput)
+}
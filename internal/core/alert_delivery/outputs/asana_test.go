@@ -0,0 +1,108 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+var asanaConfig = &outputmodels.AsanaConfig{
+	PersonalAccessToken: aws.String("token"),
+	ProjectGids:         aws.StringSlice([]string{"1199906407794339", "1199906407794340"}),
+}
+
+func TestAsanaAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	policyURLPrefix = "https://panther.io/policies/"
+	alert := &alertmodels.Alert{
+		PolicyID:          aws.String("policyId"),
+		PolicyName:        aws.String("S3 Encryption"),
+		PolicyDescription: aws.String("description"),
+		Severity:          aws.String("MEDIUM"),
+		Context:           []*alertmodels.ContextField{{Key: "bucket", Value: "logs"}},
+	}
+
+	expectedPostInput := &PostInput{
+		url: aws.String("https://app.asana.com/api/1.0/tasks"),
+		body: &asanaTaskRequest{
+			Data: asanaTask{
+				Name: aws.String("Policy Failure: S3 Encryption"),
+				Notes: "Description: description\nLink: https://panther.io/policies/policyId\n" +
+					"Runbook: \nSeverity: MEDIUM\nTags: \nbucket: logs",
+				Projects: asanaConfig.ProjectGids,
+			},
+		},
+		headers: map[string]*string{
+			"Accept":        aws.String("application/json"),
+			"Authorization": aws.String("Bearer token"),
+		},
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	assert.Nil(t, client.Asana(alert, asanaConfig))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestAsanaServer(t *testing.T) {
+	var request *http.Request
+	var requestBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		requestBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	defaultEndpoint := asanaEndpoint
+	asanaEndpoint = server.URL + "/api/1.0/tasks"
+	defer func() { asanaEndpoint = defaultEndpoint }()
+
+	client := &OutputClient{httpWrapper: &HTTPWrapper{httpClient: server.Client()}}
+	alert := &alertmodels.Alert{
+		AlertID:    aws.String("alertId"),
+		PolicyID:   aws.String("ruleId"),
+		PolicyName: aws.String("Root Login"),
+		Severity:   aws.String("HIGH"),
+		Type:       aws.String(alertmodels.RuleType),
+		Message:    &alertmodels.Message{Body: aws.String("templated body")},
+	}
+	require.Nil(t, client.Asana(alert, asanaConfig))
+
+	assert.Equal(t, "/api/1.0/tasks", request.URL.Path)
+	assert.Equal(t, "Bearer token", request.Header.Get("Authorization"))
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+	var task asanaTaskRequest
+	require.NoError(t, jsoniter.Unmarshal(requestBody, &task))
+	assert.Equal(t, "New Alert: Root Login", *task.Data.Name)
+	assert.Equal(t, "templated body", task.Data.Notes)
+	assert.Equal(t, asanaConfig.ProjectGids, task.Data.Projects)
+}
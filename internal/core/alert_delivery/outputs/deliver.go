@@ -0,0 +1,90 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// resolvableOutputs are the output types with incidents which are resolved when the alert is resolved.
+var resolvableOutputs = map[string]bool{
+	"pagerduty": true,
+	"opsgenie":  true,
+	"jira":      true,
+	"github":    true,
+}
+
+// Resolvable returns true if the output type supports resolving the incidents opened for an alert.
+func Resolvable(outputType string) bool {
+	return resolvableOutputs[outputType]
+}
+
+// Deliver sends an alert to an output with the method of the output type.
+//
+// The message is rendered from the output template if it has one, the alert itself is not modified.
+// A message already set on the alert, e.g. a digest, is not replaced by the template.
+func Deliver(client API, alert *alertmodels.Alert, output *outputmodels.AlertOutput) *AlertDeliveryError {
+	if output.OutputConfig.Template != nil && alert.Message == nil {
+		message, err := RenderMessage(alert, output.OutputConfig.Template)
+		if err != nil {
+			// The templates are validated when the output is saved, fall back to the default message
+			zap.L().Warn("failed to render output template",
+				zap.String("outputID", aws.StringValue(output.OutputID)), zap.Error(err))
+		} else {
+			templatedAlert := *alert
+			templatedAlert.Message = message
+			alert = &templatedAlert
+		}
+	}
+
+	switch aws.StringValue(output.OutputType) {
+	case "email":
+		return client.Email(alert, output.OutputConfig.Email)
+	case "slack":
+		return client.Slack(alert, output.OutputConfig.Slack)
+	case "pagerduty":
+		return client.PagerDuty(alert, output.OutputConfig.PagerDuty)
+	case "github":
+		return client.Github(alert, output.OutputConfig.Github)
+	case "opsgenie":
+		return client.Opsgenie(alert, output.OutputConfig.Opsgenie)
+	case "jira":
+		return client.Jira(alert, output.OutputConfig.Jira)
+	case "msteams":
+		return client.MsTeams(alert, output.OutputConfig.MsTeams)
+	case "sqs":
+		return client.Sqs(alert, output.OutputConfig.Sqs)
+	case "sns":
+		return client.Sns(alert, output.OutputConfig.Sns)
+	case "webhook":
+		return client.Webhook(alert, output.OutputConfig.Webhook)
+	case "servicenow":
+		return client.ServiceNow(alert, output.OutputConfig.ServiceNow)
+	case "asana":
+		return client.Asana(alert, output.OutputConfig.Asana)
+	case "splunkhec":
+		return client.SplunkHEC(alert, output.OutputConfig.SplunkHEC)
+	default:
+		return &AlertDeliveryError{Message: "unsupported output type: " + aws.StringValue(output.OutputType), Permanent: true}
+	}
+}
@@ -0,0 +1,91 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+type mockOutputClient struct {
+	API
+	mock.Mock
+}
+
+func (m *mockOutputClient) Slack(alert *alertmodels.Alert, config *outputmodels.SlackConfig) *AlertDeliveryError {
+	args := m.Called(alert, config)
+	return args.Get(0).(*AlertDeliveryError)
+}
+
+func TestDeliver(t *testing.T) {
+	client := &mockOutputClient{}
+	config := &outputmodels.SlackConfig{WebhookURL: aws.String("https://slack.com")}
+	output := &outputmodels.AlertOutput{
+		OutputID:     aws.String("output-id"),
+		OutputType:   aws.String("slack"),
+		OutputConfig: &outputmodels.OutputConfig{Slack: config},
+	}
+	alert := &alertmodels.Alert{PolicyID: aws.String("rule-id"), Severity: aws.String("INFO")}
+	client.On("Slack", alert, config).Return((*AlertDeliveryError)(nil)).Once()
+
+	assert.Nil(t, Deliver(client, alert, output))
+	client.AssertExpectations(t)
+}
+
+func TestDeliverTemplated(t *testing.T) {
+	client := &mockOutputClient{}
+	config := &outputmodels.SlackConfig{WebhookURL: aws.String("https://slack.com")}
+	output := &outputmodels.AlertOutput{
+		OutputID:   aws.String("output-id"),
+		OutputType: aws.String("slack"),
+		OutputConfig: &outputmodels.OutputConfig{
+			Slack:    config,
+			Template: &outputmodels.MessageTemplate{Title: aws.String("{{.Severity}} alert")},
+		},
+	}
+	alert := &alertmodels.Alert{PolicyID: aws.String("rule-id"), Severity: aws.String("INFO")}
+	client.On("Slack", mock.Anything, config).Return((*AlertDeliveryError)(nil)).Once()
+
+	assert.Nil(t, Deliver(client, alert, output))
+	sent := client.Calls[0].Arguments[0].(*alertmodels.Alert)
+	require.NotNil(t, sent.Message)
+	assert.Equal(t, "INFO alert", *sent.Message.Title)
+	// The alert shared by the outputs is not modified
+	assert.Nil(t, alert.Message)
+	client.AssertExpectations(t)
+}
+
+func TestDeliverUnsupportedType(t *testing.T) {
+	output := &outputmodels.AlertOutput{
+		OutputType:   aws.String("carrier-pigeon"),
+		OutputConfig: &outputmodels.OutputConfig{},
+	}
+
+	err := Deliver(&mockOutputClient{}, &alertmodels.Alert{}, output)
+	require.NotNil(t, err)
+	assert.True(t, err.Permanent)
+	assert.Equal(t, "unsupported output type: carrier-pigeon", err.Message)
+}
@@ -0,0 +1,64 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// severityRank orders the severities, a digest has the highest severity of its alerts
+var severityRank = map[string]int{
+	"INFO":     0,
+	"LOW":      1,
+	"MEDIUM":   2,
+	"HIGH":     3,
+	"CRITICAL": 4,
+}
+
+// NewDigest combines the alerts held back by the rate limit of an output into a single alert.
+//
+// The digest is a copy of the latest alert with the highest severity of all the alerts and a message listing them.
+func NewDigest(alerts []*alertmodels.Alert, outputName string) *alertmodels.Alert {
+	digest := *alerts[len(alerts)-1]
+	digest.Context = nil
+	digest.Attempt = nil
+
+	var body strings.Builder
+	for _, alert := range alerts {
+		if severityRank[aws.StringValue(alert.Severity)] > severityRank[aws.StringValue(digest.Severity)] {
+			digest.Severity = alert.Severity
+		}
+		title := getDisplayName(alert)
+		if aws.StringValue(alert.Title) != "" {
+			title = *alert.Title
+		}
+		body.WriteString(fmt.Sprintf("[%s] %s %s\n", aws.StringValue(alert.Severity), title, generateURL(alert)))
+	}
+
+	digest.Message = &alertmodels.Message{
+		Title: aws.String(fmt.Sprintf("Digest: %d alerts exceeded the rate limit of %s", len(alerts), outputName)),
+		Body:  aws.String(strings.TrimSuffix(body.String(), "\n")),
+	}
+	return &digest
+}
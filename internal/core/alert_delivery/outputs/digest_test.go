@@ -0,0 +1,86 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+func TestNewDigest(t *testing.T) {
+	alertURLPrefix = "https://panther.io/alerts/"
+	alerts := []*alertmodels.Alert{
+		{
+			AlertID:  aws.String("alert-1"),
+			PolicyID: aws.String("rule-1"),
+			Severity: aws.String("HIGH"),
+			Type:     aws.String(alertmodels.RuleType),
+			Title:    aws.String("Root login from 1.2.3.4"),
+		},
+		{
+			AlertID:    aws.String("alert-2"),
+			PolicyID:   aws.String("rule-2"),
+			PolicyName: aws.String("Failed Logins"),
+			Severity:   aws.String("LOW"),
+			Type:       aws.String(alertmodels.RuleType),
+			Context:    []*alertmodels.ContextField{{Key: "user", Value: "bob"}},
+			Attempt:    aws.Int(2),
+		},
+	}
+
+	digest := NewDigest(alerts, "alerts-channel")
+	assert.Equal(t, "alert-2", *digest.AlertID)
+	assert.Equal(t, "HIGH", *digest.Severity)
+	assert.Nil(t, digest.Context)
+	assert.Nil(t, digest.Attempt)
+	require.NotNil(t, digest.Message)
+	assert.Equal(t, "Digest: 2 alerts exceeded the rate limit of alerts-channel", *digest.Message.Title)
+	assert.Equal(t,
+		"[HIGH] Root login from 1.2.3.4 https://panther.io/alerts/alert-1\n"+
+			"[LOW] Failed Logins https://panther.io/alerts/alert-2",
+		*digest.Message.Body)
+	// The alerts are not modified
+	assert.Equal(t, "LOW", *alerts[1].Severity)
+	assert.Nil(t, alerts[1].Message)
+}
+
+func TestDeliverDigestIgnoresTemplate(t *testing.T) {
+	client := &mockOutputClient{}
+	config := &outputmodels.SlackConfig{WebhookURL: aws.String("https://slack.com")}
+	output := &outputmodels.AlertOutput{
+		OutputID:   aws.String("output-id"),
+		OutputType: aws.String("slack"),
+		OutputConfig: &outputmodels.OutputConfig{
+			Slack:    config,
+			Template: &outputmodels.MessageTemplate{Title: aws.String("{{.Severity}} alert")},
+		},
+	}
+	message := &alertmodels.Message{Title: aws.String("Digest")}
+	alert := &alertmodels.Alert{PolicyID: aws.String("rule-id"), Severity: aws.String("INFO"), Message: message}
+	client.On("Slack", alert, config).Return((*AlertDeliveryError)(nil)).Once()
+
+	assert.Nil(t, Deliver(client, alert, output))
+	client.AssertExpectations(t)
+}
@@ -20,7 +20,9 @@ package outputs
 
 import (
 	"fmt"
+	"html"
 	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -35,19 +37,30 @@ const emailTemplate = "<h2>Message</h2>%s<br>" +
 	"<h2>Runbook</h2>%s<br>" +
 	"<h2>Description</h2>%s"
 
+const emailContextTemplate = "<li><b>%s:</b> %s</li>"
+
 var sesConfigurationSet = os.Getenv("SES_CONFIGURATION_SET")
 
 func generateEmailContent(alert *alertmodels.Alert) *string {
+	// The templated body is plain text rendered from the alert, it is escaped
+	if body := templateBody(alert); body != nil {
+		return aws.String(strings.ReplaceAll(html.EscapeString(*body), "\n", "<br>"))
+	}
+
 	messageField := fmt.Sprintf("<a href='%s'>%s</a>",
 		generateURL(alert),
 		aws.StringValue(generateAlertMessage(alert)))
-	return aws.String(fmt.Sprintf(
+	content := fmt.Sprintf(
 		emailTemplate,
 		messageField,
 		aws.StringValue(alert.Severity),
 		aws.StringValue(alert.Runbook),
 		aws.StringValue(alert.PolicyDescription),
-	))
+	)
+	if len(alert.Context) > 0 {
+		content += "<br><h2>Context</h2><ul>" + generateContextText(alert, emailContextTemplate, html.EscapeString) + "</ul>"
+	}
+	return aws.String(content)
 }
 
 // Email sends email to destination
@@ -131,6 +131,29 @@ func TestSendEmailRule(t *testing.T) {
 	client.AssertExpectations(t)
 }
 
+func TestGenerateEmailContentContext(t *testing.T) {
+	alert := &alertmodels.Alert{
+		AlertID:  aws.String("alertId"),
+		PolicyID: aws.String("policyId"),
+		Severity: aws.String("HIGH"),
+		Type:     aws.String(alertmodels.RuleType),
+		Context:  []*alertmodels.ContextField{{Key: "user", Value: "<script>alice</script>"}},
+	}
+
+	// The context is computed from the event, it is escaped
+	content := *generateEmailContent(alert)
+	assert.Contains(t, content, "<h2>Context</h2><ul><li><b>user:</b> &lt;script&gt;alice&lt;/script&gt;</li></ul>")
+}
+
+func TestGenerateEmailContentTemplated(t *testing.T) {
+	alert := &alertmodels.Alert{
+		PolicyID: aws.String("policyId"),
+		Severity: aws.String("HIGH"),
+		Message:  &alertmodels.Message{Body: aws.String("<b>root</b> logged in\nfrom 1.2.3.4")},
+	}
+	assert.Equal(t, "&lt;b&gt;root&lt;/b&gt; logged in<br>from 1.2.3.4", *generateEmailContent(alert))
+}
+
 func TestSendEmailPermanentError(t *testing.T) {
 	client := &mockSesClient{}
 	outputClient := &OutputClient{sesClient: client}
@@ -19,6 +19,9 @@ package outputs
  */
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -33,10 +36,35 @@ const (
 	requestType    = "/issues"
 )
 
+// The characters GitHub parses as markdown, HTML and mentions, e.g. "[login](https://evil.com)"
+var githubEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "~", `\~`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"#", `\#`, "|", `\|`, "<", `\<`, ">", `\>`, "&", `\&`, "@", `\@`,
+)
+
+// githubIssue is the part of the GitHub issue used to resolve an incident
+type githubIssue struct {
+	Number int `json:"number"`
+}
+
 // Github alert send an issue.
+//
+// The issues are labeled with the incident key of the alert, a resolved alert closes the open issues with the label.
 func (client *OutputClient) Github(
 	alert *alertmodels.Alert, config *outputmodels.GithubConfig) *AlertDeliveryError {
 
+	accept := "application/json"
+	token := "token " + *config.Token
+	repoURL := githubEndpoint + *config.RepoName + requestType
+	requestHeader := map[string]*string{
+		"Authorization": &token,
+		"Accept":        &accept,
+	}
+
+	if aws.BoolValue(alert.Resolved) {
+		return client.resolveGithub(alert, repoURL, requestHeader)
+	}
+
 	var tagsItem = aws.StringValueSlice(alert.Tags)
 
 	description := "**Description:** " + aws.StringValue(alert.PolicyDescription)
@@ -44,18 +72,18 @@ func (client *OutputClient) Github(
 	runBook := "\n **Runbook:** " + aws.StringValue(alert.Runbook)
 	severity := "\n **Severity:** " + aws.StringValue(alert.Severity)
 	tags := "\n **Tags:** " + strings.Join(tagsItem, ", ")
+	context := generateContextText(alert, "\n **%s:** %s", githubEscaper.Replace)
 
-	githubRequest := map[string]interface{}{
-		"title": aws.StringValue(generateAlertTitle(alert)),
-		"body":  description + link + runBook + severity + tags,
+	body := description + link + runBook + severity + tags + context
+	if templatedBody := templateBody(alert); templatedBody != nil {
+		body = *templatedBody
 	}
 
-	accept := "application/json"
-	token := "token " + *config.Token
-	repoURL := githubEndpoint + *config.RepoName + requestType
-	requestHeader := map[string]*string{
-		"Authorization": &token,
-		"Accept":        &accept,
+	githubRequest := map[string]interface{}{
+		// The title is plain text, only the body is rendered as markdown
+		"title":  aws.StringValue(generateAlertTitle(alert)),
+		"body":   body,
+		"labels": []string{incidentKey(alert)},
 	}
 
 	postInput := &PostInput{
@@ -65,3 +93,37 @@ func (client *OutputClient) Github(
 	}
 	return client.httpWrapper.post(postInput)
 }
+
+// resolveGithub comments on the open issues of the incident and closes them
+func (client *OutputClient) resolveGithub(
+	alert *alertmodels.Alert, issuesURL string, headers map[string]*string) *AlertDeliveryError {
+
+	listURL := issuesURL + "?state=open&labels=" + url.QueryEscape(incidentKey(alert))
+	var issues []githubIssue
+	if err := client.httpWrapper.send(http.MethodGet, &PostInput{url: &listURL, headers: headers}, &issues); err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		commentURL := fmt.Sprintf("%s/%d/comments", issuesURL, issue.Number)
+		comment := &PostInput{
+			url:     &commentURL,
+			body:    map[string]string{"body": generateResolvedText(alert)},
+			headers: headers,
+		}
+		if err := client.httpWrapper.send(http.MethodPost, comment, nil); err != nil {
+			return err
+		}
+
+		issueURL := fmt.Sprintf("%s/%d", issuesURL, issue.Number)
+		closeIssue := &PostInput{
+			url:     &issueURL,
+			body:    map[string]string{"state": "closed"},
+			headers: headers,
+		}
+		if err := client.httpWrapper.send(http.MethodPatch, closeIssue, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
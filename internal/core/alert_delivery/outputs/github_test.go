@@ -19,10 +19,14 @@ package outputs
  */
 
 import (
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -50,6 +54,7 @@ func TestGithubAlert(t *testing.T) {
 		"body": "**Description:** description\n " +
 			"[Click here to view in the Panther UI](https://panther.io/policies/ruleId)\n" +
 			" **Runbook:** \n **Severity:** INFO\n **Tags:** ",
+		"labels": []string{incidentKey(alert)},
 	}
 
 	authorization := "token " + *githubConfig.Token
@@ -70,3 +75,38 @@ func TestGithubAlert(t *testing.T) {
 	require.Nil(t, client.Github(alert, githubConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestGithubResolveAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	alert := resolvedAlert()
+
+	issuesURL := "https://api.github.com/repos/profile/reponame/issues"
+	httpWrapper.On("send", http.MethodGet, urlIs(issuesURL+"?state=open&labels="+incidentKey(alert))).Return(
+		(*AlertDeliveryError)(nil), `[{"number": 7}]`).Once()
+	httpWrapper.On("send", http.MethodPost, urlIs(issuesURL+"/7/comments")).Return((*AlertDeliveryError)(nil), "").Once()
+	httpWrapper.On("send", http.MethodPatch, mock.MatchedBy(func(input *PostInput) bool {
+		return *input.url == issuesURL+"/7" && input.body.(map[string]string)["state"] == "closed"
+	})).Return((*AlertDeliveryError)(nil), "").Once()
+
+	require.Nil(t, client.Github(alert, githubConfig))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestGithubAlertContextEscaped(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alert := &alertmodels.Alert{
+		PolicyID: aws.String("ruleId"),
+		Severity: aws.String("INFO"),
+		Context:  []*alertmodels.ContextField{{Key: "user_name", Value: "**@admin** [login](https://evil.com) <img>"}},
+	}
+	httpWrapper.On("post", mock.Anything).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.Github(alert, githubConfig))
+	body := httpWrapper.Calls[0].Arguments[0].(*PostInput).body.(map[string]interface{})
+	assert.True(t, strings.HasSuffix(body["body"].(string),
+		"\n **"+`user\_name:** \*\*\@admin\*\* \[login\]\(https://evil.com\) \<img\>`), body["body"])
+	httpWrapper.AssertExpectations(t)
+}
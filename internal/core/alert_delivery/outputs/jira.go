@@ -20,6 +20,8 @@ package outputs
 
 import (
 	"encoding/base64"
+	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -29,13 +31,53 @@ import (
 )
 
 const (
-	jiraEndpoint = "/rest/api/latest/issue/"
+	jiraEndpoint       = "/rest/api/latest/issue/"
+	jiraSearchEndpoint = "/rest/api/latest/search"
 )
 
+// The characters Jira parses as wiki markup, e.g. "[login|https://evil.com]"
+var jiraEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "-", `\-`, "+", `\+`, "^", `\^`, "~", `\~`,
+	"{", `\{`, "}", `\}`, "[", `\[`, "]", `\]`, "|", `\|`, "!", `\!`, "#", `\#`,
+)
+
+// jiraSearchResult is the part of the Jira search response used to find the issues of an incident
+type jiraSearchResult struct {
+	Issues []struct {
+		Key string `json:"key"`
+	} `json:"issues"`
+}
+
+// jiraTransitions is the part of the Jira transitions response used to find the transition to done
+type jiraTransitions struct {
+	Transitions []struct {
+		ID string `json:"id"`
+		To struct {
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"to"`
+	} `json:"transitions"`
+}
+
 // Jira alert send an issue.
+//
+// The issues are labeled with the incident key of the alert, a resolved alert closes the open issues with the label.
 func (client *OutputClient) Jira(
 	alert *alertmodels.Alert, config *outputmodels.JiraConfig) *AlertDeliveryError {
 
+	auth := *config.UserName + ":" + *config.APIKey
+	basicAuthToken := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
+	accept := "application/json"
+	requestHeader := map[string]*string{
+		"Accept":        &accept,
+		"Authorization": &basicAuthToken,
+	}
+
+	if aws.BoolValue(alert.Resolved) {
+		return client.resolveJira(alert, config, requestHeader)
+	}
+
 	var tagsItem = aws.StringValueSlice(alert.Tags)
 
 	description := "*Description:* " + aws.StringValue(alert.PolicyDescription)
@@ -43,16 +85,24 @@ func (client *OutputClient) Jira(
 	runBook := "\n *Runbook:* " + aws.StringValue(alert.Runbook)
 	severity := "\n *Severity:* " + aws.StringValue(alert.Severity)
 	tags := "\n *Tags:* " + strings.Join(tagsItem, ", ")
+	context := generateContextText(alert, "\n *%s:* %s", jiraEscaper.Replace)
+
+	body := description + link + runBook + severity + tags + context
+	if templatedBody := templateBody(alert); templatedBody != nil {
+		body = *templatedBody
+	}
 
 	fields := map[string]interface{}{
+		// The summary is plain text, only the description is rendered as markup
 		"summary":     *generateAlertTitle(alert),
-		"description": description + link + runBook + severity + tags,
+		"description": body,
 		"project": map[string]*string{
 			"key": config.ProjectKey,
 		},
 		"issuetype": map[string]string{
 			"name": "Task",
 		},
+		"labels": []string{incidentKey(alert)},
 	}
 
 	if config.AssigneeID != nil {
@@ -65,15 +115,7 @@ func (client *OutputClient) Jira(
 		"fields": fields,
 	}
 
-	auth := *config.UserName + ":" + *config.APIKey
-	basicAuthToken := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
-	accept := "application/json"
 	jiraRestURL := *config.OrgDomain + jiraEndpoint
-	requestHeader := map[string]*string{
-		"Accept":        &accept,
-		"Authorization": &basicAuthToken,
-	}
-
 	postInput := &PostInput{
 		url:     &jiraRestURL,
 		body:    jiraRequest,
@@ -81,3 +123,50 @@ func (client *OutputClient) Jira(
 	}
 	return client.httpWrapper.post(postInput)
 }
+
+// resolveJira comments on the open issues of the incident and transitions them to done, if the workflow allows it
+func (client *OutputClient) resolveJira(
+	alert *alertmodels.Alert, config *outputmodels.JiraConfig, headers map[string]*string) *AlertDeliveryError {
+
+	jql := `labels = "` + incidentKey(alert) + `" AND statusCategory != Done`
+	searchURL := *config.OrgDomain + jiraSearchEndpoint + "?fields=key&jql=" + url.QueryEscape(jql)
+	var result jiraSearchResult
+	if err := client.httpWrapper.send(http.MethodGet, &PostInput{url: &searchURL, headers: headers}, &result); err != nil {
+		return err
+	}
+
+	for _, issue := range result.Issues {
+		commentURL := *config.OrgDomain + jiraEndpoint + issue.Key + "/comment"
+		comment := &PostInput{
+			url:     &commentURL,
+			body:    map[string]string{"body": generateResolvedText(alert)},
+			headers: headers,
+		}
+		if err := client.httpWrapper.send(http.MethodPost, comment, nil); err != nil {
+			return err
+		}
+
+		transitionsURL := *config.OrgDomain + jiraEndpoint + issue.Key + "/transitions"
+		var transitions jiraTransitions
+		if err := client.httpWrapper.send(
+			http.MethodGet, &PostInput{url: &transitionsURL, headers: headers}, &transitions); err != nil {
+
+			return err
+		}
+		for _, transition := range transitions.Transitions {
+			if transition.To.StatusCategory.Key != "done" {
+				continue
+			}
+			transitionInput := &PostInput{
+				url:     &transitionsURL,
+				body:    map[string]interface{}{"transition": map[string]string{"id": transition.ID}},
+				headers: headers,
+			}
+			if err := client.httpWrapper.send(http.MethodPost, transitionInput, nil); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
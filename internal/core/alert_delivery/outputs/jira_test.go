@@ -20,10 +20,15 @@ package outputs
 
 import (
 	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -63,6 +68,7 @@ func TestJiraAlert(t *testing.T) {
 			"issuetype": map[string]string{
 				"name": "Task",
 			},
+			"labels": []string{incidentKey(alert)},
 			"assignee": map[string]*string{
 				"id": jiraConfig.AssigneeID,
 			},
@@ -87,3 +93,55 @@ func TestJiraAlert(t *testing.T) {
 	require.Nil(t, client.Jira(alert, jiraConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestJiraResolveAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	alert := resolvedAlert()
+
+	searchURL := "https://panther-labs.atlassian.net/rest/api/latest/search?fields=key&jql=" +
+		url.QueryEscape(`labels = "`+incidentKey(alert)+`" AND statusCategory != Done`)
+	issueURL := "https://panther-labs.atlassian.net/rest/api/latest/issue/QR-1"
+	httpWrapper.On("send", http.MethodGet, urlIs(searchURL)).Return(
+		(*AlertDeliveryError)(nil), `{"issues": [{"key": "QR-1"}]}`).Once()
+	httpWrapper.On("send", http.MethodPost, urlIs(issueURL+"/comment")).Return((*AlertDeliveryError)(nil), "").Once()
+	httpWrapper.On("send", http.MethodGet, urlIs(issueURL+"/transitions")).Return((*AlertDeliveryError)(nil),
+		`{"transitions": [{"id": "11", "to": {"statusCategory": {"key": "indeterminate"}}}, `+
+			`{"id": "31", "to": {"statusCategory": {"key": "done"}}}]}`).Once()
+	httpWrapper.On("send", http.MethodPost, mock.MatchedBy(func(input *PostInput) bool {
+		return *input.url == issueURL+"/transitions" &&
+			assert.ObjectsAreEqual(map[string]interface{}{"transition": map[string]string{"id": "31"}}, input.body)
+	})).Return((*AlertDeliveryError)(nil), "").Once()
+
+	require.Nil(t, client.Jira(alert, jiraConfig))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestJiraResolveAlertSearchFails(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	deliveryErr := &AlertDeliveryError{Message: "request failed: 401 Unauthorized", StatusCode: 401}
+	httpWrapper.On("send", http.MethodGet, mock.Anything).Return(deliveryErr, "").Once()
+
+	require.Equal(t, deliveryErr, client.Jira(resolvedAlert(), jiraConfig))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestJiraAlertContextEscaped(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alert := &alertmodels.Alert{
+		PolicyID: aws.String("ruleId"),
+		Severity: aws.String("INFO"),
+		Context:  []*alertmodels.ContextField{{Key: "user_name", Value: "*admin* [login|https://evil.com] {code}"}},
+	}
+	httpWrapper.On("post", mock.Anything).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.Jira(alert, jiraConfig))
+	fields := httpWrapper.Calls[0].Arguments[0].(*PostInput).body.(map[string]interface{})["fields"].(map[string]interface{})
+	assert.True(t, strings.HasSuffix(fields["description"].(string),
+		"\n *"+`user\_name:* \*admin\* \[login\|https://evil.com\] \{code\}`), fields["description"])
+	httpWrapper.AssertExpectations(t)
+}
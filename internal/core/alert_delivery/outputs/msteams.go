@@ -27,6 +27,12 @@ import (
 	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
 )
 
+// The characters Microsoft Teams parses as HTML and markdown
+var msTeamsEscaper = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;",
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "#", `\#`,
+)
+
 // MsTeams alert send an alert.
 func (client *OutputClient) MsTeams(
 	alert *alertmodels.Alert, config *outputmodels.MsTeamsConfig) *AlertDeliveryError {
@@ -38,22 +44,34 @@ func (client *OutputClient) MsTeams(
 	ruleDescription := aws.StringValue(alert.PolicyDescription)
 	severity := aws.StringValue(alert.Severity)
 	tags := strings.Join(tagsItem, ", ")
+	facts := []interface{}{
+		map[string]string{"name": "Description", "value": ruleDescription},
+		map[string]string{"name": "Runbook", "value": runBook},
+		map[string]string{"name": "Severity", "value": severity},
+		map[string]string{"name": "Tags", "value": tags},
+	}
+	// The title and the context are computed by the rule from the event, they are escaped
+	for _, field := range alert.Context {
+		facts = append(facts, map[string]string{
+			"name":  msTeamsEscaper.Replace(field.Key),
+			"value": msTeamsEscaper.Replace(field.Value),
+		})
+	}
+
+	section := map[string]interface{}{
+		"facts": facts,
+		"text":  link,
+	}
+	// The templated body replaces the default facts
+	if body := templateBody(alert); body != nil {
+		section = map[string]interface{}{"text": *body}
+	}
 
 	msTeamsRequestBody := map[string]interface{}{
 		"@context": "http://schema.org/extensions",
 		"@type":    "MessageCard",
-		"text":     *generateAlertTitle(alert),
-		"sections": []interface{}{
-			map[string]interface{}{
-				"facts": []interface{}{
-					map[string]string{"name": "Description", "value": ruleDescription},
-					map[string]string{"name": "Runbook", "value": runBook},
-					map[string]string{"name": "Severity", "value": severity},
-					map[string]string{"name": "Tags", "value": tags},
-				},
-				"text": link,
-			},
-		},
+		"text":     msTeamsEscaper.Replace(*generateAlertTitle(alert)),
+		"sections": []interface{}{section},
 		"potentialAction": []interface{}{
 			map[string]interface{}{
 				"@type": "OpenUri",
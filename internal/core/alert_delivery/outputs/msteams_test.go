@@ -23,6 +23,8 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -91,3 +93,28 @@ func TestMsTeamsAlert(t *testing.T) {
 	require.Nil(t, client.MsTeams(alert, msTeamConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestMsTeamsAlertContextEscaped(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alert := &alertmodels.Alert{
+		AlertID:  aws.String("alertId"),
+		PolicyID: aws.String("policyId"),
+		Type:     aws.String(alertmodels.RuleType),
+		Title:    aws.String("[login](https://evil.com)"),
+		Severity: aws.String("INFO"),
+		Context:  []*alertmodels.ContextField{{Key: "user_name", Value: "**admin** [login](https://evil.com) <b>"}},
+	}
+	httpWrapper.On("post", mock.Anything).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.MsTeams(alert, msTeamConfig))
+	body := httpWrapper.Calls[0].Arguments[0].(*PostInput).body.(map[string]interface{})
+	assert.Equal(t, `New Alert: \[login\]\(https://evil.com\)`, body["text"])
+	facts := body["sections"].([]interface{})[0].(map[string]interface{})["facts"].([]interface{})
+	assert.Equal(t, map[string]string{
+		"name":  `user\_name`,
+		"value": `\*\*admin\*\* \[login\]\(https://evil.com\) &lt;b&gt;`,
+	}, facts[len(facts)-1])
+	httpWrapper.AssertExpectations(t)
+}
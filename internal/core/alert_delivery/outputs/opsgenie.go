@@ -38,9 +38,30 @@ var pantherToOpsGeniePriority = map[string]string{
 }
 
 // Opsgenie alert send an alert.
+//
+// The alerts are identified by the incident key of the alert as alias, a resolved alert closes the Opsgenie alert.
 func (client *OutputClient) Opsgenie(
 	alert *alertmodels.Alert, config *outputmodels.OpsgenieConfig) *AlertDeliveryError {
 
+	authorization := "GenieKey " + *config.APIKey
+	accept := "application/json"
+	requestHeader := map[string]*string{
+		"Accept":        &accept,
+		"Authorization": &authorization,
+	}
+
+	if aws.BoolValue(alert.Resolved) {
+		closeURL := opsgenieEndpoint + "/" + incidentKey(alert) + "/close?identifierType=alias"
+		return client.httpWrapper.post(&PostInput{
+			url: &closeURL,
+			body: map[string]string{
+				"source": "Panther",
+				"note":   generateResolvedText(alert),
+			},
+			headers: requestHeader,
+		})
+	}
+
 	tagsItem := aws.StringValueSlice(alert.Tags)
 
 	description := "<strong>Description:</strong> " + aws.StringValue(alert.PolicyDescription)
@@ -48,17 +69,24 @@ func (client *OutputClient) Opsgenie(
 	runBook := "\n <strong>Runbook:</strong> " + aws.StringValue(alert.Runbook)
 	severity := "\n <strong>Severity:</strong> " + aws.StringValue(alert.Severity)
 
+	body := description + link + runBook + severity
+	if templatedBody := templateBody(alert); templatedBody != nil {
+		body = *templatedBody
+	}
+
 	opsgenieRequest := map[string]interface{}{
+		"alias":       incidentKey(alert),
 		"message":     *generateAlertTitle(alert),
-		"description": description + link + runBook + severity,
+		"description": body,
 		"tags":        tagsItem,
 		"priority":    pantherToOpsGeniePriority[aws.StringValue(alert.Severity)],
 	}
-	authorization := "GenieKey " + *config.APIKey
-	accept := "application/json"
-	requestHeader := map[string]*string{
-		"Accept":        &accept,
-		"Authorization": &authorization,
+	if len(alert.Context) > 0 {
+		details := make(map[string]string, len(alert.Context))
+		for _, field := range alert.Context {
+			details[field.Key] = field.Value
+		}
+		opsgenieRequest["details"] = details
 	}
 
 	postInput := &PostInput{
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -46,6 +47,7 @@ func TestOpsgenieAlert(t *testing.T) {
 	}
 
 	opsgenieRequest := map[string]interface{}{
+		"alias":   incidentKey(alert),
 		"message": "Policy Failure: policyName",
 		"description": strings.Join([]string{
 			"<strong>Description:</strong> ",
@@ -76,3 +78,19 @@ func TestOpsgenieAlert(t *testing.T) {
 	require.Nil(t, client.Opsgenie(alert, opsgenieConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestOpsgenieResolveAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	alertURLPrefix = "https://panther.io/alerts/"
+	alert := resolvedAlert()
+
+	closeURL := "https://api.opsgenie.com/v2/alerts/" + incidentKey(alert) + "/close?identifierType=alias"
+	httpWrapper.On("post", mock.MatchedBy(func(input *PostInput) bool {
+		return *input.url == closeURL && input.body.(map[string]string)["note"] ==
+			"The alert was closed in Panther: https://panther.io/alerts/alert-id"
+	})).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.Opsgenie(alert, opsgenieConfig))
+	httpWrapper.AssertExpectations(t)
+}
@@ -19,8 +19,12 @@ package outputs
  */
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -46,13 +50,16 @@ type HTTPWrapper struct {
 // PostInput type
 type PostInput struct {
 	url     *string
-	body    map[string]interface{}
+	body    interface{} // serialized to JSON
 	headers map[string]*string
+	// When set, the body is signed with this secret in the signature header
+	signingSecret *string
 }
 
 // HTTPWrapperiface is the interface for our wrapper around Golang's http client
 type HTTPWrapperiface interface {
 	post(*PostInput) *AlertDeliveryError
+	send(method string, input *PostInput, output interface{}) *AlertDeliveryError
 }
 
 // HTTPiface is an interface for http.Client to simplify unit testing.
@@ -71,6 +78,10 @@ type API interface {
 	MsTeams(*alertmodels.Alert, *outputmodels.MsTeamsConfig) *AlertDeliveryError
 	Sqs(*alertmodels.Alert, *outputmodels.SqsConfig) *AlertDeliveryError
 	Sns(*alertmodels.Alert, *outputmodels.SnsConfig) *AlertDeliveryError
+	Webhook(*alertmodels.Alert, *outputmodels.WebhookConfig) *AlertDeliveryError
+	ServiceNow(*alertmodels.Alert, *outputmodels.ServiceNowConfig) *AlertDeliveryError
+	Asana(*alertmodels.Alert, *outputmodels.AsanaConfig) *AlertDeliveryError
+	SplunkHEC(*alertmodels.Alert, *outputmodels.SplunkHECConfig) *AlertDeliveryError
 	getSnsClient(topicArn string) (snsiface.SNSAPI, error)
 	getSqsClient(queueURL string) (sqsiface.SQSAPI, error)
 }
@@ -110,12 +121,35 @@ func generateAlertMessage(alert *alertmodels.Alert) *string {
 }
 
 func generateAlertTitle(alert *alertmodels.Alert) *string {
+	if title := templateTitle(alert); title != nil {
+		return title
+	}
 	if aws.StringValue(alert.Type) == alertmodels.RuleType {
+		// The title computed by the rule describes the match which triggered the alert
+		if aws.StringValue(alert.Title) != "" {
+			return aws.String("New Alert: " + *alert.Title)
+		}
 		return aws.String("New Alert: " + getDisplayName(alert))
 	}
 	return aws.String("Policy Failure: " + getDisplayName(alert))
 }
 
+// generateContextText renders each context field of the alert with the given format, e.g. "\n *%s:* %s"
+//
+// The context is computed by the rule from the event, it is escaped for the markup of the output.
+func generateContextText(alert *alertmodels.Alert, format string, escape func(string) string) string {
+	var result strings.Builder
+	for _, field := range alert.Context {
+		result.WriteString(fmt.Sprintf(format, escape(field.Key), escape(field.Value)))
+	}
+	return result.String()
+}
+
+// plainText leaves the text of the outputs which do not interpret markup as is
+func plainText(text string) string {
+	return text
+}
+
 func getDisplayName(alert *alertmodels.Alert) string {
 	if alert.PolicyName != nil && *alert.PolicyName != "" {
 		return *alert.PolicyName
@@ -123,6 +157,32 @@ func getDisplayName(alert *alertmodels.Alert) string {
 	return *alert.PolicyID
 }
 
+// incidentKey is the stable key of the incident opened in an output for an alert, it is used to resolve it later.
+//
+// The incident of a rule alert is identified by the alert, the incident of a policy by the failing resource.
+func incidentKey(alert *alertmodels.Alert) string {
+	var key string
+	switch {
+	case aws.StringValue(alert.Type) != alertmodels.RuleType:
+		key = "policy:" + aws.StringValue(alert.PolicyID) + ":" + aws.StringValue(alert.ResourceID)
+	case alert.AlertID != nil:
+		key = "alert:" + *alert.AlertID
+	default:
+		key = "rule:" + aws.StringValue(alert.PolicyID) + ":" + aws.StringValue(alert.Dedup)
+	}
+	// Hashed to fit in the labels of the issue trackers, e.g. GitHub labels are limited to 50 characters
+	hash := sha256.Sum256([]byte(key))
+	return "panther-" + hex.EncodeToString(hash[:8])
+}
+
+// generateResolvedText describes the resolution in the comments added to the incidents
+func generateResolvedText(alert *alertmodels.Alert) string {
+	if aws.StringValue(alert.Type) == alertmodels.RuleType {
+		return "The alert was closed in Panther: " + generateURL(alert)
+	}
+	return "The resource " + aws.StringValue(alert.ResourceID) + " is compliant with the policy again: " + generateURL(alert)
+}
+
 func generateURL(alert *alertmodels.Alert) string {
 	if aws.StringValue(alert.Type) == alertmodels.RuleType {
 		return alertURLPrefix + *alert.AlertID
@@ -18,7 +18,17 @@ package outputs
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
-import "github.com/stretchr/testify/mock"
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
 
 type mockHTTPWrapper struct {
 	HTTPWrapper
@@ -29,3 +39,68 @@ func (m *mockHTTPWrapper) post(postInput *PostInput) *AlertDeliveryError {
 	args := m.Called(postInput)
 	return args.Get(0).(*AlertDeliveryError)
 }
+
+// send decodes the JSON response given to the mock into the output
+func (m *mockHTTPWrapper) send(method string, input *PostInput, output interface{}) *AlertDeliveryError {
+	args := m.Called(method, input)
+	if response := args.String(1); output != nil && response != "" {
+		if err := jsoniter.UnmarshalFromString(response, output); err != nil {
+			panic(err)
+		}
+	}
+	return args.Get(0).(*AlertDeliveryError)
+}
+
+func TestGenerateAlertTitle(t *testing.T) {
+	alert := &alertmodels.Alert{
+		PolicyID:   aws.String("rule.id"),
+		PolicyName: aws.String("Root Login"),
+		Type:       aws.String(alertmodels.RuleType),
+	}
+	assert.Equal(t, "New Alert: Root Login", *generateAlertTitle(alert))
+
+	// The title computed by the rule replaces the rule name
+	alert.Title = aws.String("Root login from 1.2.3.4")
+	assert.Equal(t, "New Alert: Root login from 1.2.3.4", *generateAlertTitle(alert))
+}
+
+func TestGenerateContextText(t *testing.T) {
+	alert := &alertmodels.Alert{
+		Context: []*alertmodels.ContextField{
+			{Key: "user", Value: "alice"},
+			{Key: "sourceIp", Value: "1.2.3.4"},
+		},
+	}
+	// The fields are rendered in the order returned by the rule
+	assert.Equal(t, "\n *user:* alice\n *sourceIp:* 1.2.3.4", generateContextText(alert, "\n *%s:* %s", plainText))
+	assert.Equal(t, "", generateContextText(&alertmodels.Alert{}, "\n *%s:* %s", plainText))
+	// The keys and the values are escaped for the markup of the output
+	assert.Equal(t, "\nUSER=ALICE\nSOURCEIP=1.2.3.4", generateContextText(alert, "\n%s=%s", strings.ToUpper))
+}
+
+func resolvedAlert() *alertmodels.Alert {
+	return &alertmodels.Alert{
+		AlertID:  aws.String("alert-id"),
+		PolicyID: aws.String("rule.id"),
+		Severity: aws.String("HIGH"),
+		Type:     aws.String(alertmodels.RuleType),
+		Resolved: aws.Bool(true),
+	}
+}
+
+// urlIs matches the requests made to a url
+func urlIs(url string) interface{} {
+	return mock.MatchedBy(func(input *PostInput) bool { return *input.url == url })
+}
+
+func TestIncidentKey(t *testing.T) {
+	ruleAlert := &alertmodels.Alert{AlertID: aws.String("alert-id"), PolicyID: aws.String("rule.id"), Type: aws.String("RULE")}
+	policyAlert := &alertmodels.Alert{PolicyID: aws.String("policy.id"), ResourceID: aws.String("arn:aws:s3:::bucket")}
+
+	assert.Regexp(t, "^panther-[0-9a-f]{16}$", incidentKey(ruleAlert))
+	// The key is stable and identifies the alert or the failing resource
+	assert.Equal(t, incidentKey(ruleAlert), incidentKey(&alertmodels.Alert{AlertID: aws.String("alert-id"), Type: aws.String("RULE")}))
+	assert.NotEqual(t, incidentKey(ruleAlert), incidentKey(policyAlert))
+	assert.NotEqual(t, incidentKey(policyAlert),
+		incidentKey(&alertmodels.Alert{PolicyID: aws.String("policy.id"), ResourceID: aws.String("arn:aws:s3:::other")}))
+}
@@ -30,6 +30,7 @@ import (
 var (
 	pagerDutyEndpoint  = "https://events.pagerduty.com/v2/enqueue"
 	triggerEventAction = "trigger"
+	resolveEventAction = "resolve"
 )
 
 func pantherSeverityToPagerDuty(severity *string) (*string, *AlertDeliveryError) {
@@ -48,27 +49,54 @@ func pantherSeverityToPagerDuty(severity *string) (*string, *AlertDeliveryError)
 }
 
 // PagerDuty sends an alert to a pager duty integration endpoint.
+//
+// The events are deduplicated by the incident key of the alert, a resolved alert resolves the incident.
 func (client *OutputClient) PagerDuty(alert *alertmodels.Alert, config *outputmodels.PagerDutyConfig) *AlertDeliveryError {
+	if aws.BoolValue(alert.Resolved) {
+		return client.httpWrapper.post(&PostInput{
+			url: &pagerDutyEndpoint,
+			body: map[string]interface{}{
+				"routing_key":  *config.IntegrationKey,
+				"event_action": resolveEventAction,
+				"dedup_key":    incidentKey(alert),
+			},
+		})
+	}
+
 	severity, err := pantherSeverityToPagerDuty(alert.Severity)
 	if err != nil {
 		return err
 	}
 
+	customDetails := map[string]interface{}{
+		"description": aws.StringValue(alert.PolicyDescription),
+		"runbook":     aws.StringValue(alert.Runbook),
+	}
+	if len(alert.Context) > 0 {
+		context := make(map[string]string, len(alert.Context))
+		for _, field := range alert.Context {
+			context[field.Key] = field.Value
+		}
+		customDetails["context"] = context
+	}
+
+	if body := templateBody(alert); body != nil {
+		customDetails["body"] = *body
+	}
+
 	payload := map[string]interface{}{
-		"summary":   *generateAlertTitle(alert),
-		"severity":  aws.StringValue(severity),
-		"timestamp": alert.CreatedAt.Format(time.RFC3339),
-		"source":    "pantherlabs",
-		"custom_details": map[string]string{
-			"description": aws.StringValue(alert.PolicyDescription),
-			"runbook":     aws.StringValue(alert.Runbook),
-		},
+		"summary":        *generateAlertTitle(alert),
+		"severity":       aws.StringValue(severity),
+		"timestamp":      alert.CreatedAt.Format(time.RFC3339),
+		"source":         "pantherlabs",
+		"custom_details": customDetails,
 	}
 
 	pagerDutyRequest := map[string]interface{}{
 		"payload":      payload,
 		"routing_key":  *config.IntegrationKey,
 		"event_action": triggerEventAction,
+		"dedup_key":    incidentKey(alert),
 	}
 
 	postInput := &PostInput{
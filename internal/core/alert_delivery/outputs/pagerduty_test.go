@@ -51,7 +51,7 @@ func TestSendPagerDutyAlert(t *testing.T) {
 	expectedPostPayload := map[string]interface{}{
 		"event_action": "trigger",
 		"payload": map[string]interface{}{
-			"custom_details": map[string]string{
+			"custom_details": map[string]interface{}{
 				"description": "",
 				"runbook":     "runbook",
 			},
@@ -61,6 +61,7 @@ func TestSendPagerDutyAlert(t *testing.T) {
 			"timestamp": "2019-05-03T11:40:13Z",
 		},
 		"routing_key": "integrationKey",
+		"dedup_key":   incidentKey(pagerDutyAlert),
 	}
 	requestEndpoint := "https://events.pagerduty.com/v2/enqueue"
 	expectedPostInput := &PostInput{
@@ -84,3 +85,23 @@ func TestSendPagerDutyAlertPostError(t *testing.T) {
 	require.Error(t, outputClient.PagerDuty(pagerDutyAlert, pagerDutyConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestResolvePagerDutyAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	outputClient := &OutputClient{httpWrapper: httpWrapper}
+	alert := resolvedAlert()
+
+	requestEndpoint := "https://events.pagerduty.com/v2/enqueue"
+	expectedPostInput := &PostInput{
+		url: &requestEndpoint,
+		body: map[string]interface{}{
+			"routing_key":  "integrationKey",
+			"event_action": "resolve",
+			"dedup_key":    incidentKey(alert),
+		},
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	assert.Nil(t, outputClient.PagerDuty(alert, pagerDutyConfig))
+	httpWrapper.AssertExpectations(t)
+}
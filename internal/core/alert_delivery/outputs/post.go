@@ -20,25 +20,44 @@ package outputs
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"io/ioutil"
 	"net/http"
 
 	jsoniter "github.com/json-iterator/go"
 )
 
+// signatureHeader carries the HMAC-SHA256 signature of the body for receivers to verify its origin
+const signatureHeader = "X-Panther-Signature"
+
 // post sends a JSON body to an endpoint.
 func (client *HTTPWrapper) post(input *PostInput) *AlertDeliveryError {
-	payload, err := jsoniter.Marshal(input.body)
-	if err != nil {
-		return &AlertDeliveryError{Message: "json marshal error: " + err.Error(), Permanent: true}
+	return client.send(http.MethodPost, input, nil)
+}
+
+// send makes a request with a JSON body, if any, and decodes the JSON response into the output, if not nil.
+func (client *HTTPWrapper) send(method string, input *PostInput, output interface{}) *AlertDeliveryError {
+	var payload []byte
+	if input.body != nil {
+		var err error
+		if payload, err = jsoniter.Marshal(input.body); err != nil {
+			return &AlertDeliveryError{Message: "json marshal error: " + err.Error(), Permanent: true}
+		}
 	}
 
-	request, err := http.NewRequest("POST", *input.url, bytes.NewBuffer(payload))
+	request, err := http.NewRequest(method, *input.url, bytes.NewBuffer(payload))
 	if err != nil {
 		return &AlertDeliveryError{Message: "http request error: " + err.Error(), Permanent: true}
 	}
 
-	request.Header.Set("Content-Type", "application/json")
+	if input.body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if input.signingSecret != nil {
+		request.Header.Set(signatureHeader, signPayload(*input.signingSecret, payload))
+	}
 
 	//Adding dynamic headers
 	for key, value := range input.headers {
@@ -54,8 +73,22 @@ func (client *HTTPWrapper) post(input *PostInput) *AlertDeliveryError {
 	if response.StatusCode < 200 || response.StatusCode > 299 {
 		body, _ := ioutil.ReadAll(response.Body)
 		return &AlertDeliveryError{
-			Message: "request failed: " + response.Status + ": " + string(body)}
+			Message:    "request failed: " + response.Status + ": " + string(body),
+			StatusCode: response.StatusCode,
+		}
 	}
 
+	if output != nil {
+		if err = jsoniter.NewDecoder(response.Body).Decode(output); err != nil {
+			return &AlertDeliveryError{Message: "json unmarshal error: " + err.Error()}
+		}
+	}
 	return nil
 }
+
+// signPayload returns the hex encoded HMAC-SHA256 of the payload, e.g. "sha256=5d5d..."
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload) // never returns an error
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
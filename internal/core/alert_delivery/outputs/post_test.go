@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockHTTPClient struct {
@@ -76,7 +77,9 @@ func TestPostNotOk(t *testing.T) {
 		url:  &requestEndpoint,
 		body: map[string]interface{}{"abc": 123},
 	}
-	assert.NotNil(t, c.post(postInput))
+	err := c.post(postInput)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
 }
 
 func TestPostOk(t *testing.T) {
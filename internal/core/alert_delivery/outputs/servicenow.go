@@ -0,0 +1,93 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+const serviceNowEndpoint = "/api/now/table/incident"
+
+// The urgency and impact of the incidents, 1 is high and 3 is low
+var pantherToServiceNowPriority = map[string]string{
+	"CRITICAL": "1",
+	"HIGH":     "1",
+	"MEDIUM":   "2",
+	"LOW":      "3",
+	"INFO":     "3",
+}
+
+// serviceNowIncident contains the fields of the incident created in ServiceNow
+type serviceNowIncident struct {
+	ShortDescription *string `json:"short_description"`
+	Description      string  `json:"description"`
+	Urgency          string  `json:"urgency"`
+	Impact           string  `json:"impact"`
+	CorrelationID    string  `json:"correlation_id"`
+	AssignmentGroup  *string `json:"assignment_group,omitempty"`
+}
+
+// ServiceNow alert creates an incident.
+func (client *OutputClient) ServiceNow(
+	alert *alertmodels.Alert, config *outputmodels.ServiceNowConfig) *AlertDeliveryError {
+
+	auth := *config.UserName + ":" + *config.Password
+	basicAuthToken := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
+	accept := "application/json"
+	requestHeader := map[string]*string{
+		"Accept":        &accept,
+		"Authorization": &basicAuthToken,
+	}
+
+	description := "Description: " + aws.StringValue(alert.PolicyDescription)
+	link := "\nLink: " + generateURL(alert)
+	runBook := "\nRunbook: " + aws.StringValue(alert.Runbook)
+	severity := "\nSeverity: " + aws.StringValue(alert.Severity)
+	tags := "\nTags: " + strings.Join(aws.StringValueSlice(alert.Tags), ", ")
+	context := generateContextText(alert, "\n%s: %s", plainText)
+
+	body := description + link + runBook + severity + tags + context
+	if templatedBody := templateBody(alert); templatedBody != nil {
+		body = *templatedBody
+	}
+
+	priority := pantherToServiceNowPriority[aws.StringValue(alert.Severity)]
+	incident := &serviceNowIncident{
+		ShortDescription: generateAlertTitle(alert),
+		Description:      body,
+		Urgency:          priority,
+		Impact:           priority,
+		CorrelationID:    incidentKey(alert),
+		AssignmentGroup:  config.AssignmentGroup,
+	}
+
+	serviceNowURL := strings.TrimSuffix(*config.InstanceURL, "/") + serviceNowEndpoint
+	postInput := &PostInput{
+		url:     &serviceNowURL,
+		body:    incident,
+		headers: requestHeader,
+	}
+	return client.httpWrapper.post(postInput)
+}
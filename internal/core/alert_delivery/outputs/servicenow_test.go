@@ -0,0 +1,136 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+var serviceNowConfig = &outputmodels.ServiceNowConfig{
+	InstanceURL:     aws.String("https://example.service-now.com/"),
+	UserName:        aws.String("panther"),
+	Password:        aws.String("password"),
+	AssignmentGroup: aws.String("Security"),
+}
+
+func TestServiceNowAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	alertURLPrefix = "https://panther.io/alerts/"
+	alert := &alertmodels.Alert{
+		AlertID:           aws.String("alertId"),
+		PolicyID:          aws.String("ruleId"),
+		PolicyName:        aws.String("Root Login"),
+		PolicyDescription: aws.String("description"),
+		Runbook:           aws.String("runbook"),
+		Severity:          aws.String("HIGH"),
+		Tags:              aws.StringSlice([]string{"IAM", "Root"}),
+		Type:              aws.String(alertmodels.RuleType),
+	}
+
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte("panther:password"))
+	expectedPostInput := &PostInput{
+		url: aws.String("https://example.service-now.com/api/now/table/incident"),
+		body: &serviceNowIncident{
+			ShortDescription: aws.String("New Alert: Root Login"),
+			Description: "Description: description\nLink: https://panther.io/alerts/alertId\n" +
+				"Runbook: runbook\nSeverity: HIGH\nTags: IAM, Root",
+			Urgency:         "1",
+			Impact:          "1",
+			CorrelationID:   incidentKey(alert),
+			AssignmentGroup: aws.String("Security"),
+		},
+		headers: map[string]*string{
+			"Accept":        aws.String("application/json"),
+			"Authorization": &auth,
+		},
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	assert.Nil(t, client.ServiceNow(alert, serviceNowConfig))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestServiceNowServer(t *testing.T) {
+	var request *http.Request
+	var requestBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		requestBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &OutputClient{httpWrapper: &HTTPWrapper{httpClient: server.Client()}}
+	config := &outputmodels.ServiceNowConfig{
+		InstanceURL: aws.String(server.URL),
+		UserName:    aws.String("panther"),
+		Password:    aws.String("password"),
+	}
+	alert := &alertmodels.Alert{
+		AlertID:    aws.String("alertId"),
+		PolicyID:   aws.String("ruleId"),
+		PolicyName: aws.String("Root Login"),
+		Severity:   aws.String("LOW"),
+		Type:       aws.String(alertmodels.RuleType),
+	}
+	require.Nil(t, client.ServiceNow(alert, config))
+
+	assert.Equal(t, "/api/now/table/incident", request.URL.Path)
+	user, password, ok := request.BasicAuth()
+	require.True(t, ok)
+	assert.Equal(t, "panther", user)
+	assert.Equal(t, "password", password)
+
+	var incident serviceNowIncident
+	require.NoError(t, jsoniter.Unmarshal(requestBody, &incident))
+	assert.Equal(t, "New Alert: Root Login", *incident.ShortDescription)
+	assert.Equal(t, "3", incident.Urgency)
+	assert.Equal(t, incidentKey(alert), incident.CorrelationID)
+	assert.Nil(t, incident.AssignmentGroup)
+}
+
+func TestServiceNowServerUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := &OutputClient{httpWrapper: &HTTPWrapper{httpClient: server.Client()}}
+	config := &outputmodels.ServiceNowConfig{
+		InstanceURL: aws.String(server.URL),
+		UserName:    aws.String("panther"),
+		Password:    aws.String("wrong"),
+	}
+	result := client.ServiceNow(&alertmodels.Alert{PolicyID: aws.String("policyId")}, config)
+	require.NotNil(t, result)
+	assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+}
@@ -20,6 +20,7 @@ package outputs
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 
@@ -36,6 +37,9 @@ var severityColors = map[string]string{
 	"INFO":     "#47b881",
 }
 
+// The characters Slack parses as links and mentions, e.g. "<!channel>"
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
 // Slack sends an alert to a slack channel.
 func (client *OutputClient) Slack(alert *alertmodels.Alert, config *outputmodels.SlackConfig) *AlertDeliveryError {
 	messageField := fmt.Sprintf("<%s|%s>",
@@ -57,16 +61,30 @@ func (client *OutputClient) Slack(alert *alertmodels.Alert, config *outputmodels
 			"short": true,
 		},
 	}
+	// The title and the context are computed by the rule from the event, they are escaped
+	for _, field := range alert.Context {
+		fields = append(fields, map[string]interface{}{
+			"title": slackEscaper.Replace(field.Key),
+			"value": slackEscaper.Replace(field.Value),
+			"short": true,
+		})
+	}
+
+	title := slackEscaper.Replace(aws.StringValue(generateAlertTitle(alert)))
+	attachment := map[string]interface{}{
+		"fallback": title,
+		"color":    severityColors[aws.StringValue(alert.Severity)],
+		"title":    title,
+		"fields":   fields,
+	}
+	// The templated body replaces the default fields
+	if body := templateBody(alert); body != nil {
+		delete(attachment, "fields")
+		attachment["text"] = *body
+	}
 
 	payload := map[string]interface{}{
-		"attachments": []map[string]interface{}{
-			{
-				"fallback": aws.StringValue(generateAlertTitle(alert)),
-				"color":    severityColors[aws.StringValue(alert.Severity)],
-				"title":    aws.StringValue(generateAlertTitle(alert)),
-				"fields":   fields,
-			},
-		},
+		"attachments": []map[string]interface{}{attachment},
 	}
 	requestEndpoint := *config.WebhookURL
 	postInput := &PostInput{
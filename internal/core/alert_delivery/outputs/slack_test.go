@@ -23,6 +23,8 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -79,3 +81,61 @@ func TestSlackAlert(t *testing.T) {
 	require.Nil(t, client.Slack(alert, slackConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestSlackAlertTemplated(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alert := &alertmodels.Alert{
+		PolicyID: aws.String("policyId"),
+		Severity: aws.String("INFO"),
+		Message:  &alertmodels.Message{Title: aws.String("Templated title"), Body: aws.String("Templated body")},
+	}
+
+	// The templated body replaces the default fields
+	expectedPostInput := &PostInput{
+		url: slackConfig.WebhookURL,
+		body: map[string]interface{}{
+			"attachments": []map[string]interface{}{
+				{
+					"color":    "#47b881",
+					"fallback": "Templated title",
+					"title":    "Templated title",
+					"text":     "Templated body",
+				},
+			},
+		},
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.Slack(alert, slackConfig))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestSlackAlertContextEscaped(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alert := &alertmodels.Alert{
+		AlertID:  aws.String("alertId"),
+		PolicyID: aws.String("policyId"),
+		Type:     aws.String(alertmodels.RuleType),
+		Title:    aws.String("<!here> login by <@admin>"),
+		Severity: aws.String("INFO"),
+		Context:  []*alertmodels.ContextField{{Key: "<user>", Value: "<!channel> & <https://evil.com|login>"}},
+	}
+	httpWrapper.On("post", mock.Anything).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.Slack(alert, slackConfig))
+	body := httpWrapper.Calls[0].Arguments[0].(*PostInput).body.(map[string]interface{})
+	attachment := body["attachments"].([]map[string]interface{})[0]
+	assert.Equal(t, "New Alert: &lt;!here&gt; login by &lt;@admin&gt;", attachment["title"])
+	assert.Equal(t, "New Alert: &lt;!here&gt; login by &lt;@admin&gt;", attachment["fallback"])
+	fields := attachment["fields"].([]map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"title": "&lt;user&gt;",
+		"value": "&lt;!channel&gt; &amp; &lt;https://evil.com|login&gt;",
+		"short": true,
+	}, fields[len(fields)-1])
+	httpWrapper.AssertExpectations(t)
+}
@@ -41,6 +41,13 @@ func (client *OutputClient) Sns(alert *alertmodels.Alert, config *outputmodels.S
 		Runbook:     alert.Runbook,
 		Severity:    alert.Severity,
 		Tags:        alert.Tags,
+		Title:       alert.Title,
+		Context:     alert.Context,
+		Body:        templateBody(alert),
+	}
+
+	if title := templateTitle(alert); title != nil {
+		outputMessage.Title = title
 	}
 
 	serializedMessage, err := jsoniter.MarshalToString(outputMessage)
@@ -69,13 +76,16 @@ func (client *OutputClient) Sns(alert *alertmodels.Alert, config *outputmodels.S
 
 //snsOutputMessage contains the fields that will be included in the SNS message
 type snsOutputMessage struct {
-	ID          *string   `json:"id"`
-	Name        *string   `json:"name,omitempty"`
-	VersionID   *string   `json:"versionId,omitempty"`
-	Description *string   `json:"description,omitempty"`
-	Runbook     *string   `json:"runbook,omitempty"`
-	Severity    *string   `json:"severity"`
-	Tags        []*string `json:"tags,omitempty"`
+	ID          *string                     `json:"id"`
+	Name        *string                     `json:"name,omitempty"`
+	VersionID   *string                     `json:"versionId,omitempty"`
+	Description *string                     `json:"description,omitempty"`
+	Runbook     *string                     `json:"runbook,omitempty"`
+	Severity    *string                     `json:"severity"`
+	Tags        []*string                   `json:"tags,omitempty"`
+	Title       *string                     `json:"title,omitempty"`
+	Context     []*alertmodels.ContextField `json:"context,omitempty"`
+	Body        *string                     `json:"body,omitempty"`
 }
 
 func (client *OutputClient) getSnsClient(topicArn string) (snsiface.SNSAPI, error) {
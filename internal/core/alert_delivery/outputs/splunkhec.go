@@ -0,0 +1,71 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+const (
+	splunkSource            = "panther"
+	splunkDefaultSourceType = "panther:alert"
+)
+
+// splunkHECEvent is the envelope of an event sent to the Splunk HTTP Event Collector
+type splunkHECEvent struct {
+	Time       *int64                `json:"time,omitempty"`
+	Source     string                `json:"source"`
+	SourceType string                `json:"sourcetype"`
+	Index      *string               `json:"index,omitempty"`
+	Event      *webhookOutputMessage `json:"event"`
+}
+
+// SplunkHEC alert sends an event to a Splunk HTTP Event Collector.
+//
+// The event contains the same alert fields as the generic webhook.
+func (client *OutputClient) SplunkHEC(
+	alert *alertmodels.Alert, config *outputmodels.SplunkHECConfig) *AlertDeliveryError {
+
+	authorization := "Splunk " + *config.Token
+	requestHeader := map[string]*string{
+		"Authorization": &authorization,
+	}
+
+	event := &splunkHECEvent{
+		Source:     splunkSource,
+		SourceType: splunkDefaultSourceType,
+		Index:      config.Index,
+		Event:      newWebhookOutputMessage(alert),
+	}
+	if config.SourceType != nil {
+		event.SourceType = *config.SourceType
+	}
+	if alert.CreatedAt != nil {
+		createdAt := alert.CreatedAt.Unix()
+		event.Time = &createdAt
+	}
+
+	postInput := &PostInput{
+		url:     config.URL,
+		body:    event,
+		headers: requestHeader,
+	}
+	return client.httpWrapper.post(postInput)
+}
@@ -0,0 +1,118 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+func TestSplunkHECAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	config := &outputmodels.SplunkHECConfig{
+		URL:   aws.String("https://splunk.example.com:8088/services/collector/event"),
+		Token: aws.String("token"),
+		Index: aws.String("security"),
+	}
+	createdAt := time.Date(2020, 2, 1, 10, 0, 0, 0, time.UTC)
+	alert := *webhookAlert
+	alert.CreatedAt = &createdAt
+
+	expectedPostInput := &PostInput{
+		url: config.URL,
+		body: &splunkHECEvent{
+			Time:       aws.Int64(createdAt.Unix()),
+			Source:     "panther",
+			SourceType: "panther:alert",
+			Index:      aws.String("security"),
+			Event:      newWebhookOutputMessage(&alert),
+		},
+		headers: map[string]*string{"Authorization": aws.String("Splunk token")},
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	assert.Nil(t, client.SplunkHEC(&alert, config))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestSplunkHECServer(t *testing.T) {
+	var request *http.Request
+	var requestBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		requestBody, _ = ioutil.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"text": "Success", "code": 0}`))
+	}))
+	defer server.Close()
+
+	client := &OutputClient{httpWrapper: &HTTPWrapper{httpClient: server.Client()}}
+	config := &outputmodels.SplunkHECConfig{
+		URL:        aws.String(server.URL + "/services/collector/event"),
+		Token:      aws.String("token"),
+		SourceType: aws.String("panther:custom"),
+	}
+	require.Nil(t, client.SplunkHEC(webhookAlert, config))
+
+	assert.Equal(t, "/services/collector/event", request.URL.Path)
+	assert.Equal(t, "Splunk token", request.Header.Get("Authorization"))
+
+	var event struct {
+		Time       *int64  `json:"time"`
+		Source     string  `json:"source"`
+		SourceType string  `json:"sourcetype"`
+		Index      *string `json:"index"`
+		Event      struct {
+			AlertID string `json:"alertId"`
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+		} `json:"event"`
+	}
+	require.NoError(t, jsoniter.Unmarshal(requestBody, &event))
+	assert.Nil(t, event.Time)
+	assert.Equal(t, "panther", event.Source)
+	assert.Equal(t, "panther:custom", event.SourceType)
+	assert.Nil(t, event.Index)
+	assert.Equal(t, "alertId", event.Event.AlertID)
+	assert.Equal(t, "New Alert: Root Login", event.Event.Title)
+	assert.Equal(t, "https://panther.io/alerts/alertId", event.Event.Link)
+}
+
+func TestSplunkHECServerForbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := &OutputClient{httpWrapper: &HTTPWrapper{httpClient: server.Client()}}
+	config := &outputmodels.SplunkHECConfig{URL: aws.String(server.URL), Token: aws.String("invalid")}
+	result := client.SplunkHEC(webhookAlert, config)
+	require.NotNil(t, result)
+	assert.Equal(t, http.StatusForbidden, result.StatusCode)
+}
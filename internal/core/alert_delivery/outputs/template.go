@@ -0,0 +1,98 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// templateData is the value the message templates are rendered against.
+//
+// All the alert fields are available, e.g. {{.Severity}}, and the context fields are looked up by key,
+// e.g. {{.Context.userName}}.
+type templateData struct {
+	*alertmodels.Alert
+	Name    string
+	Link    string
+	Context map[string]string
+}
+
+// RenderMessage renders the title and body templates of an output for the alert.
+func RenderMessage(alert *alertmodels.Alert, messageTemplate *outputmodels.MessageTemplate) (*alertmodels.Message, error) {
+	data := &templateData{
+		Alert:   alert,
+		Name:    getDisplayName(alert),
+		Link:    generateURL(alert),
+		Context: make(map[string]string, len(alert.Context)),
+	}
+	for _, field := range alert.Context {
+		data.Context[field.Key] = field.Value
+	}
+
+	var err error
+	message := &alertmodels.Message{}
+	if message.Title, err = renderTemplate("title", messageTemplate.Title, data); err != nil {
+		return nil, err
+	}
+	if message.Body, err = renderTemplate("body", messageTemplate.Body, data); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// renderTemplate returns nil if there is no template text.
+func renderTemplate(name string, text *string, data *templateData) (*string, error) {
+	if aws.StringValue(text) == "" {
+		return nil, nil
+	}
+
+	// Missing context keys are rendered as empty strings instead of "<no value>"
+	parsed, err := template.New(name).Option("missingkey=zero").Parse(*text)
+	if err != nil {
+		return nil, err
+	}
+
+	var result strings.Builder
+	if err = parsed.Execute(&result, data); err != nil {
+		return nil, err
+	}
+	return aws.String(result.String()), nil
+}
+
+// templateTitle is the title rendered from the output template, if any.
+func templateTitle(alert *alertmodels.Alert) *string {
+	if alert.Message == nil {
+		return nil
+	}
+	return alert.Message.Title
+}
+
+// templateBody is the body rendered from the output template, if any.
+func templateBody(alert *alertmodels.Alert) *string {
+	if alert.Message == nil {
+		return nil
+	}
+	return alert.Message.Body
+}
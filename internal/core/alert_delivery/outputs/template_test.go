@@ -0,0 +1,76 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+var templateAlert = &alertmodels.Alert{
+	AlertID:    aws.String("alertId"),
+	PolicyID:   aws.String("ruleId"),
+	PolicyName: aws.String("Root Login"),
+	Severity:   aws.String("HIGH"),
+	Type:       aws.String(alertmodels.RuleType),
+	Context:    []*alertmodels.ContextField{{Key: "user", Value: "root"}},
+}
+
+func TestRenderMessage(t *testing.T) {
+	message, err := RenderMessage(templateAlert, &outputmodels.MessageTemplate{
+		Title: aws.String("{{.Severity}}: {{.Name}}"),
+		Body:  aws.String("{{.Context.user}} logged in{{.Context.missing}}, see {{.Link}}"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &alertmodels.Message{
+		Title: aws.String("HIGH: Root Login"),
+		Body:  aws.String("root logged in, see https://panther.io/alerts/alertId"),
+	}, message)
+}
+
+func TestRenderMessageTitleOnly(t *testing.T) {
+	message, err := RenderMessage(templateAlert, &outputmodels.MessageTemplate{Title: aws.String("{{.PolicyID}}")})
+	require.NoError(t, err)
+	assert.Equal(t, &alertmodels.Message{Title: aws.String("ruleId")}, message)
+}
+
+func TestRenderMessageParseError(t *testing.T) {
+	_, err := RenderMessage(templateAlert, &outputmodels.MessageTemplate{Title: aws.String("{{.Severity")})
+	assert.Error(t, err)
+}
+
+func TestRenderMessageUnknownField(t *testing.T) {
+	_, err := RenderMessage(templateAlert, &outputmodels.MessageTemplate{Body: aws.String("{{.Unknown}}")})
+	assert.Error(t, err)
+}
+
+func TestGenerateAlertTitleTemplated(t *testing.T) {
+	alert := &alertmodels.Alert{
+		PolicyID: aws.String("ruleId"),
+		Type:     aws.String(alertmodels.RuleType),
+		Message:  &alertmodels.Message{Title: aws.String("Templated title")},
+	}
+	assert.Equal(t, "Templated title", *generateAlertTitle(alert))
+}
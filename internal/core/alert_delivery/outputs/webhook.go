@@ -0,0 +1,134 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// Webhook sends an alert to a generic HTTP endpoint.
+func (client *OutputClient) Webhook(
+	alert *alertmodels.Alert, config *outputmodels.WebhookConfig) *AlertDeliveryError {
+
+	payload, err := jsoniter.Marshal(newWebhookOutputMessage(alert))
+	if err != nil {
+		return &AlertDeliveryError{Message: "json marshal error: " + err.Error(), Permanent: true}
+	}
+	if config.Body != nil {
+		if payload, err = addWebhookFields(payload, *config.Body); err != nil {
+			return &AlertDeliveryError{Message: "invalid webhook body: " + err.Error(), Permanent: true}
+		}
+	}
+
+	requestHeader := make(map[string]*string, len(config.Headers))
+	for _, header := range config.Headers {
+		requestHeader[*header.Key] = header.Value
+	}
+
+	postInput := &PostInput{
+		url:           config.URL,
+		body:          jsoniter.RawMessage(payload),
+		headers:       requestHeader,
+		signingSecret: config.SigningSecret,
+	}
+	return client.httpWrapper.post(postInput)
+}
+
+// webhookOutputMessage contains the fields that will be included in the webhook request
+type webhookOutputMessage struct {
+	AlertID     *string                     `json:"alertId,omitempty"`
+	Type        *string                     `json:"type,omitempty"`
+	ID          *string                     `json:"id"`
+	Name        *string                     `json:"name,omitempty"`
+	VersionID   *string                     `json:"versionId,omitempty"`
+	Description *string                     `json:"description,omitempty"`
+	Runbook     *string                     `json:"runbook,omitempty"`
+	Severity    *string                     `json:"severity"`
+	Tags        []*string                   `json:"tags,omitempty"`
+	Title       *string                     `json:"title"`
+	Context     []*alertmodels.ContextField `json:"context,omitempty"`
+	CreatedAt   *time.Time                  `json:"createdAt,omitempty"`
+	Link        *string                     `json:"link"`
+	Body        *string                     `json:"body,omitempty"`
+}
+
+// newWebhookOutputMessage converts an alert into the fields of the webhook request
+func newWebhookOutputMessage(alert *alertmodels.Alert) *webhookOutputMessage {
+	return &webhookOutputMessage{
+		AlertID:     alert.AlertID,
+		Type:        alert.Type,
+		ID:          alert.PolicyID,
+		Name:        alert.PolicyName,
+		VersionID:   alert.PolicyVersionID,
+		Description: alert.PolicyDescription,
+		Runbook:     alert.Runbook,
+		Severity:    alert.Severity,
+		Tags:        alert.Tags,
+		Title:       generateAlertTitle(alert),
+		Context:     alert.Context,
+		CreatedAt:   alert.CreatedAt,
+		Link:        aws.String(generateURL(alert)),
+		Body:        templateBody(alert),
+	}
+}
+
+// addWebhookFields adds the fields of the configured body to the alert payload.
+//
+// The fields are added in key order and the alert fields cannot be overwritten.
+func addWebhookFields(payload []byte, body string) ([]byte, error) {
+	var alertFields, fields map[string]jsoniter.RawMessage
+	if err := jsoniter.Unmarshal(payload, &alertFields); err != nil {
+		return nil, err
+	}
+	if err := jsoniter.UnmarshalFromString(body, &fields); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if _, ok := alertFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// Re-open the serialized alert object and append each field before closing it again
+	var result bytes.Buffer
+	result.Write(bytes.TrimSuffix(payload, []byte("}")))
+	for _, key := range keys {
+		serializedKey, err := jsoniter.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		result.WriteByte(',')
+		result.Write(serializedKey)
+		result.WriteByte(':')
+		result.Write(bytes.TrimSpace(fields[key]))
+	}
+	result.WriteByte('}')
+	return result.Bytes(), nil
+}
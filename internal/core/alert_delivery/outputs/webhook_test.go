@@ -0,0 +1,133 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+var webhookAlert = &alertmodels.Alert{
+	AlertID:    aws.String("alertId"),
+	PolicyID:   aws.String("ruleId"),
+	PolicyName: aws.String("Root Login"),
+	Severity:   aws.String("HIGH"),
+	Type:       aws.String(alertmodels.RuleType),
+	Context:    []*alertmodels.ContextField{{Key: "user", Value: "root"}},
+}
+
+func TestWebhookAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	config := &outputmodels.WebhookConfig{
+		URL:           aws.String("https://soar.example.com/alerts"),
+		Headers:       []*outputmodels.WebhookHeader{{Key: aws.String("X-Api-Key"), Value: aws.String("key")}},
+		SigningSecret: aws.String("secret"),
+		Body:          aws.String(`{"source": "panther", "severity": "LOW"}`),
+	}
+
+	// The configured fields are sorted after the alert fields, which cannot be overwritten
+	expectedBody := `{"alertId":"alertId","type":"RULE","id":"ruleId","name":"Root Login","severity":"HIGH",` +
+		`"title":"New Alert: Root Login","context":[{"key":"user","value":"root"}],` +
+		`"link":"https://panther.io/alerts/alertId","source":"panther"}`
+	expectedPostInput := &PostInput{
+		url:           config.URL,
+		body:          jsoniter.RawMessage(expectedBody),
+		headers:       map[string]*string{"X-Api-Key": aws.String("key")},
+		signingSecret: config.SigningSecret,
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	assert.Nil(t, client.Webhook(webhookAlert, config))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestWebhookInvalidBody(t *testing.T) {
+	client := &OutputClient{httpWrapper: &mockHTTPWrapper{}}
+	config := &outputmodels.WebhookConfig{URL: aws.String("https://soar.example.com/alerts"), Body: aws.String("[]")}
+
+	result := client.Webhook(webhookAlert, config)
+	require.NotNil(t, result)
+	assert.True(t, result.Permanent)
+}
+
+func TestWebhookServer(t *testing.T) {
+	var request *http.Request
+	var requestBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		requestBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := &OutputClient{httpWrapper: &HTTPWrapper{httpClient: server.Client()}}
+	config := &outputmodels.WebhookConfig{
+		URL:           aws.String(server.URL),
+		Headers:       []*outputmodels.WebhookHeader{{Key: aws.String("X-Api-Key"), Value: aws.String("key")}},
+		SigningSecret: aws.String("secret"),
+		Body:          aws.String(`{"source": "panther"}`),
+	}
+	require.Nil(t, client.Webhook(webhookAlert, config))
+
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.Equal(t, "key", request.Header.Get("X-Api-Key"))
+
+	// The receiver can verify the signature of the body with the shared secret
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(requestBody)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), request.Header.Get(signatureHeader))
+
+	var body struct {
+		AlertID string                      `json:"alertId"`
+		Title   string                      `json:"title"`
+		Context []*alertmodels.ContextField `json:"context"`
+		Source  string                      `json:"source"`
+	}
+	require.NoError(t, jsoniter.Unmarshal(requestBody, &body))
+	assert.Equal(t, "alertId", body.AlertID)
+	assert.Equal(t, "New Alert: Root Login", body.Title)
+	assert.Equal(t, webhookAlert.Context, body.Context)
+	assert.Equal(t, "panther", body.Source)
+}
+
+func TestWebhookServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &OutputClient{httpWrapper: &HTTPWrapper{httpClient: server.Client()}}
+	result := client.Webhook(webhookAlert, &outputmodels.WebhookConfig{URL: aws.String(server.URL)})
+	require.NotNil(t, result)
+	assert.False(t, result.Permanent)
+}
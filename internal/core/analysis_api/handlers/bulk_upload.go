@@ -199,18 +199,22 @@ func extractZipFile(input *models.BulkUpload) (map[models.ID]*tableItem, error)
 			// Use filename as placeholder for the body which we lookup later
 			Body: models.Body(config.Filename),
 
-			Description:   models.Description(config.Description),
-			DisplayName:   models.DisplayName(config.DisplayName),
-			Enabled:       models.Enabled(config.Enabled),
-			ID:            models.ID(config.PolicyID),
-			Reference:     models.Reference(config.Reference),
-			ResourceTypes: models.TypeSet(config.ResourceTypes),
-			Runbook:       models.Runbook(config.Runbook),
-			Severity:      models.Severity(strings.ToUpper(config.Severity)),
-			Suppressions:  models.Suppressions(config.Suppressions),
-			Tags:          config.Tags,
-			Tests:         make([]*models.UnitTest, len(config.Tests)),
-			Type:          strings.ToUpper(config.AnalysisType),
+			Correlation:            parseCorrelation(config.Correlation),
+			DedupPeriodMinutes:     models.DedupPeriodMinutes(config.DedupPeriodMinutes),
+			Description:            models.Description(config.Description),
+			DisplayName:            models.DisplayName(config.DisplayName),
+			Enabled:                models.Enabled(config.Enabled),
+			ID:                     models.ID(config.PolicyID),
+			Reference:              models.Reference(config.Reference),
+			ResourceTypes:          models.TypeSet(config.ResourceTypes),
+			Runbook:                models.Runbook(config.Runbook),
+			Severity:               models.Severity(strings.ToUpper(config.Severity)),
+			Suppressions:           models.Suppressions(config.Suppressions),
+			Tags:                   config.Tags,
+			Tests:                  make([]*models.UnitTest, len(config.Tests)),
+			Threshold:              models.Threshold(config.Threshold),
+			ThresholdWindowMinutes: models.ThresholdWindowMinutes(config.ThresholdWindowMinutes),
+			Type:                   strings.ToUpper(config.AnalysisType),
 		}
 
 		for i, test := range config.Tests {
@@ -233,11 +237,16 @@ func extractZipFile(input *models.BulkUpload) (map[models.ID]*tableItem, error)
 		result[policy.ID] = &policy
 	}
 
+	correlationRules, err := uploadedCorrelationRules(result)
+	if err != nil {
+		return nil, err
+	}
+
 	// Finish each policy by adding its body and then validate it
 	for _, policy := range result {
 		if body, ok := policyBodies[string(policy.Body)]; ok {
 			policy.Body = body
-			if err := validateUploadedPolicy(policy, input.UserID); err != nil {
+			if err := validateUploadedPolicy(policy, input.UserID, correlationRules); err != nil {
 				return nil, err
 			}
 		} else {
@@ -262,7 +271,7 @@ func readZipFile(zf *zip.File) ([]byte, error) {
 }
 
 // Ensure that the uploaded policy is valid according to the API spec for a Policy
-func validateUploadedPolicy(item *tableItem, userID models.UserID) error {
+func validateUploadedPolicy(item *tableItem, userID models.UserID, correlationRules map[models.ID]bool) error {
 	if item.Type != typePolicy && item.Type != typeRule {
 		return fmt.Errorf("policy ID %s is invalid: unknown analysis type %s", item.ID, item.Type)
 	}
@@ -277,5 +286,85 @@ func validateUploadedPolicy(item *tableItem, userID models.UserID) error {
 	if err := policy.Validate(nil); err != nil {
 		return fmt.Errorf("policy ID %s is invalid: %s", policy.ID, err)
 	}
+
+	if err := validateUploadedThreshold(item); err != nil {
+		return err
+	}
+
+	if item.Correlation != nil {
+		if item.Type != typeRule {
+			return fmt.Errorf("policy ID %s is invalid: only rules can be correlation rules", item.ID)
+		}
+		if err := item.Correlation.Validate(nil); err != nil {
+			return fmt.Errorf("policy ID %s is invalid: %s", item.ID, err)
+		}
+		if err := validateCorrelation(item.ID, item.Correlation, correlationRules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateUploadedThreshold validates the threshold of a rule like the API spec for a Rule, which the Policy lacks
+func validateUploadedThreshold(item *tableItem) error {
+	if item.Threshold == 0 && item.ThresholdWindowMinutes == 0 {
+		return nil
+	}
+	if item.Type != typeRule {
+		return fmt.Errorf("policy ID %s is invalid: only rules can have a threshold", item.ID)
+	}
+	if item.Threshold != 0 {
+		if err := item.Threshold.Validate(nil); err != nil {
+			return fmt.Errorf("policy ID %s is invalid: threshold: %s", item.ID, err)
+		}
+	}
+	if item.ThresholdWindowMinutes != 0 {
+		if err := item.ThresholdWindowMinutes.Validate(nil); err != nil {
+			return fmt.Errorf("policy ID %s is invalid: thresholdWindowMinutes: %s", item.ID, err)
+		}
+	}
 	return nil
 }
+
+// uploadedCorrelationRules reports which of the rules referenced by the uploaded correlation rules are correlation rules
+//
+// The uploaded version of a rule replaces the stored one.
+func uploadedCorrelationRules(policies map[models.ID]*tableItem) (map[models.ID]bool, error) {
+	result := make(map[models.ID]bool)
+	for _, policy := range policies {
+		if policy.Correlation == nil {
+			continue
+		}
+		for _, id := range policy.Correlation.RuleIds {
+			if _, ok := result[id]; ok {
+				continue
+			}
+			if uploaded, ok := policies[id]; ok {
+				result[id] = uploaded.Correlation != nil
+				continue
+			}
+			item, err := dynamoGet(id, false)
+			if err != nil {
+				return nil, err
+			}
+			result[id] = item != nil && item.Correlation != nil
+		}
+	}
+	return result, nil
+}
+
+// parseCorrelation maps the correlation of a rule spec to the API model
+func parseCorrelation(config *analysis.Correlation) *models.Correlation {
+	if config == nil {
+		return nil
+	}
+	result := &models.Correlation{
+		Ordered:       config.Ordered,
+		RuleIds:       make([]models.ID, len(config.RuleIDs)),
+		WindowMinutes: aws.Int64(config.WindowMinutes),
+	}
+	for i, ruleID := range config.RuleIDs {
+		result.RuleIds[i] = models.ID(ruleID)
+	}
+	return result
+}
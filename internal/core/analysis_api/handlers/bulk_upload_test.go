@@ -0,0 +1,38 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateUploadedThreshold(t *testing.T) {
+	assert.NoError(t, validateUploadedThreshold(&tableItem{ID: "rule", Type: typeRule}))
+	assert.NoError(t, validateUploadedThreshold(&tableItem{ID: "rule", Type: typeRule, Threshold: 10, ThresholdWindowMinutes: 30}))
+
+	// Same bounds as the rule create and update paths
+	assert.Error(t, validateUploadedThreshold(&tableItem{ID: "rule", Type: typeRule, Threshold: -1}))
+	assert.Error(t, validateUploadedThreshold(&tableItem{ID: "rule", Type: typeRule, Threshold: 1000001}))
+	assert.Error(t, validateUploadedThreshold(&tableItem{ID: "rule", Type: typeRule, Threshold: 10, ThresholdWindowMinutes: 1441}))
+
+	// Policies do not have thresholds
+	assert.Error(t, validateUploadedThreshold(&tableItem{ID: "policy", Type: typePolicy, Threshold: 10}))
+}
@@ -40,6 +40,7 @@ func CreatePolicy(request *events.APIGatewayProxyRequest) *events.APIGatewayProx
 		AutoRemediationID:         input.AutoRemediationID,
 		AutoRemediationParameters: input.AutoRemediationParameters,
 		Body:                      input.Body,
+		DedupPeriodMinutes:        input.DedupPeriodMinutes,
 		Description:               input.Description,
 		DisplayName:               input.DisplayName,
 		Enabled:                   input.Enabled,
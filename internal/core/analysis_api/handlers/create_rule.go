@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -35,20 +36,27 @@ func CreateRule(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyR
 	if err != nil {
 		return badRequest(err)
 	}
+	if response := validateRuleCorrelation(input); response != nil {
+		return response
+	}
 
 	item := &tableItem{
-		Body:          input.Body,
-		Description:   input.Description,
-		DisplayName:   input.DisplayName,
-		Enabled:       input.Enabled,
-		ID:            input.ID,
-		Reference:     input.Reference,
-		ResourceTypes: input.LogTypes,
-		Runbook:       input.Runbook,
-		Severity:      input.Severity,
-		Tags:          input.Tags,
-		Tests:         input.Tests,
-		Type:          typeRule,
+		Body:                   input.Body,
+		Correlation:            input.Correlation,
+		DedupPeriodMinutes:     input.DedupPeriodMinutes,
+		Description:            input.Description,
+		DisplayName:            input.DisplayName,
+		Enabled:                input.Enabled,
+		ID:                     input.ID,
+		Reference:              input.Reference,
+		ResourceTypes:          input.LogTypes,
+		Runbook:                input.Runbook,
+		Severity:               input.Severity,
+		Tags:                   input.Tags,
+		Tests:                  input.Tests,
+		Threshold:              input.Threshold,
+		ThresholdWindowMinutes: input.ThresholdWindowMinutes,
+		Type:                   typeRule,
 	}
 
 	if _, err := writeItem(item, input.UserID, aws.Bool(false)); err != nil {
@@ -74,3 +82,44 @@ func parseUpdateRule(request *events.APIGatewayProxyRequest) (*models.UpdateRule
 
 	return &result, nil
 }
+
+// validateRuleCorrelation checks the rules referenced by a created or modified correlation rule
+//
+// Returns nil if the correlation is valid.
+func validateRuleCorrelation(input *models.UpdateRule) *events.APIGatewayProxyResponse {
+	if input.Correlation == nil {
+		return nil
+	}
+
+	correlationRules := make(map[models.ID]bool, len(input.Correlation.RuleIds))
+	for _, id := range input.Correlation.RuleIds {
+		item, err := dynamoGet(id, false)
+		if err != nil {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+		correlationRules[id] = item != nil && item.Correlation != nil
+	}
+
+	if err := validateCorrelation(input.ID, input.Correlation, correlationRules); err != nil {
+		return badRequest(err)
+	}
+	return nil
+}
+
+// validateCorrelation checks that a correlation rule does not reference itself or another correlation rule
+//
+// The alerts of correlation rules are not correlated further, so a sequence referencing one would never complete.
+func validateCorrelation(ruleID models.ID, correlation *models.Correlation, correlationRules map[models.ID]bool) error {
+	if correlation == nil {
+		return nil
+	}
+	for _, id := range correlation.RuleIds {
+		if id == ruleID {
+			return fmt.Errorf("correlation rule %s can not reference itself", ruleID)
+		}
+		if correlationRules[id] {
+			return fmt.Errorf("correlation rule %s can not reference the correlation rule %s", ruleID, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/panther-labs/panther/api/gateway/analysis/models"
+)
+
+func TestValidateCorrelation(t *testing.T) {
+	correlation := &models.Correlation{RuleIds: []models.ID{"rule.a", "rule.b"}}
+	assert.NoError(t, validateCorrelation("correlation.rule", nil, nil))
+	assert.NoError(t, validateCorrelation("correlation.rule", correlation, map[models.ID]bool{"rule.a": false}))
+
+	// A correlation rule can not reference itself
+	assert.Error(t, validateCorrelation("rule.a", correlation, nil))
+
+	// The alerts of correlation rules are not correlated further
+	err := validateCorrelation("correlation.rule", correlation, map[models.ID]bool{"rule.b": true})
+	assert.EqualError(t, err, "correlation rule correlation.rule can not reference the correlation rule rule.b")
+}
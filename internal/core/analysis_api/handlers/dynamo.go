@@ -52,6 +52,8 @@ type tableItem struct {
 	Body                      models.Body                      `json:"body"`
 	CreatedAt                 models.ModifyTime                `json:"createdAt"`
 	CreatedBy                 models.UserID                    `json:"createdBy"`
+	Correlation               *models.Correlation              `json:"correlation,omitempty"`
+	DedupPeriodMinutes        models.DedupPeriodMinutes        `json:"dedupPeriodMinutes,omitempty"`
 	Description               models.Description               `json:"description,omitempty"`
 	DisplayName               models.DisplayName               `json:"displayName,omitempty"`
 	Enabled                   models.Enabled                   `json:"enabled"`
@@ -65,6 +67,8 @@ type tableItem struct {
 	Suppressions              models.Suppressions              `json:"suppressions,omitempty" dynamodbav:"suppressions,stringset,omitempty"`
 	Tags                      models.Tags                      `json:"tags,omitempty" dynamodbav:"tags,stringset,omitempty"`
 	Tests                     []*models.UnitTest               `json:"tests,omitempty"`
+	Threshold                 models.Threshold                 `json:"threshold,omitempty"`
+	ThresholdWindowMinutes    models.ThresholdWindowMinutes    `json:"thresholdWindowMinutes,omitempty"`
 	VersionID                 models.VersionID                 `json:"versionId,omitempty"`
 
 	// Logic type (policy or rule)
@@ -100,6 +104,7 @@ func (r *tableItem) Policy(status models.ComplianceStatus) *models.Policy {
 		ComplianceStatus:          status,
 		CreatedAt:                 r.CreatedAt,
 		CreatedBy:                 r.CreatedBy,
+		DedupPeriodMinutes:        r.DedupPeriodMinutes,
 		Description:               r.Description,
 		DisplayName:               r.DisplayName,
 		Enabled:                   r.Enabled,
@@ -143,22 +148,26 @@ func (r *tableItem) PolicySummary(status models.ComplianceStatus) *models.Policy
 func (r *tableItem) Rule() *models.Rule {
 	r.normalize()
 	result := &models.Rule{
-		Body:           r.Body,
-		CreatedAt:      r.CreatedAt,
-		CreatedBy:      r.CreatedBy,
-		Description:    r.Description,
-		DisplayName:    r.DisplayName,
-		Enabled:        r.Enabled,
-		ID:             r.ID,
-		LastModified:   r.LastModified,
-		LastModifiedBy: r.LastModifiedBy,
-		LogTypes:       r.ResourceTypes,
-		Reference:      r.Reference,
-		Runbook:        r.Runbook,
-		Severity:       r.Severity,
-		Tags:           r.Tags,
-		Tests:          r.Tests,
-		VersionID:      r.VersionID,
+		Body:                   r.Body,
+		Correlation:            r.Correlation,
+		CreatedAt:              r.CreatedAt,
+		CreatedBy:              r.CreatedBy,
+		DedupPeriodMinutes:     r.DedupPeriodMinutes,
+		Description:            r.Description,
+		DisplayName:            r.DisplayName,
+		Enabled:                r.Enabled,
+		ID:                     r.ID,
+		LastModified:           r.LastModified,
+		LastModifiedBy:         r.LastModifiedBy,
+		LogTypes:               r.ResourceTypes,
+		Reference:              r.Reference,
+		Runbook:                r.Runbook,
+		Severity:               r.Severity,
+		Tags:                   r.Tags,
+		Tests:                  r.Tests,
+		Threshold:              r.Threshold,
+		ThresholdWindowMinutes: r.ThresholdWindowMinutes,
+		VersionID:              r.VersionID,
 	}
 	gatewayapi.ReplaceMapSliceNils(result)
 	return result
@@ -43,12 +43,16 @@ func GetEnabledPolicies(request *events.APIGatewayProxyRequest) *events.APIGatew
 	policies := make([]*models.EnabledPolicy, 0, 100)
 	err = scanPages(scanInput, func(policy *tableItem) error {
 		policies = append(policies, &models.EnabledPolicy{
-			Body:          policy.Body,
-			ID:            policy.ID,
-			ResourceTypes: policy.ResourceTypes,
-			Severity:      policy.Severity,
-			Suppressions:  policy.Suppressions,
-			VersionID:     policy.VersionID,
+			Body:                   policy.Body,
+			Correlation:            policy.Correlation,
+			DedupPeriodMinutes:     policy.DedupPeriodMinutes,
+			ID:                     policy.ID,
+			ResourceTypes:          policy.ResourceTypes,
+			Severity:               policy.Severity,
+			Suppressions:           policy.Suppressions,
+			Threshold:              policy.Threshold,
+			ThresholdWindowMinutes: policy.ThresholdWindowMinutes,
+			VersionID:              policy.VersionID,
 		})
 		return nil
 	})
@@ -74,10 +78,14 @@ func buildEnabledScan(ruleType string) (*dynamodb.ScanInput, error) {
 	projection := expression.NamesList(
 		// does not include unit tests, last modified, org id, reference, tags, etc
 		expression.Name("body"),
+		expression.Name("correlation"),
+		expression.Name("dedupPeriodMinutes"),
 		expression.Name("id"),
 		expression.Name("resourceTypes"),
 		expression.Name("severity"),
 		expression.Name("suppressions"),
+		expression.Name("threshold"),
+		expression.Name("thresholdWindowMinutes"),
 		expression.Name("versionId"),
 	)
 
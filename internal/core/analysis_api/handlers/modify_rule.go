@@ -33,20 +33,27 @@ func ModifyRule(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyR
 	if err != nil {
 		return badRequest(err)
 	}
+	if response := validateRuleCorrelation(input); response != nil {
+		return response
+	}
 
 	item := &tableItem{
-		Body:          input.Body,
-		Description:   input.Description,
-		DisplayName:   input.DisplayName,
-		Enabled:       input.Enabled,
-		ID:            input.ID,
-		Reference:     input.Reference,
-		ResourceTypes: input.LogTypes,
-		Runbook:       input.Runbook,
-		Severity:      input.Severity,
-		Tags:          input.Tags,
-		Tests:         input.Tests,
-		Type:          typeRule,
+		Body:                   input.Body,
+		Correlation:            input.Correlation,
+		DedupPeriodMinutes:     input.DedupPeriodMinutes,
+		Description:            input.Description,
+		DisplayName:            input.DisplayName,
+		Enabled:                input.Enabled,
+		ID:                     input.ID,
+		Reference:              input.Reference,
+		ResourceTypes:          input.LogTypes,
+		Runbook:                input.Runbook,
+		Severity:               input.Severity,
+		Tags:                   input.Tags,
+		Tests:                  input.Tests,
+		Threshold:              input.Threshold,
+		ThresholdWindowMinutes: input.ThresholdWindowMinutes,
+		Type:                   typeRule,
 	}
 
 	if _, err := writeItem(item, input.UserID, aws.Bool(true)); err != nil {
@@ -35,6 +35,7 @@ func (API) CreateOrganization(
 	// Then write the new org to the Dynamo table
 	org := &models.Organization{
 		AlertReportFrequency: input.AlertReportFrequency,
+		AlertRetention:       input.AlertRetention,
 		AwsConfig:            input.AwsConfig,
 		CreatedAt:            aws.String(time.Now().Format(time.RFC3339)),
 		DisplayName:          input.DisplayName,
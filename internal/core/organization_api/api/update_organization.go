@@ -26,6 +26,7 @@ func (API) UpdateOrganization(
 
 	updated, err := orgTable.Update(&models.Organization{
 		AlertReportFrequency: input.AlertReportFrequency,
+		AlertRetention:       input.AlertRetention,
 		AwsConfig:            input.AwsConfig,
 		DisplayName:          input.DisplayName,
 		Email:                input.Email,
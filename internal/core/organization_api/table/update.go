@@ -31,6 +31,8 @@ import (
 )
 
 // Update updates account details and returns the updated item
+//
+// The alert retention is only replaced when it is set, the settings forms update the other fields.
 func (table *OrganizationsTable) Update(org *models.Organization) (*models.Organization, error) {
 	update := expression.
 		Set(expression.Name("alertReportFrequency"), expression.Value(org.AlertReportFrequency)).
@@ -39,6 +41,9 @@ func (table *OrganizationsTable) Update(org *models.Organization) (*models.Organ
 		Set(expression.Name("email"), expression.Value(org.Email)).
 		Set(expression.Name("phone"), expression.Value(org.Phone)).
 		Set(expression.Name("remediationConfig"), expression.Value(org.RemediationConfig))
+	if org.AlertRetention != nil {
+		update = update.Set(expression.Name("alertRetention"), expression.Value(org.AlertRetention))
+	}
 	return table.doUpdate(update)
 }
 
@@ -134,3 +134,38 @@ func TestAddActions(t *testing.T) {
 	expected := &models.Organization{}
 	assert.Equal(t, expected, result)
 }
+
+func TestUpdateAlertRetention(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	org := &models.Organization{
+		AlertRetention: &models.AlertRetention{
+			RetentionDays: aws.Int64(90),
+			SeverityRetentionDays: []*models.SeverityRetention{
+				{Severity: aws.String("CRITICAL"), RetentionDays: aws.Int64(365)},
+			},
+		},
+	}
+
+	output := &dynamodb.UpdateItemOutput{
+		Attributes: DynamoItem{"id": {S: aws.String("1")}},
+	}
+	mockClient.On("UpdateItem", mock.Anything).Return(output, nil)
+	table := &OrganizationsTable{client: mockClient, Name: aws.String("test-table")}
+
+	_, err := table.Update(org)
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+
+	input := mockClient.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Contains(t, aws.StringValueSlice(valuesOf(input.ExpressionAttributeNames)), "alertRetention")
+	assert.Equal(t, int64(365), org.AlertRetention.Days("CRITICAL"))
+	assert.Equal(t, int64(90), org.AlertRetention.Days("LOW"))
+}
+
+func valuesOf(names map[string]*string) []*string {
+	result := make([]*string, 0, len(names))
+	for _, name := range names {
+		result = append(result, name)
+	}
+	return result
+}
@@ -46,6 +46,10 @@ func (API) AddOutput(input *models.AddOutputInput) (*models.AddOutputOutput, err
 		return nil, &genericapi.InvalidInputError{Message: err.Error()}
 	}
 
+	if err = validateTemplate(input.OutputConfig); err != nil {
+		return nil, err
+	}
+
 	alertOutput := &models.AlertOutput{
 		OutputID:           aws.String(uuid.New().String()),
 		DisplayName:        input.DisplayName,
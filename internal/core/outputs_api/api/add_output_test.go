@@ -29,6 +29,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 func TestAddOutputSameNameAlreadyExists(t *testing.T) {
@@ -83,6 +84,26 @@ func TestAddOutputPutOutputError(t *testing.T) {
 	mockOutputVerification.AssertExpectations(t)
 }
 
+func TestAddOutputInvalidTemplate(t *testing.T) {
+	mockOutputTable := &mockOutputTable{}
+	outputsTable = mockOutputTable
+	mockOutputTable.On("GetOutputByName", aws.String("my-channel")).Return(nil, nil)
+
+	input := &models.AddOutputInput{
+		UserID:      aws.String("userId"),
+		DisplayName: aws.String("my-channel"),
+		OutputConfig: &models.OutputConfig{
+			Slack:    &models.SlackConfig{WebhookURL: aws.String("hooks.slack.com")},
+			Template: &models.MessageTemplate{Title: aws.String("{{.Unknown}}")},
+		},
+	}
+
+	result, err := (API{}).AddOutput(input)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockOutputTable.AssertExpectations(t)
+}
+
 func TestAddOutputSlack(t *testing.T) {
 	mockEncryptionKey := &mockEncryptionKey{}
 	encryptionKey = mockEncryptionKey
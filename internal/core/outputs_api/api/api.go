@@ -23,7 +23,10 @@ import (
 	"os"
 
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
 	"github.com/panther-labs/panther/internal/core/outputs_api/encryption"
 	"github.com/panther-labs/panther/internal/core/outputs_api/table"
 	"github.com/panther-labs/panther/internal/core/outputs_api/verification"
@@ -44,6 +47,16 @@ var (
 	defaultsTable table.DefaultsAPI = table.NewDefaults(
 		os.Getenv("DEFAULTS_TABLE_NAME"),
 		awsSession)
+	routingRulesTable table.RoutingRulesAPI = table.NewRoutingRules(
+		os.Getenv("ROUTING_RULES_TABLE_NAME"),
+		awsSession)
 
 	outputVerification verification.OutputVerificationAPI = verification.NewVerification(awsSession)
+
+	// The output client sends the test alerts with the same methods as the alert delivery
+	outputClient outputs.API = outputs.New(awsSession)
+
+	// The delivery history of the alerts, kept by the alerts-api, is used to check the health of the outputs
+	lambdaClient lambdaiface.LambdaAPI = lambda.New(awsSession)
+	alertsAPI                          = os.Getenv("ALERTS_API")
 )
@@ -19,9 +19,13 @@ package api
  */
 
 import (
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	deliverymodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
 	"github.com/panther-labs/panther/internal/core/outputs_api/encryption"
 	"github.com/panther-labs/panther/internal/core/outputs_api/table"
 	"github.com/panther-labs/panther/internal/core/outputs_api/verification"
@@ -66,6 +70,11 @@ func (m *mockOutputTable) PutOutput(output *models.AlertOutputItem) error {
 	return args.Error(0)
 }
 
+func (m *mockOutputTable) UpdateHealth(outputID *string, healthStatus *string, consecutiveFailures *int) error {
+	args := m.Called(outputID, healthStatus, consecutiveFailures)
+	return args.Error(0)
+}
+
 type mockDefaultsTable struct {
 	table.DefaultsTable
 	mock.Mock
@@ -86,6 +95,31 @@ func (m *mockDefaultsTable) PutDefaults(item *models.DefaultOutputsItem) error {
 	return args.Error(0)
 }
 
+type mockRoutingRulesTable struct {
+	table.RoutingRulesTable
+	mock.Mock
+}
+
+func (m *mockRoutingRulesTable) PutRoutingRule(rule *models.RoutingRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *mockRoutingRulesTable) GetRoutingRule(routingRuleID *string) (*models.RoutingRule, error) {
+	args := m.Called(routingRuleID)
+	return args.Get(0).(*models.RoutingRule), args.Error(1)
+}
+
+func (m *mockRoutingRulesTable) GetRoutingRules() ([]*models.RoutingRule, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.RoutingRule), args.Error(1)
+}
+
+func (m *mockRoutingRulesTable) DeleteRoutingRule(routingRuleID *string) error {
+	args := m.Called(routingRuleID)
+	return args.Error(0)
+}
+
 type mockEncryptionKey struct {
 	encryption.Key
 	mock.Mock
@@ -115,3 +149,23 @@ func (m *mockOutputVerification) VerifyOutput(output *models.AlertOutput) (*mode
 	args := m.Called(output)
 	return args.Get(0).(*models.AlertOutput), args.Error(1)
 }
+
+type mockOutputClient struct {
+	outputs.API
+	mock.Mock
+}
+
+func (m *mockOutputClient) Slack(alert *deliverymodels.Alert, config *models.SlackConfig) *outputs.AlertDeliveryError {
+	args := m.Called(alert, config)
+	return args.Get(0).(*outputs.AlertDeliveryError)
+}
+
+type mockLambdaClient struct {
+	lambdaiface.LambdaAPI
+	mock.Mock
+}
+
+func (m *mockLambdaClient) Invoke(input *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*lambda.InvokeOutput), args.Error(1)
+}
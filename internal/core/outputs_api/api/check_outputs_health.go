@@ -0,0 +1,108 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	alertmodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// unhealthyThreshold is the number of consecutive failed deliveries after which an output is unhealthy
+const unhealthyThreshold = 3
+
+// CheckOutputsHealth updates the health status of the outputs from their latest delivery attempts.
+//
+// Only the deliveries of rule alerts are recorded: the health of an output is unknown until a rule
+// alert is sent to it, and stays unknown if it only receives policy alerts.
+func (API) CheckOutputsHealth(_ *models.CheckOutputsHealthInput) error {
+	items, err := outputsTable.GetOutputs()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		deliveries, err := listOutputDeliveries(item.OutputID)
+		if err != nil {
+			return err
+		}
+		deliveries = sentDeliveries(deliveries)
+
+		failures := countConsecutiveFailures(deliveries)
+		status := models.HealthStatusHealthy
+		switch {
+		case len(deliveries) == 0:
+			status = models.HealthStatusUnknown
+		case failures >= unhealthyThreshold:
+			status = models.HealthStatusUnhealthy
+		}
+
+		if aws.StringValue(item.HealthStatus) == status && aws.IntValue(item.ConsecutiveFailures) == failures {
+			continue
+		}
+
+		zap.L().Info("health status of output has changed",
+			zap.String("outputId", *item.OutputID),
+			zap.String("healthStatus", status),
+			zap.Int("consecutiveFailures", failures))
+		if err = outputsTable.UpdateHealth(item.OutputID, aws.String(status), aws.Int(failures)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listOutputDeliveries returns the latest delivery attempts to the output from the alerts-api, newest first
+func listOutputDeliveries(outputID *string) ([]*alertmodels.AlertDelivery, error) {
+	input := alertmodels.LambdaInput{ListOutputDeliveries: &alertmodels.ListOutputDeliveriesInput{
+		OutputID: outputID,
+		Limit:    aws.Int(unhealthyThreshold),
+	}}
+	var deliveries alertmodels.ListOutputDeliveriesOutput
+	if err := genericapi.Invoke(lambdaClient, alertsAPI, &input, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// sentDeliveries removes the alerts held back by the rate limit of the output, they do not show its health
+func sentDeliveries(deliveries []*alertmodels.AlertDelivery) []*alertmodels.AlertDelivery {
+	result := make([]*alertmodels.AlertDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		if aws.StringValue(delivery.Status) != alertmodels.DeliveryStatusDigested {
+			result = append(result, delivery)
+		}
+	}
+	return result
+}
+
+// countConsecutiveFailures counts the failed deliveries, newest first, until the first successful one
+func countConsecutiveFailures(deliveries []*alertmodels.AlertDelivery) int {
+	failures := 0
+	for _, delivery := range deliveries {
+		if aws.StringValue(delivery.Status) == alertmodels.DeliveryStatusSuccess {
+			break
+		}
+		failures++
+	}
+	return failures
+}
@@ -0,0 +1,95 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	alertmodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+func deliveriesWithStatus(statuses ...string) []*alertmodels.AlertDelivery {
+	deliveries := make([]*alertmodels.AlertDelivery, len(statuses))
+	for i, status := range statuses {
+		deliveries[i] = &alertmodels.AlertDelivery{Status: aws.String(status)}
+	}
+	return deliveries
+}
+
+// mockOutputDeliveries returns the deliveries with the given statuses when the alerts-api is asked for those of the output
+func mockOutputDeliveries(t *testing.T, mockLambda *mockLambdaClient, outputID string, statuses ...string) {
+	payload, err := jsoniter.Marshal(deliveriesWithStatus(statuses...))
+	require.NoError(t, err)
+	mockLambda.On("Invoke", mock.MatchedBy(func(input *lambda.InvokeInput) bool {
+		var lambdaInput alertmodels.LambdaInput
+		return jsoniter.Unmarshal(input.Payload, &lambdaInput) == nil && lambdaInput.ListOutputDeliveries != nil &&
+			*lambdaInput.ListOutputDeliveries.OutputID == outputID && *lambdaInput.ListOutputDeliveries.Limit == unhealthyThreshold
+	})).Return(&lambda.InvokeOutput{Payload: payload}, nil).Once()
+}
+
+func TestCheckOutputsHealth(t *testing.T) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockLambda := &mockLambdaClient{}
+	lambdaClient = mockLambda
+
+	items := []*models.AlertOutputItem{
+		// Failing repeatedly, becomes unhealthy
+		{OutputID: aws.String("failing")},
+		// Recovered after a failure
+		{OutputID: aws.String("recovered"), HealthStatus: aws.String(models.HealthStatusUnhealthy), ConsecutiveFailures: aws.Int(3)},
+		// Unchanged, not updated
+		{OutputID: aws.String("healthy"), HealthStatus: aws.String(models.HealthStatusHealthy), ConsecutiveFailures: aws.Int(0)},
+		// Only digested alerts, the health is unknown
+		{OutputID: aws.String("unused")},
+		// No recorded deliveries, e.g. only policy alerts, still unknown and not updated
+		{OutputID: aws.String("policies"), HealthStatus: aws.String(models.HealthStatusUnknown), ConsecutiveFailures: aws.Int(0)},
+	}
+	mockOutputsTable.On("GetOutputs").Return(items, nil)
+	mockOutputDeliveries(t, mockLambda, "failing",
+		alertmodels.DeliveryStatusFailure, alertmodels.DeliveryStatusPermanentFailure, alertmodels.DeliveryStatusFailure)
+	mockOutputDeliveries(t, mockLambda, "recovered", alertmodels.DeliveryStatusSuccess, alertmodels.DeliveryStatusFailure)
+	mockOutputDeliveries(t, mockLambda, "healthy", alertmodels.DeliveryStatusSuccess)
+	mockOutputDeliveries(t, mockLambda, "unused", alertmodels.DeliveryStatusDigested)
+	mockOutputDeliveries(t, mockLambda, "policies")
+	mockOutputsTable.On("UpdateHealth", aws.String("failing"), aws.String(models.HealthStatusUnhealthy), aws.Int(3)).Return(nil)
+	mockOutputsTable.On("UpdateHealth", aws.String("recovered"), aws.String(models.HealthStatusHealthy), aws.Int(0)).Return(nil)
+	mockOutputsTable.On("UpdateHealth", aws.String("unused"), aws.String(models.HealthStatusUnknown), aws.Int(0)).Return(nil)
+
+	assert.NoError(t, (API{}).CheckOutputsHealth(&models.CheckOutputsHealthInput{}))
+	mockOutputsTable.AssertExpectations(t)
+	mockLambda.AssertExpectations(t)
+	mockOutputsTable.AssertNumberOfCalls(t, "UpdateHealth", 3)
+}
+
+func TestCountConsecutiveFailures(t *testing.T) {
+	assert.Equal(t, 0, countConsecutiveFailures(deliveriesWithStatus(alertmodels.DeliveryStatusSuccess)))
+	assert.Equal(t, 1, countConsecutiveFailures(deliveriesWithStatus(
+		alertmodels.DeliveryStatusFailure, alertmodels.DeliveryStatusSuccess, alertmodels.DeliveryStatusFailure)))
+	assert.Equal(t, 2, countConsecutiveFailures(deliveriesWithStatus(
+		alertmodels.DeliveryStatusPermanentFailure, alertmodels.DeliveryStatusFailure)))
+}
@@ -51,5 +51,9 @@ func (API) DeleteOutput(input *models.DeleteOutputInput) error {
 		}
 	}
 
+	if err = removeFromRoutingRules(input.OutputID, aws.BoolValue(input.Force)); err != nil {
+		return err
+	}
+
 	return outputsTable.DeleteOutput(input.OutputID)
 }
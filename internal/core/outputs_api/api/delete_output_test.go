@@ -39,6 +39,9 @@ func TestDeleteOutput(t *testing.T) {
 	outputsTable = mockOutputsTable
 	mockDefaultsTable := &mockDefaultsTable{}
 	defaultsTable = mockDefaultsTable
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+	mockRoutingRulesTable.On("GetRoutingRules").Return([]*models.RoutingRule{}, nil)
 
 	mockDefaultsTable.On("GetDefaults").Return(make([]*models.DefaultOutputsItem, 0), nil)
 	mockOutputsTable.On("DeleteOutput", aws.String("outputId")).Return(nil)
@@ -88,6 +91,9 @@ func TestDeleteOutputDeleteFails(t *testing.T) {
 	outputsTable = mockOutputsTable
 	mockDefaultsTable := &mockDefaultsTable{}
 	defaultsTable = mockDefaultsTable
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+	mockRoutingRulesTable.On("GetRoutingRules").Return([]*models.RoutingRule{}, nil)
 
 	mockDefaultsTable.On("GetDefaults").Return(make([]*models.DefaultOutputsItem, 0), nil)
 	mockOutputsTable.On("DeleteOutput", aws.String("outputId")).Return(errors.New("error"))
@@ -104,6 +110,9 @@ func TestDeleteOutputRemovesDefaults(t *testing.T) {
 	outputsTable = mockOutputsTable
 	mockDefaultsTable := &mockDefaultsTable{}
 	defaultsTable = mockDefaultsTable
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+	mockRoutingRulesTable.On("GetRoutingRules").Return([]*models.RoutingRule{}, nil)
 
 	defaultOutputs := []*models.DefaultOutputsItem{{
 		OutputIDs: aws.StringSlice([]string{"outputId1", "outputId2"}),
@@ -0,0 +1,77 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// sampleAlert is used to validate and preview the message templates
+var sampleAlert = &alertmodels.Alert{
+	AlertID:           aws.String("e2b7a8c31f0d4d4a9c3a5d5f0c6b8a71"),
+	CreatedAt:         aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+	PolicyDescription: aws.String("A root account login was detected"),
+	PolicyID:          aws.String("AWS.CloudTrail.RootLogin"),
+	PolicyName:        aws.String("AWS Root Account Login"),
+	PolicyVersionID:   aws.String("VaZ5BqcmOQyvTTHZNjS8jHvXwJkRWsEX"),
+	Runbook:           aws.String("Contact the owner of the account"),
+	Severity:          aws.String("HIGH"),
+	Tags:              aws.StringSlice([]string{"AWS", "Identity & Access Management"}),
+	Type:              aws.String(alertmodels.RuleType),
+	Dedup:             aws.String("123456789012"),
+	EventCount:        aws.Int64(1),
+	Title:             aws.String("Root login from 1.2.3.4"),
+	Context: []*alertmodels.ContextField{
+		{Key: "sourceIPAddress", Value: "1.2.3.4"},
+		{Key: "userAgent", Value: "Mozilla/5.0"},
+	},
+}
+
+// PreviewTemplate renders a message template against a sample alert.
+func (API) PreviewTemplate(input *models.PreviewTemplateInput) (*models.PreviewTemplateOutput, error) {
+	message, err := renderSampleMessage(input.Template)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PreviewTemplateOutput{Title: message.Title, Body: message.Body}, nil
+}
+
+// validateTemplate returns an InvalidInputError if the output template cannot be rendered.
+func validateTemplate(outputConfig *models.OutputConfig) error {
+	if outputConfig.Template == nil {
+		return nil
+	}
+	_, err := renderSampleMessage(outputConfig.Template)
+	return err
+}
+
+func renderSampleMessage(template *models.MessageTemplate) (*alertmodels.Message, error) {
+	message, err := outputs.RenderMessage(sampleAlert, template)
+	if err != nil {
+		return nil, &genericapi.InvalidInputError{Message: "invalid template: " + err.Error()}
+	}
+	return message, nil
+}
@@ -0,0 +1,53 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestPreviewTemplate(t *testing.T) {
+	result, err := (API{}).PreviewTemplate(&models.PreviewTemplateInput{
+		Template: &models.MessageTemplate{
+			Title: aws.String("{{.Severity}}: {{.Name}}"),
+			Body:  aws.String("Login from {{.Context.sourceIPAddress}}"),
+		},
+	})
+	require.NoError(t, err)
+	expected := &models.PreviewTemplateOutput{
+		Title: aws.String("HIGH: AWS Root Account Login"),
+		Body:  aws.String("Login from 1.2.3.4"),
+	}
+	assert.Equal(t, expected, result)
+}
+
+func TestPreviewTemplateInvalid(t *testing.T) {
+	result, err := (API{}).PreviewTemplate(&models.PreviewTemplateInput{
+		Template: &models.MessageTemplate{Body: aws.String("{{if .Severity}}")},
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
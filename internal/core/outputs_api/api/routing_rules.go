@@ -0,0 +1,149 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// AddRoutingRule stores a new alert routing rule.
+func (API) AddRoutingRule(input *models.AddRoutingRuleInput) (*models.AddRoutingRuleOutput, error) {
+	if err := validateRoutingOutputs(input.OutputIDs); err != nil {
+		return nil, err
+	}
+
+	now := aws.String(time.Now().Format(time.RFC3339))
+	rule := &models.RoutingRule{
+		RoutingRuleID:    aws.String(uuid.New().String()),
+		DisplayName:      input.DisplayName,
+		Priority:         input.Priority,
+		Match:            input.Match,
+		OutputIDs:        input.OutputIDs,
+		Continue:         input.Continue,
+		CreatedBy:        input.UserID,
+		CreationTime:     now,
+		LastModifiedBy:   input.UserID,
+		LastModifiedTime: now,
+	}
+	if err := routingRulesTable.PutRoutingRule(rule); err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("stored new routing rule", zap.String("routingRuleId", *rule.RoutingRuleID))
+	return rule, nil
+}
+
+// UpdateRoutingRule replaces the configuration of an existing routing rule.
+func (API) UpdateRoutingRule(input *models.UpdateRoutingRuleInput) (*models.UpdateRoutingRuleOutput, error) {
+	existing, err := routingRulesTable.GetRoutingRule(input.RoutingRuleID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "routingRuleId=" + *input.RoutingRuleID}
+	}
+	if err = validateRoutingOutputs(input.OutputIDs); err != nil {
+		return nil, err
+	}
+
+	rule := &models.RoutingRule{
+		RoutingRuleID:    input.RoutingRuleID,
+		DisplayName:      input.DisplayName,
+		Priority:         input.Priority,
+		Match:            input.Match,
+		OutputIDs:        input.OutputIDs,
+		Continue:         input.Continue,
+		CreatedBy:        existing.CreatedBy,
+		CreationTime:     existing.CreationTime,
+		LastModifiedBy:   input.UserID,
+		LastModifiedTime: aws.String(time.Now().Format(time.RFC3339)),
+	}
+	if err = routingRulesTable.PutRoutingRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRoutingRule removes an alert routing rule.
+func (API) DeleteRoutingRule(input *models.DeleteRoutingRuleInput) error {
+	return routingRulesTable.DeleteRoutingRule(input.RoutingRuleID)
+}
+
+// GetRoutingRules lists the routing rules in the order they are evaluated.
+func (API) GetRoutingRules(input *models.GetRoutingRulesInput) (models.GetRoutingRulesOutput, error) {
+	rules, err := routingRulesTable.GetRoutingRules()
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []*models.RoutingRule{}
+	}
+	return rules, nil
+}
+
+// validateRoutingOutputs returns an InvalidInputError if one of the outputs does not exist.
+func validateRoutingOutputs(outputIDs []*string) error {
+	for _, outputID := range outputIDs {
+		if _, err := outputsTable.GetOutput(outputID); err != nil {
+			if _, ok := err.(*genericapi.DoesNotExistError); ok {
+				return &genericapi.InvalidInputError{Message: "destination " + *outputID + " does not exist"}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFromRoutingRules removes a deleted output from the routing rules.
+//
+// A routing rule without any other output is deleted. Unless forced, an InUseError is returned instead.
+func removeFromRoutingRules(outputID *string, force bool) error {
+	rules, err := routingRulesTable.GetRoutingRules()
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		outputIDs := removeFromSlice(rule.OutputIDs, outputID)
+		if len(outputIDs) == len(rule.OutputIDs) {
+			continue
+		}
+		if !force {
+			return &genericapi.InUseError{Message: "This destination is used by the routing rule " + aws.StringValue(rule.DisplayName)}
+		}
+
+		if len(outputIDs) == 0 {
+			err = routingRulesTable.DeleteRoutingRule(rule.RoutingRuleID)
+		} else {
+			rule.OutputIDs = outputIDs
+			err = routingRulesTable.PutRoutingRule(rule)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var routingMatch = &models.RoutingMatch{Tags: aws.StringSlice([]string{"pci"})}
+
+func TestAddRoutingRule(t *testing.T) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+
+	mockOutputsTable.On("GetOutput", aws.String("outputId")).Return(&models.AlertOutputItem{}, nil)
+	mockRoutingRulesTable.On("PutRoutingRule", mock.Anything).Return(nil)
+
+	result, err := (API{}).AddRoutingRule(&models.AddRoutingRuleInput{
+		UserID:      aws.String("userId"),
+		DisplayName: aws.String("PCI to Jira"),
+		Priority:    aws.Int(10),
+		Match:       routingMatch,
+		OutputIDs:   aws.StringSlice([]string{"outputId"}),
+		Continue:    aws.Bool(true),
+	})
+	require.NoError(t, err)
+
+	expected := &models.AddRoutingRuleOutput{
+		RoutingRuleID:    result.RoutingRuleID,
+		DisplayName:      aws.String("PCI to Jira"),
+		Priority:         aws.Int(10),
+		Match:            routingMatch,
+		OutputIDs:        aws.StringSlice([]string{"outputId"}),
+		Continue:         aws.Bool(true),
+		CreatedBy:        aws.String("userId"),
+		CreationTime:     result.CreationTime,
+		LastModifiedBy:   aws.String("userId"),
+		LastModifiedTime: result.CreationTime,
+	}
+	assert.Equal(t, expected, result)
+	_, err = uuid.Parse(*result.RoutingRuleID)
+	assert.NoError(t, err)
+	mockOutputsTable.AssertExpectations(t)
+	mockRoutingRulesTable.AssertExpectations(t)
+}
+
+func TestAddRoutingRuleOutputDoesNotExist(t *testing.T) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+
+	mockOutputsTable.On("GetOutput", aws.String("outputId")).Return(
+		(*models.AlertOutputItem)(nil), &genericapi.DoesNotExistError{})
+
+	result, err := (API{}).AddRoutingRule(&models.AddRoutingRuleInput{
+		UserID:    aws.String("userId"),
+		Priority:  aws.Int(10),
+		Match:     routingMatch,
+		OutputIDs: aws.StringSlice([]string{"outputId"}),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockOutputsTable.AssertExpectations(t)
+	mockRoutingRulesTable.AssertExpectations(t)
+}
+
+func TestUpdateRoutingRule(t *testing.T) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+
+	existing := &models.RoutingRule{
+		RoutingRuleID: aws.String("routingRuleId"),
+		CreatedBy:     aws.String("creator"),
+		CreationTime:  aws.String("2020-01-01T00:00:00Z"),
+	}
+	mockRoutingRulesTable.On("GetRoutingRule", aws.String("routingRuleId")).Return(existing, nil)
+	mockOutputsTable.On("GetOutput", aws.String("outputId")).Return(&models.AlertOutputItem{}, nil)
+	mockRoutingRulesTable.On("PutRoutingRule", mock.Anything).Return(nil)
+
+	result, err := (API{}).UpdateRoutingRule(&models.UpdateRoutingRuleInput{
+		RoutingRuleID: aws.String("routingRuleId"),
+		UserID:        aws.String("userId"),
+		DisplayName:   aws.String("PCI to Jira"),
+		Priority:      aws.Int(5),
+		Match:         routingMatch,
+		OutputIDs:     aws.StringSlice([]string{"outputId"}),
+	})
+	require.NoError(t, err)
+
+	// The creation is preserved
+	assert.Equal(t, aws.String("creator"), result.CreatedBy)
+	assert.Equal(t, aws.String("2020-01-01T00:00:00Z"), result.CreationTime)
+	assert.Equal(t, aws.String("userId"), result.LastModifiedBy)
+	assert.Equal(t, aws.Int(5), result.Priority)
+	mockOutputsTable.AssertExpectations(t)
+	mockRoutingRulesTable.AssertExpectations(t)
+}
+
+func TestUpdateRoutingRuleDoesNotExist(t *testing.T) {
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+	mockRoutingRulesTable.On("GetRoutingRule", aws.String("routingRuleId")).Return((*models.RoutingRule)(nil), nil)
+
+	result, err := (API{}).UpdateRoutingRule(&models.UpdateRoutingRuleInput{RoutingRuleID: aws.String("routingRuleId")})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockRoutingRulesTable.AssertExpectations(t)
+}
+
+func TestGetRoutingRulesEmpty(t *testing.T) {
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+	mockRoutingRulesTable.On("GetRoutingRules").Return(([]*models.RoutingRule)(nil), nil)
+
+	result, err := (API{}).GetRoutingRules(&models.GetRoutingRulesInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.RoutingRule{}, result)
+	mockRoutingRulesTable.AssertExpectations(t)
+}
+
+func TestDeleteOutputUsedByRoutingRule(t *testing.T) {
+	mockDefaultsTable := &mockDefaultsTable{}
+	defaultsTable = mockDefaultsTable
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+
+	mockDefaultsTable.On("GetDefaults").Return([]*models.DefaultOutputsItem{}, nil)
+	mockRoutingRulesTable.On("GetRoutingRules").Return([]*models.RoutingRule{
+		{RoutingRuleID: aws.String("routingRuleId"), OutputIDs: aws.StringSlice([]string{"outputId"})},
+	}, nil)
+
+	err := (API{}).DeleteOutput(&models.DeleteOutputInput{OutputID: aws.String("outputId")})
+	assert.IsType(t, &genericapi.InUseError{}, err)
+	mockDefaultsTable.AssertExpectations(t)
+	mockRoutingRulesTable.AssertExpectations(t)
+}
+
+func TestDeleteOutputRemovesRoutingRules(t *testing.T) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockDefaultsTable := &mockDefaultsTable{}
+	defaultsTable = mockDefaultsTable
+	mockRoutingRulesTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockRoutingRulesTable
+
+	mockDefaultsTable.On("GetDefaults").Return([]*models.DefaultOutputsItem{}, nil)
+	mockRoutingRulesTable.On("GetRoutingRules").Return([]*models.RoutingRule{
+		{RoutingRuleID: aws.String("onlyOutput"), OutputIDs: aws.StringSlice([]string{"outputId"})},
+		{RoutingRuleID: aws.String("twoOutputs"), OutputIDs: aws.StringSlice([]string{"outputId", "otherId"})},
+		{RoutingRuleID: aws.String("otherOutput"), OutputIDs: aws.StringSlice([]string{"otherId"})},
+	}, nil)
+	// The rule without any other output is deleted
+	mockRoutingRulesTable.On("DeleteRoutingRule", aws.String("onlyOutput")).Return(nil)
+	mockRoutingRulesTable.On("PutRoutingRule", &models.RoutingRule{
+		RoutingRuleID: aws.String("twoOutputs"),
+		OutputIDs:     aws.StringSlice([]string{"otherId"}),
+	}).Return(nil)
+	mockOutputsTable.On("DeleteOutput", aws.String("outputId")).Return(nil)
+
+	err := (API{}).DeleteOutput(&models.DeleteOutputInput{OutputID: aws.String("outputId"), Force: aws.Bool(true)})
+	require.NoError(t, err)
+	mockOutputsTable.AssertExpectations(t)
+	mockDefaultsTable.AssertExpectations(t)
+	mockRoutingRulesTable.AssertExpectations(t)
+}
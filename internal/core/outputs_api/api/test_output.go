@@ -0,0 +1,63 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+const testAlertPrefix = "[TEST] "
+
+// TestOutput sends a sample alert to an output and returns the error of the delivery, if any.
+func (API) TestOutput(input *models.TestOutputInput) (*models.TestOutputOutput, error) {
+	item, err := outputsTable.GetOutput(input.OutputID)
+	if err != nil {
+		return nil, err
+	}
+
+	alertOutput, err := ItemToAlertOutput(item)
+	if err != nil {
+		return nil, err
+	}
+
+	// The test alert is clearly marked so that it is not mistaken for a real one
+	alert := *sampleAlert
+	alert.CreatedAt = aws.Time(time.Now().UTC())
+	alert.PolicyName = aws.String(testAlertPrefix + *sampleAlert.PolicyName)
+	alert.Title = aws.String(testAlertPrefix + *sampleAlert.Title)
+	alert.PolicyDescription = aws.String("This is a test alert sent from Panther to verify the destination")
+
+	zap.L().Info("sending test alert", zap.String("outputId", *input.OutputID))
+	deliveryErr := outputs.Deliver(outputClient, &alert, alertOutput)
+	if deliveryErr == nil {
+		return &models.TestOutputOutput{Success: aws.Bool(true)}, nil
+	}
+
+	result := &models.TestOutputOutput{Success: aws.Bool(false), Message: aws.String(deliveryErr.Message)}
+	if deliveryErr.StatusCode != 0 {
+		result.HTTPStatusCode = aws.Int(deliveryErr.StatusCode)
+	}
+	return result, nil
+}
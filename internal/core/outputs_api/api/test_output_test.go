@@ -0,0 +1,100 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	deliverymodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var (
+	mockTestOutputInput = &models.TestOutputInput{OutputID: aws.String("outputId")}
+	mockTestOutputItem  = &models.AlertOutputItem{
+		OutputID:        aws.String("outputId"),
+		OutputType:      aws.String("slack"),
+		EncryptedConfig: make([]byte, 1),
+	}
+	mockSlackConfig = &models.SlackConfig{WebhookURL: aws.String("https://hooks.slack.com")}
+)
+
+func setupTestOutput(deliveryErr *outputs.AlertDeliveryError) *mockOutputClient {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockEncryptionKey := &mockEncryptionKey{}
+	encryptionKey = mockEncryptionKey
+	mockClient := &mockOutputClient{}
+	outputClient = mockClient
+
+	mockOutputsTable.On("GetOutput", aws.String("outputId")).Return(mockTestOutputItem, nil)
+	mockEncryptionKey.On("DecryptConfig", make([]byte, 1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.OutputConfig).Slack = mockSlackConfig
+	})
+	mockClient.On("Slack", mock.Anything, mockSlackConfig).Return(deliveryErr)
+	return mockClient
+}
+
+func TestTestOutput(t *testing.T) {
+	mockClient := setupTestOutput(nil)
+
+	result, err := (API{}).TestOutput(mockTestOutputInput)
+	require.NoError(t, err)
+	assert.Equal(t, &models.TestOutputOutput{Success: aws.Bool(true)}, result)
+
+	alert := mockClient.Calls[0].Arguments.Get(0).(*deliverymodels.Alert)
+	assert.True(t, strings.HasPrefix(*alert.Title, "[TEST] "))
+	assert.True(t, strings.HasPrefix(*alert.PolicyName, "[TEST] "))
+	// The sample alert used by the template previews is not modified
+	assert.Equal(t, "Root login from 1.2.3.4", *sampleAlert.Title)
+	mockClient.AssertExpectations(t)
+}
+
+func TestTestOutputDeliveryFails(t *testing.T) {
+	mockClient := setupTestOutput(&outputs.AlertDeliveryError{Message: "request failed: 404 Not Found", StatusCode: 404})
+
+	result, err := (API{}).TestOutput(mockTestOutputInput)
+	require.NoError(t, err)
+	assert.Equal(t, &models.TestOutputOutput{
+		Success:        aws.Bool(false),
+		Message:        aws.String("request failed: 404 Not Found"),
+		HTTPStatusCode: aws.Int(404),
+	}, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestTestOutputDoesNotExist(t *testing.T) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockOutputsTable.On("GetOutput", aws.String("outputId")).Return(
+		(*models.AlertOutputItem)(nil), &genericapi.DoesNotExistError{})
+
+	result, err := (API{}).TestOutput(mockTestOutputInput)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockOutputsTable.AssertExpectations(t)
+}
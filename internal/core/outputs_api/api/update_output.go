@@ -45,6 +45,10 @@ func (API) UpdateOutput(input *models.UpdateOutputInput) (*models.UpdateOutputOu
 		return nil, err
 	}
 
+	if err = validateTemplate(input.OutputConfig); err != nil {
+		return nil, err
+	}
+
 	alertOutput := &models.AlertOutput{
 		DisplayName:        input.DisplayName,
 		LastModifiedBy:     input.UserID,
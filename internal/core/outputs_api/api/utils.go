@@ -29,14 +29,16 @@ import (
 // AlertOutputToItem converts an AlertOutput to an AlertOutputItem
 func AlertOutputToItem(input *models.AlertOutput) (*models.AlertOutputItem, error) {
 	item := &models.AlertOutputItem{
-		CreatedBy:          input.CreatedBy,
-		CreationTime:       input.CreationTime,
-		DisplayName:        input.DisplayName,
-		LastModifiedBy:     input.LastModifiedBy,
-		LastModifiedTime:   input.LastModifiedTime,
-		OutputID:           input.OutputID,
-		OutputType:         input.OutputType,
-		VerificationStatus: input.VerificationStatus,
+		ConsecutiveFailures: input.ConsecutiveFailures,
+		CreatedBy:           input.CreatedBy,
+		CreationTime:        input.CreationTime,
+		DisplayName:         input.DisplayName,
+		HealthStatus:        input.HealthStatus,
+		LastModifiedBy:      input.LastModifiedBy,
+		LastModifiedTime:    input.LastModifiedTime,
+		OutputID:            input.OutputID,
+		OutputType:          input.OutputType,
+		VerificationStatus:  input.VerificationStatus,
 	}
 
 	encryptedConfig, err := encryptionKey.EncryptConfig(input.OutputConfig)
@@ -53,14 +55,16 @@ func AlertOutputToItem(input *models.AlertOutput) (*models.AlertOutputItem, erro
 // ItemToAlertOutput converts an AlertOutputItem to an AlertOutput
 func ItemToAlertOutput(input *models.AlertOutputItem) (alertOutput *models.AlertOutput, err error) {
 	alertOutput = &models.AlertOutput{
-		CreatedBy:          input.CreatedBy,
-		CreationTime:       input.CreationTime,
-		DisplayName:        input.DisplayName,
-		LastModifiedBy:     input.LastModifiedBy,
-		LastModifiedTime:   input.LastModifiedTime,
-		OutputID:           input.OutputID,
-		OutputType:         input.OutputType,
-		VerificationStatus: input.VerificationStatus,
+		ConsecutiveFailures: input.ConsecutiveFailures,
+		CreatedBy:           input.CreatedBy,
+		CreationTime:        input.CreationTime,
+		DisplayName:         input.DisplayName,
+		HealthStatus:        input.HealthStatus,
+		LastModifiedBy:      input.LastModifiedBy,
+		LastModifiedTime:    input.LastModifiedTime,
+		OutputID:            input.OutputID,
+		OutputType:          input.OutputType,
+		VerificationStatus:  input.VerificationStatus,
 	}
 
 	alertOutput.OutputConfig = &models.OutputConfig{}
@@ -101,6 +105,18 @@ func getOutputType(outputConfig *models.OutputConfig) (*string, error) {
 	if outputConfig.Sqs != nil {
 		return aws.String("sqs"), nil
 	}
+	if outputConfig.Webhook != nil {
+		return aws.String("webhook"), nil
+	}
+	if outputConfig.ServiceNow != nil {
+		return aws.String("servicenow"), nil
+	}
+	if outputConfig.Asana != nil {
+		return aws.String("asana"), nil
+	}
+	if outputConfig.SplunkHEC != nil {
+		return aws.String("splunkhec"), nil
+	}
 
 	return nil, errors.New("no valid output configuration specified for alert output")
 }
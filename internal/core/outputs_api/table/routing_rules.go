@@ -0,0 +1,134 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// PutRoutingRule creates or replaces a routing rule.
+func (table *RoutingRulesTable) PutRoutingRule(rule *models.RoutingRule) error {
+	item, err := dynamodbattribute.MarshalMap(rule)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal RoutingRule to a dynamo item: " + err.Error()}
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: table.Name,
+	}
+	if _, err = table.client.PutItem(input); err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.PutItem", Err: err}
+	}
+	return nil
+}
+
+// GetRoutingRule returns a routing rule, nil if it does not exist.
+func (table *RoutingRulesTable) GetRoutingRule(routingRuleID *string) (*models.RoutingRule, error) {
+	result, err := table.client.GetItem(&dynamodb.GetItemInput{
+		TableName: table.Name,
+		Key: DynamoItem{
+			"routingRuleId": {S: routingRuleID},
+		},
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.GetItem", Err: err}
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var rule models.RoutingRule
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &rule); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal dynamo item to a RoutingRule: " + err.Error()}
+	}
+	return &rule, nil
+}
+
+// GetRoutingRules returns all the routing rules sorted by priority.
+func (table *RoutingRulesTable) GetRoutingRules() (rules []*models.RoutingRule, err error) {
+	var scanInput = &dynamodb.ScanInput{
+		TableName: table.Name,
+	}
+
+	var internalErr error
+	scanErr := table.client.ScanPages(scanInput,
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			var rulesPartial []*models.RoutingRule
+			if internalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &rulesPartial); internalErr != nil {
+				return false
+			}
+			rules = append(rules, rulesPartial...)
+			return true
+		})
+
+	if scanErr != nil {
+		return nil, &genericapi.AWSError{Err: scanErr, Method: "dynamodb.ScanPages"}
+	}
+	if internalErr != nil {
+		return nil, &genericapi.InternalError{
+			Message: "failed to unmarshal dynamo items: " + internalErr.Error()}
+	}
+
+	// Rules with the same priority are evaluated in a stable order
+	sort.Slice(rules, func(i, j int) bool {
+		left, right := aws.IntValue(rules[i].Priority), aws.IntValue(rules[j].Priority)
+		if left != right {
+			return left < right
+		}
+		return aws.StringValue(rules[i].RoutingRuleID) < aws.StringValue(rules[j].RoutingRuleID)
+	})
+	return rules, nil
+}
+
+// DeleteRoutingRule removes a routing rule from the table.
+func (table *RoutingRulesTable) DeleteRoutingRule(routingRuleID *string) error {
+	condition := expression.Name("routingRuleId").Equal(expression.Value(routingRuleID))
+	conditionExpression, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	_, err = table.client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: table.Name,
+		Key: DynamoItem{
+			"routingRuleId": {S: routingRuleID},
+		},
+		ConditionExpression:       conditionExpression.Condition(),
+		ExpressionAttributeNames:  conditionExpression.Names(),
+		ExpressionAttributeValues: conditionExpression.Values(),
+	})
+	if err != nil {
+		aerr, ok := err.(awserr.Error)
+		if ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return &genericapi.DoesNotExistError{Message: "routingRuleId=" + *routingRuleID}
+		}
+		return &genericapi.AWSError{Method: "dynamodb.DeleteItem", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestPutRoutingRule(t *testing.T) {
+	mockClient := &mockDynamoDB{}
+	table := &RoutingRulesTable{client: mockClient, Name: aws.String("routingTable")}
+
+	rule := &models.RoutingRule{
+		RoutingRuleID: aws.String("routingRuleId"),
+		Priority:      aws.Int(10),
+		Match:         &models.RoutingMatch{Tags: aws.StringSlice([]string{"pci"})},
+		OutputIDs:     aws.StringSlice([]string{"outputId"}),
+	}
+	mockClient.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil)
+
+	require.NoError(t, table.PutRoutingRule(rule))
+	input := mockClient.Calls[0].Arguments.Get(0).(*dynamodb.PutItemInput)
+	assert.Equal(t, aws.String("routingTable"), input.TableName)
+	assert.Equal(t, aws.String("routingRuleId"), input.Item["routingRuleId"].S)
+	assert.Equal(t, aws.String("10"), input.Item["priority"].N)
+	assert.Equal(t, aws.String("pci"), input.Item["match"].M["tags"].L[0].S)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetRoutingRuleDoesNotExist(t *testing.T) {
+	mockClient := &mockDynamoDB{}
+	table := &RoutingRulesTable{client: mockClient, Name: aws.String("routingTable")}
+	mockClient.On("GetItem", &dynamodb.GetItemInput{
+		TableName: aws.String("routingTable"),
+		Key:       DynamoItem{"routingRuleId": {S: aws.String("routingRuleId")}},
+	}).Return(&dynamodb.GetItemOutput{}, nil)
+
+	result, err := table.GetRoutingRule(aws.String("routingRuleId"))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetRoutingRulesSorted(t *testing.T) {
+	defaultScanOutput := mockScanOutput
+	defer func() { mockScanOutput = defaultScanOutput }()
+	mockScanOutput = &dynamodb.ScanOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"routingRuleId": {S: aws.String("c")}, "priority": {N: aws.String("20")}},
+			{"routingRuleId": {S: aws.String("b")}, "priority": {N: aws.String("10")}},
+			{"routingRuleId": {S: aws.String("a")}, "priority": {N: aws.String("20")}},
+		},
+	}
+
+	mockClient := &mockDynamoDB{}
+	table := &RoutingRulesTable{client: mockClient, Name: aws.String("routingTable")}
+	mockClient.On("ScanPages", &dynamodb.ScanInput{TableName: aws.String("routingTable")}, mock.Anything).Return(nil)
+
+	result, err := table.GetRoutingRules()
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, "b", *result[0].RoutingRuleID)
+	assert.Equal(t, "a", *result[1].RoutingRuleID)
+	assert.Equal(t, "c", *result[2].RoutingRuleID)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDeleteRoutingRuleDoesNotExist(t *testing.T) {
+	mockClient := &mockDynamoDB{}
+	table := &RoutingRulesTable{client: mockClient, Name: aws.String("routingTable")}
+	mockClient.On("DeleteItem", mock.Anything).Return(
+		&dynamodb.DeleteItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "attribute does not exist", nil))
+
+	err := table.DeleteRoutingRule(aws.String("routingRuleId"))
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockClient.AssertExpectations(t)
+}
@@ -36,6 +36,7 @@ type OutputsAPI interface {
 	GetOutput(*string) (*models.AlertOutputItem, error)
 	PutOutput(*models.AlertOutputItem) error
 	UpdateOutput(*models.AlertOutputItem) (*models.AlertOutputItem, error)
+	UpdateHealth(*string, *string, *int) error
 }
 
 // OutputsTable encapsulates a connection to the Dynamo rules table.
@@ -75,5 +76,27 @@ func NewDefaults(name string, sess *session.Session) *DefaultsTable {
 	}
 }
 
+// RoutingRulesAPI defines the interface for the table storing the alert routing rules
+type RoutingRulesAPI interface {
+	PutRoutingRule(rule *models.RoutingRule) error
+	GetRoutingRule(routingRuleID *string) (*models.RoutingRule, error)
+	GetRoutingRules() ([]*models.RoutingRule, error)
+	DeleteRoutingRule(routingRuleID *string) error
+}
+
+// RoutingRulesTable allows interacting with DDB table storing the alert routing rules
+type RoutingRulesTable struct {
+	Name   *string
+	client dynamodbiface.DynamoDBAPI
+}
+
+// NewRoutingRules creates an AWS client to interface with the routing rules table.
+func NewRoutingRules(name string, sess *session.Session) *RoutingRulesTable {
+	return &RoutingRulesTable{
+		Name:   aws.String(name),
+		client: dynamodb.New(sess),
+	}
+}
+
 // DynamoItem is a type alias for the item format expected by the Dynamo SDK.
 type DynamoItem = map[string]*dynamodb.AttributeValue
@@ -80,3 +80,41 @@ func (table *OutputsTable) UpdateOutput(alertOutput *models.AlertOutputItem) (*m
 	}
 	return &output, nil
 }
+
+// UpdateHealth records the health status of an existing output
+func (table *OutputsTable) UpdateHealth(outputID *string, healthStatus *string, consecutiveFailures *int) error {
+	updateExpression := expression.
+		Set(expression.Name("healthStatus"), expression.Value(healthStatus)).
+		Set(expression.Name("consecutiveFailures"), expression.Value(consecutiveFailures))
+
+	conditionExpression := expression.Name("outputId").Equal(expression.Value(outputID))
+	combinedExpression, err := expression.NewBuilder().
+		WithCondition(conditionExpression).
+		WithUpdate(updateExpression).
+		Build()
+
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	_, err = table.client.UpdateItem(
+		&dynamodb.UpdateItemInput{
+			TableName: table.Name,
+			Key: DynamoItem{
+				"outputId": {S: outputID},
+			},
+			UpdateExpression:          combinedExpression.Update(),
+			ConditionExpression:       combinedExpression.Condition(),
+			ExpressionAttributeNames:  combinedExpression.Names(),
+			ExpressionAttributeValues: combinedExpression.Values(),
+		})
+
+	if err != nil {
+		aerr, ok := err.(awserr.Error)
+		if ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return &genericapi.DoesNotExistError{Message: "outputId=" + *outputID}
+		}
+		return &genericapi.AWSError{Method: "dynamodb.UpdateItem", Err: err}
+	}
+	return nil
+}
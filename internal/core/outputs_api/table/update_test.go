@@ -182,3 +182,46 @@ func TestUpdateMarshallingError(t *testing.T) {
 	assert.NotNil(t, err.(*genericapi.InternalError))
 	dynamoDBClient.AssertExpectations(t)
 }
+
+func TestUpdateHealth(t *testing.T) {
+	dynamoDBClient := &mockDynamoDB{}
+	table := &OutputsTable{client: dynamoDBClient, Name: aws.String("TableName")}
+
+	expectedUpdateExpression := expression.
+		Set(expression.Name("healthStatus"), expression.Value(aws.String(models.HealthStatusUnhealthy))).
+		Set(expression.Name("consecutiveFailures"), expression.Value(aws.Int(3)))
+	expectedConditionExpression := expression.Name("outputId").Equal(expression.Value(aws.String("outputId")))
+	expectedExpression, _ := expression.NewBuilder().
+		WithCondition(expectedConditionExpression).
+		WithUpdate(expectedUpdateExpression).
+		Build()
+
+	expectedUpdateItemInput := &dynamodb.UpdateItemInput{
+		Key: DynamoItem{
+			"outputId": {S: aws.String("outputId")},
+		},
+		TableName:                 aws.String("TableName"),
+		UpdateExpression:          expectedExpression.Update(),
+		ConditionExpression:       expectedExpression.Condition(),
+		ExpressionAttributeNames:  expectedExpression.Names(),
+		ExpressionAttributeValues: expectedExpression.Values(),
+	}
+
+	dynamoDBClient.On("UpdateItem", expectedUpdateItemInput).Return(&dynamodb.UpdateItemOutput{}, nil)
+	assert.NoError(t, table.UpdateHealth(aws.String("outputId"), aws.String(models.HealthStatusUnhealthy), aws.Int(3)))
+	dynamoDBClient.AssertExpectations(t)
+}
+
+func TestUpdateHealthDoesNotExist(t *testing.T) {
+	dynamoDBClient := &mockDynamoDB{}
+	table := &OutputsTable{client: dynamoDBClient, Name: aws.String("TableName")}
+
+	dynamoDBClient.On("UpdateItem", mock.Anything).Return(
+		&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "attribute does not exist", nil))
+
+	err := table.UpdateHealth(aws.String("outputId"), aws.String(models.HealthStatusHealthy), aws.Int(0))
+	assert.Error(t, err)
+	assert.NotNil(t, err.(*genericapi.DoesNotExistError))
+	dynamoDBClient.AssertExpectations(t)
+}
@@ -0,0 +1,198 @@
+package archiver
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+)
+
+// ArchivePrefix is the location of the archived alerts in the alert events bucket, a Glue table is defined over it
+const ArchivePrefix = "archive/alerts/"
+
+var (
+	env          envConfig
+	alertsDB     table.API
+	s3Client     s3iface.S3API
+	lambdaClient lambdaiface.LambdaAPI
+
+	// errDeadline stops archiving before the Lambda times out
+	errDeadline = errors.New("archiver deadline reached")
+)
+
+type envConfig struct {
+	AlertsTableName     string `required:"true" split_words:"true"`
+	ExpiryIndexName     string `required:"true" split_words:"true"`
+	CommentsTableName   string `required:"true" split_words:"true"`
+	CommentsIndexName   string `required:"true" split_words:"true"`
+	DeliveriesTableName string `required:"true" split_words:"true"`
+	HistoryTableName    string `required:"true" split_words:"true"`
+	AlertEventsBucket   string `required:"true" split_words:"true"`
+	OrganizationsAPI    string `required:"true" split_words:"true"`
+}
+
+// archivedAlert is the JSON line written to S3 for an expired alert
+type archivedAlert struct {
+	*models.AlertItem
+	Events     []*string               `json:"events"`
+	Comments   []*models.AlertComment  `json:"comments"`
+	Deliveries []*models.AlertDelivery `json:"deliveries"`
+	ArchivedAt time.Time               `json:"archivedAt"`
+}
+
+// Setup parses the environment and builds the AWS clients.
+func Setup() {
+	envconfig.MustProcess("", &env)
+
+	awsSession := session.Must(session.NewSession())
+	s3Client = s3.New(awsSession)
+	lambdaClient = lambda.New(awsSession)
+	alertsDB = &table.AlertsTable{
+		AlertsTableName:                 env.AlertsTableName,
+		TimePartitionExpiresAtIndexName: env.ExpiryIndexName,
+		CommentsTableName:               env.CommentsTableName,
+		CommentsCreatedAtIndexName:      env.CommentsIndexName,
+		DeliveriesTableName:             env.DeliveriesTableName,
+		HistoryTableName:                env.HistoryTableName,
+		EventsBucket:                    env.AlertEventsBucket,
+		Client:                          dynamodb.New(awsSession),
+		S3Client:                        s3Client,
+	}
+}
+
+// Archive writes the alerts whose retention period ended to S3 and deletes them with their events, comments,
+// deliveries and history
+//
+// Alerts are archived one at a time, the alerts left at the deadline are archived by the next run.
+// The remaining time is then used to apply the retention settings to the existing alerts.
+func Archive(deadline time.Time) error {
+	now := time.Now()
+	count := 0
+	err := alertsDB.ListExpiredAlerts(now, func(alertIDs []*string) error {
+		for _, alertID := range alertIDs {
+			if time.Now().After(deadline) {
+				return errDeadline
+			}
+			if err := archiveAlert(alertID, now); err != nil {
+				zap.L().Error("failed to archive alert", zap.String("alertId", *alertID), zap.Error(err))
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	zap.L().Info("archived expired alerts", zap.Int("count", count))
+	if err == errDeadline {
+		zap.L().Warn("stopped archiving at the deadline")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return applyRetention(deadline)
+}
+
+// archiveAlert writes an alert to S3 and only then deletes it
+func archiveAlert(alertID *string, now time.Time) error {
+	alert, err := alertsDB.GetAlert(alertID)
+	if err != nil {
+		return err
+	}
+	// The index is eventually consistent, the alert may have been deleted or its retention extended
+	if alert.AlertID == nil || alert.ExpiresAt == nil || alert.ExpiresAt.After(now) {
+		return nil
+	}
+
+	events, _, err := alertsDB.ListEvents(alert.AlertID, nil, nil)
+	if err != nil {
+		return err
+	}
+	comments, err := listComments(alert.AlertID)
+	if err != nil {
+		return err
+	}
+	deliveries, err := alertsDB.ListDeliveries(alert.AlertID)
+	if err != nil {
+		return err
+	}
+	if alert.History, err = alertsDB.GetAlertHistory(alert); err != nil {
+		return err
+	}
+
+	body, err := jsoniter.Marshal(&archivedAlert{
+		AlertItem:  alert,
+		Events:     events,
+		Comments:   comments,
+		Deliveries: deliveries,
+		ArchivedAt: now.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(env.AlertEventsBucket),
+		Key:         aws.String(archiveKey(alert)),
+		Body:        bytes.NewReader(append(body, '\n')),
+		ContentType: aws.String("application/json"),
+	}); err != nil {
+		return err
+	}
+
+	return alertsDB.DeleteAlert(alert.AlertID)
+}
+
+// listComments returns all the comments of an alert
+func listComments(alertID *string) ([]*models.AlertComment, error) {
+	var result []*models.AlertComment
+	var exclusiveStartKey *string
+	for {
+		comments, lastEvaluatedKey, err := alertsDB.ListComments(alertID, exclusiveStartKey, nil)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, comments...)
+		if lastEvaluatedKey == nil {
+			return result, nil
+		}
+		exclusiveStartKey = lastEvaluatedKey
+	}
+}
+
+// archiveKey groups the archived alerts by the day they were created
+func archiveKey(alert *models.AlertItem) string {
+	return path.Join(ArchivePrefix, alert.CreationTime.UTC().Format("2006/01/02"), *alert.AlertID+".json")
+}
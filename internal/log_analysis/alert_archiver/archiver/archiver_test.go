@@ -0,0 +1,334 @@
+package archiver
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+)
+
+type mockAlertsTable struct {
+	table.API
+	mock.Mock
+}
+
+// ListExpiredAlerts calls the handler with the pages of alert IDs given to Return
+func (m *mockAlertsTable) ListExpiredAlerts(now time.Time, handler func([]*string) error) error {
+	args := m.Called(now)
+	for _, page := range args.Get(0).([][]string) {
+		if err := handler(aws.StringSlice(page)); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockAlertsTable) GetAlert(alertID *string) (*models.AlertItem, error) {
+	args := m.Called(alertID)
+	return args.Get(0).(*models.AlertItem), args.Error(1)
+}
+
+func (m *mockAlertsTable) ScanAlertExpiry(exclusiveStartKey *string) ([]*models.AlertItem, *string, error) {
+	args := m.Called(exclusiveStartKey)
+	return args.Get(0).([]*models.AlertItem), args.Get(1).(*string), args.Error(2)
+}
+
+func (m *mockAlertsTable) SetAlertExpiry(alertID *string, expiresAt *time.Time) error {
+	args := m.Called(alertID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *mockAlertsTable) ListEvents(alertID, exclusiveStartKey *string, pageSize *int) ([]*string, *string, error) {
+	args := m.Called(alertID, exclusiveStartKey, pageSize)
+	return args.Get(0).([]*string), args.Get(1).(*string), args.Error(2)
+}
+
+func (m *mockAlertsTable) ListComments(alertID, exclusiveStartKey *string, pageSize *int) (
+	[]*models.AlertComment, *string, error) {
+
+	args := m.Called(alertID, exclusiveStartKey, pageSize)
+	return args.Get(0).([]*models.AlertComment), args.Get(1).(*string), args.Error(2)
+}
+
+func (m *mockAlertsTable) ListDeliveries(alertID *string) ([]*models.AlertDelivery, error) {
+	args := m.Called(alertID)
+	return args.Get(0).([]*models.AlertDelivery), args.Error(1)
+}
+
+func (m *mockAlertsTable) GetAlertHistory(alertItem *models.AlertItem) ([]*models.AlertChange, error) {
+	args := m.Called(alertItem)
+	return args.Get(0).([]*models.AlertChange), args.Error(1)
+}
+
+func (m *mockAlertsTable) DeleteAlert(alertID *string) error {
+	args := m.Called(alertID)
+	return args.Error(0)
+}
+
+type mockS3 struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (m *mockS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
+func (m *mockS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
+}
+
+type mockLambda struct {
+	lambdaiface.LambdaAPI
+	mock.Mock
+}
+
+func (m *mockLambda) Invoke(input *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*lambda.InvokeOutput), args.Error(1)
+}
+
+var expiredAlert = &models.AlertItem{
+	AlertID:      aws.String("alert-id"),
+	RuleID:       aws.String("rule.id"),
+	CreationTime: aws.Time(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+	ExpiresAt:    aws.Time(time.Date(2020, 2, 1, 3, 4, 5, 0, time.UTC)),
+}
+
+// retentionSettings keeps LOW alerts for 30 days and the other alerts indefinitely
+const retentionSettings = `{"severityRetentionDays":[{"severity":"LOW","retentionDays":30}]}`
+
+func setupMocks() (*mockAlertsTable, *mockS3, *mockLambda) {
+	tableMock, s3Mock, lambdaMock := &mockAlertsTable{}, &mockS3{}, &mockLambda{}
+	alertsDB, s3Client, lambdaClient = tableMock, s3Mock, lambdaMock
+	env.AlertEventsBucket = "bucket"
+	env.OrganizationsAPI = "panther-organization-api"
+	return tableMock, s3Mock, lambdaMock
+}
+
+func mockRetention(s3Mock *mockS3, lambdaMock *mockLambda, settings string, state *retentionState) {
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{
+		Payload: []byte(`{"organization":{"alertRetention":` + settings + `}}`),
+	}, nil).Once()
+	if state == nil {
+		s3Mock.On("GetObject", mock.Anything).Return(&s3.GetObjectOutput{},
+			awserr.New(s3.ErrCodeNoSuchKey, "", nil)).Once()
+		return
+	}
+	body, _ := jsoniter.Marshal(state)
+	s3Mock.On("GetObject", mock.Anything).Return(
+		&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(body))}, nil).Once()
+}
+
+func appliedRetention(settings string) *retentionState {
+	state := &retentionState{Complete: true}
+	if err := jsoniter.UnmarshalFromString(settings, &state.Settings); err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func TestArchive(t *testing.T) {
+	tableMock, s3Mock, lambdaMock := setupMocks()
+
+	tableMock.On("ListExpiredAlerts", mock.Anything).Return([][]string{{"alert-id"}}, nil).Once()
+	tableMock.On("GetAlert", aws.String("alert-id")).Return(expiredAlert, nil).Once()
+	tableMock.On("ListEvents", expiredAlert.AlertID, (*string)(nil), (*int)(nil)).
+		Return([]*string{aws.String(`{"event":1}`)}, (*string)(nil), nil).Once()
+	comment := &models.AlertComment{AlertID: expiredAlert.AlertID, CommentID: aws.String("comment-id")}
+	tableMock.On("ListComments", expiredAlert.AlertID, (*string)(nil), (*int)(nil)).
+		Return([]*models.AlertComment{comment}, aws.String("next"), nil).Once()
+	tableMock.On("ListComments", expiredAlert.AlertID, aws.String("next"), (*int)(nil)).
+		Return([]*models.AlertComment{comment}, (*string)(nil), nil).Once()
+	delivery := &models.AlertDelivery{AlertID: expiredAlert.AlertID, DeliveryID: aws.String("delivery-id")}
+	tableMock.On("ListDeliveries", expiredAlert.AlertID).Return([]*models.AlertDelivery{delivery}, nil).Once()
+	change := &models.AlertChange{AlertID: expiredAlert.AlertID, ChangeID: aws.String("change-id")}
+	tableMock.On("GetAlertHistory", expiredAlert).Return([]*models.AlertChange{change}, nil).Once()
+	s3Mock.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+	tableMock.On("DeleteAlert", expiredAlert.AlertID).Return(nil).Once()
+	mockRetention(s3Mock, lambdaMock, retentionSettings, appliedRetention(retentionSettings))
+
+	require.NoError(t, Archive(time.Now().Add(time.Hour)))
+
+	input := s3Mock.Calls[0].Arguments[0].(*s3.PutObjectInput)
+	assert.Equal(t, "archive/alerts/2020/01/02/alert-id.json", *input.Key)
+	body, err := ioutil.ReadAll(input.Body)
+	require.NoError(t, err)
+	var archived struct {
+		AlertID  string   `json:"alertId"`
+		Events   []string `json:"events"`
+		Comments []struct {
+			CommentID string `json:"commentId"`
+		} `json:"comments"`
+		Deliveries []struct {
+			DeliveryID string `json:"deliveryId"`
+		} `json:"deliveries"`
+		History []struct {
+			ChangeID string `json:"changeId"`
+		} `json:"history"`
+	}
+	require.NoError(t, jsoniter.Unmarshal(body, &archived))
+	assert.Equal(t, "alert-id", archived.AlertID)
+	assert.Equal(t, []string{`{"event":1}`}, archived.Events)
+	assert.Len(t, archived.Comments, 2)
+	require.Len(t, archived.Deliveries, 1)
+	assert.Equal(t, "delivery-id", archived.Deliveries[0].DeliveryID)
+	require.Len(t, archived.History, 1)
+	assert.Equal(t, "change-id", archived.History[0].ChangeID)
+	tableMock.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+	lambdaMock.AssertExpectations(t)
+}
+
+func TestArchiveKeepsAlertOnFailure(t *testing.T) {
+	tableMock, s3Mock, lambdaMock := setupMocks()
+
+	tableMock.On("ListExpiredAlerts", mock.Anything).Return([][]string{{"alert-id"}}, nil).Once()
+	tableMock.On("GetAlert", aws.String("alert-id")).Return(expiredAlert, nil).Once()
+	tableMock.On("ListEvents", expiredAlert.AlertID, (*string)(nil), (*int)(nil)).
+		Return([]*string{}, (*string)(nil), nil).Once()
+	tableMock.On("ListComments", expiredAlert.AlertID, (*string)(nil), (*int)(nil)).
+		Return([]*models.AlertComment{}, (*string)(nil), nil).Once()
+	tableMock.On("ListDeliveries", expiredAlert.AlertID).Return([]*models.AlertDelivery{}, nil).Once()
+	tableMock.On("GetAlertHistory", expiredAlert).Return([]*models.AlertChange{}, nil).Once()
+	s3Mock.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, errors.New("access denied")).Once()
+
+	// The alert is not deleted if it could not be archived
+	assert.Error(t, Archive(time.Now().Add(time.Hour)))
+	tableMock.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+	lambdaMock.AssertExpectations(t)
+}
+
+func TestArchiveSkipsUnexpiredAlert(t *testing.T) {
+	tableMock, s3Mock, lambdaMock := setupMocks()
+
+	// The retention of the alert was extended after the index was read
+	extended := *expiredAlert
+	extended.ExpiresAt = aws.Time(time.Now().Add(time.Hour))
+	tableMock.On("ListExpiredAlerts", mock.Anything).Return([][]string{{"alert-id"}}, nil).Once()
+	tableMock.On("GetAlert", aws.String("alert-id")).Return(&extended, nil).Once()
+	mockRetention(s3Mock, lambdaMock, retentionSettings, appliedRetention(retentionSettings))
+
+	require.NoError(t, Archive(time.Now().Add(time.Hour)))
+	tableMock.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+	lambdaMock.AssertExpectations(t)
+}
+
+func TestArchiveDeadline(t *testing.T) {
+	tableMock, s3Mock, lambdaMock := setupMocks()
+
+	tableMock.On("ListExpiredAlerts", mock.Anything).Return([][]string{{"alert-id"}}, nil).Once()
+
+	// The remaining alerts are archived by the next run
+	require.NoError(t, Archive(time.Now().Add(-time.Minute)))
+	tableMock.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+	lambdaMock.AssertExpectations(t)
+}
+
+func TestApplyRetention(t *testing.T) {
+	tableMock, s3Mock, lambdaMock := setupMocks()
+	mockRetention(s3Mock, lambdaMock, retentionSettings, nil)
+
+	creationTime := aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	tableMock.On("ScanAlertExpiry", (*string)(nil)).Return([]*models.AlertItem{
+		{AlertID: aws.String("alert-1"), Severity: aws.String("LOW"), CreationTime: creationTime},
+		{AlertID: aws.String("alert-2"), Severity: aws.String("CRITICAL"), CreationTime: creationTime},
+	}, aws.String("alert-2"), nil).Once()
+	tableMock.On("ScanAlertExpiry", aws.String("alert-2")).Return([]*models.AlertItem{
+		{AlertID: aws.String("alert-3"), Severity: aws.String("HIGH"), CreationTime: creationTime, ExpiresAt: creationTime},
+		{
+			AlertID:      aws.String("alert-4"),
+			Severity:     aws.String("LOW"),
+			CreationTime: creationTime,
+			ExpiresAt:    aws.Time(time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)),
+		},
+	}, (*string)(nil), nil).Once()
+	tableMock.On("SetAlertExpiry", aws.String("alert-1"), aws.Time(time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC))).
+		Return(nil).Once()
+	tableMock.On("SetAlertExpiry", aws.String("alert-3"), (*time.Time)(nil)).Return(nil).Once()
+	s3Mock.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Twice()
+
+	require.NoError(t, applyRetention(time.Now().Add(time.Hour)))
+
+	var state retentionState
+	input := s3Mock.Calls[1].Arguments[0].(*s3.PutObjectInput)
+	assert.Equal(t, "archive/retention.json", *input.Key)
+	require.NoError(t, jsoniter.NewDecoder(input.Body).Decode(&state))
+	assert.Equal(t, "alert-2", *state.ExclusiveStartKey)
+	assert.False(t, state.Complete)
+	input = s3Mock.Calls[2].Arguments[0].(*s3.PutObjectInput)
+	require.NoError(t, jsoniter.NewDecoder(input.Body).Decode(&state))
+	assert.True(t, state.Complete)
+	assert.Equal(t, int64(30), state.Settings.Days("LOW"))
+	tableMock.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+	lambdaMock.AssertExpectations(t)
+}
+
+func TestApplyRetentionResumes(t *testing.T) {
+	tableMock, s3Mock, lambdaMock := setupMocks()
+	state := appliedRetention(retentionSettings)
+	state.Complete = false
+	state.ExclusiveStartKey = aws.String("alert-2")
+	mockRetention(s3Mock, lambdaMock, retentionSettings, state)
+
+	tableMock.On("ScanAlertExpiry", aws.String("alert-2")).Return([]*models.AlertItem{}, (*string)(nil), nil).Once()
+	s3Mock.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+
+	require.NoError(t, applyRetention(time.Now().Add(time.Hour)))
+	tableMock.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+	lambdaMock.AssertExpectations(t)
+}
+
+func TestApplyRetentionSettingsChanged(t *testing.T) {
+	tableMock, s3Mock, lambdaMock := setupMocks()
+	mockRetention(s3Mock, lambdaMock, `{"retentionDays":90}`, appliedRetention(retentionSettings))
+
+	// The scan starts over with the new settings
+	tableMock.On("ScanAlertExpiry", (*string)(nil)).Return([]*models.AlertItem{}, (*string)(nil), nil).Once()
+	s3Mock.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+
+	require.NoError(t, applyRetention(time.Now().Add(time.Hour)))
+	tableMock.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+	lambdaMock.AssertExpectations(t)
+}
@@ -0,0 +1,171 @@
+package archiver
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	organizationmodels "github.com/panther-labs/panther/api/lambda/organization/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// retentionStateKey is the S3 object recording which retention settings were applied to the existing alerts
+const retentionStateKey = "archive/retention.json"
+
+// retentionState is the progress of applying the retention settings to the existing alerts
+type retentionState struct {
+	Settings *organizationmodels.AlertRetention `json:"settings"`
+	// ExclusiveStartKey is the alert the scan resumes after
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+	Complete          bool    `json:"complete"`
+}
+
+// applyRetention sets the expiry of all the existing alerts when the retention settings changed
+//
+// New alerts get their expiry from the alert merger, this covers the alerts created before the settings changed
+// or before retention was configured. The alerts are scanned a page at a time and the progress is saved after
+// each page, the next run resumes the scan at the deadline.
+func applyRetention(deadline time.Time) error {
+	settings, err := getRetentionSettings()
+	if err != nil {
+		return err
+	}
+	state, err := getRetentionState()
+	if err != nil {
+		return err
+	}
+
+	if state == nil || !sameSettings(state.Settings, settings) {
+		zap.L().Info("applying the retention settings to the existing alerts")
+		state = &retentionState{Settings: settings}
+	} else if state.Complete {
+		return nil
+	}
+
+	for time.Now().Before(deadline) {
+		alerts, lastEvaluatedKey, err := alertsDB.ScanAlertExpiry(state.ExclusiveStartKey)
+		if err != nil {
+			return err
+		}
+		for _, alert := range alerts {
+			if err = updateExpiry(alert, settings); err != nil {
+				return err
+			}
+		}
+
+		state.ExclusiveStartKey = lastEvaluatedKey
+		state.Complete = lastEvaluatedKey == nil
+		if err = putRetentionState(state); err != nil {
+			return err
+		}
+		if state.Complete {
+			zap.L().Info("applied the retention settings to the existing alerts")
+			return nil
+		}
+	}
+	zap.L().Warn("stopped applying the retention settings at the deadline")
+	return nil
+}
+
+// updateExpiry sets the expiry of an alert from the retention settings if it changed
+func updateExpiry(alert *models.AlertItem, settings *organizationmodels.AlertRetention) error {
+	var expiresAt *time.Time
+	if days := settings.Days(aws.StringValue(alert.Severity)); days > 0 && alert.CreationTime != nil {
+		expiresAt = aws.Time(alert.CreationTime.UTC().Add(time.Duration(days) * 24 * time.Hour))
+	}
+
+	if expiresAt == nil && alert.ExpiresAt == nil {
+		return nil
+	}
+	if expiresAt != nil && alert.ExpiresAt != nil && expiresAt.Equal(*alert.ExpiresAt) {
+		return nil
+	}
+	return alertsDB.SetAlertExpiry(alert.AlertID, expiresAt)
+}
+
+// getRetentionSettings returns the alert retention settings of the organization
+func getRetentionSettings() (*organizationmodels.AlertRetention, error) {
+	input := &organizationmodels.LambdaInput{GetOrganization: &organizationmodels.GetOrganizationInput{}}
+	var output organizationmodels.GetOrganizationOutput
+	if err := genericapi.Invoke(lambdaClient, env.OrganizationsAPI, input, &output); err != nil {
+		return nil, err
+	}
+	if output.Organization == nil {
+		return nil, nil
+	}
+	return output.Organization.AlertRetention, nil
+}
+
+// sameSettings compares the settings as they are serialized, unset and empty lists are equivalent
+func sameSettings(left, right *organizationmodels.AlertRetention) bool {
+	leftJSON, err := jsoniter.Marshal(left)
+	if err != nil {
+		return false
+	}
+	rightJSON, err := jsoniter.Marshal(right)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(leftJSON, rightJSON)
+}
+
+// getRetentionState returns the saved progress, nil if the retention settings were never applied
+func getRetentionState() (*retentionState, error) {
+	output, err := s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(env.AlertEventsBucket),
+		Key:    aws.String(retentionStateKey),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, nil
+		}
+		return nil, &genericapi.AWSError{Method: "s3.GetObject", Err: err}
+	}
+	defer output.Body.Close()
+
+	var state retentionState
+	if err = jsoniter.NewDecoder(output.Body).Decode(&state); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal retention state: " + err.Error()}
+	}
+	return &state, nil
+}
+
+func putRetentionState(state *retentionState) error {
+	body, err := jsoniter.Marshal(state)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal retention state: " + err.Error()}
+	}
+	if _, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(env.AlertEventsBucket),
+		Key:         aws.String(retentionStateKey),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	}); err != nil {
+		return &genericapi.AWSError{Method: "s3.PutObject", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/panther-labs/panther/internal/log_analysis/alert_archiver/archiver"
+	"github.com/panther-labs/panther/pkg/lambdalogger"
+)
+
+// deadlineMargin is the time left to finish the alert being archived when the archiver stops
+const deadlineMargin = time.Minute
+
+func lambdaHandler(ctx context.Context, event events.CloudWatchEvent) error {
+	lambdalogger.ConfigureGlobal(ctx, nil)
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(15 * time.Minute)
+	}
+	return archiver.Archive(deadline.Add(-deadlineMargin))
+}
+
+func main() {
+	archiver.Setup()
+	lambda.Start(lambdaHandler)
+}
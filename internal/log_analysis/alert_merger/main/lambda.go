@@ -23,6 +23,7 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
 	jsoniter "github.com/json-iterator/go"
 	"go.uber.org/zap"
 	"gopkg.in/go-playground/validator.v9"
@@ -38,8 +39,16 @@ func main() {
 }
 
 // Handler is the entry point for the alert merger Lambda
-func Handler(ctx context.Context, event events.SQSEvent) error {
+func Handler(ctx context.Context, event events.SQSEvent) (err error) {
 	_, logger := lambdalogger.ConfigureGlobal(ctx, nil)
+	// The metrics are written once the batch succeeded, a retry of a failed batch counts them again
+	defer func() {
+		if err != nil {
+			merger.DiscardMetrics()
+			return
+		}
+		merger.FlushMetrics()
+	}()
 	for _, record := range event.Records {
 		input := &merger.AlertNotification{}
 		if err := jsoniter.UnmarshalFromString(record.Body, input); err != nil {
@@ -47,8 +56,11 @@ func Handler(ctx context.Context, event events.SQSEvent) error {
 			return err
 		}
 
+		// An invalid notification fails again on every retry, it is dropped
 		if err := validate.Struct(input); err != nil {
-			logger.Error("invalid message received", zap.Error(err))
+			logger.Error("dropping invalid notification",
+				zap.String("ruleId", aws.StringValue(input.RuleID)), zap.String("messageId", record.MessageId), zap.Error(err))
+			merger.LogFailure(merger.MetricInvalidNotifications)
 			continue
 		}
 
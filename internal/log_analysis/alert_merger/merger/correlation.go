@@ -0,0 +1,308 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/md5" // nolint: gosec
+	"encoding/hex"
+	"errors"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	policiesoperations "github.com/panther-labs/panther/api/gateway/analysis/client/operations"
+	policiesmodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+)
+
+const (
+	// The correlation rules are cached by each Lambda container
+	correlationCacheDuration = 5 * time.Minute
+
+	// The number of alerts kept per correlation rule and dedup string, the oldest are dropped first
+	maxCorrelationMatches = 100
+
+	// The number of times the sequence of a correlation rule is updated when concurrent updates conflict
+	maxCorrelationAttempts = 3
+)
+
+var (
+	correlationsTable = os.Getenv("CORRELATIONS_TABLE")
+
+	// correlationCache maps the ID of a rule to the correlation rules referencing it
+	correlationCache     map[string][]*policiesmodels.EnabledPolicy
+	correlationCacheTime time.Time
+)
+
+// correlationMatch is an alert of a rule referenced by a correlation rule
+type correlationMatch struct {
+	RuleID    string    `json:"ruleId"`
+	AlertID   string    `json:"alertId"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// correlationState is stored per correlation rule and dedup string, with the matches within the window
+type correlationState struct {
+	PartitionKey string              `json:"partitionKey"`
+	Matches      []*correlationMatch `json:"matches"`
+	Version      int64               `json:"version"`
+	ExpiresAt    int64               `json:"expiresAt"`
+}
+
+// correlationEvent is the event of an alert of a correlation rule, it links the contributing alerts
+type correlationEvent struct {
+	CorrelatedAlerts []*correlationMatch `json:"correlatedAlerts"`
+}
+
+// correlate adds the alert of the notification to the sequences of the correlation rules referencing its rule
+//
+// When a sequence is complete, the notification of the correlation rule is handled like the notification of any
+// other rule: its dedup string is the dedup string shared by the contributing alerts.
+func correlate(notification *AlertNotification, alertID *string) error {
+	for _, rule := range correlationRules(*notification.RuleID) {
+		sequence, err := addToSequence(rule, notification, alertID)
+		if err != nil {
+			zap.L().Warn("failed to update correlation sequence", zap.String("ruleId", string(rule.ID)), zap.Error(err))
+			return err
+		}
+		if sequence == nil {
+			continue
+		}
+
+		zap.L().Info("correlation sequence complete", zap.String("ruleId", string(rule.ID)), zap.Int("alertCount", len(sequence)))
+		correlation, err := correlationNotification(rule, notification, sequence)
+		if err != nil {
+			return err
+		}
+		if err = Handle(correlation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// correlationRules returns the enabled correlation rules referencing the given rule
+func correlationRules(ruleID string) []*policiesmodels.EnabledPolicy {
+	if time.Since(correlationCacheTime) > correlationCacheDuration {
+		response, err := policyClient.Operations.GetEnabledPolicies(policiesoperations.NewGetEnabledPoliciesParams().
+			WithType(aws.String("RULE")).
+			WithHTTPClient(httpClient))
+		if err != nil {
+			// Alerts are not held back by the analysis api, the last known correlation rules are used
+			zap.L().Warn("failed to get correlation rules", zap.Error(err))
+			return correlationCache[ruleID]
+		}
+
+		correlationCache = make(map[string][]*policiesmodels.EnabledPolicy)
+		for _, rule := range response.Payload.Policies {
+			if rule.Correlation == nil {
+				continue
+			}
+			for _, id := range rule.Correlation.RuleIds {
+				correlationCache[string(id)] = append(correlationCache[string(id)], rule)
+			}
+		}
+		correlationCacheTime = time.Now()
+	}
+	return correlationCache[ruleID]
+}
+
+// addToSequence stores the alert in the sequence of the correlation rule for the dedup string of the notification
+//
+// The alerts which complete the sequence are returned, the sequence then starts over. The sequence is read and
+// written back with a version check, it is read again if another match updated it in the meantime.
+func addToSequence(rule *policiesmodels.EnabledPolicy, notification *AlertNotification,
+	alertID *string) ([]*correlationMatch, error) {
+
+	match := &correlationMatch{RuleID: *notification.RuleID, AlertID: *alertID, Timestamp: notificationTime(notification)}
+	key := correlationKey(rule, notification)
+	for attempt := 0; attempt < maxCorrelationAttempts; attempt++ {
+		state, err := getCorrelationState(key)
+		if err != nil {
+			return nil, err
+		}
+
+		matches := addCorrelationMatch(rule.Correlation, state.Matches, match)
+		sequence := completeSequence(rule.Correlation, matches)
+		if sequence != nil {
+			matches = []*correlationMatch{}
+		}
+
+		err = putCorrelationState(&correlationState{
+			PartitionKey: key,
+			Matches:      matches,
+			Version:      state.Version + 1,
+			ExpiresAt:    match.Timestamp.Add(correlationWindow(rule.Correlation)).Unix(),
+		}, state.Version)
+		if err == nil {
+			return sequence, nil
+		}
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, err
+		}
+		zap.L().Debug("correlation sequence was updated concurrently, retrying", zap.String("ruleId", string(rule.ID)))
+	}
+	return nil, errors.New("correlation sequence " + key + " was updated concurrently too many times")
+}
+
+// addCorrelationMatch adds the match to the matches still within the window of the correlation, sorted by time
+func addCorrelationMatch(correlation *policiesmodels.Correlation, matches []*correlationMatch,
+	match *correlationMatch) []*correlationMatch {
+
+	windowStart := match.Timestamp.Add(-correlationWindow(correlation))
+	result := make([]*correlationMatch, 0, len(matches)+1)
+	duplicate := false
+	for _, existing := range matches {
+		if existing.Timestamp.Before(windowStart) {
+			continue
+		}
+		// A notification is handled again when a batch is retried
+		duplicate = duplicate || *existing == *match
+		result = append(result, existing)
+	}
+	if !duplicate {
+		result = append(result, match)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Timestamp.Before(result[j].Timestamp) })
+	if len(result) > maxCorrelationMatches {
+		result = result[len(result)-maxCorrelationMatches:]
+	}
+	return result
+}
+
+// completeSequence returns an alert of each rule referenced by the correlation, nil if the sequence is not complete
+//
+// The matches must be sorted by time. The latest alert of each rule is used for unordered sequences, ordered
+// sequences use the earliest alert of each rule which is not before the alert of the previous rule.
+func completeSequence(correlation *policiesmodels.Correlation, matches []*correlationMatch) []*correlationMatch {
+	sequence := make([]*correlationMatch, 0, len(correlation.RuleIds))
+	var previous time.Time
+	for _, ruleID := range correlation.RuleIds {
+		var next *correlationMatch
+		for _, match := range matches {
+			if match.RuleID != string(ruleID) {
+				continue
+			}
+			if !correlation.Ordered {
+				next = match
+				continue
+			}
+			if !match.Timestamp.Before(previous) {
+				next = match
+				break
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+		sequence = append(sequence, next)
+		previous = next.Timestamp
+	}
+	return sequence
+}
+
+// correlationNotification builds the notification of a correlation rule from the alerts of its sequence
+func correlationNotification(rule *policiesmodels.EnabledPolicy, notification *AlertNotification,
+	sequence []*correlationMatch) (*AlertNotification, error) {
+
+	event, err := jsoniter.MarshalToString(&correlationEvent{CorrelatedAlerts: sequence})
+	if err != nil {
+		return nil, err
+	}
+
+	alertIDs := make([]*string, len(sequence))
+	for i, match := range sequence {
+		alertIDs[i] = aws.String(match.AlertID)
+	}
+
+	result := &AlertNotification{
+		RuleID:             aws.String(string(rule.ID)),
+		RuleVersionID:      aws.String(string(rule.VersionID)),
+		Event:              aws.String(event),
+		Timestamp:          notification.Timestamp,
+		Dedup:              notification.Dedup,
+		CorrelatedAlertIDs: alertIDs,
+	}
+	if rule.DedupPeriodMinutes > 0 {
+		result.DedupPeriodMinutes = aws.Int64(int64(rule.DedupPeriodMinutes))
+	}
+	return result, nil
+}
+
+func getCorrelationState(key string) (*correlationState, error) {
+	response, err := ddbClient.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            map[string]*dynamodb.AttributeValue{"partitionKey": {S: aws.String(key)}},
+		TableName:      aws.String(correlationsTable),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	state := &correlationState{}
+	if err = dynamodbattribute.UnmarshalMap(response.Item, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// putCorrelationState writes the state if it was not updated since the given version was read
+func putCorrelationState(state *correlationState, previousVersion int64) error {
+	item, err := dynamodbattribute.MarshalMap(state)
+	if err != nil {
+		return err
+	}
+
+	condition := expression.Name("version").Equal(expression.Value(previousVersion))
+	if previousVersion == 0 {
+		condition = expression.Name("partitionKey").AttributeNotExists()
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = ddbClient.PutItem(&dynamodb.PutItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Item:                      item,
+		TableName:                 aws.String(correlationsTable),
+	})
+	return err
+}
+
+// correlationKey identifies the sequence of a correlation rule for the dedup string shared by its alerts
+func correlationKey(rule *policiesmodels.EnabledPolicy, notification *AlertNotification) string {
+	key := md5.Sum([]byte(string(rule.ID) + ":" + aws.StringValue(notification.Dedup))) // nolint: gosec
+	return hex.EncodeToString(key[:])
+}
+
+func correlationWindow(correlation *policiesmodels.Correlation) time.Duration {
+	return time.Duration(aws.Int64Value(correlation.WindowMinutes)) * time.Minute
+}
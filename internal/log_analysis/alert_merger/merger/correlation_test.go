@@ -0,0 +1,232 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	policiesclient "github.com/panther-labs/panther/api/gateway/analysis/client"
+	policiesmodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+)
+
+var (
+	correlationStart = time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// A console login without MFA followed by IAM policy changes within 30 minutes
+	testCorrelation = &policiesmodels.Correlation{
+		Ordered:       true,
+		RuleIds:       []policiesmodels.ID{"Console.Login.NoMFA", "IAM.Policy.Changed"},
+		WindowMinutes: aws.Int64(30),
+	}
+	testCorrelationRule = &policiesmodels.EnabledPolicy{
+		Correlation:        testCorrelation,
+		DedupPeriodMinutes: 60,
+		ID:                 "Suspicious.IAM.Changes",
+		VersionID:          "version",
+	}
+)
+
+func testMatch(ruleID, alertID string, minutes int) *correlationMatch {
+	timestamp := correlationStart.Add(time.Duration(minutes) * time.Minute)
+	return &correlationMatch{RuleID: ruleID, AlertID: alertID, Timestamp: timestamp}
+}
+
+func TestAddCorrelationMatch(t *testing.T) {
+	login := testMatch("Console.Login.NoMFA", "login-alert", 0)
+	change := testMatch("IAM.Policy.Changed", "change-alert", 10)
+	late := testMatch("IAM.Policy.Changed", "change-alert", 45)
+
+	matches := addCorrelationMatch(testCorrelation, nil, change)
+	matches = addCorrelationMatch(testCorrelation, matches, login)
+	// Sorted by time
+	assert.Equal(t, []*correlationMatch{login, change}, matches)
+
+	// Retried notifications are not added twice
+	retried := *change
+	assert.Equal(t, []*correlationMatch{login, change}, addCorrelationMatch(testCorrelation, matches, &retried))
+
+	// Matches outside of the window are dropped
+	assert.Equal(t, []*correlationMatch{late}, addCorrelationMatch(testCorrelation, matches, late))
+}
+
+func TestAddCorrelationMatchLimit(t *testing.T) {
+	var matches []*correlationMatch
+	for i := 0; i < maxCorrelationMatches+5; i++ {
+		match := testMatch("IAM.Policy.Changed", "alert", 0)
+		match.Timestamp = match.Timestamp.Add(time.Duration(i) * time.Second)
+		matches = addCorrelationMatch(testCorrelation, matches, match)
+	}
+
+	// The oldest matches are dropped
+	assert.Len(t, matches, maxCorrelationMatches)
+	assert.Equal(t, correlationStart.Add(5*time.Second), matches[0].Timestamp)
+}
+
+func TestCompleteSequenceOrdered(t *testing.T) {
+	change := testMatch("IAM.Policy.Changed", "change-alert", 0)
+	login := testMatch("Console.Login.NoMFA", "login-alert", 5)
+	secondChange := testMatch("IAM.Policy.Changed", "change-alert", 10)
+
+	// The policy change happened before the login
+	assert.Nil(t, completeSequence(testCorrelation, []*correlationMatch{change, login}))
+	assert.Equal(t, []*correlationMatch{login, secondChange},
+		completeSequence(testCorrelation, []*correlationMatch{change, login, secondChange}))
+}
+
+func TestCompleteSequenceUnordered(t *testing.T) {
+	correlation := *testCorrelation
+	correlation.Ordered = false
+	change := testMatch("IAM.Policy.Changed", "change-alert", 0)
+	login := testMatch("Console.Login.NoMFA", "login-alert", 5)
+	secondLogin := testMatch("Console.Login.NoMFA", "other-login-alert", 10)
+
+	assert.Nil(t, completeSequence(&correlation, []*correlationMatch{change}))
+	// The latest alert of each rule is linked
+	assert.Equal(t, []*correlationMatch{secondLogin, change},
+		completeSequence(&correlation, []*correlationMatch{change, login, secondLogin}))
+}
+
+func TestCorrelationNotification(t *testing.T) {
+	notification := &AlertNotification{
+		RuleID:    aws.String("IAM.Policy.Changed"),
+		Dedup:     aws.String("arn:aws:iam::123456789012:user/alice"),
+		Timestamp: aws.Time(correlationStart),
+	}
+	sequence := []*correlationMatch{
+		testMatch("Console.Login.NoMFA", "login-alert", 0),
+		testMatch("IAM.Policy.Changed", "change-alert", 10),
+	}
+
+	result, err := correlationNotification(testCorrelationRule, notification, sequence)
+	require.NoError(t, err)
+	assert.Equal(t, "Suspicious.IAM.Changes", *result.RuleID)
+	assert.Equal(t, "version", *result.RuleVersionID)
+	assert.Equal(t, notification.Dedup, result.Dedup)
+	assert.Equal(t, int64(60), *result.DedupPeriodMinutes)
+	assert.Equal(t, []*string{aws.String("login-alert"), aws.String("change-alert")}, result.CorrelatedAlertIDs)
+
+	var event correlationEvent
+	require.NoError(t, jsoniter.UnmarshalFromString(*result.Event, &event))
+	assert.Equal(t, sequence, event.CorrelatedAlerts)
+}
+
+func TestAddToSequence(t *testing.T) {
+	mockDdb := &mockDynamoDB{}
+	ddbClient = mockDdb
+	notification := &AlertNotification{
+		RuleID:    aws.String("IAM.Policy.Changed"),
+		Dedup:     aws.String("alice"),
+		Timestamp: aws.Time(correlationStart.Add(10 * time.Minute)),
+	}
+
+	state, err := dynamodbattribute.MarshalMap(&correlationState{
+		PartitionKey: correlationKey(testCorrelationRule, notification),
+		Matches:      []*correlationMatch{testMatch("Console.Login.NoMFA", "login-alert", 0)},
+		Version:      3,
+	})
+	require.NoError(t, err)
+	mockDdb.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: state}, nil).Twice()
+
+	// The first write conflicts with another update of the sequence
+	conflict := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "conflict", nil)
+	mockDdb.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, conflict).Once()
+	mockDdb.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	sequence, err := addToSequence(testCorrelationRule, notification, aws.String("change-alert"))
+	require.NoError(t, err)
+	assert.Equal(t, []*correlationMatch{
+		testMatch("Console.Login.NoMFA", "login-alert", 0),
+		testMatch("IAM.Policy.Changed", "change-alert", 10),
+	}, sequence)
+	mockDdb.AssertExpectations(t)
+
+	// The complete sequence starts over, the write is conditioned on the version which was read
+	put := mockDdb.Calls[3].Arguments.Get(0).(*dynamodb.PutItemInput)
+	var written correlationState
+	require.NoError(t, dynamodbattribute.UnmarshalMap(put.Item, &written))
+	assert.Empty(t, written.Matches)
+	assert.Equal(t, int64(4), written.Version)
+	assert.Equal(t, correlationStart.Add(40*time.Minute).Unix(), written.ExpiresAt)
+	assert.Equal(t, "3", *put.ExpressionAttributeValues[":0"].N)
+}
+
+func TestAddToSequenceIncomplete(t *testing.T) {
+	mockDdb := &mockDynamoDB{}
+	ddbClient = mockDdb
+	notification := &AlertNotification{
+		RuleID:    aws.String("Console.Login.NoMFA"),
+		Timestamp: aws.Time(correlationStart),
+	}
+
+	mockDdb.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockDdb.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	sequence, err := addToSequence(testCorrelationRule, notification, aws.String("login-alert"))
+	require.NoError(t, err)
+	assert.Nil(t, sequence)
+	mockDdb.AssertExpectations(t)
+
+	// A new sequence is only written if it does not exist yet
+	put := mockDdb.Calls[1].Arguments.Get(0).(*dynamodb.PutItemInput)
+	assert.Equal(t, "attribute_not_exists (#0)", *put.ConditionExpression)
+	var written correlationState
+	require.NoError(t, dynamodbattribute.UnmarshalMap(put.Item, &written))
+	assert.Equal(t, []*correlationMatch{testMatch("Console.Login.NoMFA", "login-alert", 0)}, written.Matches)
+}
+
+func TestCorrelationRules(t *testing.T) {
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/v1/enabled", r.URL.Path)
+		assert.Equal(t, "RULE", r.URL.Query().Get("type"))
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := jsoniter.Marshal(&policiesmodels.EnabledPolicies{
+			Policies: []*policiesmodels.EnabledPolicy{{ID: "Console.Login.NoMFA"}, testCorrelationRule},
+		})
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	policyClient = policiesclient.NewHTTPClientWithConfig(nil,
+		policiesclient.DefaultTransportConfig().WithHost(serverURL.Host).WithBasePath("/v1"))
+	httpClient = server.Client()
+	correlationCacheTime = time.Time{}
+
+	assert.Equal(t, []*policiesmodels.EnabledPolicy{testCorrelationRule}, correlationRules("IAM.Policy.Changed"))
+	// The correlation rules are cached
+	assert.Equal(t, []*policiesmodels.EnabledPolicy{testCorrelationRule}, correlationRules("Console.Login.NoMFA"))
+	assert.Empty(t, correlationRules("Suspicious.IAM.Changes"))
+	assert.Equal(t, 1, requests)
+}
@@ -0,0 +1,125 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"go.uber.org/zap"
+)
+
+// SQS keeps the messages of a failed batch for at most 14 days, the matches are remembered as long
+const matchRetention = 14 * 24 * time.Hour
+
+var matchesTable = aws.String(os.Getenv("ALERT_MATCHES_TABLE"))
+
+// mergedMatch records a match merged into an alert, a retry of its batch merges it again
+//
+// The key of the match is the key of its event in the alert, identical events of an alert are counted once.
+type mergedMatch struct {
+	MatchKey string `json:"matchKey"`
+	// Overflow is set when the match was counted beyond the event limit of the alert, its event is not kept
+	Overflow bool `json:"overflow,omitempty"`
+	// MatchCount and NewAlert are the metrics counted for the match, see recordCountedMetrics
+	MatchCount int64 `json:"matchCount"`
+	NewAlert   bool  `json:"newAlert,omitempty"`
+	// UncorrelatedAlertID is the new alert created by the match until it is correlated
+	UncorrelatedAlertID string `json:"uncorrelatedAlertId,omitempty"`
+	ExpiresAt           int64  `json:"expiresAt"`
+}
+
+// recordMatch records a match before it is counted, false if an earlier attempt already counted it
+func recordMatch(match *mergedMatch) (bool, error) {
+	match.ExpiresAt = time.Now().Add(matchRetention).Unix()
+	item, err := dynamodbattribute.MarshalMap(match)
+	if err != nil {
+		return false, err
+	}
+	expr, err := expression.NewBuilder().WithCondition(expression.Name("matchKey").AttributeNotExists()).Build()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = ddbClient.PutItem(&dynamodb.PutItemInput{
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+		Item:                     item,
+		TableName:                matchesTable,
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return false, nil
+		}
+		zap.L().Warn("failed to record match", zap.Error(err))
+		return false, err
+	}
+	return true, nil
+}
+
+// getMatch returns the record of a match merged by an earlier attempt
+func getMatch(matchKey string) (*mergedMatch, error) {
+	response, err := ddbClient.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            map[string]*dynamodb.AttributeValue{"matchKey": {S: aws.String(matchKey)}},
+		TableName:      matchesTable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	match := &mergedMatch{}
+	if err = dynamodbattribute.UnmarshalMap(response.Item, match); err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// forgetMatch deletes the record of a match which could not be counted, so that a retry counts it
+func forgetMatch(matchKey string) {
+	_, err := ddbClient.DeleteItem(&dynamodb.DeleteItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"matchKey": {S: aws.String(matchKey)}},
+		TableName: matchesTable,
+	})
+	if err != nil {
+		zap.L().Error("failed to delete match, a retry will not count it", zap.String("matchKey", matchKey), zap.Error(err))
+	}
+}
+
+// updateMatch sets or removes a flag of a recorded match
+func updateMatch(matchKey string, update expression.UpdateBuilder) error {
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = ddbClient.UpdateItem(&dynamodb.UpdateItemInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key:                       map[string]*dynamodb.AttributeValue{"matchKey": {S: aws.String(matchKey)}},
+		TableName:                 matchesTable,
+		UpdateExpression:          expr.Update(),
+	})
+	return err
+}
@@ -0,0 +1,233 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	policiesmodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+	alertsapimodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+)
+
+func (m *mockDynamoDB) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
+}
+
+// onTable matches the requests to a table
+func onTable(table string) interface{} {
+	return mock.MatchedBy(func(input interface{}) bool {
+		switch request := input.(type) {
+		case *dynamodb.UpdateItemInput:
+			return *request.TableName == table
+		case *dynamodb.GetItemInput:
+			return *request.TableName == table
+		case *dynamodb.PutItemInput:
+			return *request.TableName == table
+		case *dynamodb.DeleteItemInput:
+			return *request.TableName == table
+		default:
+			return false
+		}
+	})
+}
+
+// setupExistingAlert mocks the merge of a notification into the active alert "rule.id-1"
+func setupExistingAlert() (*mockDynamoDB, *mockS3, *AlertNotification) {
+	client, s3Mock := &mockDynamoDB{}, &mockS3{}
+	ddbClient, s3Client = client, s3Mock
+	recentAlertsTable, alertsTable, matchesTable = aws.String("recent"), aws.String("alerts"), aws.String("matches")
+	alertDimensions.Purge()
+	alertDimensions.Add("rule.id-1", []alertsapimodels.MetricDimension{{Type: alertsapimodels.MetricTotal}})
+	pendingMetrics = make(map[metricsKey]*alertsapimodels.MetricCounts)
+
+	client.On("UpdateItem", onTable("recent")).Return(&dynamodb.UpdateItemOutput{}, conditionFailed()).Once()
+	client.On("GetItem", onTable("recent")).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"alertCount":   {N: aws.String("1")},
+		"creationTime": {N: aws.String("1577836800")},
+	}}, nil).Once()
+	s3Mock.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+
+	notification := &AlertNotification{
+		RuleID:    aws.String("rule.id"),
+		Event:     aws.String(`{"key": "value"}`),
+		Timestamp: aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	return client, s3Mock, notification
+}
+
+func conditionFailed() error {
+	return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil)
+}
+
+func TestRecordMatch(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient, matchesTable = client, aws.String("matches")
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, conditionFailed()).Once()
+
+	recorded, err := recordMatch(&mergedMatch{MatchKey: "alerts/alert-id/key.json", MatchCount: 1})
+	require.NoError(t, err)
+	assert.True(t, recorded)
+	recorded, err = recordMatch(&mergedMatch{MatchKey: "alerts/alert-id/key.json", MatchCount: 1})
+	require.NoError(t, err)
+	assert.False(t, recorded)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.PutItemInput)
+	assert.Equal(t, "alerts/alert-id/key.json", *input.Item["matchKey"].S)
+	assert.NotNil(t, input.Item["expiresAt"].N)
+	assert.Equal(t, "1", *input.Item["matchCount"].N)
+	assert.NotNil(t, input.ConditionExpression)
+	assert.NotContains(t, input.Item, "uncorrelatedAlertId")
+	client.AssertExpectations(t)
+}
+
+func TestHandleCountsMatch(t *testing.T) {
+	client, s3Mock, notification := setupExistingAlert()
+	client.On("PutItem", onTable("matches")).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	client.On("UpdateItem", onTable("alerts")).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"eventCount": {N: aws.String("10")},
+		"eventLimit": {N: aws.String("10")},
+	}}, nil).Once()
+
+	require.NoError(t, Handle(notification))
+	assert.Len(t, pendingMetrics, 1)
+	client.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestHandleCountsMatchBeyondLimit(t *testing.T) {
+	client, s3Mock, notification := setupExistingAlert()
+	client.On("PutItem", onTable("matches")).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	client.On("UpdateItem", onTable("alerts")).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"eventCount": {N: aws.String("11")},
+		"eventLimit": {N: aws.String("10")},
+	}}, nil).Once()
+	client.On("UpdateItem", onTable("matches")).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	s3Mock.On("DeleteObject", mock.Anything).Return(&s3.DeleteObjectOutput{}, nil).Once()
+
+	require.NoError(t, Handle(notification))
+	stored := s3Mock.Calls[0].Arguments[0].(*s3.PutObjectInput)
+	deleted := s3Mock.Calls[1].Arguments[0].(*s3.DeleteObjectInput)
+	assert.Equal(t, *stored.Key, *deleted.Key)
+	client.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestHandleRetriedMatchKeepsEvent(t *testing.T) {
+	client, s3Mock, notification := setupExistingAlert()
+	client.On("PutItem", onTable("matches")).Return(&dynamodb.PutItemOutput{}, conditionFailed()).Once()
+	client.On("GetItem", onTable("matches")).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"matchKey": {S: aws.String("key")},
+	}}, nil).Once()
+
+	// The match is not counted again and its event, kept by the first attempt, is not deleted.
+	// Its metrics were discarded with the failed batch, they are counted again.
+	require.NoError(t, Handle(notification))
+	assert.Len(t, pendingMetrics, 1)
+	client.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestHandleRetriedMatchBeyondLimit(t *testing.T) {
+	client, s3Mock, notification := setupExistingAlert()
+	client.On("PutItem", onTable("matches")).Return(&dynamodb.PutItemOutput{}, conditionFailed()).Once()
+	client.On("GetItem", onTable("matches")).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"matchKey": {S: aws.String("key")},
+		"overflow": {BOOL: aws.Bool(true)},
+	}}, nil).Once()
+	s3Mock.On("DeleteObject", mock.Anything).Return(&s3.DeleteObjectOutput{}, nil).Once()
+
+	require.NoError(t, Handle(notification))
+	client.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestHandleForgetsUncountedMatch(t *testing.T) {
+	client, s3Mock, notification := setupExistingAlert()
+	client.On("PutItem", onTable("matches")).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	client.On("UpdateItem", onTable("alerts")).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "", nil)).Once()
+	client.On("DeleteItem", onTable("matches")).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	// The retry counts the match
+	assert.Error(t, Handle(notification))
+	client.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestHandleRetriedMatchCorrelatesAlert(t *testing.T) {
+	client, s3Mock, notification := setupExistingAlert()
+	correlationsTable = "correlations"
+	correlationCache = map[string][]*policiesmodels.EnabledPolicy{"rule.id": {testCorrelationRule}}
+	correlationCacheTime = time.Now()
+	defer func() { correlationCache, correlationCacheTime = nil, time.Time{} }()
+
+	// The earlier attempt created the alert but failed to correlate it
+	client.On("PutItem", onTable("matches")).Return(&dynamodb.PutItemOutput{}, conditionFailed()).Once()
+	client.On("GetItem", onTable("matches")).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"matchKey":            {S: aws.String("key")},
+		"uncorrelatedAlertId": {S: aws.String("rule.id-1")},
+	}}, nil).Once()
+	client.On("GetItem", onTable("correlations")).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	client.On("PutItem", onTable("correlations")).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	client.On("UpdateItem", onTable("matches")).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	require.NoError(t, Handle(notification))
+	var state correlationState
+	put := client.Calls[len(client.Calls)-2].Arguments[0].(*dynamodb.PutItemInput)
+	require.NoError(t, dynamodbattribute.UnmarshalMap(put.Item, &state))
+	require.Len(t, state.Matches, 1)
+	assert.Equal(t, "rule.id-1", state.Matches[0].AlertID)
+	update := client.Calls[len(client.Calls)-1].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *update.UpdateExpression, "REMOVE")
+	client.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestHandleRetriedMatchCorrelationFails(t *testing.T) {
+	client, s3Mock, notification := setupExistingAlert()
+	correlationsTable = "correlations"
+	correlationCache = map[string][]*policiesmodels.EnabledPolicy{"rule.id": {testCorrelationRule}}
+	correlationCacheTime = time.Now()
+	defer func() { correlationCache, correlationCacheTime = nil, time.Time{} }()
+
+	client.On("PutItem", onTable("matches")).Return(&dynamodb.PutItemOutput{}, conditionFailed()).Once()
+	client.On("GetItem", onTable("matches")).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"matchKey":            {S: aws.String("key")},
+		"uncorrelatedAlertId": {S: aws.String("rule.id-1")},
+	}}, nil).Once()
+	client.On("GetItem", onTable("correlations")).Return(&dynamodb.GetItemOutput{},
+		awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "", nil)).Once()
+
+	// The alert stays uncorrelated for the next retry
+	assert.Error(t, Handle(notification))
+	client.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+}
@@ -0,0 +1,133 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	lru "github.com/hashicorp/golang-lru"
+	"go.uber.org/zap"
+
+	alertsapimodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	alertstable "github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+)
+
+// maxCachedDimensions bounds the number of alerts whose metric dimensions are cached
+const maxCachedDimensions = 10000
+
+// metricsKey identifies the hourly metrics of a dimension
+type metricsKey struct {
+	hour      time.Time
+	dimension alertsapimodels.MetricDimension
+}
+
+var (
+	// pendingMetrics are the metrics counted since the last flush, they are written once per batch of notifications
+	pendingMetrics = make(map[metricsKey]*alertsapimodels.MetricCounts)
+
+	// alertDimensions caches the metric dimensions of the most recently merged alerts, they do not change once an
+	// alert is created
+	alertDimensions *lru.Cache
+)
+
+func init() {
+	var err error
+	alertDimensions, err = lru.New(maxCachedDimensions)
+	if err != nil {
+		panic("failed to create alert dimensions cache")
+	}
+}
+
+// recordMetrics counts the matched events, and the alert if it is new, in the hourly alert metrics
+//
+// The counts are only written by FlushMetrics, once the batch of notifications succeeded.
+func recordMetrics(notification *AlertNotification, info *alertInfo, alertItem *alertsapimodels.AlertItem) {
+	hour := notificationTime(notification).UTC().Truncate(time.Hour)
+	for _, dimension := range metricDimensions(*info.alertID, alertItem) {
+		key := metricsKey{hour: hour, dimension: dimension}
+		counts := pendingMetrics[key]
+		if counts == nil {
+			counts = &alertsapimodels.MetricCounts{}
+			pendingMetrics[key] = counts
+		}
+		counts.EventCount += info.matchCount
+		if info.isNew {
+			counts.AlertCount++
+		}
+	}
+}
+
+// recordCountedMetrics counts the metrics of a match counted by an earlier attempt of its batch
+//
+// The earlier attempt failed, so its metrics were discarded with the rest of the batch.
+func recordCountedMetrics(notification *AlertNotification, alertID string, match *mergedMatch) error {
+	alertItem := &alertsapimodels.AlertItem{}
+	if !alertDimensions.Contains(alertID) {
+		alerts := &alertstable.AlertsTable{AlertsTableName: aws.StringValue(alertsTable), Client: ddbClient}
+		var err error
+		if alertItem, err = alerts.GetAlert(aws.String(alertID)); err != nil {
+			zap.L().Warn("failed to get alert", zap.String("alertId", alertID), zap.Error(err))
+			return err
+		}
+	}
+	recordMetrics(notification, &alertInfo{alertID: &alertID, isNew: match.NewAlert, matchCount: match.MatchCount}, alertItem)
+	return nil
+}
+
+// DiscardMetrics drops the metrics counted since the last flush, when the batch failed
+//
+// The retry of the batch counts them again: the matches counted by the failed attempt are recorded, see recordCountedMetrics.
+func DiscardMetrics() {
+	pendingMetrics = make(map[metricsKey]*alertsapimodels.MetricCounts)
+}
+
+// FlushMetrics writes the metrics counted since the last flush, once the batch of notifications succeeded
+//
+// The batch is not retried, so failures are logged as errors and not returned.
+func FlushMetrics() {
+	metrics := &alertstable.AlertsTable{MetricsTableName: metricsTable, Client: ddbClient}
+	for key, counts := range pendingMetrics {
+		err := metrics.AddAlertMetrics(key.hour, []alertsapimodels.MetricDimension{key.dimension}, counts)
+		if err != nil {
+			zap.L().Error("failed to record alert metrics", zap.Time("hour", key.hour),
+				zap.String("type", key.dimension.Type), zap.String("value", key.dimension.Value), zap.Error(err))
+		}
+	}
+	pendingMetrics = make(map[metricsKey]*alertsapimodels.MetricCounts)
+}
+
+// metricDimensions returns the metric dimensions of an alert, from the cache if the alert was seen before
+func metricDimensions(alertID string, alertItem *alertsapimodels.AlertItem) []alertsapimodels.MetricDimension {
+	if dimensions, ok := alertDimensions.Get(alertID); ok {
+		return dimensions.([]alertsapimodels.MetricDimension)
+	}
+	dimensions := alertsapimodels.MetricDimensions(alertItem)
+	alertDimensions.Add(alertID, dimensions)
+	return dimensions
+}
+
+// notificationTime returns when the rule matched, the current time if the notification has no timestamp
+func notificationTime(notification *AlertNotification) time.Time {
+	timestamp := aws.TimeValue(notification.Timestamp)
+	if timestamp.IsZero() {
+		return time.Now()
+	}
+	return timestamp
+}
@@ -0,0 +1,169 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	alertsapimodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	metricsTable = "metrics"
+	pendingMetrics = make(map[metricsKey]*alertsapimodels.MetricCounts)
+	alertDimensions.Purge()
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Times(5)
+
+	// The new alert and a match merged into it in the same hour are written once per dimension
+	notification := &AlertNotification{Timestamp: aws.Time(time.Date(2020, 2, 1, 13, 30, 0, 0, time.UTC))}
+	recordMetrics(notification, &alertInfo{alertID: aws.String("alert-id"), isNew: true, matchCount: 3},
+		&alertsapimodels.AlertItem{
+			RuleID:   aws.String("rule.id"),
+			Severity: aws.String("HIGH"),
+			LogTypes: aws.StringSlice([]string{"AWS.CloudTrail", "AWS.S3ServerAccess"}),
+		})
+	notification = &AlertNotification{Timestamp: aws.Time(time.Date(2020, 2, 1, 13, 50, 0, 0, time.UTC))}
+	recordMetrics(notification, &alertInfo{alertID: aws.String("alert-id"), matchCount: 1}, &alertsapimodels.AlertItem{})
+	FlushMetrics()
+
+	var keys []string
+	for _, call := range client.Calls {
+		input := call.Arguments[0].(*dynamodb.UpdateItemInput)
+		assert.Equal(t, "metrics", *input.TableName)
+		assert.Regexp(t, `^2020-02-01#\d$`, *input.Key["day"].S)
+		keys = append(keys, *input.Key["key"].S)
+
+		values := make(map[string]string)
+		for _, value := range input.ExpressionAttributeValues {
+			values[*value.N] = *value.N
+		}
+		assert.Equal(t, map[string]string{"1": "1", "4": "4"}, values)
+	}
+	assert.ElementsMatch(t, []string{
+		"13#total#", "13#rule#rule.id", "13#severity#HIGH", "13#logType#AWS.CloudTrail", "13#logType#AWS.S3ServerAccess",
+	}, keys)
+	assert.Empty(t, pendingMetrics)
+	client.AssertExpectations(t)
+}
+
+func TestRecordMetricsExistingAlert(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	pendingMetrics = make(map[metricsKey]*alertsapimodels.MetricCounts)
+	alertDimensions.Purge()
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Twice()
+
+	notification := &AlertNotification{Timestamp: aws.Time(time.Now())}
+	info := &alertInfo{alertID: aws.String("alert-id"), matchCount: 1}
+	recordMetrics(notification, info, &alertsapimodels.AlertItem{RuleID: aws.String("rule.id")})
+	FlushMetrics()
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "ADD #0 :0\n", *input.UpdateExpression)
+	assert.Equal(t, "eventCount", *input.ExpressionAttributeNames["#0"])
+	client.AssertExpectations(t)
+}
+
+func TestFlushMetricsError(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	pendingMetrics = make(map[metricsKey]*alertsapimodels.MetricCounts)
+	alertDimensions.Purge()
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, errors.New("throttled")).Once()
+
+	info := &alertInfo{alertID: aws.String("alert-id"), matchCount: 1}
+	recordMetrics(&AlertNotification{Timestamp: aws.Time(time.Now())}, info, &alertsapimodels.AlertItem{})
+	FlushMetrics()
+
+	// The failed counts are dropped, the batch succeeded and is not retried
+	assert.Empty(t, pendingMetrics)
+	client.AssertExpectations(t)
+}
+
+func TestDiscardMetrics(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	pendingMetrics = make(map[metricsKey]*alertsapimodels.MetricCounts)
+	alertDimensions.Purge()
+
+	info := &alertInfo{alertID: aws.String("alert-id"), matchCount: 1}
+	recordMetrics(&AlertNotification{Timestamp: aws.Time(time.Now())}, info, &alertsapimodels.AlertItem{})
+	DiscardMetrics()
+	FlushMetrics()
+
+	// The retry of the failed batch counts them again
+	client.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestRecordCountedMetrics(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient, alertsTable = client, aws.String("alerts")
+	pendingMetrics = make(map[metricsKey]*alertsapimodels.MetricCounts)
+	alertDimensions.Purge()
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"alertId":  {S: aws.String("alert-id")},
+		"ruleId":   {S: aws.String("rule.id")},
+		"severity": {S: aws.String("HIGH")},
+	}}, nil).Once()
+
+	// The dimensions of an alert which is not cached are read from the alert
+	notification := &AlertNotification{Timestamp: aws.Time(time.Date(2020, 2, 1, 13, 30, 0, 0, time.UTC))}
+	require.NoError(t, recordCountedMetrics(notification, "alert-id", &mergedMatch{MatchCount: 3, NewAlert: true}))
+	require.Len(t, pendingMetrics, 3)
+	for _, counts := range pendingMetrics {
+		assert.Equal(t, &alertsapimodels.MetricCounts{AlertCount: 1, EventCount: 3}, counts)
+	}
+
+	// Then from the cache
+	require.NoError(t, recordCountedMetrics(notification, "alert-id", &mergedMatch{MatchCount: 1}))
+	assert.Len(t, pendingMetrics, 3)
+	client.AssertExpectations(t)
+}
+
+func TestMetricDimensionsBounded(t *testing.T) {
+	alertDimensions.Purge()
+	for i := 0; i < maxCachedDimensions+10; i++ {
+		metricDimensions(strconv.Itoa(i), &alertsapimodels.AlertItem{})
+	}
+
+	// The least recently merged alerts are evicted
+	assert.Equal(t, maxCachedDimensions, alertDimensions.Len())
+	assert.False(t, alertDimensions.Contains("0"))
+	assert.True(t, alertDimensions.Contains(strconv.Itoa(maxCachedDimensions+9)))
+}
+
+func TestMetricDimensionsCached(t *testing.T) {
+	alertDimensions.Purge()
+	item := &alertsapimodels.AlertItem{RuleID: aws.String("rule.id"), Severity: aws.String("LOW")}
+	assert.Equal(t, alertsapimodels.MetricDimensions(item), metricDimensions("alert-id", item))
+
+	// The updated attributes of an existing alert do not include its dimensions
+	assert.Equal(t, alertsapimodels.MetricDimensions(item), metricDimensions("alert-id", &alertsapimodels.AlertItem{}))
+}
@@ -19,8 +19,9 @@ package merger
  */
 
 import (
-	"crypto/sha1" // nolint: gosec
 	"time"
+
+	alertsapimodels "github.com/panther-labs/panther/api/lambda/alerts/models"
 )
 
 // AlertNotification models a notification sent to Alert merger
@@ -29,11 +30,18 @@ type AlertNotification struct {
 	RuleVersionID *string    `json:"ruleVersionId"`
 	Event         *string    `json:"event"`
 	Timestamp     *time.Time `json:"timestamp"`
-}
-
-// MatchedEvent represents an event matched by the Panther rule engine
-type MatchedEvent struct {
-	EventHash [sha1.Size]byte `json:"eventHash"`
-	Timestamp *time.Time      `json:"timestamp"`
-	Event     *string         `json:"event"`
+	// Dedup is computed by the rule, matches with different dedup strings are not merged into the same alert
+	Dedup *string `json:"dedup,omitempty" validate:"omitempty,max=1000"`
+	// DedupPeriodMinutes is the merge window configured on the rule, the default window is used if not set
+	DedupPeriodMinutes *int64 `json:"dedupPeriodMinutes,omitempty" validate:"omitempty,min=5,max=1440"`
+	// Threshold is the number of matches within the threshold window required to trigger an alert
+	Threshold *int64 `json:"threshold,omitempty" validate:"omitempty,min=1,max=1000000"`
+	// ThresholdWindowMinutes is the window in which the threshold must be reached, the merge window is used if not set
+	ThresholdWindowMinutes *int64 `json:"thresholdWindowMinutes,omitempty" validate:"omitempty,min=1,max=1440"`
+	// Title is computed by the rule, the rule name is the title of the alert if not set
+	Title *string `json:"title,omitempty" validate:"omitempty,max=1000"`
+	// AlertContext is information about the match returned by the rule, stored in the alert it triggers
+	AlertContext []*alertsapimodels.ContextField `json:"alertContext,omitempty" validate:"omitempty,max=50,dive,required"`
+	// CorrelatedAlertIDs are set by the merger on the notifications of correlation rules
+	CorrelatedAlertIDs []*string `json:"correlatedAlertIds,omitempty"`
 }
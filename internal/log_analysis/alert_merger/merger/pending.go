@@ -0,0 +1,123 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/md5" // nolint: gosec
+	"encoding/hex"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	// The prefix of the events matched below the threshold of a rule, they expire with a lifecycle rule
+	pendingEventsRoot = "pending"
+
+	// The number of events copied concurrently to a new alert
+	moveConcurrency = 10
+
+	// The maximum number of objects deleted in one request
+	maxDeleteObjects = 1000
+)
+
+// pendingEventsPrefix is the prefix of the events matched in a threshold window of a (ruleId, dedup) pair
+//
+// Every window has its own prefix, so the events of a window which expired below the threshold are
+// not added to a later alert.
+func pendingEventsPrefix(notification *AlertNotification, windowStart int64) string {
+	key := md5.Sum([]byte(dedupPartition(notification))) // nolint: gosec
+	return path.Join(pendingEventsRoot, hex.EncodeToString(key[:]), strconv.FormatInt(windowStart, 10)) + "/"
+}
+
+// moveEvents moves the events stored under a prefix to another prefix, keeping their names
+//
+// Copies are idempotent, so moving the events again after a failure is safe.
+func moveEvents(fromPrefix, toPrefix string) error {
+	var keys []*string
+	err := s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(alertEventsBucket),
+		Prefix: aws.String(fromPrefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, object.Key)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = copyEvents(keys, fromPrefix, toPrefix); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(keys); start += maxDeleteObjects {
+		end := start + maxDeleteObjects
+		if end > len(keys) {
+			end = len(keys)
+		}
+		objects := make([]*s3.ObjectIdentifier, 0, end-start)
+		for _, key := range keys[start:end] {
+			objects = append(objects, &s3.ObjectIdentifier{Key: key})
+		}
+		if _, err = s3Client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(alertEventsBucket),
+			Delete: &s3.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyEvents copies the objects concurrently and returns the first error
+func copyEvents(keys []*string, fromPrefix, toPrefix string) error {
+	queue := make(chan *string)
+	errs := make(chan error, len(keys))
+	var wg sync.WaitGroup
+	for i := 0; i < moveConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				// The event keys only contain URL safe characters
+				_, err := s3Client.CopyObject(&s3.CopyObjectInput{
+					Bucket:     aws.String(alertEventsBucket),
+					CopySource: aws.String(alertEventsBucket + "/" + *key),
+					Key:        aws.String(toPrefix + strings.TrimPrefix(*key, fromPrefix)),
+				})
+				if err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	for _, key := range keys {
+		queue <- key
+	}
+	close(queue)
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
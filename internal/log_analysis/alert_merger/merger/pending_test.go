@@ -0,0 +1,134 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockS3 struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (m *mockS3) ListObjectsV2Pages(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	args := m.Called(input, fn)
+	return args.Error(0)
+}
+
+func (m *mockS3) CopyObject(input *s3.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.CopyObjectOutput), args.Error(1)
+}
+
+func (m *mockS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
+func (m *mockS3) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.DeleteObjectOutput), args.Error(1)
+}
+
+func (m *mockS3) DeleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.DeleteObjectsOutput), args.Error(1)
+}
+
+func listObjects(client *mockS3, keys ...string) {
+	client.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		output := &s3.ListObjectsV2Output{}
+		for _, key := range keys {
+			output.Contents = append(output.Contents, &s3.Object{Key: aws.String(key)})
+		}
+		args.Get(1).(func(*s3.ListObjectsV2Output, bool) bool)(output, true)
+	}).Return(nil).Once()
+}
+
+func TestPendingEventsPrefix(t *testing.T) {
+	notification := &AlertNotification{RuleID: aws.String("rule.id"), Dedup: aws.String("dedup")}
+	prefix := pendingEventsPrefix(notification, 1577836800)
+	assert.True(t, strings.HasPrefix(prefix, "pending/"))
+	assert.True(t, strings.HasSuffix(prefix, "/1577836800/"))
+
+	// Every window and every (ruleId, dedup) pair has its own prefix
+	assert.NotEqual(t, prefix, pendingEventsPrefix(notification, 1577840400))
+	other := &AlertNotification{RuleID: aws.String("rule.id"), Dedup: aws.String("other")}
+	assert.NotEqual(t, prefix, pendingEventsPrefix(other, 1577836800))
+}
+
+func TestMoveEvents(t *testing.T) {
+	client := &mockS3{}
+	s3Client = client
+	alertEventsBucket = "bucket"
+
+	listObjects(client, "pending/key/1/first.json", "pending/key/1/second.json")
+	client.On("CopyObject", mock.Anything).Return(&s3.CopyObjectOutput{}, nil).Twice()
+	client.On("DeleteObjects", mock.Anything).Return(&s3.DeleteObjectsOutput{}, nil).Once()
+
+	require.NoError(t, moveEvents("pending/key/1/", "alerts/alert-id/"))
+
+	var copied []string
+	for _, call := range client.Calls {
+		if input, ok := call.Arguments[0].(*s3.CopyObjectInput); ok {
+			assert.Equal(t, "bucket", *input.Bucket)
+			copied = append(copied, *input.CopySource+" "+*input.Key)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"bucket/pending/key/1/first.json alerts/alert-id/first.json",
+		"bucket/pending/key/1/second.json alerts/alert-id/second.json",
+	}, copied)
+
+	deleted := client.Calls[len(client.Calls)-1].Arguments[0].(*s3.DeleteObjectsInput)
+	assert.Len(t, deleted.Delete.Objects, 2)
+	client.AssertExpectations(t)
+}
+
+func TestMoveEventsCopyError(t *testing.T) {
+	client := &mockS3{}
+	s3Client = client
+
+	// The events are only deleted once they were all copied
+	listObjects(client, "pending/key/1/first.json")
+	client.On("CopyObject", mock.Anything).Return(&s3.CopyObjectOutput{}, errors.New("service error")).Once()
+
+	assert.Error(t, moveEvents("pending/key/1/", "alerts/alert-id/"))
+	client.AssertExpectations(t)
+	client.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+}
+
+func TestMoveEventsNone(t *testing.T) {
+	client := &mockS3{}
+	s3Client = client
+
+	listObjects(client)
+	require.NoError(t, moveEvents("pending/key/1/", "alerts/alert-id/"))
+	client.AssertExpectations(t)
+}
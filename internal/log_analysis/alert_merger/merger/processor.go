@@ -19,9 +19,12 @@ package merger
  */
 
 import (
+	"crypto/md5"  // nolint: gosec
 	"crypto/sha1" // nolint: gosec
+	"encoding/hex"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -31,6 +34,10 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	jsoniter "github.com/json-iterator/go"
@@ -38,21 +45,28 @@ import (
 
 	policiesclient "github.com/panther-labs/panther/api/gateway/analysis/client"
 	policiesoperations "github.com/panther-labs/panther/api/gateway/analysis/client/operations"
+	policiesmodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+	alertsapimodels "github.com/panther-labs/panther/api/lambda/alerts/models"
 	alertmodel "github.com/panther-labs/panther/internal/core/alert_delivery/models"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
 var (
 	recentAlertsTable = aws.String(os.Getenv("RECENT_ALERTS_TABLE"))
-	eventsTable       = aws.String(os.Getenv("EVENTS_TABLE"))
 	alertsTable       = aws.String(os.Getenv("ALERTS_TABLE"))
+	metricsTable      = os.Getenv("ALERT_METRICS_TABLE")
+	alertEventsBucket = os.Getenv("ALERT_EVENTS_BUCKET")
+	eventLimit        = parseEventLimit(os.Getenv("MAX_EVENTS_PER_ALERT"))
 	analysisAPIHost   = os.Getenv("ANALYSIS_API_HOST")
 	analysisAPIPath   = os.Getenv("ANALYSIS_API_PATH")
 	alertingQueueURL  = os.Getenv("ALERTING_QUEUE_URL")
+	organizationsAPI  = os.Getenv("ORGANIZATIONS_API")
 
-	awsSession                           = session.Must(session.NewSession())
-	ddbClient  dynamodbiface.DynamoDBAPI = dynamodb.New(awsSession)
-	sqsClient  sqsiface.SQSAPI           = sqs.New(awsSession)
+	awsSession                             = session.Must(session.NewSession())
+	ddbClient    dynamodbiface.DynamoDBAPI = dynamodb.New(awsSession)
+	lambdaClient lambdaiface.LambdaAPI     = lambda.New(awsSession)
+	s3Client     s3iface.S3API             = s3.New(awsSession)
+	sqsClient    sqsiface.SQSAPI           = sqs.New(awsSession)
 
 	httpClient   = gatewayapi.GatewayClient(awsSession)
 	policyConfig = policiesclient.DefaultTransportConfig().
@@ -61,79 +75,270 @@ var (
 	policyClient = policiesclient.NewHTTPClientWithConfig(nil, policyConfig)
 )
 
-const eventMergingPeriodSeconds = 3600 // One hour
+const (
+	defaultMergingPeriodMinutes = 60 // One hour
+	defaultEventLimit           = 1000
+
+	// Fixed width so that the keys of the events sort by time
+	eventKeyTimeFormat = "20060102T150405.000000000Z"
+)
+
+// alertInfo describes the alert a match is merged into
+type alertInfo struct {
+	alertID      *string
+	creationTime *time.Time
+	// isNew is set when the match triggers a new alert
+	isNew bool
+	// isPending is set when the threshold of the rule is not reached yet, alertID is then unset
+	isPending bool
+	// pendingPrefix is the prefix of the events matched in the threshold window, they are moved to
+	// the alert created once the threshold is reached
+	pendingPrefix string
+	// matchCount is the number of matches added to the alert, more than one when a threshold is reached
+	matchCount int64
+	// retentionDays is the number of days a new alert is kept before it is archived, zero to keep it indefinitely
+	retentionDays int64
+}
+
+// matchWindow is the threshold window of a (ruleId, dedup) pair stored in the recent alerts table
+type matchWindow struct {
+	AlertCount  int64 `json:"alertCount"`
+	WindowStart int64 `json:"windowStart"`
+	WindowCount int64 `json:"windowCount"`
+}
 
 // Handle handles alert notifications
 func Handle(notification *AlertNotification) error {
 	zap.L().Info("received new alert notification")
 
-	event, err := storeMatchedEvent(notification)
+	info, err := getAlertInfo(notification)
 	if err != nil {
-		zap.L().Warn("failed to store event")
 		return err
 	}
 
-	zap.L().Info("successfully stored event")
+	// Matches below the threshold are kept as context for the alert created once it is reached
+	if info.isPending {
+		if info.matchCount > int64(eventLimit) {
+			zap.L().Info("threshold window reached the event limit, event not stored", zap.String("prefix", info.pendingPrefix))
+			return nil
+		}
+		if err = storeMatchedEvent(notification, info.pendingPrefix); err != nil {
+			zap.L().Warn("failed to store event")
+			return err
+		}
+		zap.L().Info("threshold not reached, stored event", zap.Int64("matchCount", info.matchCount))
+		return nil
+	}
 
-	isNewAlert, alertID, creationTime, err := getAlertInfo(notification)
-	if err != nil {
+	zap.L().Info("successfully got alert id",
+		zap.String("alertId", *info.alertID))
+
+	// The rule information is stored in new alerts to search them without calling the analysis api
+	var rule *policiesmodels.Rule
+	if info.isNew {
+		if rule, err = getRule(notification); err != nil {
+			return err
+		}
+		info.retentionDays = retentionDays(string(rule.Severity))
+	}
+
+	// The event is stored before it is counted, so a retry after a failure to store it does not count it
+	// twice. Storing it again on a retry overwrites the same object.
+	prefix := alertsapimodels.EventKeyPrefix(*info.alertID)
+	if err = storeMatchedEvent(notification, prefix); err != nil {
+		zap.L().Warn("failed to store event")
 		return err
 	}
 
-	zap.L().Info("successfully got alert id",
-		zap.String("alertId", *alertID))
+	// A retried batch merges its matches again, each match is only counted once
+	matchKey := eventKey(notification, prefix)
+	match := &mergedMatch{MatchKey: matchKey, MatchCount: info.matchCount, NewAlert: info.isNew}
+	if needsCorrelation(notification, info) {
+		match.UncorrelatedAlertID = *info.alertID
+	}
+	recorded, err := recordMatch(match)
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		return mergeCountedMatch(notification, *info.alertID, prefix, matchKey)
+	}
 
-	if err = addEventToAlert(event, notification, alertID, creationTime); err != nil {
+	alertItem, err := addEventToAlert(notification, info, rule)
+	if err != nil {
+		forgetMatch(matchKey)
 		return err
 	}
+	recordMetrics(notification, info, alertItem)
+
+	if storeEvent(alertItem) {
+		zap.L().Info("successfully stored event")
+	} else {
+		zap.L().Info("alert reached its event limit, event not stored", zap.String("alertId", *info.alertID))
+		// The match is marked first: a retry only deletes the events the alert did not keep
+		if err = updateMatch(matchKey, expression.Set(expression.Name("overflow"), expression.Value(true))); err != nil {
+			zap.L().Warn("failed to mark match beyond the event limit", zap.Error(err))
+			return err
+		}
+		if err = deleteMatchedEvent(notification, prefix); err != nil {
+			zap.L().Warn("failed to delete event")
+			return err
+		}
+	}
+
+	if info.isNew && info.pendingPrefix != "" {
+		// A retry would merge the match into the alert created here, so failures are not retried: the
+		// events left behind expire with the threshold window.
+		if err = moveEvents(info.pendingPrefix, alertsapimodels.EventKeyPrefix(*info.alertID)); err != nil {
+			zap.L().Error("failed to move the events of the threshold window",
+				zap.String("alertId", *info.alertID), zap.String("prefix", info.pendingPrefix), zap.Error(err))
+			LogFailure(MetricEventMoveFailures)
+		}
+	}
 
-	if isNewAlert {
-		if err = sendAlert(notification, alertID); err != nil {
+	if info.isNew {
+		if err = sendAlert(notification, info, rule); err != nil {
 			zap.L().Warn("failed to send alert")
 			return err
 		}
 	}
 
-	return nil
+	if match.UncorrelatedAlertID == "" {
+		return nil
+	}
+	return correlateAlert(notification, matchKey, match.UncorrelatedAlertID)
+}
+
+// needsCorrelation returns true if the match creates an alert of a rule referenced by correlation rules
+//
+// Only new alerts are correlated, the matches merged into an existing alert were correlated with it.
+// Alerts of correlation rules are not correlated further.
+func needsCorrelation(notification *AlertNotification, info *alertInfo) bool {
+	return info.isNew && len(notification.CorrelatedAlertIDs) == 0 && len(correlationRules(*notification.RuleID)) > 0
 }
 
-func storeMatchedEvent(notification *AlertNotification) (*MatchedEvent, error) {
-	event := &MatchedEvent{
-		EventHash: sha1.Sum([]byte(*notification.Event)), //nolint: gosec
-		Timestamp: notification.Timestamp,
-		Event:     notification.Event,
+// correlateAlert correlates the alert created by the match, a retry of its batch correlates it again if this fails
+//
+// The sequences of the correlation rules hold each alert once, correlating the alert again does not add it twice.
+func correlateAlert(notification *AlertNotification, matchKey, alertID string) error {
+	if err := correlate(notification, aws.String(alertID)); err != nil {
+		return err
+	}
+	if err := updateMatch(matchKey, expression.Remove(expression.Name("uncorrelatedAlertId"))); err != nil {
+		zap.L().Warn("failed to mark alert as correlated", zap.String("alertId", alertID), zap.Error(err))
 	}
+	return nil
+}
 
-	marshalledItem, err := dynamodbattribute.MarshalMap(event)
+// mergeCountedMatch handles a match an earlier attempt of its batch already counted in its alert
+//
+// Its event was stored again, it is only deleted if the earlier attempt counted it beyond the event limit.
+// Its metrics are counted again and the alert created by the match is correlated if the earlier attempt
+// failed to correlate it.
+func mergeCountedMatch(notification *AlertNotification, alertID, prefix, matchKey string) error {
+	zap.L().Info("match was already merged into the alert", zap.String("matchKey", matchKey))
+	match, err := getMatch(matchKey)
 	if err != nil {
-		return nil, err
+		zap.L().Warn("failed to get match", zap.Error(err))
+		return err
 	}
-
-	input := &dynamodb.PutItemInput{
-		Item:      marshalledItem,
-		TableName: eventsTable,
+	if match.Overflow {
+		if err = deleteMatchedEvent(notification, prefix); err != nil {
+			zap.L().Warn("failed to delete event")
+			return err
+		}
 	}
+	if err = recordCountedMetrics(notification, alertID, match); err != nil {
+		return err
+	}
+	if match.UncorrelatedAlertID != "" {
+		return correlateAlert(notification, matchKey, match.UncorrelatedAlertID)
+	}
+	return nil
+}
 
-	_, err = ddbClient.PutItem(input)
-	if err != nil {
-		return nil, err
+// storeMatchedEvent writes the event to its own object under the prefix of the alert or threshold window
+func storeMatchedEvent(notification *AlertNotification, prefix string) error {
+	_, err := s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(alertEventsBucket),
+		Key:         aws.String(eventKey(notification, prefix)),
+		Body:        strings.NewReader(*notification.Event),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+// deleteMatchedEvent deletes the event of an alert which reached its event limit
+func deleteMatchedEvent(notification *AlertNotification, prefix string) error {
+	_, err := s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(alertEventsBucket),
+		Key:    aws.String(eventKey(notification, prefix)),
+	})
+	return err
+}
+
+// eventKey sorts the events of an alert by time, the hash of the event makes retries idempotent
+func eventKey(notification *AlertNotification, prefix string) string {
+	eventHash := sha1.Sum([]byte(*notification.Event)) //nolint: gosec
+	return prefix +
+		aws.TimeValue(notification.Timestamp).UTC().Format(eventKeyTimeFormat) + "-" +
+		hex.EncodeToString(eventHash[:]) + ".json"
+}
+
+// parseEventLimit returns the maximum number of events stored per alert
+func parseEventLimit(value string) int {
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return defaultEventLimit
 	}
-	return event, nil
+	return limit
 }
 
-// getAlertInfo returns whether a new alert is created, the alert key and the alert creation time
-func getAlertInfo(notification *AlertNotification) (bool, *string, *time.Time, error) {
+// getAlertInfo returns the alert the match is merged into, creating a new alert if needed
+func getAlertInfo(notification *AlertNotification) (*alertInfo, error) {
+	matchCount, pendingPrefix := int64(1), ""
+	threshold := aws.Int64Value(notification.Threshold)
+	if threshold > 1 {
+		window, err := countMatch(notification)
+		if err != nil {
+			return nil, err
+		}
+		if window == nil {
+			zap.L().Info("alert is active, merging the match into it")
+			return getCurrentAlertInfo(notification)
+		}
+		pendingPrefix = pendingEventsPrefix(notification, window.WindowStart)
+		if window.WindowCount < threshold {
+			return &alertInfo{
+				isPending:     true,
+				pendingPrefix: pendingPrefix,
+				matchCount:    window.WindowCount,
+			}, nil
+		}
+		matchCount = window.WindowCount
+	}
+
 	timeNow := time.Now().Unix()
-	expiresAt := int64(eventMergingPeriodSeconds) + timeNow
+	mergingPeriodSeconds := mergingPeriod(notification)
+	expiresAt := mergingPeriodSeconds + timeNow
 
 	updateExpression := expression.
 		Set(expression.Name("creationTime"), expression.Value(aws.Int64(timeNow))).
 		Set(expression.Name("expiresAt"), expression.Value(expiresAt)).
 		Add(expression.Name("alertCount"), expression.Value(1))
+	if notification.Dedup != nil && *notification.Dedup != "" {
+		updateExpression = updateExpression.Set(expression.Name("dedup"), expression.Value(notification.Dedup))
+	}
+	if threshold > 1 {
+		// The next window starts with the first match after this alert
+		updateExpression = updateExpression.
+			Remove(expression.Name("windowStart")).
+			Remove(expression.Name("windowCount"))
+	}
 
-	// The Condition will succeed only if `alertSuppressPeriod` has passed since the time the previous
+	// The Condition will succeed only if the merging period has passed since the time the previous
 	// alert was triggered
-	conditionExpression := expression.Name("creationTime").LessThan(expression.Value(timeNow - int64(eventMergingPeriodSeconds))).
+	conditionExpression := expression.Name("creationTime").LessThan(expression.Value(timeNow - mergingPeriodSeconds)).
 		Or(expression.Name("creationTime").AttributeNotExists())
 
 	buildExpression, err := expression.NewBuilder().
@@ -143,14 +348,12 @@ func getAlertInfo(notification *AlertNotification) (bool, *string, *time.Time, e
 
 	if err != nil {
 		zap.L().Error("failed to build expression", zap.Error(err))
-		return false, nil, nil, err
+		return nil, err
 	}
 
 	input := &dynamodb.UpdateItemInput{
-		TableName: recentAlertsTable,
-		Key: map[string]*dynamodb.AttributeValue{
-			"ruleId": {S: notification.RuleID},
-		},
+		TableName:                 recentAlertsTable,
+		Key:                       recentAlertKey(notification),
 		UpdateExpression:          buildExpression.Update(),
 		ConditionExpression:       buildExpression.Condition(),
 		ExpressionAttributeNames:  buildExpression.Names(),
@@ -163,43 +366,115 @@ func getAlertInfo(notification *AlertNotification) (bool, *string, *time.Time, e
 		aerr, ok := err.(awserr.Error)
 		if ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
 			zap.L().Info("update on ddb failed on condition, we will not trigger an alert")
-			alertID, alertCreationTime, err := getCurrentAlertInfo(notification)
-			return false, alertID, alertCreationTime, err
+			return getCurrentAlertInfo(notification)
 		}
 		zap.L().Warn("experienced issue while updating ddb table", zap.Error(err))
-		return false, nil, nil, err
+		return nil, err
 	}
 
-	compositeAlertKey := compositeAlertID(notification.RuleID, response.Attributes["alertCount"].N)
+	compositeAlertKey := compositeAlertID(notification, response.Attributes["alertCount"].N)
 	alertCreationTime, err := stringToTime(response.Attributes["creationTime"].N)
 	if err != nil {
-		return false, nil, nil, err
+		return nil, err
 	}
 
-	return true, compositeAlertKey, alertCreationTime, nil
+	return &alertInfo{
+		alertID:       compositeAlertKey,
+		creationTime:  alertCreationTime,
+		isNew:         true,
+		pendingPrefix: pendingPrefix,
+		matchCount:    matchCount,
+	}, nil
 }
 
-func getCurrentAlertInfo(notification *AlertNotification) (*string, *time.Time, error) {
+// countMatch counts the match in the threshold window of its (ruleId, dedup) pair with an atomic
+// counter, a new window is started when the previous one has expired. Matches are not counted while
+// an alert is active, a nil window is then returned.
+func countMatch(notification *AlertNotification) (*matchWindow, error) {
+	timeNow := time.Now().Unix()
+	windowExpiry := timeNow - thresholdWindow(notification)
+	noActiveAlert := expression.Name("creationTime").AttributeNotExists().
+		Or(expression.Name("creationTime").LessThan(expression.Value(timeNow - mergingPeriod(notification))))
+
+	increment := expression.Add(expression.Name("windowCount"), expression.Value(1))
+	windowOpen := expression.Name("windowStart").GreaterThanEqual(expression.Value(windowExpiry)).And(noActiveAlert)
+
+	start := expression.
+		Set(expression.Name("windowStart"), expression.Value(timeNow)).
+		Set(expression.Name("windowCount"), expression.Value(1))
+	windowExpired := expression.Name("windowStart").AttributeNotExists().
+		Or(expression.Name("windowStart").LessThan(expression.Value(windowExpiry))).
+		And(noActiveAlert)
+
+	// If starting a window fails, another match may have just started it: count in it once more
+	attempts := []struct {
+		update    expression.UpdateBuilder
+		condition expression.ConditionBuilder
+	}{
+		{increment, windowOpen},
+		{start, windowExpired},
+		{increment, windowOpen},
+	}
+	for _, attempt := range attempts {
+		window, err := updateMatchWindow(notification, attempt.update, attempt.condition)
+		if err == nil {
+			return window, nil
+		}
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != dynamodb.ErrCodeConditionalCheckFailedException {
+			zap.L().Warn("failed to count match", zap.Error(err))
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func updateMatchWindow(notification *AlertNotification, update expression.UpdateBuilder,
+	condition expression.ConditionBuilder) (*matchWindow, error) {
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := ddbClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 recentAlertsTable,
+		Key:                       recentAlertKey(notification),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	window := &matchWindow{}
+	if err = dynamodbattribute.UnmarshalMap(response.Attributes, window); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+func getCurrentAlertInfo(notification *AlertNotification) (*alertInfo, error) {
 	input := &dynamodb.GetItemInput{
-		Key: map[string]*dynamodb.AttributeValue{
-			"ruleId": {S: notification.RuleID},
-		},
+		Key:       recentAlertKey(notification),
 		TableName: recentAlertsTable,
 	}
 
 	response, err := ddbClient.GetItem(input)
 	if err != nil {
 		zap.L().Warn("failed to get alertCount", zap.Error(err))
-		return nil, nil, err
+		return nil, err
 	}
 
-	alertID := compositeAlertID(notification.RuleID, response.Item["alertCount"].N)
+	alertID := compositeAlertID(notification, response.Item["alertCount"].N)
 	alertCreationTime, err := stringToTime(response.Item["creationTime"].N)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return alertID, alertCreationTime, nil
+	return &alertInfo{alertID: alertID, creationTime: alertCreationTime, matchCount: 1}, nil
 }
 
 func stringToTime(input *string) (*time.Time, error) {
@@ -211,31 +486,93 @@ func stringToTime(input *string) (*time.Time, error) {
 	return aws.Time(time.Unix(unixTime, 0)), nil
 }
 
-func compositeAlertID(ruleID *string, alertCount *string) *string {
-	return aws.String(*ruleID + "-" + *alertCount)
+// thresholdWindow is the window in seconds in which the threshold of the rule must be reached
+func thresholdWindow(notification *AlertNotification) int64 {
+	minutes := aws.Int64Value(notification.ThresholdWindowMinutes)
+	if minutes <= 0 {
+		return mergingPeriod(notification)
+	}
+	return minutes * 60
 }
 
-type eventHashSet [][sha1.Size]byte
+// mergingPeriod returns the number of seconds during which matches of a rule are merged into the same alert
+func mergingPeriod(notification *AlertNotification) int64 {
+	minutes := aws.Int64Value(notification.DedupPeriodMinutes)
+	if minutes <= 0 {
+		minutes = defaultMergingPeriodMinutes
+	}
+	return minutes * 60
+}
 
-// Marshal binary slice as a Dynamo BinarySet instead of a List
-func (s eventHashSet) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
-	av.BS = make([][]byte, 0, len(s))
-	for _, eventHash := range s {
-		av.BS = append(av.BS, eventHash[:])
+// recentAlertKey returns the key of the recent alerts table, matches of a rule are merged per dedup string
+func recentAlertKey(notification *AlertNotification) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"ruleId": {S: aws.String(dedupPartition(notification))},
 	}
-	return nil
 }
 
-func addEventToAlert(event *MatchedEvent, alertNotification *AlertNotification, alertID *string, creationTime *time.Time) error {
+func hasDedup(notification *AlertNotification) bool {
+	return aws.StringValue(notification.Dedup) != ""
+}
+
+// dedupPartition identifies the (ruleId, dedup) pair
+//
+// Rules without a dedup string keep the rule ID as key, so the rows written before dedup strings
+// existed are still found. The dedup string is hashed since it can be long.
+func dedupPartition(notification *AlertNotification) string {
+	if !hasDedup(notification) {
+		return *notification.RuleID
+	}
+	key := md5.Sum([]byte(*notification.Dedup)) // nolint: gosec
+	return *notification.RuleID + ":" + hex.EncodeToString(key[:])
+}
+
+// compositeAlertID is unique per (ruleId, dedup) pair and the number of alerts created for it
+//
+// Alerts of rules without a dedup string keep the "ruleId-alertCount" format of existing alerts.
+func compositeAlertID(notification *AlertNotification, alertCount *string) *string {
+	if !hasDedup(notification) {
+		return aws.String(*notification.RuleID + "-" + *alertCount)
+	}
+	key := md5.Sum([]byte(dedupPartition(notification) + ":" + *alertCount)) // nolint: gosec
+	return aws.String(hex.EncodeToString(key[:]))
+}
+
+// addEventToAlert counts the event in the alert and returns the updated alert
+//
+// The rule is only set for new alerts. The matches below the threshold of the rule are counted in
+// the new alert as well, their events were stored before it was created.
+//
+// Only the updated attributes are returned, they include the metric dimensions of new alerts. The whole
+// alert is returned for existing alerts whose metric dimensions are not cached yet.
+func addEventToAlert(alertNotification *AlertNotification, info *alertInfo,
+	rule *policiesmodels.Rule) (*alertsapimodels.AlertItem, error) {
+
 	update := expression.
-		Add(expression.Name("eventHashes"), expression.Value(eventHashSet([][sha1.Size]byte{event.EventHash}))).
-		Set(expression.Name("creationTime"), expression.Value(creationTime)).
+		Add(expression.Name("eventCount"), expression.Value(info.matchCount)).
+		Set(expression.Name("eventLimit"), expression.IfNotExists(expression.Name("eventLimit"), expression.Value(eventLimit))).
+		Set(expression.Name("creationTime"), expression.Value(info.creationTime)).
 		Set(expression.Name("ruleId"), expression.Value(alertNotification.RuleID)).
-		Set(expression.Name("lastEventMatched"), expression.Value(event.Timestamp))
+		Set(expression.Name("lastEventMatched"), expression.Value(alertNotification.Timestamp)).
+		Set(expression.Name("timePartition"), expression.Value(alertsapimodels.TimePartition(*info.alertID))).
+		Set(expression.Name("status"), expression.IfNotExists(expression.Name("status"), expression.Value(alertsapimodels.StatusOpen)))
+	if alertNotification.Dedup != nil && *alertNotification.Dedup != "" {
+		update = update.Set(expression.Name("dedup"), expression.Value(alertNotification.Dedup))
+	}
+	if len(alertNotification.CorrelatedAlertIDs) > 0 {
+		alertIDs := stringSet(alertNotification.CorrelatedAlertIDs)
+		update = update.Add(expression.Name("correlatedAlertIds"), expression.Value(alertIDs))
+	}
+	if rule != nil {
+		update = setRuleInfo(update, alertNotification, rule)
+	}
+	if info.retentionDays > 0 {
+		update = setExpiry(update, *info.creationTime, info.retentionDays)
+	}
 
 	expr, err := expression.NewBuilder().WithUpdate(update).Build()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	input := &dynamodb.UpdateItemInput{
@@ -243,25 +580,75 @@ func addEventToAlert(event *MatchedEvent, alertNotification *AlertNotification,
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		Key: map[string]*dynamodb.AttributeValue{
-			"alertId": {S: alertID},
+			"alertId": {S: info.alertID},
 		},
 		TableName:        alertsTable,
 		UpdateExpression: expr.Update(),
+		ReturnValues:     aws.String(dynamodb.ReturnValueUpdatedNew),
+	}
+	if !alertDimensions.Contains(*info.alertID) && rule == nil {
+		input.ReturnValues = aws.String(dynamodb.ReturnValueAllNew)
 	}
 
-	_, err = ddbClient.UpdateItem(input)
+	response, err := ddbClient.UpdateItem(input)
 	if err != nil {
-		zap.L().Warn("failed to add event hashes to alert", zap.Error(err))
-		return err
+		zap.L().Warn("failed to add event to alert", zap.Error(err))
+		return nil, err
 	}
+
+	alertItem := &alertsapimodels.AlertItem{}
+	if err = dynamodbattribute.UnmarshalMap(response.Attributes, alertItem); err != nil {
+		return nil, err
+	}
+	return alertItem, nil
+}
+
+type stringSet []*string
+
+// Marshal string slice as a Dynamo StringSet instead of a List
+func (s stringSet) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	av.SS = s
 	return nil
 }
 
-func sendAlert(notification *AlertNotification, alertID *string) error {
-	alert, err := getAlert(notification, alertID)
-	if err != nil {
-		return err
+// storeEvent returns whether the last event of the alert is within its event limit
+func storeEvent(alertItem *alertsapimodels.AlertItem) bool {
+	return aws.IntValue(alertItem.EventCount) <= aws.IntValue(alertItem.EventLimit)
+}
+
+// setRuleInfo stores the rule information used to search alerts
+//
+// The title and context returned by the rule for the match which triggered the alert are stored as well.
+func setRuleInfo(update expression.UpdateBuilder, notification *AlertNotification,
+	rule *policiesmodels.Rule) expression.UpdateBuilder {
+
+	title := notification.RuleID
+	if rule.DisplayName != "" {
+		title = aws.String(string(rule.DisplayName))
+		update = update.Set(expression.Name("ruleDisplayName"), expression.Value(title))
+	}
+	if aws.StringValue(notification.Title) != "" {
+		title = notification.Title
+	}
+	if len(notification.AlertContext) > 0 {
+		update = update.Set(expression.Name("context"), expression.Value(notification.AlertContext))
+	}
+	update = update.
+		Set(expression.Name("title"), expression.Value(title)).
+		Set(expression.Name("severity"), expression.Value(string(rule.Severity)))
+
+	// Empty lists are not stored, Dynamo would store them as NULL
+	if len(rule.LogTypes) > 0 {
+		update = update.Set(expression.Name("logTypes"), expression.Value([]string(rule.LogTypes)))
+	}
+	if len(rule.Tags) > 0 {
+		update = update.Set(expression.Name("tags"), expression.Value([]string(rule.Tags)))
 	}
+	return update
+}
+
+func sendAlert(notification *AlertNotification, info *alertInfo, rule *policiesmodels.Rule) error {
+	alert := getAlert(notification, info, rule)
 	msgBody, err := jsoniter.MarshalToString(alert)
 	if err != nil {
 		return err
@@ -279,27 +666,28 @@ func sendAlert(notification *AlertNotification, alertID *string) error {
 	return nil
 }
 
-func getAlert(notification *AlertNotification, alertID *string) (*alertmodel.Alert, error) {
-	rule, err := policyClient.Operations.GetRule(&policiesoperations.GetRuleParams{
-		RuleID:     *notification.RuleID,
-		HTTPClient: httpClient,
-	})
+func getRule(notification *AlertNotification) (*policiesmodels.Rule, error) {
+	// The params are initialized with the default timeout, a zero timeout expires the request immediately
+	rule, err := policyClient.Operations.GetRule(
+		policiesoperations.NewGetRuleParams().WithRuleID(*notification.RuleID).WithHTTPClient(httpClient))
 
 	if err != nil {
 		zap.L().Warn("failed to fetch rule information", zap.Error(err))
 		return nil, err
 	}
+	return rule.Payload, nil
+}
 
-	return &alertmodel.Alert{
-		CreatedAt:         notification.Timestamp,
-		PolicyDescription: aws.String(string(rule.Payload.Description)),
-		PolicyID:          notification.RuleID,
-		PolicyName:        aws.String(string(rule.Payload.DisplayName)),
-		PolicyVersionID:   notification.RuleVersionID,
-		Runbook:           aws.String(string(rule.Payload.Runbook)),
-		Severity:          aws.String(string(rule.Payload.Severity)),
-		Tags:              aws.StringSlice(rule.Payload.Tags),
-		Type:              aws.String(alertmodel.RuleType),
-		AlertID:           alertID,
-	}, nil
+func getAlert(notification *AlertNotification, info *alertInfo, rule *policiesmodels.Rule) *alertmodel.Alert {
+	alert := alertmodel.NewRuleAlert(rule, info.alertID, notification.Dedup, notification.Timestamp, info.matchCount)
+	// The notification identifies the version of the rule which matched the events
+	alert.PolicyID = notification.RuleID
+	alert.PolicyVersionID = notification.RuleVersionID
+	if aws.StringValue(notification.Title) != "" {
+		alert.Title = notification.Title
+	}
+	for _, field := range notification.AlertContext {
+		alert.Context = append(alert.Context, &alertmodel.ContextField{Key: field.Key, Value: field.Value})
+	}
+	return alert
 }
@@ -0,0 +1,424 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	policiesmodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+	alertsapimodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+)
+
+type mockDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoDB) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoDB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoDB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.PutItemOutput), args.Error(1)
+}
+
+func TestDedupPartition(t *testing.T) {
+	noDedup := &AlertNotification{RuleID: aws.String("rule.id")}
+	emptyDedup := &AlertNotification{RuleID: aws.String("rule.id"), Dedup: aws.String("")}
+	dedup1 := &AlertNotification{RuleID: aws.String("rule.id"), Dedup: aws.String("1.2.3.4")}
+	dedup2 := &AlertNotification{RuleID: aws.String("rule.id"), Dedup: aws.String("5.6.7.8")}
+	otherRule := &AlertNotification{RuleID: aws.String("other.rule.id"), Dedup: aws.String("1.2.3.4")}
+
+	assert.Equal(t, "rule.id", dedupPartition(noDedup))
+	assert.Equal(t, dedupPartition(noDedup), dedupPartition(emptyDedup))
+	assert.Len(t, dedupPartition(dedup1), len("rule.id:")+32)
+	assert.NotEqual(t, dedupPartition(noDedup), dedupPartition(dedup1))
+	assert.NotEqual(t, dedupPartition(dedup1), dedupPartition(dedup2))
+	assert.NotEqual(t, dedupPartition(dedup1), dedupPartition(otherRule))
+}
+
+func TestCompositeAlertID(t *testing.T) {
+	noDedup := &AlertNotification{RuleID: aws.String("rule.id")}
+	dedup1 := &AlertNotification{RuleID: aws.String("rule.id"), Dedup: aws.String("1.2.3.4")}
+	dedup2 := &AlertNotification{RuleID: aws.String("rule.id"), Dedup: aws.String("5.6.7.8")}
+
+	assert.Equal(t, *compositeAlertID(dedup1, aws.String("1")), *compositeAlertID(dedup1, aws.String("1")))
+	assert.NotEqual(t, *compositeAlertID(dedup1, aws.String("1")), *compositeAlertID(dedup1, aws.String("2")))
+	assert.NotEqual(t, *compositeAlertID(dedup1, aws.String("1")), *compositeAlertID(dedup2, aws.String("1")))
+	assert.Equal(t, "rule.id-1", *compositeAlertID(noDedup, aws.String("1")))
+}
+
+func TestMergingPeriod(t *testing.T) {
+	assert.Equal(t, int64(3600), mergingPeriod(&AlertNotification{RuleID: aws.String("rule.id")}))
+	assert.Equal(t, int64(300), mergingPeriod(&AlertNotification{RuleID: aws.String("rule.id"), DedupPeriodMinutes: aws.Int64(5)}))
+	assert.Equal(t, int64(86400), mergingPeriod(&AlertNotification{RuleID: aws.String("rule.id"), DedupPeriodMinutes: aws.Int64(1440)}))
+}
+
+func TestThresholdWindow(t *testing.T) {
+	// The merging period is the default window
+	assert.Equal(t, int64(3600), thresholdWindow(&AlertNotification{RuleID: aws.String("rule.id")}))
+	assert.Equal(t, int64(300), thresholdWindow(&AlertNotification{RuleID: aws.String("rule.id"), DedupPeriodMinutes: aws.Int64(5)}))
+	assert.Equal(t, int64(600), thresholdWindow(&AlertNotification{
+		RuleID: aws.String("rule.id"), DedupPeriodMinutes: aws.Int64(5), ThresholdWindowMinutes: aws.Int64(10)}))
+}
+
+func TestSetRuleInfo(t *testing.T) {
+	notification := &AlertNotification{RuleID: aws.String("rule.id")}
+	rule := &policiesmodels.Rule{
+		DisplayName: "Root Login",
+		LogTypes:    []string{"AWS.CloudTrail"},
+		Severity:    "HIGH",
+	}
+
+	expr, err := expression.NewBuilder().
+		WithUpdate(setRuleInfo(expression.UpdateBuilder{}, notification, rule)).
+		Build()
+	require.NoError(t, err)
+
+	var names []string
+	for _, name := range expr.Names() {
+		names = append(names, *name)
+	}
+	// Tags are empty and not stored
+	assert.ElementsMatch(t, []string{"ruleDisplayName", "title", "severity", "logTypes"}, names)
+
+	var values []string
+	for _, value := range expr.Values() {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.ElementsMatch(t, []string{"Root Login", "Root Login", "HIGH"}, values)
+}
+
+func TestSetRuleInfoNoDisplayName(t *testing.T) {
+	notification := &AlertNotification{RuleID: aws.String("rule.id")}
+	expr, err := expression.NewBuilder().
+		WithUpdate(setRuleInfo(expression.UpdateBuilder{}, notification, &policiesmodels.Rule{Severity: "INFO"})).
+		Build()
+	require.NoError(t, err)
+
+	// The title defaults to the rule id
+	var values []string
+	for _, value := range expr.Values() {
+		values = append(values, *value.S)
+	}
+	assert.ElementsMatch(t, []string{"rule.id", "INFO"}, values)
+	assert.Len(t, expr.Names(), 2)
+}
+
+func TestSetRuleInfoTitleAndContext(t *testing.T) {
+	notification := &AlertNotification{
+		RuleID:       aws.String("rule.id"),
+		Title:        aws.String("Root login from 1.2.3.4"),
+		AlertContext: []*alertsapimodels.ContextField{{Key: "user", Value: "root"}},
+	}
+	expr, err := expression.NewBuilder().
+		WithUpdate(setRuleInfo(expression.UpdateBuilder{}, notification, &policiesmodels.Rule{DisplayName: "Root Login", Severity: "INFO"})).
+		Build()
+	require.NoError(t, err)
+
+	var names []string
+	for _, name := range expr.Names() {
+		names = append(names, *name)
+	}
+	assert.ElementsMatch(t, []string{"ruleDisplayName", "title", "severity", "context"}, names)
+
+	// The title computed by the rule replaces the display name
+	var values []string
+	for _, value := range expr.Values() {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.ElementsMatch(t, []string{"Root Login", "Root login from 1.2.3.4", "INFO"}, values)
+}
+
+func TestEventKey(t *testing.T) {
+	prefix := alertsapimodels.EventKeyPrefix("alert-id")
+	first := &AlertNotification{
+		Event:     aws.String(`{"key": "value"}`),
+		Timestamp: aws.Time(time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)),
+	}
+	second := &AlertNotification{
+		Event:     aws.String(`{"key": "value"}`),
+		Timestamp: aws.Time(time.Date(2020, 1, 1, 10, 0, 0, 500, time.UTC)),
+	}
+	third := &AlertNotification{
+		Event:     aws.String(`{"key": "other value"}`),
+		Timestamp: aws.Time(time.Date(2020, 1, 2, 0, 0, 0, 0, time.FixedZone("PST", -8*3600))),
+	}
+
+	assert.Equal(t, eventKey(first, prefix), eventKey(first, prefix))
+	assert.True(t, strings.HasPrefix(eventKey(first, prefix), "alerts/alert-id/20200101T090000.000000000Z-"))
+
+	keys := []string{eventKey(third, prefix), eventKey(second, prefix), eventKey(first, prefix)}
+	sort.Strings(keys)
+	assert.Equal(t, []string{eventKey(first, prefix), eventKey(second, prefix), eventKey(third, prefix)}, keys)
+}
+
+func TestStoreMatchedEvent(t *testing.T) {
+	client := &mockS3{}
+	s3Client = client
+	alertEventsBucket = "bucket"
+	notification := &AlertNotification{Event: aws.String(`{"key": "value"}`), Timestamp: aws.Time(time.Now())}
+	prefix := alertsapimodels.EventKeyPrefix("alert-id")
+
+	// The event of an alert beyond its event limit is deleted from the object it was stored in
+	client.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+	client.On("DeleteObject", mock.Anything).Return(&s3.DeleteObjectOutput{}, nil).Once()
+	require.NoError(t, storeMatchedEvent(notification, prefix))
+	require.NoError(t, deleteMatchedEvent(notification, prefix))
+
+	stored := client.Calls[0].Arguments[0].(*s3.PutObjectInput)
+	deleted := client.Calls[1].Arguments[0].(*s3.DeleteObjectInput)
+	assert.Equal(t, eventKey(notification, prefix), *stored.Key)
+	assert.Equal(t, *stored.Key, *deleted.Key)
+	assert.Equal(t, "bucket", *deleted.Bucket)
+	client.AssertExpectations(t)
+}
+
+func TestParseEventLimit(t *testing.T) {
+	assert.Equal(t, 500, parseEventLimit("500"))
+	assert.Equal(t, defaultEventLimit, parseEventLimit(""))
+	assert.Equal(t, defaultEventLimit, parseEventLimit("0"))
+	assert.Equal(t, defaultEventLimit, parseEventLimit("many"))
+}
+
+func TestAddEventToAlertLimit(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	alertDimensions.Purge()
+	notification := &AlertNotification{
+		RuleID:    aws.String("rule.id"),
+		Event:     aws.String("{}"),
+		Timestamp: aws.Time(time.Now()),
+	}
+
+	counts := func(count, limit string) *dynamodb.UpdateItemOutput {
+		return &dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+			"eventCount": {N: aws.String(count)},
+			"eventLimit": {N: aws.String(limit)},
+		}}
+	}
+	client.On("UpdateItem", mock.Anything).Return(counts("10", "10"), nil).Once()
+	client.On("UpdateItem", mock.Anything).Return(counts("11", "10"), nil).Once()
+
+	info := &alertInfo{alertID: aws.String("alert-id"), creationTime: aws.Time(time.Now()), matchCount: 1}
+	alertItem, err := addEventToAlert(notification, info, nil)
+	require.NoError(t, err)
+	assert.True(t, storeEvent(alertItem))
+
+	alertItem, err = addEventToAlert(notification, info, nil)
+	require.NoError(t, err)
+	assert.False(t, storeEvent(alertItem))
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Equal(t, dynamodb.ReturnValueAllNew, *input.ReturnValues)
+	assert.Contains(t, *input.UpdateExpression, "ADD")
+	client.AssertExpectations(t)
+}
+
+func TestAddEventToAlertReturnValues(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	alertDimensions.Purge()
+	alertDimensions.Add("cached-id", []alertsapimodels.MetricDimension{{Type: alertsapimodels.MetricTotal}})
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Times(3)
+	notification := &AlertNotification{RuleID: aws.String("rule.id"), Timestamp: aws.Time(time.Now())}
+
+	// New alerts set their metric dimensions, the dimensions of existing alerts are cached after their first update
+	rule := &policiesmodels.Rule{Severity: "HIGH", LogTypes: []string{"AWS.CloudTrail"}}
+	_, err := addEventToAlert(notification, &alertInfo{alertID: aws.String("new-id"), isNew: true, matchCount: 1}, rule)
+	require.NoError(t, err)
+	_, err = addEventToAlert(notification, &alertInfo{alertID: aws.String("cached-id"), matchCount: 1}, nil)
+	require.NoError(t, err)
+	_, err = addEventToAlert(notification, &alertInfo{alertID: aws.String("other-id"), matchCount: 1}, nil)
+	require.NoError(t, err)
+
+	var returnValues []string
+	for _, call := range client.Calls {
+		returnValues = append(returnValues, *call.Arguments[0].(*dynamodb.UpdateItemInput).ReturnValues)
+	}
+	assert.Equal(t, []string{
+		dynamodb.ReturnValueUpdatedNew, dynamodb.ReturnValueUpdatedNew, dynamodb.ReturnValueAllNew,
+	}, returnValues)
+	client.AssertExpectations(t)
+}
+
+func TestAddEventToAlertCorrelated(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	notification := &AlertNotification{
+		RuleID:             aws.String("correlation.rule.id"),
+		Event:              aws.String("{}"),
+		Timestamp:          aws.Time(time.Now()),
+		CorrelatedAlertIDs: []*string{aws.String("alert-1"), aws.String("alert-2")},
+	}
+	output := &dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"correlatedAlertIds": {SS: notification.CorrelatedAlertIDs},
+	}}
+	client.On("UpdateItem", mock.Anything).Return(output, nil).Once()
+
+	info := &alertInfo{alertID: aws.String("alert-id"), creationTime: aws.Time(time.Now()), matchCount: 1}
+	alertItem, err := addEventToAlert(notification, info, nil)
+	require.NoError(t, err)
+	assert.Equal(t, notification.CorrelatedAlertIDs, alertItem.CorrelatedAlertIDs)
+
+	// The contributing alerts are added to a string set, alerts merged later link all of them
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	var linked bool
+	for _, value := range input.ExpressionAttributeValues {
+		if value.SS != nil {
+			assert.Equal(t, notification.CorrelatedAlertIDs, value.SS)
+			linked = true
+		}
+	}
+	assert.True(t, linked)
+	client.AssertExpectations(t)
+}
+
+func windowOutput(alertCount, windowCount string) *dynamodb.UpdateItemOutput {
+	return &dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"alertCount":  {N: aws.String(alertCount)},
+		"windowStart": {N: aws.String("1577836800")},
+		"windowCount": {N: aws.String(windowCount)},
+	}}
+}
+
+func TestGetAlertInfoBelowThreshold(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	notification := &AlertNotification{RuleID: aws.String("rule.id"), Threshold: aws.Int64(10)}
+
+	// The current window has expired, a new one is started
+	conditionFailed := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil)
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, conditionFailed).Once()
+	client.On("UpdateItem", mock.Anything).Return(windowOutput("2", "1"), nil).Once()
+
+	info, err := getAlertInfo(notification)
+	require.NoError(t, err)
+	assert.True(t, info.isPending)
+	assert.False(t, info.isNew)
+	assert.Equal(t, int64(1), info.matchCount)
+	// The events are stored under the prefix of the window until the alert is created
+	assert.Nil(t, info.alertID)
+	assert.Equal(t, pendingEventsPrefix(notification, 1577836800), info.pendingPrefix)
+
+	start := client.Calls[1].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *start.UpdateExpression, "SET")
+	assert.Equal(t, dynamodb.ReturnValueAllNew, *start.ReturnValues)
+	client.AssertExpectations(t)
+}
+
+func TestGetAlertInfoThresholdReached(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	notification := &AlertNotification{RuleID: aws.String("rule.id"), Threshold: aws.Int64(10)}
+
+	client.On("UpdateItem", mock.Anything).Return(windowOutput("2", "10"), nil).Once()
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"alertCount":   {N: aws.String("3")},
+		"creationTime": {N: aws.String("1577836800")},
+	}}, nil).Once()
+
+	info, err := getAlertInfo(notification)
+	require.NoError(t, err)
+	assert.True(t, info.isNew)
+	assert.False(t, info.isPending)
+	assert.Equal(t, int64(10), info.matchCount)
+	assert.Equal(t, compositeAlertID(notification, aws.String("3")), info.alertID)
+	assert.Equal(t, pendingEventsPrefix(notification, 1577836800), info.pendingPrefix)
+
+	// The window is reset when the alert is created
+	create := client.Calls[1].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *create.UpdateExpression, "REMOVE")
+	client.AssertExpectations(t)
+}
+
+func TestCountMatchActiveAlert(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	notification := &AlertNotification{RuleID: aws.String("rule.id"), Threshold: aws.Int64(10)}
+
+	conditionFailed := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil)
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, conditionFailed).Times(3)
+
+	window, err := countMatch(notification)
+	require.NoError(t, err)
+	assert.Nil(t, window)
+	client.AssertExpectations(t)
+}
+
+func TestCountMatchThresholdWindow(t *testing.T) {
+	client := &mockDynamoDB{}
+	ddbClient = client
+	notification := &AlertNotification{RuleID: aws.String("rule.id"), Threshold: aws.Int64(10), ThresholdWindowMinutes: aws.Int64(10)}
+
+	client.On("UpdateItem", mock.Anything).Return(windowOutput("2", "3"), nil).Once()
+	_, err := countMatch(notification)
+	require.NoError(t, err)
+
+	// The window expires after the threshold window, an alert is active during the merging period
+	now := time.Now().Unix()
+	var expiries []int64
+	for _, value := range client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput).ExpressionAttributeValues {
+		if value.N == nil || *value.N == "1" {
+			continue
+		}
+		expiry, err := strconv.ParseInt(*value.N, 10, 64)
+		require.NoError(t, err)
+		expiries = append(expiries, now-expiry)
+	}
+	require.Len(t, expiries, 2)
+	sort.Slice(expiries, func(i, j int) bool { return expiries[i] < expiries[j] })
+	assert.InDelta(t, 600, expiries[0], 5)
+	assert.InDelta(t, 3600, expiries[1], 5)
+	client.AssertExpectations(t)
+}
+
+func TestGetAlertEventCount(t *testing.T) {
+	notification := &AlertNotification{RuleID: aws.String("rule.id"), Timestamp: aws.Time(time.Now())}
+	info := &alertInfo{alertID: aws.String("alert-id"), isNew: true, matchCount: 10}
+
+	alert := getAlert(notification, info, &policiesmodels.Rule{Severity: "HIGH"})
+	assert.Equal(t, aws.Int64(10), alert.EventCount)
+	assert.Equal(t, aws.String("alert-id"), alert.AlertID)
+}
@@ -0,0 +1,63 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"go.uber.org/zap"
+
+	organizationmodels "github.com/panther-labs/panther/api/lambda/organization/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The retention settings are cached by each Lambda container
+const retentionCacheDuration = 5 * time.Minute
+
+var (
+	retentionCache     *organizationmodels.AlertRetention
+	retentionCacheTime time.Time
+)
+
+// retentionDays returns the number of days alerts of the given severity are kept, zero to keep them indefinitely
+func retentionDays(severity string) int64 {
+	if time.Since(retentionCacheTime) > retentionCacheDuration {
+		input := &organizationmodels.LambdaInput{GetOrganization: &organizationmodels.GetOrganizationInput{}}
+		var output organizationmodels.GetOrganizationOutput
+		if err := genericapi.Invoke(lambdaClient, organizationsAPI, input, &output); err != nil {
+			// Alerts are not held back by the retention settings, the last known settings are used
+			zap.L().Warn("failed to get alert retention settings", zap.Error(err))
+			return retentionCache.Days(severity)
+		}
+
+		retentionCache = nil
+		if output.Organization != nil {
+			retentionCache = output.Organization.AlertRetention
+		}
+		retentionCacheTime = time.Now()
+	}
+	return retentionCache.Days(severity)
+}
+
+// setExpiry stores when the alert is archived, the alert archiver only deletes it once it is archived
+func setExpiry(update expression.UpdateBuilder, creationTime time.Time, days int64) expression.UpdateBuilder {
+	expiresAt := creationTime.UTC().Add(time.Duration(days) * 24 * time.Hour)
+	return update.Set(expression.Name("expiresAt"), expression.Value(expiresAt))
+}
@@ -0,0 +1,87 @@
+package merger
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLambda struct {
+	lambdaiface.LambdaAPI
+	mock.Mock
+}
+
+func (m *mockLambda) Invoke(input *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*lambda.InvokeOutput), args.Error(1)
+}
+
+func TestRetentionDays(t *testing.T) {
+	client := &mockLambda{}
+	lambdaClient = client
+	retentionCacheTime = time.Time{}
+
+	payload := `{"organization": {"alertRetention": {"retentionDays": 90, ` +
+		`"severityRetentionDays": [{"severity": "CRITICAL", "retentionDays": 365}]}}}`
+	client.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{Payload: []byte(payload)}, nil).Once()
+
+	assert.Equal(t, int64(365), retentionDays("CRITICAL"))
+	// The settings are cached
+	assert.Equal(t, int64(90), retentionDays("LOW"))
+	client.AssertExpectations(t)
+}
+
+func TestRetentionDaysError(t *testing.T) {
+	client := &mockLambda{}
+	lambdaClient = client
+	retentionCache = nil
+	retentionCacheTime = time.Time{}
+
+	client.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{}, errors.New("unavailable"))
+
+	// Alerts are kept indefinitely when the settings are unknown
+	assert.Equal(t, int64(0), retentionDays("HIGH"))
+	client.AssertExpectations(t)
+}
+
+func TestSetExpiry(t *testing.T) {
+	creationTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	update := setExpiry(expression.UpdateBuilder{}, creationTime, 30)
+
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	require.NoError(t, err)
+	require.Len(t, expr.Values(), 1)
+	for _, value := range expr.Values() {
+		assert.Equal(t, "2020-01-31T00:00:00Z", *value.S)
+	}
+	// The alert is not deleted by a TTL before it is archived
+	require.Len(t, expr.Names(), 1)
+	for _, name := range expr.Names() {
+		assert.Equal(t, "expiresAt", *name)
+	}
+}
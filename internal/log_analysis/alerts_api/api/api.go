@@ -24,6 +24,14 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/kelseyhightower/envconfig"
 
 	"github.com/panther-labs/panther/api/gateway/analysis/client"
@@ -40,14 +48,29 @@ var (
 	httpClient     *http.Client
 	policiesClient *client.PantherAnalysis
 	alertsDB       table.API
+	lambdaClient   lambdaiface.LambdaAPI
+	s3Client       s3iface.S3API
+	s3Uploader     s3manageriface.UploaderAPI
+	sqsClient      sqsiface.SQSAPI
 )
 
 type envConfig struct {
-	AnalysisAPIHost string `required:"true" split_words:"true"`
-	AnalysisAPIPath string `required:"true" split_words:"true"`
-	AlertsTableName string `required:"true" split_words:"true"`
-	RuleIndexName   string `required:"true" split_words:"true"`
-	EventsTableName string `required:"true" split_words:"true"`
+	AnalysisAPIHost           string `required:"true" split_words:"true"`
+	AnalysisAPIPath           string `required:"true" split_words:"true"`
+	AlertsTableName           string `required:"true" split_words:"true"`
+	RuleIndexName             string `required:"true" split_words:"true"`
+	TimeIndexName             string `required:"true" split_words:"true"`
+	EventsTableName           string `required:"true" split_words:"true"`
+	CommentsTableName         string `required:"true" split_words:"true"`
+	CommentsIndexName         string `required:"true" split_words:"true"`
+	DeliveriesTableName       string `required:"true" split_words:"true"`
+	DeliveriesOutputIndexName string `required:"true" split_words:"true"`
+	HistoryTableName          string `required:"true" split_words:"true"`
+	AlertEventsBucket         string `required:"true" split_words:"true"`
+	ExportsTableName          string `required:"true" split_words:"true"`
+	MetricsTableName          string `required:"true" split_words:"true"`
+	AlertingQueueURL          string `required:"true" split_words:"true"`
+	ExportFunctionName        string `required:"true" split_words:"true"`
 }
 
 // Setup parses the environment and builds the AWS and http clients.
@@ -62,10 +85,25 @@ func Setup() {
 			WithHost(env.AnalysisAPIHost).
 			WithBasePath("/"+env.AnalysisAPIPath))
 
+	lambdaClient = lambda.New(awsSession)
+	s3Client = s3.New(awsSession)
+	s3Uploader = s3manager.NewUploaderWithClient(s3Client)
+	sqsClient = sqs.New(awsSession)
+
 	alertsDB = &table.AlertsTable{
-		AlertsTableName:             env.AlertsTableName,
-		Client:                      dynamodb.New(awsSession),
-		EventsTableName:             env.EventsTableName,
-		RuleIDCreationTimeIndexName: env.RuleIndexName,
+		AlertsTableName:                    env.AlertsTableName,
+		Client:                             dynamodb.New(awsSession),
+		EventsTableName:                    env.EventsTableName,
+		RuleIDCreationTimeIndexName:        env.RuleIndexName,
+		TimePartitionCreationTimeIndexName: env.TimeIndexName,
+		CommentsTableName:                  env.CommentsTableName,
+		CommentsCreatedAtIndexName:         env.CommentsIndexName,
+		DeliveriesTableName:                env.DeliveriesTableName,
+		DeliveriesOutputIndexName:          env.DeliveriesOutputIndexName,
+		HistoryTableName:                   env.HistoryTableName,
+		ExportsTableName:                   env.ExportsTableName,
+		MetricsTableName:                   env.MetricsTableName,
+		EventsBucket:                       env.AlertEventsBucket,
+		S3Client:                           s3Client,
 	}
 }
@@ -0,0 +1,121 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+)
+
+type mockTable struct {
+	table.API
+	mock.Mock
+}
+
+func (m *mockTable) GetAlert(alertID *string) (*models.AlertItem, error) {
+	args := m.Called(alertID)
+	return args.Get(0).(*models.AlertItem), args.Error(1)
+}
+
+func (m *mockTable) AddAlertChange(alertID *string, change *models.AlertChange) error {
+	args := m.Called(alertID, change)
+	return args.Error(0)
+}
+
+func (m *mockTable) GetAlertHistory(alertItem *models.AlertItem) ([]*models.AlertChange, error) {
+	args := m.Called(alertItem)
+	return args.Get(0).([]*models.AlertChange), args.Error(1)
+}
+
+func (m *mockTable) ListAlerts(input *models.ListAlertsInput) ([]*models.AlertItem, *string, error) {
+	args := m.Called(input)
+	return args.Get(0).([]*models.AlertItem), args.Get(1).(*string), args.Error(2)
+}
+
+func (m *mockTable) ListEvents(alertID, exclusiveStartKey *string, pageSize *int) ([]*string, *string, error) {
+	args := m.Called(alertID, exclusiveStartKey, pageSize)
+	return args.Get(0).([]*string), args.Get(1).(*string), args.Error(2)
+}
+
+func (m *mockTable) ListDeliveries(alertID *string) ([]*models.AlertDelivery, error) {
+	args := m.Called(alertID)
+	return args.Get(0).([]*models.AlertDelivery), args.Error(1)
+}
+
+func (m *mockTable) ListComments(alertID, exclusiveStartKey *string, pageSize *int) (
+	[]*models.AlertComment, *string, error) {
+
+	args := m.Called(alertID, exclusiveStartKey, pageSize)
+	return args.Get(0).([]*models.AlertComment), args.Get(1).(*string), args.Error(2)
+}
+
+func (m *mockTable) AddDeliveries(deliveries []*models.AlertDelivery) error {
+	args := m.Called(deliveries)
+	return args.Error(0)
+}
+
+func (m *mockTable) ListOutputDeliveries(outputID *string, limit int) ([]*models.AlertDelivery, error) {
+	args := m.Called(outputID, limit)
+	return args.Get(0).([]*models.AlertDelivery), args.Error(1)
+}
+
+func (m *mockTable) PutExportJob(job *models.ExportJob) error {
+	// Record a copy, the job is updated after it is stored
+	stored := *job
+	args := m.Called(&stored)
+	return args.Error(0)
+}
+
+func (m *mockTable) GetExportJob(jobID *string) (*models.ExportJob, error) {
+	args := m.Called(jobID)
+	return args.Get(0).(*models.ExportJob), args.Error(1)
+}
+
+func (m *mockTable) UpdateAlertStatus(alertID, status, userID, resolution *string) (*models.AlertItem, error) {
+	args := m.Called(alertID, status, userID, resolution)
+	return args.Get(0).(*models.AlertItem), args.Error(1)
+}
+
+func (m *mockTable) AddAlertMetrics(
+	timestamp time.Time, dimensions []models.MetricDimension, counts *models.MetricCounts) error {
+
+	args := m.Called(timestamp, dimensions, counts)
+	return args.Error(0)
+}
+
+func (m *mockTable) ListAlertMetrics(start, end time.Time) ([]*models.AlertMetricItem, error) {
+	args := m.Called(start, end)
+	return args.Get(0).([]*models.AlertMetricItem), args.Error(1)
+}
+
+type mockSQS struct {
+	sqsiface.SQSAPI
+	mock.Mock
+}
+
+func (m *mockSQS) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*sqs.SendMessageOutput), args.Error(1)
+}
@@ -0,0 +1,70 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// AddAlertComment adds a comment to an existing alert
+func (API) AddAlertComment(input *models.AddAlertCommentInput) (*models.AddAlertCommentOutput, error) {
+	zap.L().Info("adding alert comment", zap.String("alertId", *input.AlertID))
+
+	alertItem, err := alertsDB.GetAlert(input.AlertID)
+	if err != nil {
+		return nil, err
+	}
+	if alertItem.AlertID == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "alertId=" + *input.AlertID}
+	}
+
+	return alertsDB.AddComment(input.AlertID, input.UserID, input.Body)
+}
+
+// UpdateAlertComment edits a comment
+func (API) UpdateAlertComment(input *models.UpdateAlertCommentInput) (*models.UpdateAlertCommentOutput, error) {
+	zap.L().Info("updating alert comment",
+		zap.String("alertId", *input.AlertID), zap.String("commentId", *input.CommentID))
+	return alertsDB.UpdateComment(input.AlertID, input.CommentID, input.UserID, input.Body)
+}
+
+// DeleteAlertComment deletes a comment
+func (API) DeleteAlertComment(input *models.DeleteAlertCommentInput) error {
+	zap.L().Info("deleting alert comment",
+		zap.String("alertId", *input.AlertID), zap.String("commentId", *input.CommentID))
+	return alertsDB.DeleteComment(input.AlertID, input.CommentID, input.UserID)
+}
+
+// ListAlertComments lists the comments of an alert, oldest first
+func (API) ListAlertComments(input *models.ListAlertCommentsInput) (*models.ListAlertCommentsOutput, error) {
+	zap.L().Info("listing alert comments", zap.Any("input", input))
+
+	comments, lastEvaluatedKey, err := alertsDB.ListComments(input.AlertID, input.ExclusiveStartKey, input.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ListAlertCommentsOutput{Comments: comments, LastEvaluatedKey: lastEvaluatedKey}
+	gatewayapi.ReplaceMapSliceNils(result)
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// GetAlertDeliveries lists the attempts to deliver an alert to its outputs, oldest first
+func (API) GetAlertDeliveries(input *models.GetAlertDeliveriesInput) (models.GetAlertDeliveriesOutput, error) {
+	zap.L().Info("getting alert deliveries", zap.Any("input", input))
+
+	alertItem, err := alertsDB.GetAlert(input.AlertID)
+	if err != nil {
+		return nil, err
+	}
+	if alertItem.AlertID == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "alertId=" + *input.AlertID}
+	}
+
+	deliveries, err := alertsDB.ListDeliveries(input.AlertID)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = make(models.GetAlertDeliveriesOutput, 0)
+	}
+	return deliveries, nil
+}
+
+// ListOutputDeliveries lists the latest attempts to deliver alerts to an output, newest first
+func (API) ListOutputDeliveries(input *models.ListOutputDeliveriesInput) (models.ListOutputDeliveriesOutput, error) {
+	zap.L().Info("listing output deliveries", zap.Any("input", input))
+
+	deliveries, err := alertsDB.ListOutputDeliveries(input.OutputID, *input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = make(models.ListOutputDeliveriesOutput, 0)
+	}
+	return deliveries, nil
+}
+
+// AddAlertDeliveries stores the attempts of the alert delivery to send alerts to their outputs
+func (API) AddAlertDeliveries(input *models.AddAlertDeliveriesInput) error {
+	zap.L().Info("adding alert deliveries", zap.Int("deliveries", len(input.Deliveries)))
+	return alertsDB.AddDeliveries(input.Deliveries)
+}
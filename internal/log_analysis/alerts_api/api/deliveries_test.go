@@ -0,0 +1,110 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestGetAlertDeliveries(t *testing.T) {
+	tableMock := &mockTable{}
+	alertsDB = tableMock
+
+	deliveries := []*models.AlertDelivery{
+		{
+			AlertID:    aws.String("alert-id"),
+			DeliveryID: aws.String("delivery-id"),
+			OutputID:   aws.String("output-id"),
+			Status:     aws.String(models.DeliveryStatusSuccess),
+			Attempt:    aws.Int(1),
+			Timestamp:  aws.Time(time.Now().UTC()),
+		},
+	}
+	tableMock.On("GetAlert", aws.String("alert-id")).Return(&models.AlertItem{AlertID: aws.String("alert-id")}, nil).Once()
+	tableMock.On("ListDeliveries", aws.String("alert-id")).Return(deliveries, nil).Once()
+
+	result, err := API{}.GetAlertDeliveries(&models.GetAlertDeliveriesInput{AlertID: aws.String("alert-id")})
+	require.NoError(t, err)
+	assert.Equal(t, deliveries, result)
+	tableMock.AssertExpectations(t)
+}
+
+func TestGetAlertDeliveriesEmpty(t *testing.T) {
+	tableMock := &mockTable{}
+	alertsDB = tableMock
+
+	tableMock.On("GetAlert", aws.String("alert-id")).Return(&models.AlertItem{AlertID: aws.String("alert-id")}, nil).Once()
+	tableMock.On("ListDeliveries", aws.String("alert-id")).Return([]*models.AlertDelivery(nil), nil).Once()
+
+	result, err := API{}.GetAlertDeliveries(&models.GetAlertDeliveriesInput{AlertID: aws.String("alert-id")})
+	require.NoError(t, err)
+	assert.Equal(t, models.GetAlertDeliveriesOutput{}, result)
+	tableMock.AssertExpectations(t)
+}
+
+func TestGetAlertDeliveriesDoesNotExist(t *testing.T) {
+	tableMock := &mockTable{}
+	alertsDB = tableMock
+
+	tableMock.On("GetAlert", aws.String("alert-id")).Return(&models.AlertItem{}, nil).Once()
+
+	result, err := API{}.GetAlertDeliveries(&models.GetAlertDeliveriesInput{AlertID: aws.String("alert-id")})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	tableMock.AssertExpectations(t)
+}
+
+func TestAddAlertDeliveries(t *testing.T) {
+	tableMock := &mockTable{}
+	alertsDB = tableMock
+
+	deliveries := []*models.AlertDelivery{
+		{
+			AlertID:   aws.String("alert-id"),
+			OutputID:  aws.String("output-id"),
+			Status:    aws.String(models.DeliveryStatusSuccess),
+			Attempt:   aws.Int(1),
+			Timestamp: aws.Time(time.Now().UTC()),
+		},
+	}
+	tableMock.On("AddDeliveries", deliveries).Return(nil).Once()
+
+	require.NoError(t, API{}.AddAlertDeliveries(&models.AddAlertDeliveriesInput{Deliveries: deliveries}))
+	tableMock.AssertExpectations(t)
+}
+
+func TestListOutputDeliveries(t *testing.T) {
+	tableMock := &mockTable{}
+	alertsDB = tableMock
+
+	tableMock.On("ListOutputDeliveries", aws.String("output-id"), 3).Return([]*models.AlertDelivery(nil), nil).Once()
+
+	result, err := API{}.ListOutputDeliveries(&models.ListOutputDeliveriesInput{OutputID: aws.String("output-id"), Limit: aws.Int(3)})
+	require.NoError(t, err)
+	assert.Equal(t, models.ListOutputDeliveriesOutput{}, result)
+	tableMock.AssertExpectations(t)
+}
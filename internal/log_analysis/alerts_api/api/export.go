@@ -0,0 +1,357 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// exportKeyPrefix is the prefix of the exports in the alert events bucket
+	exportKeyPrefix = "exports/"
+	// exportRetention is how long export jobs are kept, the bucket expires the exports after the same period
+	exportRetention = 7 * 24 * time.Hour
+	// exportURLExpiration is the validity of the presigned download URLs
+	exportURLExpiration = time.Hour
+	// maxExportRange is the longest time range of an export
+	maxExportRange = 90 * 24 * time.Hour
+	// exportTimeout is after how long an unfinished job is failed, the exporter Lambda times out after 15 minutes
+	exportTimeout  = 20 * time.Minute
+	exportPageSize = 50
+	// exportReadConcurrency is the number of alerts whose events are read in parallel
+	exportReadConcurrency = 5
+)
+
+// ExportAlerts creates an export job and runs it asynchronously
+func (API) ExportAlerts(input *models.ExportAlertsInput) (*models.ExportAlertsOutput, error) {
+	zap.L().Info("exporting alerts", zap.Any("input", input))
+
+	if !input.CreatedAtAfter.Before(*input.CreatedAtBefore) {
+		return nil, &genericapi.InvalidInputError{Message: "createdAtAfter must be before createdAtBefore"}
+	}
+	if input.CreatedAtBefore.Sub(*input.CreatedAtAfter) > maxExportRange {
+		return nil, &genericapi.InvalidInputError{
+			Message: fmt.Sprintf("the time range of an export can't exceed %d days", maxExportRange/(24*time.Hour))}
+	}
+
+	format := models.ExportFormatJSON
+	if input.Format != nil {
+		format = *input.Format
+	}
+	jobID := uuid.New().String()
+	now := time.Now().UTC()
+	job := &models.ExportJob{
+		JobID:           aws.String(jobID),
+		Status:          aws.String(models.ExportStatusPending),
+		Format:          aws.String(format),
+		CreatedAtAfter:  input.CreatedAtAfter,
+		CreatedAtBefore: input.CreatedAtBefore,
+		RuleID:          input.RuleID,
+		Severity:        input.Severity,
+		CreatedBy:       input.UserID,
+		CreatedAt:       aws.Time(now),
+		ObjectKey:       aws.String(exportKeyPrefix + jobID + exportExtension(format)),
+		ExpiresAt:       now.Add(exportRetention).Unix(),
+	}
+	if err := alertsDB.PutExportJob(job); err != nil {
+		return nil, err
+	}
+
+	if err := startExport(job.JobID); err != nil {
+		job.Status = aws.String(models.ExportStatusFailed)
+		job.Error = aws.String("failed to start the export")
+		job.CompletedAt = aws.Time(time.Now().UTC())
+		if putErr := alertsDB.PutExportJob(job); putErr != nil {
+			zap.L().Error("failed to update export job", zap.String("jobId", jobID), zap.Error(putErr))
+		}
+		return nil, err
+	}
+	return exportJobOutput(job), nil
+}
+
+// startExport invokes the exporter asynchronously to run an export job
+func startExport(jobID *string) error {
+	payload, err := jsoniter.Marshal(&models.LambdaInput{RunAlertsExport: &models.RunAlertsExportInput{JobID: jobID}})
+	if err != nil {
+		return &genericapi.InternalError{Message: "jsoniter.Marshal(input) failed: " + err.Error()}
+	}
+
+	if _, err = lambdaClient.Invoke(&lambda.InvokeInput{
+		FunctionName:   aws.String(env.ExportFunctionName),
+		InvocationType: aws.String(lambda.InvocationTypeEvent),
+		Payload:        payload,
+	}); err != nil {
+		return &genericapi.AWSError{Method: "lambda.Invoke", Err: err}
+	}
+	return nil
+}
+
+// GetAlertsExport returns an export job, with a download URL once it succeeded
+func (API) GetAlertsExport(input *models.GetAlertsExportInput) (*models.GetAlertsExportOutput, error) {
+	zap.L().Info("getting alerts export", zap.Any("input", input))
+
+	job, err := alertsDB.GetExportJob(input.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "jobId=" + *input.JobID}
+	}
+
+	if exportTimedOut(job) {
+		zap.L().Warn("export job timed out", zap.String("jobId", *job.JobID))
+		job.Status = aws.String(models.ExportStatusFailed)
+		job.Error = aws.String("the export did not complete in time")
+		job.CompletedAt = aws.Time(time.Now().UTC())
+		if err = alertsDB.PutExportJob(job); err != nil {
+			return nil, err
+		}
+	}
+
+	if aws.StringValue(job.Status) == models.ExportStatusSucceeded {
+		filename := "alerts-" + *job.JobID + exportExtension(*job.Format)
+		request, _ := s3Client.GetObjectRequest(&s3.GetObjectInput{
+			Bucket:                     aws.String(env.AlertEventsBucket),
+			Key:                        job.ObjectKey,
+			ResponseContentDisposition: aws.String(fmt.Sprintf(`attachment; filename="%s"`, filename)),
+		})
+		url, err := request.Presign(exportURLExpiration)
+		if err != nil {
+			return nil, &genericapi.AWSError{Method: "s3.GetObjectRequest.Presign", Err: err}
+		}
+		job.URL = aws.String(url)
+	}
+	return exportJobOutput(job), nil
+}
+
+// exportTimedOut returns true if a job is still pending or running after the exporter timed out
+func exportTimedOut(job *models.ExportJob) bool {
+	switch aws.StringValue(job.Status) {
+	case models.ExportStatusPending, models.ExportStatusRunning:
+		return job.CreatedAt != nil && time.Since(*job.CreatedAt) > exportTimeout
+	default:
+		return false
+	}
+}
+
+// RunAlertsExport writes the alerts of an export job to S3
+//
+// Failures are recorded in the job rather than returned, the asynchronous invocation is not retried.
+func (API) RunAlertsExport(input *models.RunAlertsExportInput) (*models.ExportJob, error) {
+	zap.L().Info("running alerts export", zap.Any("input", input))
+
+	job, err := alertsDB.GetExportJob(input.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "jobId=" + *input.JobID}
+	}
+	switch aws.StringValue(job.Status) {
+	case models.ExportStatusSucceeded, models.ExportStatusFailed:
+		zap.L().Warn("export job already completed", zap.String("jobId", *job.JobID))
+		return exportJobOutput(job), nil
+	case models.ExportStatusRunning:
+		zap.L().Warn("export job already running", zap.String("jobId", *job.JobID))
+		return exportJobOutput(job), nil
+	}
+
+	job.Status = aws.String(models.ExportStatusRunning)
+	if err = alertsDB.PutExportJob(job); err != nil {
+		return nil, err
+	}
+
+	if err = exportAlerts(job); err != nil {
+		zap.L().Error("alerts export failed", zap.String("jobId", *job.JobID), zap.Error(err))
+		job.Status = aws.String(models.ExportStatusFailed)
+		job.Error = aws.String(err.Error())
+	} else {
+		job.Status = aws.String(models.ExportStatusSucceeded)
+	}
+	job.CompletedAt = aws.Time(time.Now().UTC())
+	if err = alertsDB.PutExportJob(job); err != nil {
+		return nil, err
+	}
+	return exportJobOutput(job), nil
+}
+
+// exportAlerts streams the alerts of a job to its S3 object
+func exportAlerts(job *models.ExportJob) error {
+	reader, writer := io.Pipe()
+	result := make(chan error, 1)
+	go func() {
+		err := writeAlerts(job, writer)
+		writer.CloseWithError(err)
+		result <- err
+	}()
+
+	contentType := "application/x-ndjson"
+	if *job.Format == models.ExportFormatCSV {
+		contentType = "text/csv"
+	}
+	_, uploadErr := s3Uploader.Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(env.AlertEventsBucket),
+		Key:         job.ObjectKey,
+		Body:        reader,
+		ContentType: aws.String(contentType),
+	})
+	// Unblock the writer if the upload stopped reading
+	reader.CloseWithError(uploadErr)
+
+	writeErr := <-result
+	if uploadErr != nil {
+		return &genericapi.AWSError{Method: "s3manager.Upload", Err: uploadErr}
+	}
+	return writeErr
+}
+
+// writeAlerts writes the alerts matching the filters of a job, with all of their events
+//
+// The number of alerts and events written are set in the job.
+func writeAlerts(job *models.ExportJob, w io.Writer) error {
+	writer := newAlertWriter(*job.Format, w)
+	listInput := &models.ListAlertsInput{
+		RuleID:          job.RuleID,
+		Severity:        job.Severity,
+		CreatedAtAfter:  job.CreatedAtAfter,
+		CreatedAtBefore: job.CreatedAtBefore,
+		SortDir:         aws.String("ascending"),
+		PageSize:        aws.Int(exportPageSize),
+	}
+
+	alertCount, eventCount := 0, 0
+	for {
+		items, lastEvaluatedKey, err := alertsDB.ListAlerts(listInput)
+		if err != nil {
+			return err
+		}
+		summaries, err := alertItemsToAlertSummary(items)
+		if err != nil {
+			return err
+		}
+
+		// The events of a few alerts at a time are kept in memory
+		for start := 0; start < len(items); start += exportReadConcurrency {
+			end := start + exportReadConcurrency
+			if end > len(items) {
+				end = len(items)
+			}
+			events, err := readAlertEvents(items[start:end])
+			if err != nil {
+				return err
+			}
+			for i, summary := range summaries[start:end] {
+				if err = writer.Write(summary, events[i]); err != nil {
+					return err
+				}
+				alertCount++
+				eventCount += len(events[i])
+			}
+		}
+
+		if lastEvaluatedKey == nil {
+			break
+		}
+		listInput.ExclusiveStartKey = lastEvaluatedKey
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	job.AlertCount = aws.Int(alertCount)
+	job.EventCount = aws.Int(eventCount)
+	return nil
+}
+
+// readAlertEvents returns the events of each alert, reading the alerts in parallel
+func readAlertEvents(items []*models.AlertItem) ([][]*string, error) {
+	events := make([][]*string, len(items))
+	errs := make([]error, len(items))
+	indexes := make(chan int, len(items))
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	for w := 0; w < exportReadConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				events[i], errs[i] = alertEvents(items[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// alertEvents returns all the stored events of an alert
+func alertEvents(item *models.AlertItem) ([]*string, error) {
+	// Alerts created before events were stored in S3 reference their events by hash
+	if len(item.EventHashes) == 0 {
+		events, _, err := alertsDB.ListEvents(item.AlertID, nil, nil)
+		return events, err
+	}
+
+	events := make([]*string, 0, len(item.EventHashes))
+	for _, hash := range item.EventHashes {
+		event, err := alertsDB.GetEvent(hash)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// exportJobOutput hides the internal fields of a job
+func exportJobOutput(job *models.ExportJob) *models.ExportJob {
+	job.ObjectKey = nil
+	job.ExpiresAt = 0
+	return job
+}
+
+func exportExtension(format string) string {
+	if format == models.ExportFormatCSV {
+		return ".csv"
+	}
+	return ".jsonl"
+}
@@ -0,0 +1,308 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockLambda struct {
+	lambdaiface.LambdaAPI
+	mock.Mock
+}
+
+func (m *mockLambda) Invoke(input *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*lambda.InvokeOutput), args.Error(1)
+}
+
+type mockUploader struct {
+	s3manageriface.UploaderAPI
+	mock.Mock
+	body string
+}
+
+func (m *mockUploader) Upload(
+	input *s3manager.UploadInput, options ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+
+	body, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.body = string(body)
+	args := m.Called(input)
+	return args.Get(0).(*s3manager.UploadOutput), args.Error(1)
+}
+
+func exportJob(format string) *models.ExportJob {
+	return &models.ExportJob{
+		JobID:           aws.String("job-id"),
+		Status:          aws.String(models.ExportStatusPending),
+		Format:          aws.String(format),
+		CreatedAtAfter:  aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		CreatedAtBefore: aws.Time(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)),
+		ObjectKey:       aws.String("exports/job-id" + exportExtension(format)),
+	}
+}
+
+func exportAlertItem(alertID string) *models.AlertItem {
+	return &models.AlertItem{
+		AlertID:      aws.String(alertID),
+		RuleID:       aws.String("rule-id"),
+		Title:        aws.String("title"),
+		Severity:     aws.String("HIGH"),
+		CreationTime: aws.Time(time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)),
+		EventCount:   aws.Int(1),
+	}
+}
+
+func TestExportAlerts(t *testing.T) {
+	mockDB, mockClient := &mockTable{}, &mockLambda{}
+	alertsDB, lambdaClient = mockDB, mockClient
+	env.ExportFunctionName = "panther-alerts-exporter"
+
+	mockDB.On("PutExportJob", mock.Anything).Return(nil).Once()
+	mockClient.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{}, nil).Once()
+
+	result, err := API{}.ExportAlerts(&models.ExportAlertsInput{
+		CreatedAtAfter:  aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		CreatedAtBefore: aws.Time(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)),
+		Format:          aws.String(models.ExportFormatCSV),
+		UserID:          aws.String("user-id"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusPending, *result.Status)
+	assert.Nil(t, result.ObjectKey)
+
+	stored := mockDB.Calls[0].Arguments[0].(*models.ExportJob)
+	assert.Equal(t, "exports/"+*result.JobID+".csv", *stored.ObjectKey)
+	assert.NotZero(t, stored.ExpiresAt)
+
+	invoke := mockClient.Calls[0].Arguments[0].(*lambda.InvokeInput)
+	assert.Equal(t, "panther-alerts-exporter", *invoke.FunctionName)
+	assert.Equal(t, lambda.InvocationTypeEvent, *invoke.InvocationType)
+	var payload models.LambdaInput
+	require.NoError(t, jsoniter.Unmarshal(invoke.Payload, &payload))
+	assert.Equal(t, &models.RunAlertsExportInput{JobID: result.JobID}, payload.RunAlertsExport)
+	mockDB.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestExportAlertsInvalidRange(t *testing.T) {
+	_, err := API{}.ExportAlerts(&models.ExportAlertsInput{
+		CreatedAtAfter:  aws.Time(time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)),
+		CreatedAtBefore: aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		UserID:          aws.String("user-id"),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestExportAlertsRangeTooLong(t *testing.T) {
+	_, err := API{}.ExportAlerts(&models.ExportAlertsInput{
+		CreatedAtAfter:  aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		CreatedAtBefore: aws.Time(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)),
+		UserID:          aws.String("user-id"),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestRunAlertsExport(t *testing.T) {
+	mockDB, uploader := &mockTable{}, &mockUploader{}
+	alertsDB, s3Uploader = mockDB, uploader
+	env.AlertEventsBucket = "bucket"
+
+	mockDB.On("GetExportJob", aws.String("job-id")).Return(exportJob(models.ExportFormatJSON), nil).Once()
+	mockDB.On("PutExportJob", mock.Anything).Return(nil).Twice()
+	mockDB.On("ListAlerts", mock.Anything).Return(
+		[]*models.AlertItem{exportAlertItem("alert-1")}, aws.String("alert-1"), nil).Once()
+	mockDB.On("ListAlerts", mock.Anything).Return(
+		[]*models.AlertItem{exportAlertItem("alert-2")}, (*string)(nil), nil).Once()
+	mockDB.On("ListEvents", aws.String("alert-1"), (*string)(nil), (*int)(nil)).Return(
+		aws.StringSlice([]string{`{"a":1}`, `{"a":2}`}), (*string)(nil), nil).Once()
+	mockDB.On("ListEvents", aws.String("alert-2"), (*string)(nil), (*int)(nil)).Return(
+		aws.StringSlice([]string{`{"b":1}`}), (*string)(nil), nil).Once()
+	uploader.On("Upload", mock.Anything).Return(&s3manager.UploadOutput{}, nil).Once()
+
+	result, err := API{}.RunAlertsExport(&models.RunAlertsExportInput{JobID: aws.String("job-id")})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusSucceeded, *result.Status)
+	assert.Equal(t, 2, *result.AlertCount)
+	assert.Equal(t, 3, *result.EventCount)
+	assert.NotNil(t, result.CompletedAt)
+
+	assert.Equal(t, models.ExportStatusRunning, *mockDB.Calls[1].Arguments[0].(*models.ExportJob).Status)
+	listInput := mockDB.Calls[2].Arguments[0].(*models.ListAlertsInput)
+	assert.Equal(t, "ascending", *listInput.SortDir)
+	assert.Equal(t, "alert-1", *listInput.ExclusiveStartKey)
+
+	upload := uploader.Calls[0].Arguments[0].(*s3manager.UploadInput)
+	assert.Equal(t, "bucket", *upload.Bucket)
+	assert.Equal(t, "exports/job-id.jsonl", *upload.Key)
+	lines := strings.Split(strings.TrimSpace(uploader.body), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"alertId":"alert-1"`)
+	assert.Contains(t, lines[0], `"events":[{"a":1},{"a":2}]`)
+	assert.Contains(t, lines[1], `"events":[{"b":1}]`)
+	mockDB.AssertExpectations(t)
+	uploader.AssertExpectations(t)
+}
+
+func TestRunAlertsExportFailure(t *testing.T) {
+	mockDB, uploader := &mockTable{}, &mockUploader{}
+	alertsDB, s3Uploader = mockDB, uploader
+
+	mockDB.On("GetExportJob", aws.String("job-id")).Return(exportJob(models.ExportFormatCSV), nil).Once()
+	mockDB.On("PutExportJob", mock.Anything).Return(nil).Twice()
+	mockDB.On("ListAlerts", mock.Anything).Return(
+		[]*models.AlertItem{}, (*string)(nil), awserr.New("AccessDenied", "", nil)).Once()
+	uploader.On("Upload", mock.Anything).Return(&s3manager.UploadOutput{}, nil).Once()
+
+	result, err := API{}.RunAlertsExport(&models.RunAlertsExportInput{JobID: aws.String("job-id")})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusFailed, *result.Status)
+	assert.Contains(t, *result.Error, "AccessDenied")
+	mockDB.AssertExpectations(t)
+}
+
+func TestRunAlertsExportCompleted(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	job := exportJob(models.ExportFormatCSV)
+	job.Status = aws.String(models.ExportStatusSucceeded)
+	mockDB.On("GetExportJob", aws.String("job-id")).Return(job, nil).Once()
+
+	result, err := API{}.RunAlertsExport(&models.RunAlertsExportInput{JobID: aws.String("job-id")})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusSucceeded, *result.Status)
+	mockDB.AssertExpectations(t)
+}
+
+func TestRunAlertsExportRunning(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	job := exportJob(models.ExportFormatCSV)
+	job.Status = aws.String(models.ExportStatusRunning)
+	mockDB.On("GetExportJob", aws.String("job-id")).Return(job, nil).Once()
+
+	result, err := API{}.RunAlertsExport(&models.RunAlertsExportInput{JobID: aws.String("job-id")})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusRunning, *result.Status)
+	mockDB.AssertExpectations(t)
+}
+
+func TestRunAlertsExportReadError(t *testing.T) {
+	mockDB, uploader := &mockTable{}, &mockUploader{}
+	alertsDB, s3Uploader = mockDB, uploader
+
+	mockDB.On("GetExportJob", aws.String("job-id")).Return(exportJob(models.ExportFormatJSON), nil).Once()
+	mockDB.On("PutExportJob", mock.Anything).Return(nil).Twice()
+	mockDB.On("ListAlerts", mock.Anything).Return(
+		[]*models.AlertItem{exportAlertItem("alert-1"), exportAlertItem("alert-2")}, (*string)(nil), nil).Once()
+	mockDB.On("ListEvents", aws.String("alert-1"), (*string)(nil), (*int)(nil)).Return(
+		aws.StringSlice([]string{`{"a":1}`}), (*string)(nil), nil).Once()
+	mockDB.On("ListEvents", aws.String("alert-2"), (*string)(nil), (*int)(nil)).Return(
+		[]*string{}, (*string)(nil), awserr.New("AccessDenied", "", nil)).Once()
+	uploader.On("Upload", mock.Anything).Return(&s3manager.UploadOutput{}, nil).Once()
+
+	result, err := API{}.RunAlertsExport(&models.RunAlertsExportInput{JobID: aws.String("job-id")})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusFailed, *result.Status)
+	assert.Contains(t, *result.Error, "AccessDenied")
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetAlertsExportTimedOut(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	job := exportJob(models.ExportFormatCSV)
+	job.Status = aws.String(models.ExportStatusRunning)
+	job.CreatedAt = aws.Time(time.Now().Add(-time.Hour))
+	mockDB.On("GetExportJob", aws.String("job-id")).Return(job, nil).Once()
+	mockDB.On("PutExportJob", mock.Anything).Return(nil).Once()
+
+	result, err := API{}.GetAlertsExport(&models.GetAlertsExportInput{JobID: aws.String("job-id")})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusFailed, *result.Status)
+	assert.NotNil(t, result.CompletedAt)
+	assert.Nil(t, result.URL)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetAlertsExportRunning(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	job := exportJob(models.ExportFormatCSV)
+	job.Status = aws.String(models.ExportStatusRunning)
+	job.CreatedAt = aws.Time(time.Now().Add(-time.Minute))
+	mockDB.On("GetExportJob", aws.String("job-id")).Return(job, nil).Once()
+
+	result, err := API{}.GetAlertsExport(&models.GetAlertsExportInput{JobID: aws.String("job-id")})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusRunning, *result.Status)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetAlertsExportDoesNotExist(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	mockDB.On("GetExportJob", aws.String("job-id")).Return((*models.ExportJob)(nil), nil).Once()
+
+	_, err := API{}.GetAlertsExport(&models.GetAlertsExportInput{JobID: aws.String("job-id")})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
+
+func TestGetAlertsExportSucceeded(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	s3Client = s3.New(session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	})))
+	env.AlertEventsBucket = "bucket"
+	job := exportJob(models.ExportFormatCSV)
+	job.Status = aws.String(models.ExportStatusSucceeded)
+	mockDB.On("GetExportJob", aws.String("job-id")).Return(job, nil).Once()
+
+	result, err := API{}.GetAlertsExport(&models.GetAlertsExportInput{JobID: aws.String("job-id")})
+	require.NoError(t, err)
+	require.NotNil(t, result.URL)
+	assert.Contains(t, *result.URL, "exports/job-id.csv")
+	assert.Contains(t, *result.URL, "X-Amz-Signature=")
+	assert.Nil(t, result.ObjectKey)
+}
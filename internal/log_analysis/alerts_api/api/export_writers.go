@@ -0,0 +1,156 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// alertWriter writes the alerts of an export
+type alertWriter interface {
+	Write(summary *models.AlertSummary, events []*string) error
+	Flush() error
+}
+
+func newAlertWriter(format string, w io.Writer) alertWriter {
+	if format == models.ExportFormatCSV {
+		return &csvAlertWriter{writer: csv.NewWriter(w)}
+	}
+	return &jsonAlertWriter{writer: w}
+}
+
+// jsonAlertWriter writes a JSON object per line, each alert with all of its events
+type jsonAlertWriter struct {
+	writer io.Writer
+}
+
+type exportedAlert struct {
+	*models.AlertSummary
+	Events []jsoniter.RawMessage `json:"events"`
+}
+
+func (w *jsonAlertWriter) Write(summary *models.AlertSummary, events []*string) error {
+	record := exportedAlert{AlertSummary: summary, Events: make([]jsoniter.RawMessage, len(events))}
+	for i, event := range events {
+		record.Events[i] = jsoniter.RawMessage(*event)
+	}
+
+	line, err := jsoniter.Marshal(&record)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal alert: " + err.Error()}
+	}
+	_, err = w.writer.Write(append(line, '\n'))
+	return err
+}
+
+func (w *jsonAlertWriter) Flush() error {
+	return nil
+}
+
+// csvAlertWriter writes a row per event, the columns of the alert are repeated in each row
+//
+// Alerts without stored events have a single row with an empty event.
+type csvAlertWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+var csvHeader = []string{
+	"alertId", "ruleId", "ruleDisplayName", "title", "severity", "status", "logTypes", "tags",
+	"creationTime", "lastEventMatched", "eventsMatched", "assigneeId", "event",
+}
+
+func (w *csvAlertWriter) Write(summary *models.AlertSummary, events []*string) error {
+	if !w.headerWritten {
+		if err := w.writer.Write(csvHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	eventsMatched := ""
+	if summary.EventsMatched != nil {
+		eventsMatched = strconv.Itoa(*summary.EventsMatched)
+	}
+	row := []string{
+		aws.StringValue(summary.AlertID),
+		aws.StringValue(summary.RuleID),
+		aws.StringValue(summary.RuleDisplayName),
+		aws.StringValue(summary.Title),
+		aws.StringValue(summary.Severity),
+		aws.StringValue(summary.Status),
+		strings.Join(aws.StringValueSlice(summary.LogTypes), ";"),
+		strings.Join(aws.StringValueSlice(summary.Tags), ";"),
+		csvTime(summary.CreationTime),
+		csvTime(summary.LastEventMatched),
+		eventsMatched,
+		aws.StringValue(summary.AssigneeID),
+		"",
+	}
+
+	for i := range row {
+		row[i] = csvCell(row[i])
+	}
+
+	if len(events) == 0 {
+		return w.writer.Write(row)
+	}
+	for _, event := range events {
+		row[len(row)-1] = csvCell(*event)
+		if err := w.writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *csvAlertWriter) Flush() error {
+	if !w.headerWritten {
+		if err := w.writer.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// csvCell escapes the values spreadsheets would evaluate as formulas
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,83 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+)
+
+func TestCSVAlertWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := newAlertWriter(models.ExportFormatCSV, &buffer)
+	summary := &models.AlertSummary{
+		AlertID:       aws.String("alert-id"),
+		RuleID:        aws.String("rule-id"),
+		Title:         aws.String("title"),
+		Severity:      aws.String("HIGH"),
+		Status:        aws.String(models.StatusOpen),
+		LogTypes:      aws.StringSlice([]string{"AWS.CloudTrail", "AWS.S3ServerAccess"}),
+		CreationTime:  aws.Time(time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)),
+		EventsMatched: aws.Int(2),
+	}
+
+	require.NoError(t, writer.Write(summary, aws.StringSlice([]string{`{"a":1}`, `{"a":2}`})))
+	require.NoError(t, writer.Write(summary, nil))
+	require.NoError(t, writer.Flush())
+
+	expected := "alertId,ruleId,ruleDisplayName,title,severity,status,logTypes,tags," +
+		"creationTime,lastEventMatched,eventsMatched,assigneeId,event\n" +
+		`alert-id,rule-id,,title,HIGH,OPEN,AWS.CloudTrail;AWS.S3ServerAccess,,2020-02-01T00:00:00Z,,2,,"{""a"":1}"` + "\n" +
+		`alert-id,rule-id,,title,HIGH,OPEN,AWS.CloudTrail;AWS.S3ServerAccess,,2020-02-01T00:00:00Z,,2,,"{""a"":2}"` + "\n" +
+		"alert-id,rule-id,,title,HIGH,OPEN,AWS.CloudTrail;AWS.S3ServerAccess,,2020-02-01T00:00:00Z,,2,,\n"
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestCSVAlertWriterNoAlerts(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, newAlertWriter(models.ExportFormatCSV, &buffer).Flush())
+	assert.Equal(t, "alertId,ruleId,ruleDisplayName,title,severity,status,logTypes,tags,"+
+		"creationTime,lastEventMatched,eventsMatched,assigneeId,event\n", buffer.String())
+}
+
+func TestCSVAlertWriterEscapesFormulas(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := newAlertWriter(models.ExportFormatCSV, &buffer)
+	summary := &models.AlertSummary{
+		AlertID: aws.String("alert-id"),
+		RuleID:  aws.String("+rule"),
+		Title:   aws.String(`=HYPERLINK("http://example.com")`),
+		Tags:    aws.StringSlice([]string{"@tag", "other"}),
+	}
+
+	require.NoError(t, writer.Write(summary, aws.StringSlice([]string{"-1"})))
+	require.NoError(t, writer.Flush())
+
+	expected := "alertId,ruleId,ruleDisplayName,title,severity,status,logTypes,tags," +
+		"creationTime,lastEventMatched,eventsMatched,assigneeId,event\n" +
+		`alert-id,'+rule,,"'=HYPERLINK(""http://example.com"")",,,,'@tag;other,,,,,'-1` + "\n"
+	assert.Equal(t, expected, buffer.String())
+}
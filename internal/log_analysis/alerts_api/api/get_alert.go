@@ -37,13 +37,47 @@ func (API) GetAlert(input *models.GetAlertInput) (result *models.GetAlertOutput,
 	if err != nil {
 		return nil, err
 	}
+	if alertItem.AlertID != nil {
+		if alertItem.History, err = alertsDB.GetAlertHistory(alertItem); err != nil {
+			return nil, err
+		}
+	}
 
 	result = &models.Alert{
-		AlertID:          alertItem.AlertID,
-		RuleID:           alertItem.RuleID,
-		CreationTime:     alertItem.CreationTime,
-		LastEventMatched: alertItem.LastEventMatched,
-		MatchedEventNum:  aws.Int(len(alertItem.EventHashes)),
+		AlertID:            alertItem.AlertID,
+		RuleID:             alertItem.RuleID,
+		Dedup:              alertItem.Dedup,
+		CorrelatedAlertIDs: alertItem.CorrelatedAlertIDs,
+		CreationTime:       alertItem.CreationTime,
+		LastEventMatched:   alertItem.LastEventMatched,
+		MatchedEventNum:    eventsMatched(alertItem),
+		EventLimit:         alertItem.EventLimit,
+		EventsTruncated:    aws.Bool(eventsTruncated(alertItem)),
+		Status:             alertStatus(alertItem),
+		AssigneeID:         alertItem.AssigneeID,
+		Resolution:         alertItem.Resolution,
+		LastUpdatedBy:      alertItem.LastUpdatedBy,
+		LastUpdatedTime:    alertItem.LastUpdatedTime,
+		History:            alertItem.History,
+		Title:              alertItem.Title,
+		Context:            alertItem.Context,
+	}
+
+	if alertItem.AlertID != nil {
+		if result.Deliveries, err = alertsDB.ListDeliveries(alertItem.AlertID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Alerts created before events were stored in S3 reference their events by hash
+	if len(alertItem.EventHashes) == 0 {
+		result.Events, result.EventsLastEvaluatedKey, err = alertsDB.ListEvents(
+			input.AlertID, input.EventsExclusiveStartKey, input.EventsPageSize)
+		if err != nil {
+			return nil, err
+		}
+		gatewayapi.ReplaceMapSliceNils(result)
+		return result, nil
 	}
 
 	var eventHashesToReturn [][]byte
@@ -101,3 +135,16 @@ func (API) GetAlert(input *models.GetAlertInput) (result *models.GetAlertOutput,
 	gatewayapi.ReplaceMapSliceNils(result)
 	return result, nil
 }
+
+// eventsMatched returns the number of events matched by an alert
+func eventsMatched(item *models.AlertItem) *int {
+	if item.EventCount != nil {
+		return item.EventCount
+	}
+	return aws.Int(len(item.EventHashes))
+}
+
+// eventsTruncated returns whether some of the events matched by an alert were not stored
+func eventsTruncated(item *models.AlertItem) bool {
+	return item.EventCount != nil && item.EventLimit != nil && *item.EventCount > *item.EventLimit
+}
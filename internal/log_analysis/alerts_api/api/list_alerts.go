@@ -23,24 +23,17 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/gateway/analysis/client/operations"
+	analysismodels "github.com/panther-labs/panther/api/gateway/analysis/models"
 	"github.com/panther-labs/panther/api/lambda/alerts/models"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
-// ListAlerts retrieves alert and event details.
+// ListAlerts searches the alerts.
 func (API) ListAlerts(input *models.ListAlertsInput) (result *models.ListAlertsOutput, err error) {
 	zap.L().Info("listing alerts", zap.Any("input", input))
 
 	result = &models.ListAlertsOutput{}
-	var alertItems []*models.AlertItem
-	if input.RuleID != nil {
-		zap.L().Info("fetching alert summaries for rule",
-			zap.String("ruleId", *input.RuleID))
-		alertItems, result.LastEvaluatedKey, err = alertsDB.ListAlertsByRule(input.RuleID, input.ExclusiveStartKey, input.PageSize)
-	} else {
-		zap.L().Info("fetching all alert summaries")
-		alertItems, result.LastEvaluatedKey, err = alertsDB.ListAlerts(input.ExclusiveStartKey, input.PageSize)
-	}
+	alertItems, lastEvaluatedKey, err := alertsDB.ListAlerts(input)
 	if err != nil {
 		return nil, err
 	}
@@ -49,60 +42,94 @@ func (API) ListAlerts(input *models.ListAlertsInput) (result *models.ListAlertsO
 	if err != nil {
 		return nil, err
 	}
+	result.LastEvaluatedKey = lastEvaluatedKey
 
 	gatewayapi.ReplaceMapSliceNils(result)
 	return result, nil
 }
 
 // alertItemsToAlertSummary converts a DDB Alert Item to an Alert Summary that will be returned by the API
+//
+// The rule information is stored in the alert when it is created. For older alerts, it is retrieved
+// from the analysis api instead.
 func alertItemsToAlertSummary(items []*models.AlertItem) ([]*models.AlertSummary, error) {
 	result := make([]*models.AlertSummary, len(items))
 
 	// Many of the alerts returned might be triggered from the same rule
-	// We are going to use this map in order to get the unique ruleIds
-	ruleIDToSeverity := make(map[string]*string)
+	// We are going to use this map in order to get each rule only once
+	rules := make(map[string]*analysismodels.Rule)
 
 	for i, item := range items {
-		ruleIDToSeverity[*item.RuleID] = nil
-		result[i] = &models.AlertSummary{
+		summary := &models.AlertSummary{
 			AlertID:          item.AlertID,
 			RuleID:           item.RuleID,
+			RuleDisplayName:  item.RuleDisplayName,
+			Title:            item.Title,
+			LogTypes:         item.LogTypes,
+			Tags:             item.Tags,
+			Dedup:            item.Dedup,
 			CreationTime:     item.CreationTime,
 			LastEventMatched: item.LastEventMatched,
-			EventsMatched:    aws.Int(len(item.EventHashes)),
+			EventsMatched:    eventsMatched(item),
+			Severity:         item.Severity,
+			Status:           alertStatus(item),
+			AssigneeID:       item.AssigneeID,
+			Resolution:       item.Resolution,
+			LastUpdatedBy:    item.LastUpdatedBy,
+			LastUpdatedTime:  item.LastUpdatedTime,
 		}
-	}
 
-	// Get the severity of each rule ID
-	for ruleID := range ruleIDToSeverity {
-		// All items are for the same org
-		severity, err := getSeverity(aws.String(ruleID))
-		if err != nil {
-			return nil, err
+		if summary.Severity == nil {
+			rule, ok := rules[*item.RuleID]
+			if !ok {
+				var err error
+				if rule, err = getRule(item.RuleID); err != nil {
+					return nil, err
+				}
+				rules[*item.RuleID] = rule
+			}
+			setRuleInfo(summary, rule)
 		}
-		ruleIDToSeverity[ruleID] = severity
-	}
 
-	// Set the correct severity
-	for _, summary := range result {
-		summary.Severity = ruleIDToSeverity[*summary.RuleID]
+		if summary.Title == nil {
+			summary.Title = summary.RuleID
+		}
+		result[i] = summary
 	}
 	return result, nil
 }
 
-// getSeverity retrieves the rule severity associated with an alert
-func getSeverity(ruleID *string) (*string, error) {
-	zap.L().Debug("fetching severity of rule",
+// setRuleInfo sets the rule information missing from alerts created before it was stored in the alert
+func setRuleInfo(summary *models.AlertSummary, rule *analysismodels.Rule) {
+	summary.Severity = aws.String(string(rule.Severity))
+	if rule.DisplayName != "" {
+		summary.RuleDisplayName = aws.String(string(rule.DisplayName))
+		summary.Title = summary.RuleDisplayName
+	}
+	summary.LogTypes = aws.StringSlice(rule.LogTypes)
+	summary.Tags = aws.StringSlice(rule.Tags)
+}
+
+// alertStatus returns the status of an alert, alerts created before status tracking are open
+func alertStatus(item *models.AlertItem) *string {
+	if item.Status == nil {
+		return aws.String(models.StatusOpen)
+	}
+	return item.Status
+}
+
+// getRule retrieves the rule associated with an alert
+func getRule(ruleID *string) (*analysismodels.Rule, error) {
+	zap.L().Debug("fetching rule",
 		zap.String("ruleId", *ruleID))
 
-	response, err := policiesClient.Operations.GetRule(&operations.GetRuleParams{
-		RuleID:     *ruleID,
-		HTTPClient: httpClient,
-	})
+	// The params are initialized with the default timeout, a zero timeout expires the request immediately
+	response, err := policiesClient.Operations.GetRule(
+		operations.NewGetRuleParams().WithRuleID(*ruleID).WithHTTPClient(httpClient))
 
 	if err != nil {
 		return nil, err
 	}
 
-	return aws.String(string(response.Payload.Severity)), nil
+	return response.Payload, nil
 }
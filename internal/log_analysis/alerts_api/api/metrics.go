@@ -0,0 +1,168 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	intervalHour    = "HOUR"
+	maxMetricsRange = 366 * 24 * time.Hour
+)
+
+// GetAlertMetrics aggregates the hourly alert metrics of a time range
+func (API) GetAlertMetrics(input *models.GetAlertMetricsInput) (*models.GetAlertMetricsOutput, error) {
+	zap.L().Info("getting alert metrics", zap.Any("input", input))
+
+	from, to := input.From.UTC().Truncate(time.Hour), input.To.UTC()
+	if !from.Before(to) {
+		return nil, &genericapi.InvalidInputError{Message: "from must be before to"}
+	}
+	if to.Sub(from) > maxMetricsRange {
+		return nil, &genericapi.InvalidInputError{Message: "the time range cannot exceed 366 days"}
+	}
+	interval := time.Hour
+	if aws.StringValue(input.Interval) != intervalHour {
+		interval = 24 * time.Hour
+	}
+
+	items, err := alertsDB.ListAlertMetrics(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	total := &models.MetricCounts{}
+	groups := map[string]map[string]*models.MetricCounts{
+		models.MetricRule:     {},
+		models.MetricSeverity: {},
+		models.MetricLogType:  {},
+	}
+	trend := make(map[time.Time]*models.MetricCounts)
+	for _, item := range items {
+		hour, dimension, err := table.ParseMetricKey(item.Key)
+		if err != nil {
+			return nil, err
+		}
+		if dimension.Type == models.MetricTotal {
+			day, err := time.Parse(table.MetricDayLayout, item.Day)
+			if err != nil {
+				return nil, &genericapi.InternalError{Message: "invalid alert metrics day " + item.Day}
+			}
+			addCounts(total, &item.MetricCounts)
+			intervalStart := intervalStart(day.Add(time.Duration(hour)*time.Hour), from, interval)
+			if trend[intervalStart] == nil {
+				trend[intervalStart] = &models.MetricCounts{}
+			}
+			addCounts(trend[intervalStart], &item.MetricCounts)
+			continue
+		}
+		if group, ok := groups[dimension.Type]; ok {
+			if group[dimension.Value] == nil {
+				group[dimension.Value] = &models.MetricCounts{}
+			}
+			addCounts(group[dimension.Value], &item.MetricCounts)
+		}
+	}
+
+	result := &models.GetAlertMetricsOutput{
+		Total:      alertMetric(nil, total),
+		ByRule:     sortedMetrics(groups[models.MetricRule]),
+		BySeverity: sortedMetrics(groups[models.MetricSeverity]),
+		ByLogType:  sortedMetrics(groups[models.MetricLogType]),
+	}
+	// Every interval of the range is returned, the intervals without alerts are zero
+	for start := from; start.Before(to); start = start.Add(interval) {
+		counts := trend[start]
+		if counts == nil {
+			counts = &models.MetricCounts{}
+		}
+		result.Trend = append(result.Trend, alertMetric(aws.String(start.Format(time.RFC3339)), counts))
+	}
+	return result, nil
+}
+
+// intervalStart returns the start of the interval containing the timestamp, intervals start at the beginning of the range
+func intervalStart(timestamp, from time.Time, interval time.Duration) time.Time {
+	return from.Add(timestamp.Sub(from) / interval * interval)
+}
+
+func addCounts(sum, counts *models.MetricCounts) {
+	sum.AlertCount += counts.AlertCount
+	sum.EventCount += counts.EventCount
+	sum.ResolvedCount += counts.ResolvedCount
+	sum.ResolveSeconds += counts.ResolveSeconds
+}
+
+func alertMetric(key *string, counts *models.MetricCounts) *models.AlertMetric {
+	metric := &models.AlertMetric{
+		Key:           key,
+		AlertCount:    counts.AlertCount,
+		EventCount:    counts.EventCount,
+		ResolvedCount: counts.ResolvedCount,
+	}
+	if counts.ResolvedCount > 0 {
+		metric.MeanTimeToResolveSeconds = aws.Float64(float64(counts.ResolveSeconds) / float64(counts.ResolvedCount))
+	}
+	return metric
+}
+
+// sortedMetrics returns the metrics of a group by decreasing number of alerts
+func sortedMetrics(group map[string]*models.MetricCounts) []*models.AlertMetric {
+	result := make([]*models.AlertMetric, 0, len(group))
+	for key, counts := range group {
+		result = append(result, alertMetric(aws.String(key), counts))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AlertCount != result[j].AlertCount {
+			return result[i].AlertCount > result[j].AlertCount
+		}
+		return *result[i].Key < *result[j].Key
+	})
+	return result
+}
+
+// recordResolution counts the first resolution of an alert in the alert metrics
+//
+// This is best effort: the status was already updated, so failures are only logged.
+func recordResolution(item *models.AlertItem) {
+	if item.ResolvedAt == nil || item.CreationTime == nil {
+		return
+	}
+	// The resolution time is only set by the update which resolved the alert for the first time
+	if !item.ResolvedAt.Equal(aws.TimeValue(item.LastUpdatedTime)) {
+		return
+	}
+
+	counts := &models.MetricCounts{
+		ResolvedCount:  1,
+		ResolveSeconds: int64(item.ResolvedAt.Sub(*item.CreationTime).Seconds()),
+	}
+	if err := alertsDB.AddAlertMetrics(*item.ResolvedAt, models.MetricDimensions(item), counts); err != nil {
+		zap.L().Warn("failed to record alert resolution", zap.String("alertId", *item.AlertID), zap.Error(err))
+	}
+}
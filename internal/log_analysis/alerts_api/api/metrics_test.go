@@ -0,0 +1,149 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func metricItem(day string, hour int, dimensionType, value string, counts models.MetricCounts) *models.AlertMetricItem {
+	return &models.AlertMetricItem{
+		Day:          day,
+		Key:          table.MetricKey(hour, models.MetricDimension{Type: dimensionType, Value: value}),
+		MetricCounts: counts,
+	}
+}
+
+func TestGetAlertMetrics(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	from, to := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC)
+	mockDB.On("ListAlertMetrics", from, to).Return([]*models.AlertMetricItem{
+		metricItem("2020-02-01", 3, models.MetricTotal, "", models.MetricCounts{AlertCount: 2, EventCount: 10}),
+		metricItem("2020-02-01", 20, models.MetricTotal, "",
+			models.MetricCounts{AlertCount: 1, EventCount: 1, ResolvedCount: 2, ResolveSeconds: 600}),
+		metricItem("2020-02-03", 0, models.MetricTotal, "", models.MetricCounts{AlertCount: 1, EventCount: 5}),
+		metricItem("2020-02-01", 3, models.MetricRule, "rule.a", models.MetricCounts{AlertCount: 2, EventCount: 10}),
+		metricItem("2020-02-01", 20, models.MetricRule, "rule.b", models.MetricCounts{AlertCount: 1, EventCount: 1}),
+		metricItem("2020-02-03", 0, models.MetricRule, "rule.b", models.MetricCounts{AlertCount: 1, EventCount: 5}),
+		metricItem("2020-02-01", 20, models.MetricSeverity, "HIGH",
+			models.MetricCounts{ResolvedCount: 2, ResolveSeconds: 600}),
+		metricItem("2020-02-01", 3, models.MetricLogType, "AWS.CloudTrail", models.MetricCounts{AlertCount: 2}),
+	}, nil).Once()
+
+	result, err := API{}.GetAlertMetrics(&models.GetAlertMetricsInput{From: &from, To: &to})
+	require.NoError(t, err)
+
+	assert.Equal(t, &models.AlertMetric{
+		AlertCount: 4, EventCount: 16, ResolvedCount: 2, MeanTimeToResolveSeconds: aws.Float64(300),
+	}, result.Total)
+	assert.Equal(t, []*models.AlertMetric{
+		{Key: aws.String("rule.a"), AlertCount: 2, EventCount: 10},
+		{Key: aws.String("rule.b"), AlertCount: 2, EventCount: 6},
+	}, result.ByRule)
+	assert.Equal(t, []*models.AlertMetric{
+		{Key: aws.String("HIGH"), ResolvedCount: 2, MeanTimeToResolveSeconds: aws.Float64(300)},
+	}, result.BySeverity)
+	assert.Equal(t, []*models.AlertMetric{{Key: aws.String("AWS.CloudTrail"), AlertCount: 2}}, result.ByLogType)
+	assert.Equal(t, []*models.AlertMetric{
+		{Key: aws.String("2020-02-01T00:00:00Z"), AlertCount: 3, EventCount: 11, ResolvedCount: 2,
+			MeanTimeToResolveSeconds: aws.Float64(300)},
+		{Key: aws.String("2020-02-02T00:00:00Z")},
+		{Key: aws.String("2020-02-03T00:00:00Z"), AlertCount: 1, EventCount: 5},
+	}, result.Trend)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetAlertMetricsHourly(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	from, to := time.Date(2020, 2, 1, 10, 15, 0, 0, time.UTC), time.Date(2020, 2, 1, 13, 0, 0, 0, time.UTC)
+	mockDB.On("ListAlertMetrics", from.Truncate(time.Hour), to).Return([]*models.AlertMetricItem{
+		metricItem("2020-02-01", 11, models.MetricTotal, "", models.MetricCounts{AlertCount: 2}),
+	}, nil).Once()
+
+	result, err := API{}.GetAlertMetrics(&models.GetAlertMetricsInput{
+		From: &from, To: &to, Interval: aws.String("HOUR")})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.AlertMetric{
+		{Key: aws.String("2020-02-01T10:00:00Z")},
+		{Key: aws.String("2020-02-01T11:00:00Z"), AlertCount: 2},
+		{Key: aws.String("2020-02-01T12:00:00Z")},
+	}, result.Trend)
+}
+
+func TestGetAlertMetricsInvalidRange(t *testing.T) {
+	from := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	_, err := API{}.GetAlertMetrics(&models.GetAlertMetricsInput{From: &from, To: &from})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	to := from.Add(400 * 24 * time.Hour)
+	_, err = API{}.GetAlertMetrics(&models.GetAlertMetricsInput{From: &from, To: &to})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestUpdateAlertStatusRecordsResolution(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	created := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	resolved := created.Add(90 * time.Minute)
+	item := &models.AlertItem{
+		AlertID:         aws.String("alert-id"),
+		RuleID:          aws.String("rule.id"),
+		Severity:        aws.String("HIGH"),
+		Title:           aws.String("title"),
+		CreationTime:    &created,
+		Status:          aws.String(models.StatusResolved),
+		ResolvedAt:      &resolved,
+		LastUpdatedTime: &resolved,
+	}
+	input := &models.UpdateAlertStatusInput{
+		AlertID: aws.String("alert-id"), Status: aws.String(models.StatusResolved), UserID: aws.String("user-id")}
+	mockDB.On("GetAlert", input.AlertID).
+		Return(&models.AlertItem{AlertID: aws.String("alert-id"), Status: aws.String(models.StatusTriaged)}, nil).Twice()
+	mockDB.On("UpdateAlertStatus", input.AlertID, input.Status, input.UserID, input.Resolution).Return(item, nil).Once()
+	mockDB.On("AddAlertMetrics", resolved, []models.MetricDimension{
+		{Type: models.MetricTotal},
+		{Type: models.MetricRule, Value: "rule.id"},
+		{Type: models.MetricSeverity, Value: "HIGH"},
+	}, &models.MetricCounts{ResolvedCount: 1, ResolveSeconds: 5400}).Return(nil).Once()
+	mockDB.On("ListDeliveries", aws.String("alert-id")).Return([]*models.AlertDelivery(nil), nil).Twice()
+
+	_, err := API{}.UpdateAlertStatus(input)
+	require.NoError(t, err)
+
+	// A later resolution keeps the first resolution time and is not counted again
+	reopened := *item
+	reopened.LastUpdatedTime = aws.Time(resolved.Add(time.Hour))
+	mockDB.On("UpdateAlertStatus", input.AlertID, input.Status, input.UserID, input.Resolution).
+		Return(&reopened, nil).Once()
+	_, err = API{}.UpdateAlertStatus(input)
+	require.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockDB.AssertNumberOfCalls(t, "AddAlertMetrics", 1)
+}
@@ -0,0 +1,86 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/gateway/analysis/client/operations"
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// ResendAlert sends an existing alert to the alert delivery again
+//
+// The alert is built from the current version of its rule, like the alert merger does for new alerts.
+func (API) ResendAlert(input *models.ResendAlertInput) error {
+	zap.L().Info("resending alert", zap.Any("input", input))
+
+	alertItem, err := alertsDB.GetAlert(input.AlertID)
+	if err != nil {
+		return err
+	}
+	if alertItem.AlertID == nil {
+		return &genericapi.DoesNotExistError{Message: "alertId=" + *input.AlertID}
+	}
+
+	rule, err := getRule(alertItem.RuleID)
+	if err != nil {
+		if _, ok := err.(*operations.GetRuleNotFound); ok {
+			return &genericapi.DoesNotExistError{Message: "ruleId=" + *alertItem.RuleID}
+		}
+		return &genericapi.InternalError{Message: "failed to get rule " + *alertItem.RuleID + ": " + err.Error()}
+	}
+
+	alert := alertmodels.NewRuleAlert(
+		rule, alertItem.AlertID, alertItem.Dedup, alertItem.CreationTime, int64(*eventsMatched(alertItem)))
+	// Keep the severity the alert was created with, it selects the default outputs
+	if alertItem.Severity != nil {
+		alert.Severity = alertItem.Severity
+	}
+	alert.Title = alertItem.Title
+	for _, field := range alertItem.Context {
+		alert.Context = append(alert.Context, &alertmodels.ContextField{Key: field.Key, Value: field.Value})
+	}
+	alert.OutputIDs = input.OutputIDs
+
+	body, err := jsoniter.MarshalToString(alert)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal alert: " + err.Error()}
+	}
+	if _, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:    aws.String(env.AlertingQueueURL),
+		MessageBody: aws.String(body),
+	}); err != nil {
+		return &genericapi.AWSError{Method: "sqs.SendMessage", Err: err}
+	}
+
+	return alertsDB.AddAlertChange(input.AlertID, &models.AlertChange{
+		Action:    aws.String(models.ActionResend),
+		UserID:    input.UserID,
+		Timestamp: aws.Time(time.Now().UTC()),
+		OutputIDs: input.OutputIDs,
+	})
+}
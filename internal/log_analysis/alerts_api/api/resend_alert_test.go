@@ -0,0 +1,137 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/gateway/analysis/client"
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// analysisServer serves the given rule from a fake analysis api
+func analysisServer(t *testing.T, rule string) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/rule", r.URL.Path)
+		if rule == "" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "not found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(rule))
+	}))
+	httpClient = server.Client()
+	policiesClient = client.NewHTTPClientWithConfig(nil, client.DefaultTransportConfig().
+		WithHost(strings.TrimPrefix(server.URL, "https://")).
+		WithBasePath("/v1"))
+	return server
+}
+
+func resendAlertItem() *models.AlertItem {
+	return &models.AlertItem{
+		AlertID:      aws.String("alert-id"),
+		RuleID:       aws.String("rule.id"),
+		Severity:     aws.String("CRITICAL"),
+		Dedup:        aws.String("dedup"),
+		CreationTime: aws.Time(time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)),
+		EventCount:   aws.Int(5),
+	}
+}
+
+func TestResendAlert(t *testing.T) {
+	server := analysisServer(t,
+		`{"id": "rule.id", "displayName": "Root Login", "severity": "HIGH", "versionId": "v1", "tags": ["IAM"]}`)
+	defer server.Close()
+	mockDB, mockQueue := &mockTable{}, &mockSQS{}
+	alertsDB, sqsClient = mockDB, mockQueue
+	env.AlertingQueueURL = "queue-url"
+
+	input := &models.ResendAlertInput{
+		AlertID:   aws.String("alert-id"),
+		OutputIDs: aws.StringSlice([]string{"output-id"}),
+		UserID:    aws.String("user-id"),
+	}
+	mockDB.On("GetAlert", aws.String("alert-id")).Return(resendAlertItem(), nil).Once()
+	mockQueue.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).Once()
+	mockDB.On("AddAlertChange", aws.String("alert-id"), mock.Anything).Return(nil).Once()
+
+	require.NoError(t, API{}.ResendAlert(input))
+
+	message := mockQueue.Calls[0].Arguments[0].(*sqs.SendMessageInput)
+	assert.Equal(t, "queue-url", *message.QueueUrl)
+	var alert alertmodels.Alert
+	require.NoError(t, jsoniter.UnmarshalFromString(*message.MessageBody, &alert))
+	assert.Equal(t, &alertmodels.Alert{
+		CreatedAt:         aws.Time(time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)),
+		OutputIDs:         aws.StringSlice([]string{"output-id"}),
+		PolicyDescription: aws.String(""),
+		PolicyID:          aws.String("rule.id"),
+		PolicyName:        aws.String("Root Login"),
+		PolicyVersionID:   aws.String("v1"),
+		Runbook:           aws.String(""),
+		Severity:          aws.String("CRITICAL"),
+		Tags:              aws.StringSlice([]string{"IAM"}),
+		AlertID:           aws.String("alert-id"),
+		Type:              aws.String(alertmodels.RuleType),
+		Dedup:             aws.String("dedup"),
+		EventCount:        aws.Int64(5),
+	}, &alert)
+
+	change := mockDB.Calls[1].Arguments[1].(*models.AlertChange)
+	assert.Equal(t, models.ActionResend, *change.Action)
+	assert.Equal(t, "user-id", *change.UserID)
+	assert.Equal(t, input.OutputIDs, change.OutputIDs)
+	mockDB.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+}
+
+func TestResendAlertRuleDeleted(t *testing.T) {
+	server := analysisServer(t, "")
+	defer server.Close()
+	mockDB, mockQueue := &mockTable{}, &mockSQS{}
+	alertsDB, sqsClient = mockDB, mockQueue
+	mockDB.On("GetAlert", aws.String("alert-id")).Return(resendAlertItem(), nil).Once()
+
+	err := API{}.ResendAlert(&models.ResendAlertInput{AlertID: aws.String("alert-id"), UserID: aws.String("user-id")})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockQueue.AssertNotCalled(t, "SendMessage", mock.Anything)
+}
+
+func TestResendAlertDoesNotExist(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+	mockDB.On("GetAlert", aws.String("alert-id")).Return(&models.AlertItem{}, nil).Once()
+
+	err := API{}.ResendAlert(&models.ResendAlertInput{AlertID: aws.String("alert-id"), UserID: aws.String("user-id")})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
@@ -0,0 +1,170 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const defaultTimelinePageSize = 25
+
+// timelinePaginationKey continues the timeline after the last comment of the previous page
+type timelinePaginationKey struct {
+	CommentsKey *string    `json:"commentsKey"`
+	After       *time.Time `json:"after"`
+}
+
+// GetAlertTimeline merges the comments, the history and the deliveries of an alert into a single timeline
+func (API) GetAlertTimeline(input *models.GetAlertTimelineInput) (*models.GetAlertTimelineOutput, error) {
+	zap.L().Info("getting alert timeline", zap.Any("input", input))
+
+	alertItem, err := alertsDB.GetAlert(input.AlertID)
+	if err != nil {
+		return nil, err
+	}
+	if alertItem.AlertID == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "alertId=" + *input.AlertID}
+	}
+
+	startKey := &timelinePaginationKey{}
+	if input.ExclusiveStartKey != nil {
+		if err = jsoniter.UnmarshalFromString(*input.ExclusiveStartKey, startKey); err != nil {
+			return nil, &genericapi.InvalidInputError{Message: "invalid exclusiveStartKey: " + err.Error()}
+		}
+	}
+
+	pageSize := defaultTimelinePageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+	comments, commentsKey, err := alertsDB.ListComments(input.AlertID, startKey.CommentsKey, &pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if alertItem.History, err = alertsDB.GetAlertHistory(alertItem); err != nil {
+		return nil, err
+	}
+	deliveries, err := alertsDB.ListDeliveries(input.AlertID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The page ends with its last comment if there are more comments
+	result := &models.GetAlertTimelineOutput{}
+	var before *time.Time
+	if commentsKey != nil && len(comments) > 0 {
+		before = comments[len(comments)-1].CreatedAt
+		lastEvaluatedKey, err := jsoniter.MarshalToString(&timelinePaginationKey{CommentsKey: commentsKey, After: before})
+		if err != nil {
+			return nil, &genericapi.InternalError{Message: "failed to marshal key: " + err.Error()}
+		}
+		result.LastEvaluatedKey = &lastEvaluatedKey
+	}
+
+	result.Activities = timelinePage(buildTimeline(alertItem, comments, deliveries), startKey.After, before)
+	gatewayapi.ReplaceMapSliceNils(result)
+	return result, nil
+}
+
+// timelinePage keeps the comments and the other activities in (after, before], the bounds are optional
+func timelinePage(activities []*models.AlertActivity, after, before *time.Time) []*models.AlertActivity {
+	page := activities[:0]
+	for _, activity := range activities {
+		timestamp := aws.TimeValue(activity.Timestamp)
+		if activity.Comment == nil &&
+			((after != nil && !timestamp.After(*after)) || (before != nil && timestamp.After(*before))) {
+
+			continue
+		}
+		page = append(page, activity)
+	}
+	return page
+}
+
+// buildTimeline returns the activities of an alert sorted by time, oldest first
+//
+// Events are not tracked individually: a single activity reports the number of events
+// matched up to the last one. Only the successful deliveries are part of the timeline, the failed
+// attempts and the resolutions are listed with the deliveries of the alert.
+func buildTimeline(alertItem *models.AlertItem, comments []*models.AlertComment,
+	deliveries []*models.AlertDelivery) []*models.AlertActivity {
+
+	activities := make([]*models.AlertActivity, 0, len(comments)+len(alertItem.History)+len(deliveries)+2)
+
+	activities = append(activities, &models.AlertActivity{
+		Type:      aws.String(models.ActivityAlertCreated),
+		Timestamp: alertItem.CreationTime,
+	})
+	if alertItem.LastEventMatched != nil {
+		activities = append(activities, &models.AlertActivity{
+			Type:       aws.String(models.ActivityEventsAdded),
+			Timestamp:  alertItem.LastEventMatched,
+			EventCount: eventsMatched(alertItem),
+		})
+	}
+
+	for _, change := range alertItem.History {
+		activities = append(activities, &models.AlertActivity{
+			Type:      change.Action,
+			Timestamp: change.Timestamp,
+			UserID:    change.UserID,
+			Change:    change,
+		})
+	}
+
+	for _, delivery := range deliveries {
+		if aws.StringValue(delivery.Status) != models.DeliveryStatusSuccess || aws.BoolValue(delivery.Resolution) {
+			continue
+		}
+		activities = append(activities, &models.AlertActivity{
+			Type:      aws.String(models.ActivityOutputDelivered),
+			Timestamp: delivery.Timestamp,
+			Change: &models.AlertChange{
+				Action:    aws.String(models.ActionOutputDelivered),
+				Timestamp: delivery.Timestamp,
+				OutputID:  delivery.OutputID,
+			},
+		})
+	}
+
+	for _, comment := range comments {
+		activities = append(activities, &models.AlertActivity{
+			Type:      aws.String(models.ActivityComment),
+			Timestamp: comment.CreatedAt,
+			UserID:    comment.UserID,
+			Comment:   comment,
+		})
+	}
+
+	// Stable sort keeps the creation before the other activities with the same timestamp
+	sort.SliceStable(activities, func(i, j int) bool {
+		return aws.TimeValue(activities[i].Timestamp).Before(aws.TimeValue(activities[j].Timestamp))
+	})
+	return activities
+}
@@ -0,0 +1,121 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestBuildTimelineDeliveries(t *testing.T) {
+	created := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	alertItem := &models.AlertItem{AlertID: aws.String("alert-id"), CreationTime: aws.Time(created)}
+	deliveries := []*models.AlertDelivery{
+		{
+			OutputID:  aws.String("failing-output"),
+			Status:    aws.String(models.DeliveryStatusFailure),
+			Timestamp: aws.Time(created.Add(time.Second)),
+		},
+		{
+			OutputID:  aws.String("output-id"),
+			Status:    aws.String(models.DeliveryStatusSuccess),
+			Timestamp: aws.Time(created.Add(time.Minute)),
+		},
+		{
+			OutputID:   aws.String("output-id"),
+			Status:     aws.String(models.DeliveryStatusSuccess),
+			Timestamp:  aws.Time(created.Add(time.Hour)),
+			Resolution: aws.Bool(true),
+		},
+	}
+
+	// Only the successful delivery is an activity, not the resolution
+	activities := buildTimeline(alertItem, nil, deliveries)
+	require.Len(t, activities, 2)
+	assert.Equal(t, models.ActivityAlertCreated, *activities[0].Type)
+	assert.Equal(t, models.ActivityOutputDelivered, *activities[1].Type)
+	assert.Equal(t, "output-id", *activities[1].Change.OutputID)
+	assert.Equal(t, created.Add(time.Minute), *activities[1].Timestamp)
+}
+
+func TestGetAlertTimelinePages(t *testing.T) {
+	tableMock := &mockTable{}
+	alertsDB = tableMock
+	created := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	alertItem := &models.AlertItem{
+		AlertID:      aws.String("alert-id"),
+		CreationTime: aws.Time(created),
+		History: []*models.AlertChange{
+			{
+				Action:    aws.String(models.ActionStatusChange),
+				Timestamp: aws.Time(created.Add(2 * time.Minute)),
+				Status:    aws.String(models.StatusTriaged),
+			},
+		},
+	}
+	comment := &models.AlertComment{CommentID: aws.String("comment-id"), CreatedAt: aws.Time(created.Add(time.Minute))}
+	tableMock.On("GetAlert", aws.String("alert-id")).Return(alertItem, nil).Twice()
+	tableMock.On("GetAlertHistory", alertItem).Return(alertItem.History, nil).Twice()
+	tableMock.On("ListDeliveries", aws.String("alert-id")).Return([]*models.AlertDelivery(nil), nil).Twice()
+	tableMock.On("ListComments", aws.String("alert-id"), (*string)(nil), aws.Int(1)).Return(
+		[]*models.AlertComment{comment}, aws.String("comments-key"), nil).Once()
+	tableMock.On("ListComments", aws.String("alert-id"), aws.String("comments-key"), aws.Int(1)).Return(
+		[]*models.AlertComment(nil), (*string)(nil), nil).Once()
+
+	// The first page ends with its comment
+	result, err := API{}.GetAlertTimeline(&models.GetAlertTimelineInput{AlertID: aws.String("alert-id"), PageSize: aws.Int(1)})
+	require.NoError(t, err)
+	require.Len(t, result.Activities, 2)
+	assert.Equal(t, models.ActivityAlertCreated, *result.Activities[0].Type)
+	assert.Equal(t, models.ActivityComment, *result.Activities[1].Type)
+	require.NotNil(t, result.LastEvaluatedKey)
+
+	// The second page has the activities after the comment
+	result, err = API{}.GetAlertTimeline(&models.GetAlertTimelineInput{
+		AlertID:           aws.String("alert-id"),
+		PageSize:          aws.Int(1),
+		ExclusiveStartKey: result.LastEvaluatedKey,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Activities, 1)
+	assert.Equal(t, models.ActivityStatusChange, *result.Activities[0].Type)
+	assert.Nil(t, result.LastEvaluatedKey)
+	tableMock.AssertExpectations(t)
+}
+
+func TestGetAlertTimelineInvalidKey(t *testing.T) {
+	tableMock := &mockTable{}
+	alertsDB = tableMock
+	tableMock.On("GetAlert", aws.String("alert-id")).Return(&models.AlertItem{AlertID: aws.String("alert-id")}, nil).Once()
+
+	result, err := API{}.GetAlertTimeline(&models.GetAlertTimelineInput{
+		AlertID:           aws.String("alert-id"),
+		ExclusiveStartKey: aws.String("not json"),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	tableMock.AssertExpectations(t)
+}
@@ -0,0 +1,130 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// UpdateAlertStatus changes the status of an alert
+func (API) UpdateAlertStatus(input *models.UpdateAlertStatusInput) (*models.UpdateAlertStatusOutput, error) {
+	zap.L().Info("updating alert status", zap.Any("input", input))
+
+	previous, err := alertsDB.GetAlert(input.AlertID)
+	if err != nil {
+		return nil, err
+	}
+	if previous.AlertID == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "alertId=" + *input.AlertID}
+	}
+
+	alertItem, err := alertsDB.UpdateAlertStatus(input.AlertID, input.Status, input.UserID, input.Resolution)
+	if err != nil {
+		return nil, err
+	}
+	recordResolution(alertItem)
+	// The incidents are only resolved when the alert is closed, not when a closed alert is saved again
+	if isClosed(input.Status) && !isClosed(alertStatus(previous)) {
+		resolveIncidents(alertItem)
+	}
+	return alertItemToAlertSummary(alertItem)
+}
+
+// isClosed returns true if the alert status ends the alert
+func isClosed(status *string) bool {
+	return *status == models.StatusClosed || *status == models.StatusResolved
+}
+
+// resolveIncidents sends the resolution of a closed alert to the outputs it was delivered to
+//
+// The outputs close the incidents they opened for the alert. This is best effort: the status
+// was already updated, so failures are only logged.
+func resolveIncidents(item *models.AlertItem) {
+	deliveries, err := alertsDB.ListDeliveries(item.AlertID)
+	if err != nil {
+		zap.L().Warn("failed to list alert deliveries", zap.String("alertId", *item.AlertID), zap.Error(err))
+		return
+	}
+
+	var outputIDs []*string
+	delivered := make(map[string]bool)
+	for _, delivery := range deliveries {
+		outputID := aws.StringValue(delivery.OutputID)
+		if aws.StringValue(delivery.Status) != models.DeliveryStatusSuccess || aws.BoolValue(delivery.Resolution) || delivered[outputID] {
+			continue
+		}
+		delivered[outputID] = true
+		outputIDs = append(outputIDs, delivery.OutputID)
+	}
+	if len(outputIDs) == 0 {
+		return
+	}
+
+	alert := &alertmodels.Alert{
+		AlertID:   item.AlertID,
+		CreatedAt: item.CreationTime,
+		Dedup:     item.Dedup,
+		OutputIDs: outputIDs,
+		PolicyID:  item.RuleID,
+		Resolved:  aws.Bool(true),
+		Severity:  item.Severity,
+		Title:     item.Title,
+		Type:      aws.String(alertmodels.RuleType),
+	}
+	body, err := jsoniter.MarshalToString(alert)
+	if err != nil {
+		zap.L().Warn("failed to marshal resolved alert", zap.String("alertId", *item.AlertID), zap.Error(err))
+		return
+	}
+	if _, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:    aws.String(env.AlertingQueueURL),
+		MessageBody: aws.String(body),
+	}); err != nil {
+		zap.L().Warn("failed to send resolved alert", zap.String("alertId", *item.AlertID), zap.Error(err))
+	}
+}
+
+// AssignAlert changes the assignee of an alert
+func (API) AssignAlert(input *models.AssignAlertInput) (*models.AssignAlertOutput, error) {
+	zap.L().Info("assigning alert", zap.Any("input", input))
+
+	alertItem, err := alertsDB.AssignAlert(input.AlertID, input.AssigneeID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return alertItemToAlertSummary(alertItem)
+}
+
+// alertItemToAlertSummary converts a single updated DDB Alert Item to an Alert Summary
+func alertItemToAlertSummary(item *models.AlertItem) (*models.AlertSummary, error) {
+	summaries, err := alertItemsToAlertSummary([]*models.AlertItem{item})
+	if err != nil {
+		return nil, err
+	}
+	gatewayapi.ReplaceMapSliceNils(summaries[0])
+	return summaries[0], nil
+}
@@ -0,0 +1,144 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestUpdateAlertStatusResolvesIncidents(t *testing.T) {
+	mockDB, mockQueue := &mockTable{}, &mockSQS{}
+	alertsDB, sqsClient = mockDB, mockQueue
+	env.AlertingQueueURL = "queue-url"
+
+	input := &models.UpdateAlertStatusInput{
+		AlertID: aws.String("alert-id"), Status: aws.String(models.StatusClosed), UserID: aws.String("user-id")}
+	mockDB.On("GetAlert", input.AlertID).Return(resendAlertItem(), nil).Once()
+	mockDB.On("UpdateAlertStatus", input.AlertID, input.Status, input.UserID, input.Resolution).
+		Return(resendAlertItem(), nil).Once()
+	mockDB.On("ListDeliveries", aws.String("alert-id")).Return([]*models.AlertDelivery{
+		{OutputID: aws.String("pagerduty-id"), Status: aws.String(models.DeliveryStatusFailure)},
+		{OutputID: aws.String("pagerduty-id"), Status: aws.String(models.DeliveryStatusSuccess)},
+		{OutputID: aws.String("jira-id"), Status: aws.String(models.DeliveryStatusPermanentFailure)},
+		{OutputID: aws.String("slack-id"), Status: aws.String(models.DeliveryStatusSuccess)},
+		{OutputID: aws.String("slack-id"), Status: aws.String(models.DeliveryStatusSuccess)},
+	}, nil).Once()
+	mockQueue.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).Once()
+
+	_, err := API{}.UpdateAlertStatus(input)
+	require.NoError(t, err)
+
+	// The resolution is sent once to every output the alert was delivered to
+	message := mockQueue.Calls[0].Arguments[0].(*sqs.SendMessageInput)
+	assert.Equal(t, "queue-url", *message.QueueUrl)
+	var alert alertmodels.Alert
+	require.NoError(t, jsoniter.UnmarshalFromString(*message.MessageBody, &alert))
+	assert.Equal(t, "alert-id", *alert.AlertID)
+	assert.Equal(t, "rule.id", *alert.PolicyID)
+	assert.Equal(t, "dedup", *alert.Dedup)
+	assert.Equal(t, alertmodels.RuleType, *alert.Type)
+	assert.Equal(t, aws.StringSlice([]string{"pagerduty-id", "slack-id"}), alert.OutputIDs)
+	assert.True(t, *alert.Resolved)
+	mockDB.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+}
+
+func TestUpdateAlertStatusNotDelivered(t *testing.T) {
+	mockDB, mockQueue := &mockTable{}, &mockSQS{}
+	alertsDB, sqsClient = mockDB, mockQueue
+
+	input := &models.UpdateAlertStatusInput{
+		AlertID: aws.String("alert-id"), Status: aws.String(models.StatusClosed), UserID: aws.String("user-id")}
+	mockDB.On("GetAlert", input.AlertID).Return(resendAlertItem(), nil).Once()
+	mockDB.On("UpdateAlertStatus", input.AlertID, input.Status, input.UserID, input.Resolution).
+		Return(resendAlertItem(), nil).Once()
+	// A resolution is not a delivery of the alert
+	mockDB.On("ListDeliveries", aws.String("alert-id")).Return([]*models.AlertDelivery{
+		{OutputID: aws.String("pagerduty-id"), Status: aws.String(models.DeliveryStatusFailure)},
+		{OutputID: aws.String("jira-id"), Status: aws.String(models.DeliveryStatusSuccess), Resolution: aws.Bool(true)},
+	}, nil).Once()
+
+	_, err := API{}.UpdateAlertStatus(input)
+	require.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockQueue.AssertNotCalled(t, "SendMessage", mock.Anything)
+}
+
+func TestUpdateAlertStatusTriaged(t *testing.T) {
+	mockDB, mockQueue := &mockTable{}, &mockSQS{}
+	alertsDB, sqsClient = mockDB, mockQueue
+
+	// Only closing the alert resolves its incidents
+	input := &models.UpdateAlertStatusInput{
+		AlertID: aws.String("alert-id"), Status: aws.String(models.StatusTriaged), UserID: aws.String("user-id")}
+	mockDB.On("GetAlert", input.AlertID).Return(resendAlertItem(), nil).Once()
+	mockDB.On("UpdateAlertStatus", input.AlertID, input.Status, input.UserID, input.Resolution).
+		Return(resendAlertItem(), nil).Once()
+
+	_, err := API{}.UpdateAlertStatus(input)
+	require.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockQueue.AssertNotCalled(t, "SendMessage", mock.Anything)
+}
+
+func TestUpdateAlertStatusAlreadyClosed(t *testing.T) {
+	mockDB, mockQueue := &mockTable{}, &mockSQS{}
+	alertsDB, sqsClient = mockDB, mockQueue
+
+	// Saving a closed alert as resolved does not send the resolution again
+	previous := resendAlertItem()
+	previous.Status = aws.String(models.StatusClosed)
+	input := &models.UpdateAlertStatusInput{
+		AlertID: aws.String("alert-id"), Status: aws.String(models.StatusResolved), UserID: aws.String("user-id")}
+	mockDB.On("GetAlert", input.AlertID).Return(previous, nil).Once()
+	mockDB.On("UpdateAlertStatus", input.AlertID, input.Status, input.UserID, input.Resolution).
+		Return(resendAlertItem(), nil).Once()
+
+	_, err := API{}.UpdateAlertStatus(input)
+	require.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "ListDeliveries", mock.Anything)
+	mockQueue.AssertNotCalled(t, "SendMessage", mock.Anything)
+}
+
+func TestUpdateAlertStatusDoesNotExist(t *testing.T) {
+	mockDB := &mockTable{}
+	alertsDB = mockDB
+
+	input := &models.UpdateAlertStatusInput{
+		AlertID: aws.String("alert-id"), Status: aws.String(models.StatusClosed), UserID: aws.String("user-id")}
+	mockDB.On("GetAlert", input.AlertID).Return(&models.AlertItem{}, nil).Once()
+
+	result, err := API{}.UpdateAlertStatus(input)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "UpdateAlertStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
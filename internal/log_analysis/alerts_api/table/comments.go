@@ -0,0 +1,201 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// AddComment stores a new comment for an alert
+func (table *AlertsTable) AddComment(alertID, userID, body *string) (*models.AlertComment, error) {
+	comment := &models.AlertComment{
+		AlertID:   alertID,
+		CommentID: aws.String(uuid.New().String()),
+		UserID:    userID,
+		Body:      body,
+		CreatedAt: aws.Time(time.Now().UTC()),
+	}
+
+	item, err := dynamodbattribute.MarshalMap(comment)
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to marshal comment: " + err.Error()}
+	}
+
+	if _, err = table.Client.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(table.CommentsTableName),
+		Item:      item,
+	}); err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.PutItem", Err: err}
+	}
+	return comment, nil
+}
+
+// UpdateComment replaces the body of a comment
+//
+// Returns DoesNotExistError if the comment does not exist or was written by another user.
+func (table *AlertsTable) UpdateComment(alertID, commentID, userID, body *string) (*models.AlertComment, error) {
+	update := expression.
+		Set(expression.Name("body"), expression.Value(body)).
+		Set(expression.Name("lastModified"), expression.Value(aws.Time(time.Now().UTC())))
+
+	updateExpression, err := expression.NewBuilder().
+		WithCondition(commentAuthorCondition(userID)).
+		WithUpdate(update).
+		Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	response, err := table.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table.CommentsTableName),
+		Key:                       commentKey(alertID, commentID),
+		UpdateExpression:          updateExpression.Update(),
+		ConditionExpression:       updateExpression.Condition(),
+		ExpressionAttributeNames:  updateExpression.Names(),
+		ExpressionAttributeValues: updateExpression.Values(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		return nil, commentError("dynamodb.UpdateItem", commentID, err)
+	}
+
+	comment := &models.AlertComment{}
+	if err = dynamodbattribute.UnmarshalMap(response.Attributes, comment); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal dynamo item to an AlertComment: " + err.Error()}
+	}
+	return comment, nil
+}
+
+// DeleteComment removes a comment
+//
+// Returns DoesNotExistError if the comment does not exist or was written by another user.
+func (table *AlertsTable) DeleteComment(alertID, commentID, userID *string) error {
+	deleteExpression, err := expression.NewBuilder().WithCondition(commentAuthorCondition(userID)).Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	if _, err = table.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:                 aws.String(table.CommentsTableName),
+		Key:                       commentKey(alertID, commentID),
+		ConditionExpression:       deleteExpression.Condition(),
+		ExpressionAttributeNames:  deleteExpression.Names(),
+		ExpressionAttributeValues: deleteExpression.Values(),
+	}); err != nil {
+		return commentError("dynamodb.DeleteItem", commentID, err)
+	}
+	return nil
+}
+
+// ListComments returns (a page of comments in chronological order, last evaluated key, any error)
+func (table *AlertsTable) ListComments(alertID *string, exclusiveStartKey *string, pageSize *int) (
+	comments []*models.AlertComment, lastEvaluatedKey *string, err error) {
+
+	keyCondition := expression.Key("alertId").Equal(expression.Value(alertID))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, nil, &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	var queryResultsLimit *int64
+	if pageSize != nil {
+		queryResultsLimit = aws.Int64(int64(*pageSize))
+	}
+
+	var queryExclusiveStartKey map[string]*dynamodb.AttributeValue
+	if exclusiveStartKey != nil {
+		key := &listCommentsPaginationKey{}
+		if err = jsoniter.UnmarshalFromString(*exclusiveStartKey, key); err != nil {
+			return nil, nil, &genericapi.InvalidInputError{Message: "invalid exclusiveStartKey: " + err.Error()}
+		}
+		queryExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			"alertId":   {S: alertID},
+			"commentId": {S: key.CommentID},
+			"createdAt": {S: key.CreatedAt},
+		}
+	}
+
+	queryOutput, err := table.Client.Query(&dynamodb.QueryInput{
+		TableName:                 aws.String(table.CommentsTableName),
+		IndexName:                 aws.String(table.CommentsCreatedAtIndexName),
+		ScanIndexForward:          aws.Bool(true),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		ExclusiveStartKey:         queryExclusiveStartKey,
+		Limit:                     queryResultsLimit,
+	})
+	if err != nil {
+		return nil, nil, &genericapi.AWSError{Method: "dynamodb.Query", Err: err}
+	}
+
+	if err = dynamodbattribute.UnmarshalListOfMaps(queryOutput.Items, &comments); err != nil {
+		return nil, nil, &genericapi.InternalError{Message: "failed to unmarshal comments: " + err.Error()}
+	}
+
+	// If DDB returned a LastEvaluatedKey, it means there are more comments to be returned
+	if len(queryOutput.LastEvaluatedKey) > 0 {
+		marshalledKey, err := jsoniter.MarshalToString(&listCommentsPaginationKey{
+			CommentID: queryOutput.LastEvaluatedKey["commentId"].S,
+			CreatedAt: queryOutput.LastEvaluatedKey["createdAt"].S,
+		})
+		if err != nil {
+			return nil, nil, &genericapi.InternalError{Message: "failed to marshal key: " + err.Error()}
+		}
+		lastEvaluatedKey = &marshalledKey
+	}
+
+	return comments, lastEvaluatedKey, nil
+}
+
+type listCommentsPaginationKey struct {
+	CommentID *string `json:"commentId"`
+	CreatedAt *string `json:"createdAt"`
+}
+
+func commentKey(alertID, commentID *string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"alertId":   {S: alertID},
+		"commentId": {S: commentID},
+	}
+}
+
+// commentAuthorCondition matches an existing comment written by the given user
+func commentAuthorCondition(userID *string) expression.ConditionBuilder {
+	return expression.AttributeExists(expression.Name("commentId")).
+		And(expression.Name("userId").Equal(expression.Value(userID)))
+}
+
+func commentError(method string, commentID *string, err error) error {
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return &genericapi.DoesNotExistError{Message: "commentId=" + *commentID}
+	}
+	return &genericapi.AWSError{Method: method, Err: err}
+}
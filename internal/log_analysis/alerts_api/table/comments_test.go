@@ -0,0 +1,125 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func commentsTable(client *mockDynamoDB) *AlertsTable {
+	return &AlertsTable{CommentsTableName: "comments", CommentsCreatedAtIndexName: "index", Client: client}
+}
+
+func TestAddComment(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	comment, err := commentsTable(client).AddComment(aws.String("alert-id"), aws.String("user-id"), aws.String("body"))
+	require.NoError(t, err)
+	assert.Equal(t, "alert-id", *comment.AlertID)
+	assert.Equal(t, "user-id", *comment.UserID)
+	assert.Equal(t, "body", *comment.Body)
+	assert.NotNil(t, comment.CommentID)
+	assert.NotNil(t, comment.CreatedAt)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.PutItemInput)
+	assert.Equal(t, "comments", *input.TableName)
+	assert.Equal(t, *comment.CommentID, *input.Item["commentId"].S)
+	assert.Equal(t, "body", *input.Item["body"].S)
+	client.AssertExpectations(t)
+}
+
+func TestUpdateCommentNotAuthor(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil)).Once()
+
+	result, err := commentsTable(client).UpdateComment(
+		aws.String("alert-id"), aws.String("comment-id"), aws.String("user-id"), aws.String("body"))
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "comments", *input.TableName)
+	assert.Equal(t, "comment-id", *input.Key["commentId"].S)
+	assert.Contains(t, *input.ConditionExpression, "attribute_exists")
+	client.AssertExpectations(t)
+}
+
+func TestDeleteComment(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	require.NoError(t, commentsTable(client).DeleteComment(
+		aws.String("alert-id"), aws.String("comment-id"), aws.String("user-id")))
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.DeleteItemInput)
+	assert.Equal(t, "alert-id", *input.Key["alertId"].S)
+	assert.NotNil(t, input.ConditionExpression)
+	client.AssertExpectations(t)
+}
+
+func TestListCommentsPagination(t *testing.T) {
+	client := &mockDynamoDB{}
+	output := &dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"alertId":   {S: aws.String("alert-id")},
+				"commentId": {S: aws.String("comment-id")},
+				"createdAt": {S: aws.String("2020-01-01T00:00:00Z")},
+			},
+		},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{
+			"alertId":   {S: aws.String("alert-id")},
+			"commentId": {S: aws.String("comment-id")},
+			"createdAt": {S: aws.String("2020-01-01T00:00:00Z")},
+		},
+	}
+	client.On("Query", mock.Anything).Return(output, nil).Twice()
+	table := commentsTable(client)
+
+	comments, lastEvaluatedKey, err := table.ListComments(aws.String("alert-id"), nil, aws.Int(1))
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "comment-id", *comments[0].CommentID)
+	require.NotNil(t, lastEvaluatedKey)
+
+	// The returned key resumes the query where the previous page stopped
+	_, _, err = table.ListComments(aws.String("alert-id"), lastEvaluatedKey, aws.Int(1))
+	require.NoError(t, err)
+	input := client.Calls[1].Arguments[0].(*dynamodb.QueryInput)
+	assert.Equal(t, "index", *input.IndexName)
+	assert.Equal(t, output.LastEvaluatedKey, input.ExclusiveStartKey)
+	assert.Equal(t, int64(1), *input.Limit)
+	client.AssertExpectations(t)
+}
+
+func TestListCommentsInvalidKey(t *testing.T) {
+	_, _, err := commentsTable(&mockDynamoDB{}).ListComments(aws.String("alert-id"), aws.String("not json"), nil)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
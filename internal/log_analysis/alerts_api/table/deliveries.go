@@ -0,0 +1,123 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/google/uuid"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// AddDeliveries records the attempts to deliver alerts to their outputs
+//
+// A random delivery ID is generated for the deliveries which do not set it.
+func (table *AlertsTable) AddDeliveries(deliveries []*models.AlertDelivery) error {
+	puts := make([]*dynamodb.WriteRequest, len(deliveries))
+	for i, delivery := range deliveries {
+		if delivery.DeliveryID == nil {
+			delivery.DeliveryID = aws.String(uuid.New().String())
+		}
+
+		item, err := dynamodbattribute.MarshalMap(delivery)
+		if err != nil {
+			return &genericapi.InternalError{Message: "failed to marshal delivery: " + err.Error()}
+		}
+		puts[i] = &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}}
+	}
+
+	if err := dynamodbbatch.BatchWriteItem(table.Client, maxBackoff, &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{table.DeliveriesTableName: puts},
+	}); err != nil {
+		return &genericapi.AWSError{Method: "dynamodbbatch.BatchWriteItem", Err: err}
+	}
+	return nil
+}
+
+// ListDeliveries returns every delivery attempt of an alert in chronological order
+func (table *AlertsTable) ListDeliveries(alertID *string) ([]*models.AlertDelivery, error) {
+	keyCondition := expression.Key("alertId").Equal(expression.Value(alertID))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	var deliveries []*models.AlertDelivery
+	var unmarshalErr error
+	err = table.Client.QueryPages(&dynamodb.QueryInput{
+		TableName:                 aws.String(table.DeliveriesTableName),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var items []*models.AlertDelivery
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false
+		}
+		deliveries = append(deliveries, items...)
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.QueryPages", Err: err}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal deliveries: " + unmarshalErr.Error()}
+	}
+
+	// The sort key is a random ID, the deliveries are ordered by time
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return aws.TimeValue(deliveries[i].Timestamp).Before(aws.TimeValue(deliveries[j].Timestamp))
+	})
+	return deliveries, nil
+}
+
+// ListOutputDeliveries returns the latest delivery attempts to an output, newest first
+func (table *AlertsTable) ListOutputDeliveries(outputID *string, limit int) ([]*models.AlertDelivery, error) {
+	keyCondition := expression.Key("outputId").Equal(expression.Value(outputID))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	queryOutput, err := table.Client.Query(&dynamodb.QueryInput{
+		TableName:                 aws.String(table.DeliveriesTableName),
+		IndexName:                 aws.String(table.DeliveriesOutputIndexName),
+		ScanIndexForward:          aws.Bool(false),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		Limit:                     aws.Int64(int64(limit)),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.Query", Err: err}
+	}
+
+	var deliveries []*models.AlertDelivery
+	if err = dynamodbattribute.UnmarshalListOfMaps(queryOutput.Items, &deliveries); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal deliveries: " + err.Error()}
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,151 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func deliveriesTable(client *mockDynamoDB) *AlertsTable {
+	return &AlertsTable{DeliveriesTableName: "deliveries", DeliveriesOutputIndexName: "index", Client: client}
+}
+
+func TestAddDeliveries(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+
+	delivery := &models.AlertDelivery{
+		AlertID:        aws.String("alert-id"),
+		OutputID:       aws.String("output-id"),
+		Status:         aws.String(models.DeliveryStatusFailure),
+		HTTPStatusCode: aws.Int(503),
+		Error:          aws.String("request failed: 503 Service Unavailable"),
+		Attempt:        aws.Int(2),
+		Timestamp:      aws.Time(time.Now().UTC()),
+	}
+	resolution := &models.AlertDelivery{
+		AlertID:    aws.String("alert-id"),
+		DeliveryID: aws.String("delivery-id"),
+		OutputID:   aws.String("output-id"),
+		Status:     aws.String(models.DeliveryStatusSuccess),
+		Attempt:    aws.Int(1),
+		Timestamp:  aws.Time(time.Now().UTC()),
+		Resolution: aws.Bool(true),
+	}
+	require.NoError(t, deliveriesTable(client).AddDeliveries([]*models.AlertDelivery{delivery, resolution}))
+	require.NotNil(t, delivery.DeliveryID)
+
+	// The deliveries are written in a single batch
+	input := client.Calls[0].Arguments[0].(*dynamodb.BatchWriteItemInput)
+	require.Len(t, input.RequestItems["deliveries"], 2)
+	item := input.RequestItems["deliveries"][0].PutRequest.Item
+	assert.Equal(t, *delivery.DeliveryID, *item["deliveryId"].S)
+	assert.Equal(t, "503", *item["httpStatusCode"].N)
+	assert.Equal(t, "2", *item["attempt"].N)
+	item = input.RequestItems["deliveries"][1].PutRequest.Item
+	assert.Equal(t, "delivery-id", *item["deliveryId"].S)
+	assert.True(t, *item["resolution"].BOOL)
+	client.AssertExpectations(t)
+}
+
+func TestAddDeliveriesFails(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, errors.New("dynamo"))
+
+	err := deliveriesTable(client).AddDeliveries([]*models.AlertDelivery{{AlertID: aws.String("alert-id")}})
+	assert.Error(t, err)
+}
+
+func TestListDeliveries(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("QueryPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*dynamodb.QueryOutput, bool) bool)
+		fn(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"alertId":    {S: aws.String("alert-id")},
+				"deliveryId": {S: aws.String("second")},
+				"timestamp":  {S: aws.String("2020-01-01T00:05:00Z")},
+			},
+		}}, false)
+		fn(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"alertId":    {S: aws.String("alert-id")},
+				"deliveryId": {S: aws.String("first")},
+				"timestamp":  {S: aws.String("2020-01-01T00:00:00Z")},
+			},
+		}}, true)
+	}).Return(nil).Once()
+
+	deliveries, err := deliveriesTable(client).ListDeliveries(aws.String("alert-id"))
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, "first", *deliveries[0].DeliveryID)
+	assert.Equal(t, "second", *deliveries[1].DeliveryID)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.QueryInput)
+	assert.Equal(t, "deliveries", *input.TableName)
+	client.AssertExpectations(t)
+}
+
+func TestListDeliveriesError(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("QueryPages", mock.Anything, mock.Anything).Return(errors.New("service error")).Once()
+
+	deliveries, err := deliveriesTable(client).ListDeliveries(aws.String("alert-id"))
+	assert.Nil(t, deliveries)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	client.AssertExpectations(t)
+}
+
+func TestListOutputDeliveries(t *testing.T) {
+	client := &mockDynamoDB{}
+	output := &dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"alertId":    {S: aws.String("alert-id")},
+				"deliveryId": {S: aws.String("delivery-id")},
+				"outputId":   {S: aws.String("output-id")},
+				"status":     {S: aws.String(models.DeliveryStatusFailure)},
+			},
+		},
+	}
+	client.On("Query", mock.Anything).Return(output, nil).Once()
+
+	deliveries, err := deliveriesTable(client).ListOutputDeliveries(aws.String("output-id"), 3)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.DeliveryStatusFailure, *deliveries[0].Status)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.QueryInput)
+	assert.Equal(t, "index", *input.IndexName)
+	assert.False(t, *input.ScanIndexForward)
+	assert.Equal(t, int64(3), *input.Limit)
+	client.AssertExpectations(t)
+}
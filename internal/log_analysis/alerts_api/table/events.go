@@ -0,0 +1,142 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// The maximum number of events returned when no page size is given, the alert merger stores at most
+	// as many events per alert (the MaxEventsPerAlert parameter)
+	maxListedEvents = 10000
+
+	// The number of event objects read concurrently
+	eventReadConcurrency = 10
+)
+
+// ListEvents returns (a page of the events stored in S3 for an alert, last evaluated key, any error)
+//
+// Events are returned in the order they were matched. If the page size is nil, all the events are returned,
+// up to maxListedEvents. The last evaluated key is the name of the last event object, relative to the prefix
+// of the alert.
+func (table *AlertsTable) ListEvents(alertID, exclusiveStartKey *string, pageSize *int) (
+	events []*string, lastEvaluatedKey *string, err error) {
+
+	if pageSize != nil && *pageSize <= 0 {
+		return nil, nil, &genericapi.InvalidInputError{Message: "the page size of the events must be positive"}
+	}
+
+	prefix := models.EventKeyPrefix(*alertID)
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(table.EventsBucket),
+		Prefix: aws.String(prefix),
+	}
+	if exclusiveStartKey != nil {
+		input.StartAfter = aws.String(prefix + *exclusiveStartKey)
+	}
+
+	var keys []*string
+	if pageSize != nil {
+		input.MaxKeys = aws.Int64(int64(*pageSize))
+		page, err := table.S3Client.ListObjectsV2(input)
+		if err != nil {
+			return nil, nil, &genericapi.AWSError{Method: "s3.ListObjectsV2", Err: err}
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, object.Key)
+		}
+		if aws.BoolValue(page.IsTruncated) && len(keys) > 0 {
+			lastEvaluatedKey = aws.String(strings.TrimPrefix(*keys[len(keys)-1], prefix))
+		}
+	} else {
+		err = table.S3Client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, object := range page.Contents {
+				keys = append(keys, object.Key)
+			}
+			return len(keys) < maxListedEvents
+		})
+		if err != nil {
+			return nil, nil, &genericapi.AWSError{Method: "s3.ListObjectsV2Pages", Err: err}
+		}
+		if len(keys) > maxListedEvents {
+			keys = keys[:maxListedEvents]
+		}
+	}
+
+	events, err = table.getEventObjects(keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events, lastEvaluatedKey, nil
+}
+
+// getEventObjects reads the event objects concurrently, the events are returned in the order of the keys
+func (table *AlertsTable) getEventObjects(keys []*string) ([]*string, error) {
+	events := make([]*string, len(keys))
+	errs := make([]error, len(keys))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < eventReadConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				events[index], errs[index] = table.getEventObject(keys[index])
+			}
+		}()
+	}
+	for index := range keys {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+func (table *AlertsTable) getEventObject(key *string) (*string, error) {
+	object, err := table.S3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(table.EventsBucket),
+		Key:    key,
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "s3.GetObject", Err: err}
+	}
+	defer object.Body.Close()
+
+	body, err := ioutil.ReadAll(object.Body)
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "s3.GetObject", Err: err}
+	}
+	return aws.String(string(body)), nil
+}
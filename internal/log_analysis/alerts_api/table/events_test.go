@@ -0,0 +1,166 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func eventObject(body string) *s3.GetObjectOutput {
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(strings.NewReader(body))}
+}
+
+func TestListEventsPage(t *testing.T) {
+	client := &mockS3{}
+	table := &AlertsTable{EventsBucket: "bucket", S3Client: client}
+
+	client.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{
+			{Key: aws.String("alerts/alert-id/1.json")},
+			{Key: aws.String("alerts/alert-id/2.json")},
+		},
+		IsTruncated: aws.Bool(true),
+	}, nil).Once()
+	client.On("GetObject", &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("alerts/alert-id/1.json")}).
+		Return(eventObject(`{"event": 1}`), nil).Once()
+	client.On("GetObject", &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("alerts/alert-id/2.json")}).
+		Return(eventObject(`{"event": 2}`), nil).Once()
+
+	events, lastEvaluatedKey, err := table.ListEvents(aws.String("alert-id"), aws.String("0.json"), aws.Int(2))
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"event": 1}`, `{"event": 2}`}, aws.StringValueSlice(events))
+	assert.Equal(t, "2.json", *lastEvaluatedKey)
+
+	input := client.Calls[0].Arguments[0].(*s3.ListObjectsV2Input)
+	assert.Equal(t, "alerts/alert-id/", *input.Prefix)
+	assert.Equal(t, "alerts/alert-id/0.json", *input.StartAfter)
+	assert.Equal(t, int64(2), *input.MaxKeys)
+	client.AssertExpectations(t)
+}
+
+func TestListEventsLastPage(t *testing.T) {
+	client := &mockS3{}
+	table := &AlertsTable{EventsBucket: "bucket", S3Client: client}
+
+	client.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents:    []*s3.Object{{Key: aws.String("alerts/alert-id/1.json")}},
+		IsTruncated: aws.Bool(false),
+	}, nil).Once()
+	client.On("GetObject", mock.Anything).Return(eventObject("{}"), nil).Once()
+
+	events, lastEvaluatedKey, err := table.ListEvents(aws.String("alert-id"), nil, aws.Int(2))
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Nil(t, lastEvaluatedKey)
+	client.AssertExpectations(t)
+}
+
+func TestListEventsAll(t *testing.T) {
+	client := &mockS3{}
+	table := &AlertsTable{EventsBucket: "bucket", S3Client: client}
+
+	client.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*s3.ListObjectsV2Output, bool) bool)
+		fn(&s3.ListObjectsV2Output{Contents: []*s3.Object{{Key: aws.String("alerts/alert-id/1.json")}}}, false)
+		fn(&s3.ListObjectsV2Output{Contents: []*s3.Object{{Key: aws.String("alerts/alert-id/2.json")}}}, true)
+	}).Return(nil).Once()
+	client.On("GetObject", mock.Anything).Return(eventObject("{}"), nil).Once()
+	client.On("GetObject", mock.Anything).Return(eventObject("{}"), nil).Once()
+
+	events, lastEvaluatedKey, err := table.ListEvents(aws.String("alert-id"), nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Nil(t, lastEvaluatedKey)
+	client.AssertExpectations(t)
+}
+
+func TestListEventsServiceError(t *testing.T) {
+	client := &mockS3{}
+	table := &AlertsTable{EventsBucket: "bucket", S3Client: client}
+	client.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, errors.New("service error")).Once()
+
+	events, _, err := table.ListEvents(aws.String("alert-id"), nil, aws.Int(2))
+	assert.Nil(t, events)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	client.AssertExpectations(t)
+}
+
+func TestListEventsAllLimit(t *testing.T) {
+	client := &mockS3{}
+	table := &AlertsTable{EventsBucket: "bucket", S3Client: client}
+
+	// The listing stops once the maximum number of events is reached
+	page := &s3.ListObjectsV2Output{Contents: make([]*s3.Object, maxListedEvents/2+1)}
+	for i := range page.Contents {
+		page.Contents[i] = &s3.Object{Key: aws.String("alerts/alert-id/event.json")}
+	}
+	pages := 0
+	client.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*s3.ListObjectsV2Output, bool) bool)
+		for fn(page, false) {
+			pages++
+		}
+	}).Return(nil).Once()
+	client.On("GetObject", mock.Anything).Return(eventObject("{}"), nil).Times(maxListedEvents)
+
+	events, _, err := table.ListEvents(aws.String("alert-id"), nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, events, maxListedEvents)
+	assert.Equal(t, 1, pages)
+	client.AssertExpectations(t)
+}
+
+func TestListEventsReadError(t *testing.T) {
+	client := &mockS3{}
+	table := &AlertsTable{EventsBucket: "bucket", S3Client: client}
+
+	client.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{{Key: aws.String("alerts/alert-id/1.json")}, {Key: aws.String("alerts/alert-id/2.json")}},
+	}, nil).Once()
+	client.On("GetObject", &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("alerts/alert-id/1.json")}).
+		Return(eventObject("{}"), nil).Once()
+	client.On("GetObject", &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("alerts/alert-id/2.json")}).
+		Return((*s3.GetObjectOutput)(nil), errors.New("service error")).Once()
+
+	events, _, err := table.ListEvents(aws.String("alert-id"), nil, aws.Int(2))
+	assert.Nil(t, events)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	client.AssertExpectations(t)
+}
+
+func TestListEventsInvalidPageSize(t *testing.T) {
+	client := &mockS3{}
+	table := &AlertsTable{EventsBucket: "bucket", S3Client: client}
+
+	events, _, err := table.ListEvents(aws.String("alert-id"), nil, aws.Int(0))
+	assert.Nil(t, events)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	client.AssertNotCalled(t, "ListObjectsV2", mock.Anything)
+}
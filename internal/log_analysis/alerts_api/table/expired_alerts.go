@@ -0,0 +1,245 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
+	"github.com/panther-labs/panther/pkg/awsbatch/s3batch"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	maxBackoff = 60 * time.Second
+	// expiryPageSize is the number of alerts read at a time from the expiry index and by the retention scan
+	expiryPageSize = 100
+)
+
+// ListExpiredAlerts calls the handler with each page of the IDs of the alerts whose retention period ended
+//
+// The alerts are read from the expiry index, which only has the alerts with an expiresAt attribute.
+// Listing stops at the first handler error, which is returned.
+func (table *AlertsTable) ListExpiredAlerts(now time.Time, handler func(alertIDs []*string) error) error {
+	for _, partition := range models.AllTimePartitions() {
+		keyCondition := expression.Key("timePartition").Equal(expression.Value(partition)).
+			And(expression.Key("expiresAt").LessThanEqual(expression.Value(now.UTC())))
+		queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+		if err != nil {
+			return &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+		}
+
+		var handlerErr error
+		err = table.Client.QueryPages(&dynamodb.QueryInput{
+			TableName:                 aws.String(table.AlertsTableName),
+			IndexName:                 aws.String(table.TimePartitionExpiresAtIndexName),
+			ExpressionAttributeNames:  queryExpression.Names(),
+			ExpressionAttributeValues: queryExpression.Values(),
+			KeyConditionExpression:    queryExpression.KeyCondition(),
+			Limit:                     aws.Int64(expiryPageSize),
+		}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+			alertIDs := make([]*string, 0, len(page.Items))
+			for _, item := range page.Items {
+				alertIDs = append(alertIDs, item["alertId"].S)
+			}
+			handlerErr = handler(alertIDs)
+			return handlerErr == nil
+		})
+		if err != nil {
+			return &genericapi.AWSError{Method: "dynamodb.QueryPages", Err: err}
+		}
+		if handlerErr != nil {
+			return handlerErr
+		}
+	}
+	return nil
+}
+
+// ScanAlertExpiry returns a page of alerts with the attributes their expiry is computed from
+//
+// The scan resumes after the given alert ID, the returned alert ID is nil after the last page.
+func (table *AlertsTable) ScanAlertExpiry(exclusiveStartKey *string) ([]*models.AlertItem, *string, error) {
+	projection := expression.NamesList(
+		expression.Name("alertId"),
+		expression.Name("creationTime"),
+		expression.Name("severity"),
+		expression.Name("expiresAt"),
+	)
+	scanExpression, err := expression.NewBuilder().WithProjection(projection).Build()
+	if err != nil {
+		return nil, nil, &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:                aws.String(table.AlertsTableName),
+		ExpressionAttributeNames: scanExpression.Names(),
+		ProjectionExpression:     scanExpression.Projection(),
+		Limit:                    aws.Int64(expiryPageSize),
+	}
+	if exclusiveStartKey != nil {
+		input.ExclusiveStartKey = DynamoItem{"alertId": {S: exclusiveStartKey}}
+	}
+	output, err := table.Client.Scan(input)
+	if err != nil {
+		return nil, nil, &genericapi.AWSError{Method: "dynamodb.Scan", Err: err}
+	}
+
+	var alerts []*models.AlertItem
+	if err = dynamodbattribute.UnmarshalListOfMaps(output.Items, &alerts); err != nil {
+		return nil, nil, &genericapi.InternalError{Message: "failed to unmarshal alerts: " + err.Error()}
+	}
+	var lastEvaluatedKey *string
+	if output.LastEvaluatedKey != nil {
+		lastEvaluatedKey = output.LastEvaluatedKey["alertId"].S
+	}
+	return alerts, lastEvaluatedKey, nil
+}
+
+// SetAlertExpiry sets when an alert is archived, a nil expiry keeps the alert indefinitely
+//
+// Alerts deleted in the meantime are not recreated.
+func (table *AlertsTable) SetAlertExpiry(alertID *string, expiresAt *time.Time) error {
+	var update expression.UpdateBuilder
+	if expiresAt == nil {
+		update = update.Remove(expression.Name("expiresAt"))
+	} else {
+		update = update.Set(expression.Name("expiresAt"), expression.Value(expiresAt.UTC()))
+	}
+	condition := expression.AttributeExists(expression.Name("alertId"))
+	updateExpression, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	if _, err = table.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table.AlertsTableName),
+		Key:                       DynamoItem{"alertId": {S: alertID}},
+		ConditionExpression:       updateExpression.Condition(),
+		ExpressionAttributeNames:  updateExpression.Names(),
+		ExpressionAttributeValues: updateExpression.Values(),
+		UpdateExpression:          updateExpression.Update(),
+	}); err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil
+		}
+		return &genericapi.AWSError{Method: "dynamodb.UpdateItem", Err: err}
+	}
+	return nil
+}
+
+// DeleteAlert deletes an alert with its events and comments
+//
+// The alert is deleted last, a failed deletion can be retried.
+func (table *AlertsTable) DeleteAlert(alertID *string) error {
+	if err := table.deleteEvents(alertID); err != nil {
+		return err
+	}
+	if err := table.deleteAlertItems(table.CommentsTableName, "commentId", alertID); err != nil {
+		return err
+	}
+	if err := table.deleteAlertItems(table.DeliveriesTableName, "deliveryId", alertID); err != nil {
+		return err
+	}
+	if err := table.deleteAlertItems(table.HistoryTableName, "changeId", alertID); err != nil {
+		return err
+	}
+
+	if _, err := table.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(table.AlertsTableName),
+		Key:       map[string]*dynamodb.AttributeValue{"alertId": {S: alertID}},
+	}); err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.DeleteItem", Err: err}
+	}
+	return nil
+}
+
+func (table *AlertsTable) deleteEvents(alertID *string) error {
+	var objects []*s3.ObjectIdentifier
+	err := table.S3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(table.EventsBucket),
+		Prefix: aws.String(models.EventKeyPrefix(*alertID)),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			objects = append(objects, &s3.ObjectIdentifier{Key: object.Key})
+		}
+		return true
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "s3.ListObjectsV2Pages", Err: err}
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+
+	if err = s3batch.DeleteObjects(table.S3Client, maxBackoff, &s3.DeleteObjectsInput{
+		Bucket: aws.String(table.EventsBucket),
+		Delete: &s3.Delete{Objects: objects},
+	}); err != nil {
+		return &genericapi.AWSError{Method: "s3batch.DeleteObjects", Err: err}
+	}
+	return nil
+}
+
+// deleteAlertItems deletes the items of an alert in a table keyed by alert ID and the given sort key
+func (table *AlertsTable) deleteAlertItems(tableName, sortKey string, alertID *string) error {
+	keyCondition := expression.Key("alertId").Equal(expression.Value(alertID))
+	projection := expression.NamesList(expression.Name("alertId"), expression.Name(sortKey))
+	queryExpression, err := expression.NewBuilder().
+		WithKeyCondition(keyCondition).
+		WithProjection(projection).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	var deletes []*dynamodb.WriteRequest
+	err = table.Client.QueryPages(&dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		ProjectionExpression:      queryExpression.Projection(),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			deletes = append(deletes, &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: item}})
+		}
+		return true
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.QueryPages", Err: err}
+	}
+	if len(deletes) == 0 {
+		return nil
+	}
+
+	if err = dynamodbbatch.BatchWriteItem(table.Client, maxBackoff, &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{tableName: deletes},
+	}); err != nil {
+		return &genericapi.AWSError{Method: "dynamodbbatch.BatchWriteItem", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func expiredAlertsTable(client *mockDynamoDB, s3Client *mockS3) *AlertsTable {
+	return &AlertsTable{
+		AlertsTableName:     "alerts",
+		CommentsTableName:   "comments",
+		DeliveriesTableName: "deliveries",
+		HistoryTableName:    "history",
+		EventsBucket:        "bucket",
+		Client:              client,
+		S3Client:            s3Client,
+	}
+}
+
+func TestListExpiredAlerts(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := expiredAlertsTable(client, nil)
+	table.TimePartitionExpiresAtIndexName = "expiry-index"
+
+	client.On("QueryPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*dynamodb.QueryOutput, bool) bool)
+		fn(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{alertItem("alert-1")}}, false)
+		fn(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{alertItem("alert-2")}}, true)
+	}).Return(nil).Once()
+	client.On("QueryPages", mock.Anything, mock.Anything).Return(nil).Times(models.TimePartitions - 1)
+
+	var pages [][]string
+	require.NoError(t, table.ListExpiredAlerts(time.Now(), func(alertIDs []*string) error {
+		pages = append(pages, aws.StringValueSlice(alertIDs))
+		return nil
+	}))
+	assert.Equal(t, [][]string{{"alert-1"}, {"alert-2"}}, pages)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.QueryInput)
+	assert.Equal(t, "alerts", *input.TableName)
+	assert.Equal(t, "expiry-index", *input.IndexName)
+	assert.Equal(t, int64(expiryPageSize), *input.Limit)
+	assert.Contains(t, aws.StringValueSlice(mapValues(input.ExpressionAttributeNames)), "expiresAt")
+	client.AssertExpectations(t)
+}
+
+func TestListExpiredAlertsHandlerError(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := expiredAlertsTable(client, nil)
+
+	client.On("QueryPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*dynamodb.QueryOutput, bool) bool)
+		assert.False(t, fn(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{alertItem("alert-1")}}, false))
+	}).Return(nil).Once()
+
+	// The remaining partitions are not read
+	handlerErr := errors.New("archive failed")
+	err := table.ListExpiredAlerts(time.Now(), func(alertIDs []*string) error { return handlerErr })
+	assert.Equal(t, handlerErr, err)
+	client.AssertExpectations(t)
+}
+
+func TestScanAlertExpiry(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := expiredAlertsTable(client, nil)
+
+	client.On("Scan", mock.Anything).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{alertItem("alert-2")},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"alertId": {S: aws.String("alert-2")}},
+	}, nil).Once()
+
+	alerts, lastEvaluatedKey, err := table.ScanAlertExpiry(aws.String("alert-1"))
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "alert-2", *alerts[0].AlertID)
+	assert.Equal(t, aws.String("alert-2"), lastEvaluatedKey)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.ScanInput)
+	assert.Equal(t, "alert-1", *input.ExclusiveStartKey["alertId"].S)
+	assert.NotNil(t, input.ProjectionExpression)
+	client.AssertExpectations(t)
+}
+
+func TestSetAlertExpiry(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := expiredAlertsTable(client, nil)
+
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	require.NoError(t, table.SetAlertExpiry(aws.String("alert-id"), aws.Time(time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC))))
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "alert-id", *input.Key["alertId"].S)
+	assert.NotNil(t, input.ConditionExpression)
+	assert.Contains(t, *input.UpdateExpression, "SET")
+	client.AssertExpectations(t)
+}
+
+func TestSetAlertExpiryIndefinitely(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := expiredAlertsTable(client, nil)
+
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	require.NoError(t, table.SetAlertExpiry(aws.String("alert-id"), nil))
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *input.UpdateExpression, "REMOVE")
+	client.AssertExpectations(t)
+}
+
+func TestSetAlertExpiryDeleted(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := expiredAlertsTable(client, nil)
+
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil)).Once()
+
+	// Deleted alerts are skipped
+	assert.NoError(t, table.SetAlertExpiry(aws.String("alert-id"), nil))
+	client.AssertExpectations(t)
+}
+
+func TestDeleteAlert(t *testing.T) {
+	client := &mockDynamoDB{}
+	s3Client := &mockS3{}
+	table := expiredAlertsTable(client, s3Client)
+
+	s3Client.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*s3.ListObjectsV2Output, bool) bool)
+		fn(&s3.ListObjectsV2Output{Contents: []*s3.Object{{Key: aws.String("alerts/alert-id/1.json")}}}, true)
+	}).Return(nil).Once()
+	s3Client.On("DeleteObjects", mock.Anything).Return(&s3.DeleteObjectsOutput{}, nil).Once()
+	client.On("QueryPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*dynamodb.QueryOutput, bool) bool)
+		fn(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{
+			{"alertId": {S: aws.String("alert-id")}, "commentId": {S: aws.String("comment-id")}},
+		}}, true)
+	}).Return(nil).Once()
+	client.On("QueryPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*dynamodb.QueryOutput, bool) bool)
+		fn(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{
+			{"alertId": {S: aws.String("alert-id")}, "deliveryId": {S: aws.String("delivery-id")}},
+		}}, true)
+	}).Return(nil).Once()
+	client.On("QueryPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*dynamodb.QueryOutput, bool) bool)
+		fn(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{
+			{"alertId": {S: aws.String("alert-id")}, "changeId": {S: aws.String("change-id")}},
+		}}, true)
+	}).Return(nil).Once()
+	client.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, nil).Times(3)
+	client.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	require.NoError(t, table.DeleteAlert(aws.String("alert-id")))
+
+	deleteObjects := s3Client.Calls[1].Arguments[0].(*s3.DeleteObjectsInput)
+	assert.Equal(t, "alerts/alert-id/1.json", *deleteObjects.Delete.Objects[0].Key)
+	batchWrite := client.Calls[1].Arguments[0].(*dynamodb.BatchWriteItemInput)
+	assert.Len(t, batchWrite.RequestItems["comments"], 1)
+	batchWrite = client.Calls[3].Arguments[0].(*dynamodb.BatchWriteItemInput)
+	assert.Equal(t, "delivery-id", *batchWrite.RequestItems["deliveries"][0].DeleteRequest.Key["deliveryId"].S)
+	batchWrite = client.Calls[5].Arguments[0].(*dynamodb.BatchWriteItemInput)
+	assert.Equal(t, "change-id", *batchWrite.RequestItems["history"][0].DeleteRequest.Key["changeId"].S)
+	client.AssertExpectations(t)
+	s3Client.AssertExpectations(t)
+}
+
+func TestDeleteAlertEventsError(t *testing.T) {
+	client := &mockDynamoDB{}
+	s3Client := &mockS3{}
+	table := expiredAlertsTable(client, s3Client)
+
+	s3Client.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Return(errors.New("service error")).Once()
+
+	// The alert is kept when its events can not be deleted
+	err := table.DeleteAlert(aws.String("alert-id"))
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	client.AssertExpectations(t)
+	s3Client.AssertExpectations(t)
+}
+
+func mapValues(values map[string]*string) []*string {
+	result := make([]*string, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+	}
+	return result
+}
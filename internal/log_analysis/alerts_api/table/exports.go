@@ -0,0 +1,64 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// PutExportJob creates or replaces an export job
+func (table *AlertsTable) PutExportJob(job *models.ExportJob) error {
+	item, err := dynamodbattribute.MarshalMap(job)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal export job: " + err.Error()}
+	}
+
+	if _, err = table.Client.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(table.ExportsTableName),
+		Item:      item,
+	}); err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.PutItem", Err: err}
+	}
+	return nil
+}
+
+// GetExportJob returns an export job, or nil if it does not exist
+func (table *AlertsTable) GetExportJob(jobID *string) (*models.ExportJob, error) {
+	response, err := table.Client.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(table.ExportsTableName),
+		Key:       map[string]*dynamodb.AttributeValue{"jobId": {S: jobID}},
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.GetItem", Err: err}
+	}
+	if len(response.Item) == 0 {
+		return nil, nil
+	}
+
+	job := &models.ExportJob{}
+	if err = dynamodbattribute.UnmarshalMap(response.Item, job); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal export job: " + err.Error()}
+	}
+	return job, nil
+}
@@ -0,0 +1,69 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+)
+
+func TestPutGetExportJob(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{ExportsTableName: "exports", Client: client}
+	job := &models.ExportJob{
+		JobID:           aws.String("job-id"),
+		Status:          aws.String(models.ExportStatusPending),
+		Format:          aws.String(models.ExportFormatCSV),
+		CreatedAtAfter:  aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		CreatedAtBefore: aws.Time(time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)),
+		Severity:        aws.StringSlice([]string{"HIGH"}),
+		ExpiresAt:       1585699200,
+	}
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	require.NoError(t, table.PutExportJob(job))
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.PutItemInput)
+	assert.Equal(t, "exports", *input.TableName)
+	assert.Equal(t, "1585699200", *input.Item["expiresAt"].N)
+
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: input.Item}, nil).Once()
+	result, err := table.GetExportJob(aws.String("job-id"))
+	require.NoError(t, err)
+	assert.Equal(t, job, result)
+	assert.Equal(t, "job-id", *client.Calls[1].Arguments[0].(*dynamodb.GetItemInput).Key["jobId"].S)
+	client.AssertExpectations(t)
+}
+
+func TestGetExportJobDoesNotExist(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+	result, err := (&AlertsTable{ExportsTableName: "exports", Client: client}).GetExportJob(aws.String("job-id"))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	client.AssertExpectations(t)
+}
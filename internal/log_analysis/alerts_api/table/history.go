@@ -0,0 +1,92 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/google/uuid"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// AddAlertChange stores a change in the history of an alert without modifying the alert
+//
+// This is used by other services to surface their actions in the activity timeline of the alert.
+func (table *AlertsTable) AddAlertChange(alertID *string, change *models.AlertChange) error {
+	change.AlertID = alertID
+	change.ChangeID = aws.String(uuid.New().String())
+
+	item, err := dynamodbattribute.MarshalMap(change)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal change: " + err.Error()}
+	}
+
+	if _, err = table.Client.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(table.HistoryTableName),
+		Item:      item,
+	}); err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.PutItem", Err: err}
+	}
+	return nil
+}
+
+// GetAlertHistory returns every change of an alert in chronological order
+//
+// Alerts changed before the history was stored separately keep their earlier changes in their item.
+func (table *AlertsTable) GetAlertHistory(alertItem *models.AlertItem) ([]*models.AlertChange, error) {
+	keyCondition := expression.Key("alertId").Equal(expression.Value(alertItem.AlertID))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	history := append([]*models.AlertChange{}, alertItem.History...)
+	var unmarshalErr error
+	err = table.Client.QueryPages(&dynamodb.QueryInput{
+		TableName:                 aws.String(table.HistoryTableName),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var items []*models.AlertChange
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false
+		}
+		history = append(history, items...)
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.QueryPages", Err: err}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal changes: " + unmarshalErr.Error()}
+	}
+
+	// The sort key is a random ID, the changes are ordered by time
+	sort.SliceStable(history, func(i, j int) bool {
+		return aws.TimeValue(history[i].Timestamp).Before(aws.TimeValue(history[j].Timestamp))
+	})
+	return history, nil
+}
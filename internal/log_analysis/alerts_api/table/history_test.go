@@ -0,0 +1,100 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestAddAlertChange(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{HistoryTableName: "history", Client: client}
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	change := &models.AlertChange{Action: aws.String(models.ActionResend), Timestamp: aws.Time(time.Now().UTC())}
+	require.NoError(t, table.AddAlertChange(aws.String("alert-id"), change))
+	require.NotNil(t, change.ChangeID)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.PutItemInput)
+	assert.Equal(t, "history", *input.TableName)
+	assert.Equal(t, "alert-id", *input.Item["alertId"].S)
+	assert.Equal(t, *change.ChangeID, *input.Item["changeId"].S)
+	client.AssertExpectations(t)
+}
+
+func TestGetAlertHistory(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{HistoryTableName: "history", Client: client}
+	client.On("QueryPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*dynamodb.QueryOutput, bool) bool)
+		fn(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"alertId":   {S: aws.String("alert-id")},
+				"changeId":  {S: aws.String("third")},
+				"timestamp": {S: aws.String("2020-01-01T00:10:00Z")},
+			},
+			{
+				"alertId":   {S: aws.String("alert-id")},
+				"changeId":  {S: aws.String("second")},
+				"timestamp": {S: aws.String("2020-01-01T00:05:00Z")},
+			},
+		}}, true)
+	}).Return(nil).Once()
+
+	// The changes stored in the item by earlier versions come first
+	alertItem := &models.AlertItem{
+		AlertID: aws.String("alert-id"),
+		History: []*models.AlertChange{
+			{Action: aws.String(models.ActionAssign), Timestamp: aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))},
+		},
+	}
+	history, err := table.GetAlertHistory(alertItem)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, models.ActionAssign, *history[0].Action)
+	assert.Equal(t, "second", *history[1].ChangeID)
+	assert.Equal(t, "third", *history[2].ChangeID)
+	assert.Len(t, alertItem.History, 1)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.QueryInput)
+	assert.Equal(t, "history", *input.TableName)
+	client.AssertExpectations(t)
+}
+
+func TestGetAlertHistoryError(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{HistoryTableName: "history", Client: client}
+	client.On("QueryPages", mock.Anything, mock.Anything).Return(errors.New("service error")).Once()
+
+	history, err := table.GetAlertHistory(&models.AlertItem{AlertID: aws.String("alert-id")})
+	assert.Nil(t, history)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	client.AssertExpectations(t)
+}
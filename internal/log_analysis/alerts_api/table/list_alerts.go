@@ -19,106 +19,336 @@ package table
  */
 
 import (
-	"fmt"
-
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 	jsoniter "github.com/json-iterator/go"
-	"github.com/pkg/errors"
-	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/lambda/alerts/models"
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
-// ListAlerts returns (a page of alerts, last evaluated key, any error)
-func (table *AlertsTable) ListAlerts(exclusiveStartKey *string, pageSize *int) (
+const (
+	sortDirAscending    = "ascending"
+	defaultListPageSize = 25
+
+	// listAlertsQueryLimit is the number of alerts read by each query, before the filters are applied
+	listAlertsQueryLimit = 50
+	// maxListAlertsRead bounds the number of alerts read to fill a page, a shorter page is returned beyond it
+	maxListAlertsRead = 2000
+)
+
+// alertsPartition is the state of the search in an index partition
+type alertsPartition struct {
+	// name is the value of the partition key, keyName
+	name    string
+	keyName string
+	query   *dynamodb.QueryInput
+	// startKey is where the next query starts, the last evaluated key of the previous one
+	startKey map[string]*dynamodb.AttributeValue
+	// items were read and matched the filters, but are not returned yet
+	items []map[string]*dynamodb.AttributeValue
+	// lastReturnedKey is the key of the last alert of the partition which was returned
+	lastReturnedKey map[string]*dynamodb.AttributeValue
+	done            bool
+}
+
+// ListAlerts returns (a page of alerts matching the input, last evaluated key, any error)
+//
+// The alerts are queried from indices sorted by creation time. The alerts of a rule have their own partition,
+// the other alerts are spread over several partitions which are merged. The other filters are applied by Dynamo
+// after it reads the alerts, so the partitions are read until the page is full or maxListAlertsRead alerts
+// were read. The page may then be shorter, the last evaluated key resumes the search.
+func (table *AlertsTable) ListAlerts(input *models.ListAlertsInput) (
 	summaries []*models.AlertItem, lastEvaluatedKey *string, err error) {
 
-	var scanLimit *int64
-	if pageSize != nil {
-		scanLimit = aws.Int64(int64(*pageSize))
+	cursor := &listAlertsCursor{}
+	if input.ExclusiveStartKey != nil {
+		if err = jsoniter.UnmarshalFromString(*input.ExclusiveStartKey, cursor); err != nil {
+			return nil, nil, &genericapi.InvalidInputError{Message: "invalid exclusiveStartKey: " + err.Error()}
+		}
 	}
 
-	var scanExclusiveStartKey map[string]*dynamodb.AttributeValue
-	if exclusiveStartKey != nil {
-		paginationKey := &listAlertsPaginationKey{}
-		err = jsoniter.UnmarshalFromString(*exclusiveStartKey, paginationKey)
-		if err != nil {
-			errMsg := "failed to unmarshal ddb start key"
-			err = errors.Wrap(err, errMsg)
-			zap.L().Error(errMsg, zap.Error(err))
-			return nil, nil, err
+	partitions, err := table.listAlertsPartitions(input, cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pageSize := defaultListPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+	ascending := aws.StringValue(input.SortDir) == sortDirAscending
+
+	read := int64(0)
+page:
+	for len(summaries) < pageSize {
+		for _, partition := range partitions {
+			for len(partition.items) == 0 && !partition.done && read < maxListAlertsRead {
+				if read, err = table.readAlertsPartition(partition, read); err != nil {
+					return nil, nil, err
+				}
+			}
+			if len(partition.items) == 0 && !partition.done {
+				// The next alert is unknown until the partition is read further
+				break page
+			}
 		}
-		scanExclusiveStartKey = map[string]*dynamodb.AttributeValue{
-			"creationTime": {S: paginationKey.CreationTime},
-			"alertId":      {S: paginationKey.AlertID},
+
+		next := nextAlertsPartition(partitions, ascending)
+		if next == nil {
+			break
 		}
-	}
+		item := next.items[0]
+		next.items = next.items[1:]
+		next.lastReturnedKey = indexKey(item, next.keyName)
 
-	var scanInput = &dynamodb.ScanInput{
-		TableName:         aws.String(table.AlertsTableName),
-		ExclusiveStartKey: scanExclusiveStartKey,
-		Limit:             scanLimit,
+		summary := &models.AlertItem{}
+		if err = dynamodbattribute.UnmarshalMap(item, summary); err != nil {
+			return nil, nil, &genericapi.InternalError{Message: "failed to unmarshal alerts: " + err.Error()}
+		}
+		summaries = append(summaries, summary)
 	}
 
-	// TODO: Sort this by time (scan does not guarantee sortedness)
-	scanOutput, err := table.Client.Scan(scanInput)
-	if err != nil {
-		errMsg := "scan to DDB failed" + err.Error()
-		err = errors.WithStack(&genericapi.InternalError{
-			Message: errMsg,
-		})
-		zap.L().Error(errMsg, zap.Error(err))
+	if lastEvaluatedKey, err = marshalCursor(partitions); err != nil {
 		return nil, nil, err
 	}
+	return summaries, lastEvaluatedKey, nil
+}
+
+// listAlertsPartitions returns the index partitions to search, where the cursor stopped
+func (table *AlertsTable) listAlertsPartitions(
+	input *models.ListAlertsInput, cursor *listAlertsCursor) ([]*alertsPartition, error) {
+
+	keyName, names := "ruleId", []string{aws.StringValue(input.RuleID)}
+	if input.RuleID == nil {
+		keyName, names = "timePartition", models.AllTimePartitions()
+	}
 
-	err = dynamodbattribute.UnmarshalListOfMaps(scanOutput.Items, &summaries)
-	if err != nil {
-		errMsg := "failed to marshal response" + err.Error()
-		err = errors.WithStack(&genericapi.InternalError{
-			Message: errMsg,
-		})
-		zap.L().Error(errMsg, zap.Error(err))
-		return nil, nil, err
+	positions := make(map[string]*partitionPosition, len(cursor.Partitions))
+	for _, position := range cursor.Partitions {
+		positions[position.Partition] = position
 	}
 
-	// If DDB returned a LastEvaluatedKey, it means there are more alerts to be returned
-	// Return populated `lastEvaluatedKey` in the response.
-	if len(scanOutput.LastEvaluatedKey) > 0 {
-		// ensure expected keys are there, fail if not
-		for _, key := range []string{"creationTime", "alertId"} {
-			if _, exists := scanOutput.LastEvaluatedKey[key]; !exists {
-				errMsg := fmt.Sprintf("cannot find expected key "+key+" in ddb record: %#v",
-					scanOutput.LastEvaluatedKey)
-				err := errors.WithStack(&genericapi.InternalError{
-					Message: errMsg,
-				})
-				zap.L().Error(errMsg, zap.Error(err))
-				return nil, nil, err
+	partitions := make([]*alertsPartition, 0, len(names))
+	for _, name := range names {
+		query, err := table.listAlertsQuery(input, name)
+		if err != nil {
+			return nil, err
+		}
+		partition := &alertsPartition{name: name, keyName: keyName, query: query}
+		if position := positions[name]; position != nil {
+			if position.Key != nil {
+				if partition.startKey, err = dynamodbattribute.MarshalMap(position.Key); err != nil {
+					return nil, &genericapi.InternalError{Message: "failed to marshal key: " + err.Error()}
+				}
 			}
+			partition.lastReturnedKey, partition.done = partition.startKey, position.Done
+		}
+		partitions = append(partitions, partition)
+	}
+	return partitions, nil
+}
+
+// readAlertsPartition reads the next alerts of a partition, it returns the total number of alerts read
+func (table *AlertsTable) readAlertsPartition(partition *alertsPartition, read int64) (int64, error) {
+	query := *partition.query
+	query.ExclusiveStartKey = partition.startKey
+	output, err := table.Client.Query(&query)
+	if err != nil {
+		return read, &genericapi.AWSError{Method: "dynamodb.Query", Err: err}
+	}
+	partition.items = output.Items
+	partition.startKey = output.LastEvaluatedKey
+	partition.done = len(output.LastEvaluatedKey) == 0
+	if len(partition.items) == 0 {
+		// The alerts read so far did not match the filters, the search resumes after them
+		partition.lastReturnedKey = output.LastEvaluatedKey
+	}
+	return read + aws.Int64Value(output.ScannedCount), nil
+}
+
+// nextAlertsPartition returns the partition of the next alert in creation time order, nil if there is none
+func nextAlertsPartition(partitions []*alertsPartition, ascending bool) *alertsPartition {
+	var next *alertsPartition
+	for _, partition := range partitions {
+		if len(partition.items) == 0 {
+			continue
 		}
-		paginationKey := listAlertsPaginationKey{
-			CreationTime: scanOutput.LastEvaluatedKey["creationTime"].S,
-			AlertID:      scanOutput.LastEvaluatedKey["alertId"].S,
+		if next == nil {
+			next = partition
+			continue
 		}
-		marshalledKey, err := jsoniter.MarshalToString(paginationKey)
-		if err != nil {
-			errMsg := "failed to marshall key" + err.Error()
-			err = errors.WithStack(&genericapi.InternalError{
-				Message: errMsg,
-			})
-			zap.L().Error(errMsg, zap.Error(err))
-			return nil, nil, err
+		creationTime := aws.StringValue(partition.items[0]["creationTime"].S)
+		nextCreationTime := aws.StringValue(next.items[0]["creationTime"].S)
+		if (ascending && creationTime < nextCreationTime) || (!ascending && creationTime > nextCreationTime) {
+			next = partition
 		}
-		lastEvaluatedKey = &marshalledKey
 	}
+	return next
+}
 
-	return summaries, lastEvaluatedKey, nil
+// indexKey returns the key of an alert in the index partitioned by keyName
+func indexKey(item map[string]*dynamodb.AttributeValue, keyName string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"alertId":      item["alertId"],
+		keyName:        item[keyName],
+		"creationTime": item["creationTime"],
+	}
+}
+
+// marshalCursor returns the key resuming the search where it stopped, nil if all the partitions were read
+func marshalCursor(partitions []*alertsPartition) (*string, error) {
+	cursor := &listAlertsCursor{}
+	more := false
+	for _, partition := range partitions {
+		position := &partitionPosition{Partition: partition.name}
+		cursor.Partitions = append(cursor.Partitions, position)
+		if partition.done && len(partition.items) == 0 {
+			position.Done = true
+			continue
+		}
+		more = true
+		if partition.lastReturnedKey == nil {
+			continue
+		}
+		position.Key = &listAlertsPaginationKey{}
+		if err := dynamodbattribute.UnmarshalMap(partition.lastReturnedKey, position.Key); err != nil {
+			return nil, &genericapi.InternalError{Message: "failed to unmarshal key: " + err.Error()}
+		}
+	}
+	if !more {
+		return nil, nil
+	}
+
+	marshalledCursor, err := jsoniter.MarshalToString(cursor)
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to marshal key: " + err.Error()}
+	}
+	return &marshalledCursor, nil
+}
+
+// listAlertsQuery selects the index and builds the expressions for the search in a partition
+func (table *AlertsTable) listAlertsQuery(input *models.ListAlertsInput, partition string) (*dynamodb.QueryInput, error) {
+	var keyCondition expression.KeyConditionBuilder
+	var indexName string
+	if input.RuleID != nil {
+		keyCondition = expression.Key("ruleId").Equal(expression.Value(partition))
+		indexName = table.RuleIDCreationTimeIndexName
+	} else {
+		keyCondition = expression.Key("timePartition").Equal(expression.Value(partition))
+		indexName = table.TimePartitionCreationTimeIndexName
+	}
+	if timeRange, ok := creationTimeKeyCondition(input); ok {
+		keyCondition = keyCondition.And(timeRange)
+	}
+
+	var filters []expression.ConditionBuilder
+	if len(input.Status) > 0 {
+		filters = append(filters, statusFilter(input.Status))
+	}
+	if len(input.Severity) > 0 {
+		filters = append(filters, anyEqual("severity", input.Severity))
+	}
+	if len(input.LogTypes) > 0 {
+		filters = append(filters, anyContains("logTypes", input.LogTypes))
+	}
+	if len(input.Tags) > 0 {
+		filters = append(filters, anyContains("tags", input.Tags))
+	}
+	if input.TitleContains != nil {
+		filters = append(filters, expression.Name("title").Contains(*input.TitleContains))
+	}
+
+	builder := expression.NewBuilder().WithKeyCondition(keyCondition)
+	if len(filters) > 0 {
+		builder = builder.WithFilter(and(filters))
+	}
+	queryExpression, err := builder.Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	return &dynamodb.QueryInput{
+		TableName:                 aws.String(table.AlertsTableName),
+		IndexName:                 aws.String(indexName),
+		ScanIndexForward:          aws.Bool(aws.StringValue(input.SortDir) == sortDirAscending),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		FilterExpression:          queryExpression.Filter(),
+		Limit:                     aws.Int64(listAlertsQueryLimit),
+	}, nil
+}
+
+func creationTimeKeyCondition(input *models.ListAlertsInput) (expression.KeyConditionBuilder, bool) {
+	key := expression.Key("creationTime")
+	switch {
+	case input.CreatedAtAfter != nil && input.CreatedAtBefore != nil:
+		return key.Between(expression.Value(input.CreatedAtAfter), expression.Value(input.CreatedAtBefore)), true
+	case input.CreatedAtAfter != nil:
+		return key.GreaterThanEqual(expression.Value(input.CreatedAtAfter)), true
+	case input.CreatedAtBefore != nil:
+		return key.LessThanEqual(expression.Value(input.CreatedAtBefore)), true
+	default:
+		return expression.KeyConditionBuilder{}, false
+	}
+}
+
+// anyEqual matches the items whose attribute is equal to any of the values
+func anyEqual(name string, values []*string) expression.ConditionBuilder {
+	operands := make([]expression.OperandBuilder, 0, len(values))
+	for _, value := range values {
+		operands = append(operands, expression.Value(value))
+	}
+	if len(operands) == 1 {
+		return expression.Name(name).Equal(operands[0])
+	}
+	return expression.Name(name).In(operands[0], operands[1:]...)
+}
+
+// anyContains matches the items whose list attribute contains any of the values
+func anyContains(name string, values []*string) expression.ConditionBuilder {
+	conditions := make([]expression.ConditionBuilder, 0, len(values))
+	for _, value := range values {
+		conditions = append(conditions, expression.Name(name).Contains(*value))
+	}
+	return or(conditions)
+}
+
+func and(conditions []expression.ConditionBuilder) expression.ConditionBuilder {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return expression.And(conditions[0], conditions[1], conditions[2:]...)
+}
+
+func or(conditions []expression.ConditionBuilder) expression.ConditionBuilder {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return expression.Or(conditions[0], conditions[1], conditions[2:]...)
+}
+
+// listAlertsCursor is the position of the search in each partition, it is the last evaluated key of ListAlerts
+type listAlertsCursor struct {
+	Partitions []*partitionPosition `json:"partitions"`
+}
+
+// partitionPosition is the position of the search in a partition
+type partitionPosition struct {
+	Partition string `json:"partition"`
+	// Key is the key of the last alert read in the partition, the partition is read from the start without it
+	Key *listAlertsPaginationKey `json:"key,omitempty"`
+	// Done is set once the partition was read entirely
+	Done bool `json:"done,omitempty"`
 }
 
+// listAlertsPaginationKey holds the keys of the table and of all the indices used to list alerts
 type listAlertsPaginationKey struct {
-	CreationTime *string `json:"creationTime"`
-	AlertID      *string `json:"alertId"`
+	AlertID       *string `json:"alertId"`
+	RuleID        *string `json:"ruleId,omitempty"`
+	TimePartition *string `json:"timePartition,omitempty"`
+	CreationTime  *string `json:"creationTime"`
 }
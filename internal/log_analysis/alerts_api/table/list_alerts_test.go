@@ -0,0 +1,289 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func listAlertsTable(client *mockDynamoDB) *AlertsTable {
+	return &AlertsTable{
+		AlertsTableName:                    "alerts",
+		RuleIDCreationTimeIndexName:        "rule-index",
+		TimePartitionCreationTimeIndexName: "time-index",
+		Client:                             client,
+	}
+}
+
+// expressionNames returns the attribute names referenced by the query
+func expressionNames(input *dynamodb.QueryInput) []string {
+	var names []string
+	for _, name := range input.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	return names
+}
+
+func alertItem(alertID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{"alertId": {S: aws.String(alertID)}}
+}
+
+// indexedAlertItem is an alert with the attributes of the index keys
+func indexedAlertItem(alertID, partition, creationTime string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"alertId":       {S: aws.String(alertID)},
+		"ruleId":        {S: aws.String("rule-id")},
+		"timePartition": {S: aws.String(partition)},
+		"creationTime":  {S: aws.String(creationTime)},
+	}
+}
+
+// inPartition matches the queries of an index partition
+func inPartition(partition string) interface{} {
+	return mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		for _, value := range input.ExpressionAttributeValues {
+			if aws.StringValue(value.S) == partition {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func TestListAlertsDefault(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{}, nil).Times(models.TimePartitions)
+
+	_, lastEvaluatedKey, err := listAlertsTable(client).ListAlerts(&models.ListAlertsInput{})
+	require.NoError(t, err)
+	assert.Nil(t, lastEvaluatedKey)
+
+	var partitions []string
+	for _, call := range client.Calls {
+		input := call.Arguments[0].(*dynamodb.QueryInput)
+		assert.Equal(t, "time-index", *input.IndexName)
+		assert.False(t, *input.ScanIndexForward)
+		assert.Equal(t, int64(listAlertsQueryLimit), *input.Limit)
+		assert.Nil(t, input.FilterExpression)
+		assert.ElementsMatch(t, []string{"timePartition"}, expressionNames(input))
+		partitions = append(partitions, *input.ExpressionAttributeValues[":0"].S)
+	}
+	assert.Equal(t, models.AllTimePartitions(), partitions)
+	client.AssertExpectations(t)
+}
+
+func TestListAlertsByRuleTimeRange(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{}, nil).Once()
+
+	_, _, err := listAlertsTable(client).ListAlerts(&models.ListAlertsInput{
+		RuleID:          aws.String("rule-id"),
+		CreatedAtAfter:  aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		CreatedAtBefore: aws.Time(time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)),
+		SortDir:         aws.String("ascending"),
+	})
+	require.NoError(t, err)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.QueryInput)
+	assert.Equal(t, "rule-index", *input.IndexName)
+	assert.True(t, *input.ScanIndexForward)
+	assert.Contains(t, *input.KeyConditionExpression, "BETWEEN")
+	assert.Nil(t, input.FilterExpression)
+	client.AssertExpectations(t)
+}
+
+func TestListAlertsFilters(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{}, nil).Once()
+
+	_, _, err := listAlertsTable(client).ListAlerts(&models.ListAlertsInput{
+		RuleID:         aws.String("rule-id"),
+		Severity:       aws.StringSlice([]string{"HIGH", "CRITICAL"}),
+		Status:         aws.StringSlice([]string{models.StatusTriaged}),
+		LogTypes:       aws.StringSlice([]string{"AWS.CloudTrail"}),
+		Tags:           aws.StringSlice([]string{"IAM", "S3"}),
+		TitleContains:  aws.String("Root"),
+		CreatedAtAfter: aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	require.NoError(t, err)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.QueryInput)
+	assert.Equal(t, "rule-index", *input.IndexName)
+	assert.Contains(t, *input.KeyConditionExpression, ">=")
+	require.NotNil(t, input.FilterExpression)
+	assert.Contains(t, *input.FilterExpression, " IN ")
+	assert.Contains(t, *input.FilterExpression, "contains")
+	assert.NotContains(t, *input.FilterExpression, "attribute_not_exists")
+	assert.ElementsMatch(t, []string{
+		"ruleId", "creationTime", "status", "severity", "logTypes", "tags", "title",
+	}, expressionNames(input))
+	client.AssertExpectations(t)
+}
+
+func TestListAlertsOpenStatus(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{}, nil).Times(models.TimePartitions)
+
+	_, _, err := listAlertsTable(client).ListAlerts(&models.ListAlertsInput{
+		Status: aws.StringSlice([]string{models.StatusOpen}),
+	})
+	require.NoError(t, err)
+
+	// Alerts created before status tracking are open
+	input := client.Calls[0].Arguments[0].(*dynamodb.QueryInput)
+	require.NotNil(t, input.FilterExpression)
+	assert.Contains(t, *input.FilterExpression, "attribute_not_exists")
+	client.AssertExpectations(t)
+}
+
+func TestListAlertsMergesPartitions(t *testing.T) {
+	client := &mockDynamoDB{}
+	partitions := models.AllTimePartitions()
+	first := indexedAlertItem("alert-1", partitions[0], "2020-01-01T01:00:00Z")
+	third := indexedAlertItem("alert-3", partitions[0], "2020-01-01T03:00:00Z")
+	client.On("Query", inPartition(partitions[0])).Return(&dynamodb.QueryOutput{
+		Items:        []map[string]*dynamodb.AttributeValue{third, first},
+		ScannedCount: aws.Int64(2),
+	}, nil).Once()
+	client.On("Query", inPartition(partitions[1])).Return(&dynamodb.QueryOutput{
+		Items:        []map[string]*dynamodb.AttributeValue{indexedAlertItem("alert-2", partitions[1], "2020-01-01T02:00:00Z")},
+		ScannedCount: aws.Int64(1),
+	}, nil).Once()
+	client.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{}, nil).Twice()
+
+	// The most recent alerts of all the partitions are returned first
+	table := listAlertsTable(client)
+	alerts, lastEvaluatedKey, err := table.ListAlerts(&models.ListAlertsInput{PageSize: aws.Int(2)})
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "alert-3", *alerts[0].AlertID)
+	assert.Equal(t, "alert-2", *alerts[1].AlertID)
+	require.NotNil(t, lastEvaluatedKey)
+	client.AssertExpectations(t)
+
+	// Only the partition with alerts left is read again, after the last alert returned
+	client.On("Query", inPartition(partitions[0])).Return(&dynamodb.QueryOutput{
+		Items:        []map[string]*dynamodb.AttributeValue{first},
+		ScannedCount: aws.Int64(1),
+	}, nil).Once()
+	alerts, lastEvaluatedKey, err = table.ListAlerts(&models.ListAlertsInput{PageSize: aws.Int(2), ExclusiveStartKey: lastEvaluatedKey})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "alert-1", *alerts[0].AlertID)
+	assert.Nil(t, lastEvaluatedKey)
+
+	require.Len(t, client.Calls, 5)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"alertId":       {S: aws.String("alert-3")},
+		"timePartition": {S: aws.String(partitions[0])},
+		"creationTime":  {S: aws.String("2020-01-01T03:00:00Z")},
+	}, client.Calls[4].Arguments[0].(*dynamodb.QueryInput).ExclusiveStartKey)
+	client.AssertExpectations(t)
+}
+
+func TestListAlertsFillsPage(t *testing.T) {
+	client := &mockDynamoDB{}
+	lastKey := map[string]*dynamodb.AttributeValue{
+		"alertId":      {S: aws.String("alert-1")},
+		"ruleId":       {S: aws.String("rule-id")},
+		"creationTime": {S: aws.String("2020-01-01T00:00:00Z")},
+	}
+	// The filter dropped all the alerts of the first read
+	client.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{
+		LastEvaluatedKey: lastKey,
+		ScannedCount:     aws.Int64(listAlertsQueryLimit),
+	}, nil).Once()
+	client.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			indexedAlertItem("alert-2", "", "2020-01-01T00:00:00Z"), indexedAlertItem("alert-3", "", "2020-01-01T00:00:00Z"),
+		},
+		ScannedCount: aws.Int64(2),
+	}, nil).Once()
+
+	alerts, lastEvaluatedKey, err := listAlertsTable(client).ListAlerts(&models.ListAlertsInput{
+		RuleID:   aws.String("rule-id"),
+		PageSize: aws.Int(2),
+		Severity: aws.StringSlice([]string{"HIGH"}),
+	})
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "alert-2", *alerts[0].AlertID)
+	assert.Nil(t, lastEvaluatedKey)
+
+	// The query limit does not depend on the space left in the page
+	first := client.Calls[0].Arguments[0].(*dynamodb.QueryInput)
+	second := client.Calls[1].Arguments[0].(*dynamodb.QueryInput)
+	assert.Equal(t, int64(listAlertsQueryLimit), *first.Limit)
+	assert.Equal(t, int64(listAlertsQueryLimit), *second.Limit)
+	assert.Equal(t, lastKey, second.ExclusiveStartKey)
+	client.AssertExpectations(t)
+}
+
+func TestListAlertsReadLimit(t *testing.T) {
+	client := &mockDynamoDB{}
+	lastKey := map[string]*dynamodb.AttributeValue{
+		"alertId":      {S: aws.String("alert-1")},
+		"ruleId":       {S: aws.String("rule-id")},
+		"creationTime": {S: aws.String("2020-01-01T00:00:00Z")},
+	}
+	// No alert matches the filter
+	client.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{
+		LastEvaluatedKey: lastKey,
+		ScannedCount:     aws.Int64(listAlertsQueryLimit),
+	}, nil).Times(maxListAlertsRead / listAlertsQueryLimit)
+
+	table := listAlertsTable(client)
+	input := &models.ListAlertsInput{RuleID: aws.String("rule-id"), Severity: aws.StringSlice([]string{"HIGH"})}
+	alerts, lastEvaluatedKey, err := table.ListAlerts(input)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+	require.NotNil(t, lastEvaluatedKey)
+	client.AssertExpectations(t)
+
+	// The key resumes the search after the alerts read
+	client.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{}, nil).Once()
+	input.ExclusiveStartKey = lastEvaluatedKey
+	_, lastEvaluatedKey, err = table.ListAlerts(input)
+	require.NoError(t, err)
+	assert.Nil(t, lastEvaluatedKey)
+	assert.Equal(t, lastKey, client.Calls[len(client.Calls)-1].Arguments[0].(*dynamodb.QueryInput).ExclusiveStartKey)
+}
+
+func TestListAlertsInvalidKey(t *testing.T) {
+	_, _, err := listAlertsTable(&mockDynamoDB{}).ListAlerts(&models.ListAlertsInput{
+		ExclusiveStartKey: aws.String("not json"),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestTimePartition(t *testing.T) {
+	partition := models.TimePartition("alert-id")
+	assert.Contains(t, models.AllTimePartitions(), partition)
+	assert.Equal(t, partition, models.TimePartition("alert-id"))
+}
@@ -0,0 +1,229 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// MetricDayLayout is the format of the day of the alert metrics
+	MetricDayLayout = "2006-01-02"
+
+	// The metrics of a day are spread over shards, so that all the merged alerts do not update the same partition
+	metricShards = 4
+	// metricReadConcurrency is the number of shards queried in parallel
+	metricReadConcurrency = 10
+)
+
+// AddAlertMetrics adds the counts to the metrics of each dimension in the hour of the timestamp
+//
+// The counts are added in a random shard of the day, ListAlertMetrics sums the shards.
+func (table *AlertsTable) AddAlertMetrics(
+	timestamp time.Time, dimensions []models.MetricDimension, counts *models.MetricCounts) error {
+
+	update := addNonZero(expression.UpdateBuilder{}, "alertCount", counts.AlertCount)
+	update = addNonZero(update, "eventCount", counts.EventCount)
+	update = addNonZero(update, "resolvedCount", counts.ResolvedCount)
+	update = addNonZero(update, "resolveSeconds", counts.ResolveSeconds)
+	updateExpression, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	timestamp = timestamp.UTC()
+	partition := metricPartition(timestamp.Format(MetricDayLayout), rand.Intn(metricShards)) // nolint: gosec
+	for _, dimension := range dimensions {
+		if _, err = table.Client.UpdateItem(&dynamodb.UpdateItemInput{
+			TableName: aws.String(table.MetricsTableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"day": {S: aws.String(partition)},
+				"key": {S: aws.String(MetricKey(timestamp.Hour(), dimension))},
+			},
+			UpdateExpression:          updateExpression.Update(),
+			ExpressionAttributeNames:  updateExpression.Names(),
+			ExpressionAttributeValues: updateExpression.Values(),
+		}); err != nil {
+			return &genericapi.AWSError{Method: "dynamodb.UpdateItem", Err: err}
+		}
+	}
+	return nil
+}
+
+func addNonZero(update expression.UpdateBuilder, name string, value int64) expression.UpdateBuilder {
+	if value == 0 {
+		return update
+	}
+	return update.Add(expression.Name(name), expression.Value(value))
+}
+
+// ListAlertMetrics returns the hourly metrics of all the dimensions from the start hour until the end (exclusive)
+//
+// The counts of the shards of a day are summed, the returned metrics are sorted by day and key.
+func (table *AlertsTable) ListAlertMetrics(start, end time.Time) ([]*models.AlertMetricItem, error) {
+	start, end = start.UTC().Truncate(time.Hour), end.UTC()
+
+	var days []time.Time
+	for day := start.Truncate(24 * time.Hour); day.Before(end); day = day.Add(24 * time.Hour) {
+		days = append(days, day)
+	}
+	shards, err := table.listShardMetrics(days)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []*models.AlertMetricItem
+	merged := make(map[string]*models.AlertMetricItem)
+	for index, items := range shards {
+		day := days[index/metricShards]
+		for _, item := range items {
+			hour, _, err := ParseMetricKey(item.Key)
+			if err != nil {
+				return nil, err
+			}
+			if timestamp := day.Add(time.Duration(hour) * time.Hour); timestamp.Before(start) || !timestamp.Before(end) {
+				continue
+			}
+			item.Day = day.Format(MetricDayLayout)
+			if metric, ok := merged[item.Day+"#"+item.Key]; ok {
+				sumCounts(&metric.MetricCounts, &item.MetricCounts)
+				continue
+			}
+			merged[item.Day+"#"+item.Key] = item
+			metrics = append(metrics, item)
+		}
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Day != metrics[j].Day {
+			return metrics[i].Day < metrics[j].Day
+		}
+		return metrics[i].Key < metrics[j].Key
+	})
+	return metrics, nil
+}
+
+// listShardMetrics queries the shards of the days in parallel
+//
+// The metrics of shard s of day d are returned at index d*metricShards+s.
+func (table *AlertsTable) listShardMetrics(days []time.Time) ([][]*models.AlertMetricItem, error) {
+	shards := make([][]*models.AlertMetricItem, len(days)*metricShards)
+	errs := make([]error, len(shards))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < metricReadConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				partition := metricPartition(days[index/metricShards].Format(MetricDayLayout), index%metricShards)
+				shards[index], errs[index] = table.listPartitionMetrics(partition)
+			}
+		}()
+	}
+	for index := range shards {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return shards, nil
+}
+
+func (table *AlertsTable) listPartitionMetrics(partition string) ([]*models.AlertMetricItem, error) {
+	keyCondition := expression.Key("day").Equal(expression.Value(partition))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	var items []*models.AlertMetricItem
+	var unmarshalErr error
+	err = table.Client.QueryPages(&dynamodb.QueryInput{
+		TableName:                 aws.String(table.MetricsTableName),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var pageItems []*models.AlertMetricItem
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageItems); unmarshalErr != nil {
+			return false
+		}
+		items = append(items, pageItems...)
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.QueryPages", Err: err}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal alert metrics: " + unmarshalErr.Error()}
+	}
+	return items, nil
+}
+
+func sumCounts(total, counts *models.MetricCounts) {
+	total.AlertCount += counts.AlertCount
+	total.EventCount += counts.EventCount
+	total.ResolvedCount += counts.ResolvedCount
+	total.ResolveSeconds += counts.ResolveSeconds
+}
+
+// metricPartition returns the partition key of a shard of the metrics of a day
+func metricPartition(day string, shard int) string {
+	return fmt.Sprintf("%s#%d", day, shard)
+}
+
+// MetricKey returns the sort key of the metrics of a dimension in an hour of the day
+func MetricKey(hour int, dimension models.MetricDimension) string {
+	return fmt.Sprintf("%02d#%s#%s", hour, dimension.Type, dimension.Value)
+}
+
+// ParseMetricKey returns the hour and the dimension of a metrics sort key
+func ParseMetricKey(key string) (int, models.MetricDimension, error) {
+	var dimension models.MetricDimension
+	parts := strings.SplitN(key, "#", 3)
+	if len(parts) != 3 {
+		return 0, dimension, &genericapi.InternalError{Message: "invalid alert metrics key " + key}
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, dimension, &genericapi.InternalError{Message: "invalid alert metrics key " + key}
+	}
+	dimension.Type, dimension.Value = parts[1], parts[2]
+	return hour, dimension, nil
+}
@@ -0,0 +1,132 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+)
+
+func TestMetricKey(t *testing.T) {
+	dimension := models.MetricDimension{Type: models.MetricRule, Value: "rule#id"}
+	key := MetricKey(7, dimension)
+	assert.Equal(t, "07#rule#rule#id", key)
+
+	hour, parsed, err := ParseMetricKey(key)
+	require.NoError(t, err)
+	assert.Equal(t, 7, hour)
+	assert.Equal(t, dimension, parsed)
+
+	_, _, err = ParseMetricKey("07")
+	assert.Error(t, err)
+}
+
+func TestListAlertMetrics(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{MetricsTableName: "metrics", Client: client}
+
+	// The hours stored in each shard, the counts of the same hour in several shards are summed
+	shards := map[string][]int{
+		"2020-02-01#0": {10, 22},
+		"2020-02-01#2": {22, 23},
+		"2020-02-02#1": {0, 1},
+		"2020-02-02#3": {2},
+	}
+	var partitions []string
+	var lock sync.Mutex
+	client.On("QueryPages", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*dynamodb.QueryInput)
+		assert.Equal(t, "metrics", *input.TableName)
+		partition := *input.ExpressionAttributeValues[":0"].S
+		lock.Lock()
+		partitions = append(partitions, partition)
+		lock.Unlock()
+
+		var items []map[string]*dynamodb.AttributeValue
+		for _, hour := range shards[partition] {
+			item, err := dynamodbattribute.MarshalMap(&models.AlertMetricItem{
+				Day:          partition,
+				Key:          MetricKey(hour, models.MetricDimension{Type: models.MetricTotal}),
+				MetricCounts: models.MetricCounts{AlertCount: 1},
+			})
+			require.NoError(t, err)
+			items = append(items, item)
+		}
+		handler := args.Get(1).(func(*dynamodb.QueryOutput, bool) bool)
+		handler(&dynamodb.QueryOutput{Items: items}, true)
+	}).Times(2 * metricShards)
+
+	// The start is rounded to the hour, the end is exclusive
+	metrics, err := table.ListAlertMetrics(
+		time.Date(2020, 2, 1, 22, 30, 0, 0, time.UTC), time.Date(2020, 2, 2, 2, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	var keys []string
+	var counts []int64
+	for _, metric := range metrics {
+		keys = append(keys, metric.Day+" "+metric.Key)
+		counts = append(counts, metric.AlertCount)
+	}
+	assert.Equal(t, []string{"2020-02-01 22#total#", "2020-02-01 23#total#", "2020-02-02 00#total#", "2020-02-02 01#total#"}, keys)
+	assert.Equal(t, []int64{2, 1, 1, 1}, counts)
+
+	sort.Strings(partitions)
+	assert.Equal(t, []string{
+		"2020-02-01#0", "2020-02-01#1", "2020-02-01#2", "2020-02-01#3",
+		"2020-02-02#0", "2020-02-02#1", "2020-02-02#2", "2020-02-02#3",
+	}, partitions)
+	client.AssertExpectations(t)
+}
+
+func TestListAlertMetricsError(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("QueryPages", mock.Anything, mock.Anything).Return(errors.New("throttled"))
+
+	_, err := (&AlertsTable{MetricsTableName: "metrics", Client: client}).ListAlertMetrics(
+		time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 2, 2, 0, 0, 0, 0, time.UTC))
+	assert.Error(t, err)
+}
+
+func TestAddAlertMetricsResolution(t *testing.T) {
+	client := &mockDynamoDB{}
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	require.NoError(t, (&AlertsTable{MetricsTableName: "metrics", Client: client}).AddAlertMetrics(
+		time.Date(2020, 2, 1, 9, 0, 0, 0, time.UTC),
+		[]models.MetricDimension{{Type: models.MetricSeverity, Value: "HIGH"}},
+		&models.MetricCounts{ResolvedCount: 1, ResolveSeconds: 3600}))
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "09#severity#HIGH", *input.Key["key"].S)
+	assert.Regexp(t, `^2020-02-01#[0-3]$`, *input.Key["day"].S)
+	assert.Equal(t, "ADD #0 :0, #1 :1\n", *input.UpdateExpression)
+	assert.Equal(t, "resolvedCount", *input.ExpressionAttributeNames["#0"])
+	assert.Equal(t, "resolveSeconds", *input.ExpressionAttributeNames["#1"])
+	client.AssertExpectations(t)
+}
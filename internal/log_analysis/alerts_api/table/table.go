@@ -1,4 +1,4 @@
-// Package table manages all of the Dynamo calls (query, scan, get, write, etc).
+// Package table manages all of the Dynamo calls (query, scan, get, write, etc) and the alert events in S3.
 package table
 
 /**
@@ -20,8 +20,12 @@ package table
  */
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 
 	"github.com/panther-labs/panther/api/lambda/alerts/models"
 )
@@ -30,16 +34,46 @@ import (
 type API interface {
 	GetAlert(*string) (*models.AlertItem, error)
 	GetEvent([]byte) (*string, error)
-	ListAlerts(*string, *int) ([]*models.AlertItem, *string, error)
-	ListAlertsByRule(*string, *string, *int) ([]*models.AlertItem, *string, error)
+	ListEvents(*string, *string, *int) ([]*string, *string, error)
+	ListAlerts(*models.ListAlertsInput) ([]*models.AlertItem, *string, error)
+	UpdateAlertStatus(*string, *string, *string, *string) (*models.AlertItem, error)
+	AssignAlert(*string, *string, *string) (*models.AlertItem, error)
+	AddAlertChange(*string, *models.AlertChange) error
+	GetAlertHistory(*models.AlertItem) ([]*models.AlertChange, error)
+	AddComment(*string, *string, *string) (*models.AlertComment, error)
+	UpdateComment(*string, *string, *string, *string) (*models.AlertComment, error)
+	DeleteComment(*string, *string, *string) error
+	ListComments(*string, *string, *int) ([]*models.AlertComment, *string, error)
+	AddDeliveries([]*models.AlertDelivery) error
+	ListDeliveries(*string) ([]*models.AlertDelivery, error)
+	ListOutputDeliveries(*string, int) ([]*models.AlertDelivery, error)
+	ListExpiredAlerts(time.Time, func([]*string) error) error
+	ScanAlertExpiry(*string) ([]*models.AlertItem, *string, error)
+	SetAlertExpiry(*string, *time.Time) error
+	DeleteAlert(*string) error
+	PutExportJob(*models.ExportJob) error
+	GetExportJob(*string) (*models.ExportJob, error)
+	AddAlertMetrics(time.Time, []models.MetricDimension, *models.MetricCounts) error
+	ListAlertMetrics(time.Time, time.Time) ([]*models.AlertMetricItem, error)
 }
 
-// AlertsTable encapsulates a connection to the Dynamo alerts table.
+// AlertsTable encapsulates a connection to the Dynamo alerts table and the S3 bucket of alert events.
 type AlertsTable struct {
-	AlertsTableName             string
-	RuleIDCreationTimeIndexName string
-	EventsTableName             string
-	Client                      dynamodbiface.DynamoDBAPI
+	AlertsTableName                    string
+	RuleIDCreationTimeIndexName        string
+	TimePartitionCreationTimeIndexName string
+	TimePartitionExpiresAtIndexName    string
+	EventsTableName                    string
+	CommentsTableName                  string
+	CommentsCreatedAtIndexName         string
+	DeliveriesTableName                string
+	DeliveriesOutputIndexName          string
+	HistoryTableName                   string
+	ExportsTableName                   string
+	MetricsTableName                   string
+	EventsBucket                       string
+	Client                             dynamodbiface.DynamoDBAPI
+	S3Client                           s3iface.S3API
 }
 
 // The AlertsTable must satisfy the API interface.
@@ -47,3 +81,16 @@ var _ API = (*AlertsTable)(nil)
 
 // DynamoItem is a type alias for the item format expected by the Dynamo SDK.
 type DynamoItem = map[string]*dynamodb.AttributeValue
+
+// statusFilter matches the alerts with any of the given statuses.
+//
+// Alerts created before status tracking was introduced have no status and are considered open.
+func statusFilter(statuses []*string) expression.ConditionBuilder {
+	filter := anyEqual("status", statuses)
+	for _, status := range statuses {
+		if *status == models.StatusOpen {
+			return filter.Or(expression.Name("status").AttributeNotExists())
+		}
+	}
+	return filter
+}
@@ -0,0 +1,135 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// UpdateAlertStatus sets the status of an alert and records the change in the alert history
+func (table *AlertsTable) UpdateAlertStatus(alertID, status, userID, resolution *string) (*models.AlertItem, error) {
+	now := aws.Time(time.Now().UTC())
+	change := &models.AlertChange{
+		Action:     aws.String(models.ActionStatusChange),
+		UserID:     userID,
+		Timestamp:  now,
+		Status:     status,
+		Resolution: resolution,
+	}
+
+	update := expression.Set(expression.Name("status"), expression.Value(status))
+	if *status == models.StatusResolved {
+		// The time to resolve is measured until the first resolution
+		update = update.Set(expression.Name("resolvedAt"),
+			expression.IfNotExists(expression.Name("resolvedAt"), expression.Value(now)))
+	}
+	switch {
+	case *status == models.StatusOpen:
+		// A reopened alert is not resolved anymore
+		update = update.Remove(expression.Name("resolution"))
+	case resolution != nil:
+		update = update.Set(expression.Name("resolution"), expression.Value(&models.Resolution{
+			Notes:     resolution,
+			UserID:    userID,
+			Timestamp: now,
+		}))
+	}
+	return table.updateAlert(alertID, setLastUpdated(update, userID, now), change)
+}
+
+// AssignAlert sets the assignee of an alert and records the change in the alert history
+//
+// If the assignee is nil, the alert is unassigned.
+func (table *AlertsTable) AssignAlert(alertID, assigneeID, userID *string) (*models.AlertItem, error) {
+	now := aws.Time(time.Now().UTC())
+	change := &models.AlertChange{
+		Action:     aws.String(models.ActionAssign),
+		UserID:     userID,
+		Timestamp:  now,
+		AssigneeID: assigneeID,
+	}
+
+	var update expression.UpdateBuilder
+	if assigneeID == nil {
+		update = expression.Remove(expression.Name("assigneeId"))
+	} else {
+		update = expression.Set(expression.Name("assigneeId"), expression.Value(assigneeID))
+	}
+	return table.updateAlert(alertID, setLastUpdated(update, userID, now), change)
+}
+
+// setLastUpdated records who changed the alert and when
+func setLastUpdated(update expression.UpdateBuilder, userID *string, now *time.Time) expression.UpdateBuilder {
+	return update.
+		Set(expression.Name("lastUpdatedBy"), expression.Value(userID)).
+		Set(expression.Name("lastUpdatedTime"), expression.Value(now))
+}
+
+// updateAlert applies the update to an existing alert and adds the change to its history
+func (table *AlertsTable) updateAlert(
+	alertID *string, update expression.UpdateBuilder, change *models.AlertChange) (*models.AlertItem, error) {
+
+	// Fail if the alert does not exist instead of creating a new item
+	condition := expression.AttributeExists(expression.Name("alertId"))
+
+	updateExpression, err := expression.NewBuilder().
+		WithCondition(condition).
+		WithUpdate(update).
+		Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build expression " + err.Error()}
+	}
+
+	response, err := table.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(table.AlertsTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"alertId": {S: alertID},
+		},
+		UpdateExpression:          updateExpression.Update(),
+		ConditionExpression:       updateExpression.Condition(),
+		ExpressionAttributeNames:  updateExpression.Names(),
+		ExpressionAttributeValues: updateExpression.Values(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, &genericapi.DoesNotExistError{Message: "alertId=" + *alertID}
+		}
+		return nil, &genericapi.AWSError{Method: "dynamodb.UpdateItem", Err: err}
+	}
+
+	alertItem := &models.AlertItem{}
+	if err = dynamodbattribute.UnmarshalMap(response.Attributes, alertItem); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal dynamo item to an AlertItem: " + err.Error()}
+	}
+
+	if err = table.AddAlertChange(alertID, change); err != nil {
+		return nil, err
+	}
+	return alertItem, nil
+}
@@ -0,0 +1,145 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestUpdateAlertStatus(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{AlertsTableName: "alerts", HistoryTableName: "history", Client: client}
+
+	output := &dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"alertId": {S: aws.String("alert-id")},
+			"status":  {S: aws.String(models.StatusResolved)},
+		},
+	}
+	client.On("UpdateItem", mock.Anything).Return(output, nil).Once()
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	result, err := table.UpdateAlertStatus(
+		aws.String("alert-id"), aws.String(models.StatusResolved), aws.String("user-id"), aws.String("notes"))
+	require.NoError(t, err)
+	assert.Equal(t, &models.AlertItem{AlertID: aws.String("alert-id"), Status: aws.String(models.StatusResolved)}, result)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "alerts", *input.TableName)
+	assert.Equal(t, "alert-id", *input.Key["alertId"].S)
+	assert.Equal(t, dynamodb.ReturnValueAllNew, *input.ReturnValues)
+	assert.NotNil(t, input.ConditionExpression)
+	assert.ElementsMatch(t, []string{"alertId", "status", "resolution", "lastUpdatedBy", "lastUpdatedTime", "resolvedAt"},
+		attributeNames(input.ExpressionAttributeNames))
+
+	// The change is stored in the history table
+	put := client.Calls[1].Arguments[0].(*dynamodb.PutItemInput)
+	assert.Equal(t, "history", *put.TableName)
+	assert.Equal(t, "alert-id", *put.Item["alertId"].S)
+	assert.NotNil(t, put.Item["changeId"].S)
+	assert.Equal(t, models.ActionStatusChange, *put.Item["action"].S)
+	assert.Equal(t, "user-id", *put.Item["userId"].S)
+	assert.Equal(t, models.StatusResolved, *put.Item["status"].S)
+	assert.Equal(t, "notes", *put.Item["resolution"].S)
+	client.AssertExpectations(t)
+}
+
+func TestUpdateAlertStatusReopen(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{AlertsTableName: "alerts", HistoryTableName: "history", Client: client}
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	_, err := table.UpdateAlertStatus(aws.String("alert-id"), aws.String(models.StatusOpen), aws.String("user-id"), nil)
+	require.NoError(t, err)
+
+	// The resolution of a reopened alert is removed
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *input.UpdateExpression, "REMOVE")
+	assert.Contains(t, attributeNames(input.ExpressionAttributeNames), "resolution")
+	client.AssertExpectations(t)
+}
+
+func TestUpdateAlertStatusHistoryError(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{AlertsTableName: "alerts", HistoryTableName: "history", Client: client}
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, errors.New("service error")).Once()
+
+	result, err := table.UpdateAlertStatus(aws.String("alert-id"), aws.String(models.StatusTriaged), aws.String("user-id"), nil)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	client.AssertExpectations(t)
+}
+
+func attributeNames(names map[string]*string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		result = append(result, *name)
+	}
+	return result
+}
+
+func TestAssignAlertUnassign(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{AlertsTableName: "alerts", HistoryTableName: "history", Client: client}
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	_, err := table.AssignAlert(aws.String("alert-id"), nil, aws.String("user-id"))
+	require.NoError(t, err)
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *input.UpdateExpression, "REMOVE")
+	client.AssertExpectations(t)
+}
+
+func TestAssignAlertDoesNotExist(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{AlertsTableName: "alerts", Client: client}
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil)).Once()
+
+	result, err := table.AssignAlert(aws.String("alert-id"), aws.String("assignee-id"), aws.String("user-id"))
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	client.AssertExpectations(t)
+}
+
+func TestAssignAlertServiceError(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &AlertsTable{AlertsTableName: "alerts", Client: client}
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, errors.New("service error")).Once()
+
+	result, err := table.AssignAlert(aws.String("alert-id"), aws.String("assignee-id"), aws.String("user-id"))
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	client.AssertExpectations(t)
+}
@@ -191,6 +191,16 @@ type ClassifierStats struct {
 	ClassificationFailureCount  uint64
 }
 
+// Add accumulates the stats of another classifier, used when a stream is classified by several classifiers
+func (s *ClassifierStats) Add(other *ClassifierStats) {
+	s.ClassifyTimeMicroseconds += other.ClassifyTimeMicroseconds
+	s.BytesProcessedCount += other.BytesProcessedCount
+	s.LogLineCount += other.LogLineCount
+	s.EventCount += other.EventCount
+	s.SuccessfullyClassifiedCount += other.SuccessfullyClassifiedCount
+	s.ClassificationFailureCount += other.ClassificationFailureCount
+}
+
 // per parser stats
 type ParserStats struct {
 	ParserTimeMicroseconds uint64 // total time parsing
@@ -199,3 +209,11 @@ type ParserStats struct {
 	EventCount             uint64 // output records
 	LogType                string
 }
+
+// Add accumulates the stats of the same parser in another classifier
+func (s *ParserStats) Add(other *ParserStats) {
+	s.ParserTimeMicroseconds += other.ParserTimeMicroseconds
+	s.BytesProcessedCount += other.BytesProcessedCount
+	s.LogLineCount += other.LogLineCount
+	s.EventCount += other.EventCount
+}
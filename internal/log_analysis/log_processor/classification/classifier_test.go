@@ -289,3 +289,39 @@ func requireLessOrEqualNumberOfCalls(t *testing.T, underTest *mockParser, method
 	}
 	require.LessOrEqual(t, timesCalled, number)
 }
+
+func TestStatsAdd(t *testing.T) {
+	stats := ClassifierStats{
+		ClassifyTimeMicroseconds:    1,
+		BytesProcessedCount:         2,
+		LogLineCount:                3,
+		EventCount:                  4,
+		SuccessfullyClassifiedCount: 5,
+		ClassificationFailureCount:  6,
+	}
+	stats.Add(&stats)
+	require.Equal(t, ClassifierStats{
+		ClassifyTimeMicroseconds:    2,
+		BytesProcessedCount:         4,
+		LogLineCount:                6,
+		EventCount:                  8,
+		SuccessfullyClassifiedCount: 10,
+		ClassificationFailureCount:  12,
+	}, stats)
+
+	parserStats := ParserStats{
+		ParserTimeMicroseconds: 1,
+		BytesProcessedCount:    2,
+		LogLineCount:           3,
+		EventCount:             4,
+		LogType:                "testLogType",
+	}
+	parserStats.Add(&parserStats)
+	require.Equal(t, ParserStats{
+		ParserTimeMicroseconds: 2,
+		BytesProcessedCount:    4,
+		LogLineCount:           6,
+		EventCount:             8,
+		LogType:                "testLogType",
+	}, parserStats)
+}
@@ -0,0 +1,68 @@
+package common
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+// CloudWatch metrics emitted using the Embedded Metric Format
+const (
+	MetricsNamespace  = "Panther/LogProcessor"
+	MetricsLogTypeDim = "LogType"
+)
+
+// Metric names
+const (
+	// classification, all log types
+	MetricLogLines               = "LogLines"
+	MetricBytesProcessed         = "BytesProcessed"
+	MetricClassifyTime           = "ClassifyTime"
+	MetricSuccessfullyClassified = "SuccessfullyClassified"
+	MetricClassificationFailures = "ClassificationFailures"
+	MetricDuplicateEvents        = "DuplicateEvents"
+
+	// parsing, per log type
+	MetricEvents     = "Events"
+	MetricParserTime = "ParserTime"
+
+	// S3 destination, per log type
+	MetricObjectsWritten    = "ObjectsWritten"
+	MetricObjectBytes       = "ObjectBytes"
+	MetricObjectStoredBytes = "ObjectStoredBytes" // compressed
+	MetricObjectEvents      = "ObjectEvents"
+	MetricPartitionsCreated = "GluePartitionsCreated"
+	MetricPartitionFailures = "GluePartitionFailures"
+)
+
+var MetricsLogger = metrics.NewLogger(MetricsNamespace)
+
+// LogMetrics writes metrics, failures are logged and otherwise ignored (metrics are best effort)
+func LogMetrics(dimensions []metrics.Dimension, values ...metrics.Metric) {
+	if err := MetricsLogger.Log(dimensions, values...); err != nil {
+		zap.L().Warn("failed to write metrics", zap.Error(err))
+	}
+}
+
+// LogTypeDimensions returns the dimensions for per log type metrics
+func LogTypeDimensions(logType string) []metrics.Dimension {
+	return []metrics.Dimension{{Name: MetricsLogTypeDim, Value: logType}}
+}
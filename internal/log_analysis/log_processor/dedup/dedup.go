@@ -0,0 +1,160 @@
+package dedup
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+)
+
+// Store remembers event identities across Lambda invocations
+type Store interface {
+	// Get returns the expiration time of the key, or nil if the key was not recorded or expired
+	Get(key string) (*time.Time, error)
+	// PutAll records the keys for the window
+	PutAll(keys []string, window time.Duration) error
+}
+
+// Deduplicator suppresses events that were already seen within a time window.
+// Identities are kept in a bounded local cache that survives across invocations of a warm Lambda and,
+// if a Store is configured, in a shared store so that concurrent Lambdas see each other's events.
+//
+// The identities of the events kept by IsDuplicate are pending until Commit is called, once the events
+// are written. If the processing fails, Discard drops them so that a retry processes the events again.
+type Deduplicator struct {
+	window time.Duration
+	store  Store // optional
+
+	mutex   sync.Mutex          // protects the local cache and the pending keys
+	cache   *lru.Cache          // event key -> expiration time
+	pending map[string]struct{} // keys of the events kept since the last Commit or Discard
+}
+
+// New returns a Deduplicator that suppresses duplicates within window, remembering at most cacheSize
+// identities locally. The store may be nil.
+func New(window time.Duration, cacheSize int, store Store) (*Deduplicator, error) {
+	if window <= 0 {
+		return nil, errors.New("deduplication window must be positive")
+	}
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create deduplication cache")
+	}
+	return &Deduplicator{
+		window:  window,
+		store:   store,
+		cache:   cache,
+		pending: make(map[string]struct{}),
+	}, nil
+}
+
+// IsDuplicate returns true if an event with the same identity was written within the window or was already
+// kept since the last Commit. Events that do not implement parsers.Identifiable are never considered duplicates.
+// If the store fails, the event is NOT considered a duplicate (better to process twice than to drop data).
+func (d *Deduplicator) IsDuplicate(logType string, event interface{}) bool {
+	identifiable, ok := event.(parsers.Identifiable)
+	if !ok {
+		return false
+	}
+	identity := identifiable.EventIdentity()
+	if identity == "" {
+		return false
+	}
+	key := eventKey(logType, identity)
+
+	if d.seenLocally(key) {
+		return true
+	}
+
+	if d.store == nil {
+		return false
+	}
+	expiresAt, err := d.store.Get(key)
+	if err != nil {
+		zap.L().Warn("failed to check deduplication store, keeping event",
+			zap.String("logType", logType), zap.Error(err))
+		return false
+	}
+	if expiresAt != nil {
+		d.recordDuplicate(key, *expiresAt)
+	}
+	return expiresAt != nil
+}
+
+// Commit records the identities of the events kept since the last Commit, call it once the events are written
+func (d *Deduplicator) Commit() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	keys := make([]string, 0, len(d.pending))
+	expiresAt := time.Now().Add(d.window)
+	for key := range d.pending {
+		keys = append(keys, key)
+		d.cache.Add(key, expiresAt)
+	}
+	d.pending = make(map[string]struct{})
+
+	if d.store == nil || len(keys) == 0 {
+		return nil
+	}
+	return d.store.PutAll(keys, d.window)
+}
+
+// Discard forgets the identities of the events kept since the last Commit, call it if the events were not written
+func (d *Deduplicator) Discard() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.pending = make(map[string]struct{})
+}
+
+// seenLocally checks the local cache and the pending keys, and marks the key as pending if it is new
+func (d *Deduplicator) seenLocally(key string) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, found := d.pending[key]; found {
+		return true
+	}
+	if value, found := d.cache.Get(key); found && time.Now().Before(value.(time.Time)) {
+		return true
+	}
+	d.pending[key] = struct{}{}
+	return false
+}
+
+// recordDuplicate remembers a key found in the store until it expires, so that it is not pending nor queried again
+func (d *Deduplicator) recordDuplicate(key string, expiresAt time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.pending, key)
+	d.cache.Add(key, expiresAt)
+}
+
+// the identity is only unique within the log type
+func eventKey(logType, identity string) string {
+	return logType + ":" + identity
+}
@@ -0,0 +1,254 @@
+package dedup
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testLogType = "testLogType"
+
+type testEvent struct {
+	id string
+}
+
+func (event *testEvent) EventIdentity() string {
+	return event.id
+}
+
+type mockStore struct {
+	mock.Mock
+}
+
+func (m *mockStore) Get(key string) (*time.Time, error) {
+	args := m.Called(key)
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *mockStore) PutAll(keys []string, window time.Duration) error {
+	args := m.Called(keys, window)
+	return args.Error(0)
+}
+
+type mockDdbClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDdbClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDdbClient) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.BatchWriteItemOutput), args.Error(1)
+}
+
+func TestNewInvalidWindow(t *testing.T) {
+	_, err := New(0, 10, nil)
+	require.Error(t, err)
+}
+
+func TestIsDuplicateLocalCache(t *testing.T) {
+	d, err := New(time.Hour, 10, nil)
+	require.NoError(t, err)
+
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+	// pending events are duplicates within the batch
+	assert.True(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+	require.NoError(t, d.Commit())
+	assert.True(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{id: "2"}))
+	// same identity in a different log type is a different event
+	assert.False(t, d.IsDuplicate("otherLogType", &testEvent{id: "1"}))
+}
+
+func TestIsDuplicateDiscard(t *testing.T) {
+	d, err := New(time.Hour, 10, nil)
+	require.NoError(t, err)
+
+	// The events of a failed batch were possibly never written, a retry processes them again
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+	d.Discard()
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+	require.NoError(t, d.Commit())
+	assert.True(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+}
+
+func TestIsDuplicateExpired(t *testing.T) {
+	d, err := New(time.Millisecond, 10, nil)
+	require.NoError(t, err)
+
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+	require.NoError(t, d.Commit())
+	time.Sleep(2 * time.Millisecond)
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+}
+
+func TestIsDuplicateNotIdentifiable(t *testing.T) {
+	d, err := New(time.Hour, 10, nil)
+	require.NoError(t, err)
+
+	assert.False(t, d.IsDuplicate(testLogType, "not identifiable"))
+	assert.False(t, d.IsDuplicate(testLogType, "not identifiable"))
+	// empty identity
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{}))
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{}))
+}
+
+func TestIsDuplicateStore(t *testing.T) {
+	store := &mockStore{}
+	d, err := New(time.Hour, 10, store)
+	require.NoError(t, err)
+
+	expiresAt := time.Now().Add(time.Hour)
+	store.On("Get", testLogType+":1").Return(&expiresAt, nil).Once()
+	assert.True(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+	// now in the local cache, store is not called again
+	assert.True(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+
+	store.On("Get", testLogType+":2").Return((*time.Time)(nil), nil).Once()
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{id: "2"}))
+
+	// only the kept event is recorded
+	store.On("PutAll", []string{testLogType + ":2"}, time.Hour).Return(nil).Once()
+	require.NoError(t, d.Commit())
+	store.AssertExpectations(t)
+}
+
+func TestIsDuplicateStoreDiscard(t *testing.T) {
+	store := &mockStore{}
+	d, err := New(time.Hour, 10, store)
+	require.NoError(t, err)
+
+	store.On("Get", testLogType+":1").Return((*time.Time)(nil), nil).Once()
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+	d.Discard()
+	// nothing is recorded
+	require.NoError(t, d.Commit())
+	store.AssertExpectations(t)
+}
+
+func TestIsDuplicateStoreError(t *testing.T) {
+	store := &mockStore{}
+	d, err := New(time.Hour, 10, store)
+	require.NoError(t, err)
+
+	store.On("Get", testLogType+":1").Return((*time.Time)(nil), errors.New("failure")).Once()
+	assert.False(t, d.IsDuplicate(testLogType, &testEvent{id: "1"}))
+
+	store.On("PutAll", []string{testLogType + ":1"}, time.Hour).Return(errors.New("failure")).Once()
+	require.Error(t, d.Commit())
+	store.AssertExpectations(t)
+}
+
+func TestDynamoDBStoreGet(t *testing.T) {
+	client := &mockDdbClient{}
+	store := &DynamoDBStore{Client: client, TableName: "table"}
+
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			expiresAtAttribute: {N: aws.String(strconv.FormatInt(expiresAt, 10))},
+		},
+	}, nil).Once()
+	result, err := store.Get("key")
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, expiresAt, result.Unix())
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.GetItemInput)
+	assert.Equal(t, "table", *input.TableName)
+	assert.Equal(t, "key", *input.Key[keyAttribute].S)
+	assert.True(t, *input.ConsistentRead)
+	client.AssertExpectations(t)
+}
+
+func TestDynamoDBStoreGetMissing(t *testing.T) {
+	client := &mockDdbClient{}
+	store := &DynamoDBStore{Client: client, TableName: "table"}
+
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	result, err := store.Get("key")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	client.AssertExpectations(t)
+}
+
+func TestDynamoDBStoreGetExpired(t *testing.T) {
+	client := &mockDdbClient{}
+	store := &DynamoDBStore{Client: client, TableName: "table"}
+
+	// not yet deleted by TTL
+	expiresAt := time.Now().Add(-time.Hour).Unix()
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			expiresAtAttribute: {N: aws.String(strconv.FormatInt(expiresAt, 10))},
+		},
+	}, nil).Once()
+	result, err := store.Get("key")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	client.AssertExpectations(t)
+}
+
+func TestDynamoDBStoreGetError(t *testing.T) {
+	client := &mockDdbClient{}
+	store := &DynamoDBStore{Client: client, TableName: "table"}
+
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, errors.New("failure")).Once()
+	_, err := store.Get("key")
+	require.Error(t, err)
+	client.AssertExpectations(t)
+}
+
+func TestDynamoDBStorePutAll(t *testing.T) {
+	client := &mockDdbClient{}
+	store := &DynamoDBStore{Client: client, TableName: "table"}
+
+	client.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+	require.NoError(t, store.PutAll([]string{"key1", "key2"}, time.Hour))
+
+	input := client.Calls[0].Arguments[0].(*dynamodb.BatchWriteItemInput)
+	require.Len(t, input.RequestItems["table"], 2)
+	item := input.RequestItems["table"][0].PutRequest.Item
+	assert.Equal(t, "key1", *item[keyAttribute].S)
+	assert.NotNil(t, item[expiresAtAttribute].N)
+	client.AssertExpectations(t)
+}
+
+func TestDynamoDBStorePutAllError(t *testing.T) {
+	client := &mockDdbClient{}
+	store := &DynamoDBStore{Client: client, TableName: "table"}
+
+	client.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, errors.New("failure")).Once()
+	require.Error(t, store.PutAll([]string{"key"}, time.Hour))
+	client.AssertExpectations(t)
+}
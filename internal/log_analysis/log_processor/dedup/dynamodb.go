@@ -0,0 +1,96 @@
+package dedup
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
+)
+
+const (
+	keyAttribute       = "eventKey"
+	expiresAtAttribute = "expiresAt" // configured as the table TTL attribute
+
+	maxWriteBackoff = 30 * time.Second
+)
+
+// DynamoDBStore keeps event identities in a DynamoDB table, expired entries are removed by DynamoDB TTL
+type DynamoDBStore struct {
+	Client    dynamodbiface.DynamoDBAPI
+	TableName string
+}
+
+// Get returns the expiration time of the key, or nil if the key was not recorded or expired
+func (s *DynamoDBStore) Get(key string) (*time.Time, error) {
+	output, err := s.Client.GetItem(&dynamodb.GetItemInput{
+		TableName:            aws.String(s.TableName),
+		Key:                  map[string]*dynamodb.AttributeValue{keyAttribute: {S: aws.String(key)}},
+		ProjectionExpression: aws.String(expiresAtAttribute),
+		ConsistentRead:       aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get deduplication key from %s", s.TableName)
+	}
+
+	attribute, found := output.Item[expiresAtAttribute]
+	if !found || attribute.N == nil {
+		return nil, nil
+	}
+	seconds, err := strconv.ParseInt(*attribute.N, 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid expiration of deduplication key in %s", s.TableName)
+	}
+	// TTL deletion is best effort and can lag for hours, so expired entries are ignored explicitly
+	expiresAt := time.Unix(seconds, 0)
+	if !time.Now().Before(expiresAt) {
+		return nil, nil
+	}
+	return &expiresAt, nil
+}
+
+// PutAll records the keys for the window, overwriting the expired entries
+func (s *DynamoDBStore) PutAll(keys []string, window time.Duration) error {
+	expiresAt := aws.String(strconv.FormatInt(time.Now().Add(window).Unix(), 10))
+	requests := make([]*dynamodb.WriteRequest, len(keys))
+	for i, key := range keys {
+		requests[i] = &dynamodb.WriteRequest{
+			PutRequest: &dynamodb.PutRequest{
+				Item: map[string]*dynamodb.AttributeValue{
+					keyAttribute:       {S: aws.String(key)},
+					expiresAtAttribute: {N: expiresAt},
+				},
+			},
+		}
+	}
+
+	input := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{s.TableName: requests},
+	}
+	if err := dynamodbbatch.BatchWriteItem(s.Client, maxWriteBackoff, input); err != nil {
+		return errors.Wrapf(err, "failed to put deduplication keys to %s", s.TableName)
+	}
+	return nil
+}
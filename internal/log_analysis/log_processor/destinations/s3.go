@@ -38,6 +38,7 @@ import (
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+	"github.com/panther-labs/panther/pkg/metrics"
 )
 
 // s3ObjectKeyFormat represents the format of the S3 object key
@@ -202,6 +203,13 @@ func (destination *S3Destination) sendData(logType string, buffer *s3EventBuffer
 		return err
 	}
 
+	common.LogMetrics(common.LogTypeDimensions(logType),
+		metrics.Metric{Name: common.MetricObjectsWritten, Unit: metrics.UnitCount, Value: 1},
+		metrics.Metric{Name: common.MetricObjectBytes, Unit: metrics.UnitBytes, Value: float64(buffer.bytes)},
+		metrics.Metric{Name: common.MetricObjectStoredBytes, Unit: metrics.UnitBytes, Value: float64(contentLength)},
+		metrics.Metric{Name: common.MetricObjectEvents, Unit: metrics.UnitCount, Value: float64(buffer.events)},
+	)
+
 	destination.createGluePartition(logType, buffer) // best effort
 
 	err = destination.sendSNSNotification(key, logType, buffer) // if send fails we fail whole operation
@@ -260,8 +268,12 @@ func (destination *S3Destination) createGluePartition(logType string, buffer *s3
 					return
 				}
 			}
+			common.LogMetrics(common.LogTypeDimensions(logType),
+				metrics.Metric{Name: common.MetricPartitionFailures, Unit: metrics.UnitCount, Value: 1})
 		} else {
 			destination.partitionExistsCache[partitionPath] = struct{}{} // remember
+			common.LogMetrics(common.LogTypeDimensions(logType),
+				metrics.Metric{Name: common.MetricPartitionsCreated, Unit: metrics.UnitCount, Value: 1})
 		}
 
 		// log outcome
@@ -20,23 +20,77 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/dedup"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/sources"
 	"github.com/panther-labs/panther/pkg/lambdalogger"
 )
 
+type envConfig struct {
+	// number of data streams processed at the same time
+	MaxConcurrentStreams int `default:"4" split_words:"true"`
+	// goroutines classifying a single data stream, 0 uses the number of CPUs
+	ClassificationWorkers int `split_words:"true"`
+	// lines handed to a classification goroutine at a time
+	ClassificationChunkSize int `default:"100" split_words:"true"`
+	// 0 disables deduplication
+	DedupWindowMinutes int `split_words:"true"`
+	// max number of event identities kept in memory
+	DedupCacheSize int `default:"100000" split_words:"true"`
+	// optional, if set identities are shared across concurrent Lambdas
+	DedupTable string `split_words:"true"`
+}
+
 func main() {
+	var env envConfig
+	envconfig.MustProcess("", &env)
+	configureProcessor(&env)
 	lambda.Start(handle)
 }
 
+func configureProcessor(env *envConfig) {
+	if env.ClassificationChunkSize <= 0 {
+		// panic is justified because this means configuration is WRONG
+		panic(errors.Errorf("CLASSIFICATION_CHUNK_SIZE must be positive, got %d", env.ClassificationChunkSize))
+	}
+	processor.MaxConcurrentStreams = env.MaxConcurrentStreams
+	if env.ClassificationWorkers > 0 {
+		processor.ClassificationWorkers = env.ClassificationWorkers
+	}
+	processor.ClassificationChunkSize = env.ClassificationChunkSize
+	processor.Deduplicator = newDeduplicator(env)
+}
+
+func newDeduplicator(env *envConfig) *dedup.Deduplicator {
+	if env.DedupWindowMinutes <= 0 {
+		return nil
+	}
+	var store dedup.Store
+	if env.DedupTable != "" {
+		store = &dedup.DynamoDBStore{
+			Client:    dynamodb.New(common.Session),
+			TableName: env.DedupTable,
+		}
+	}
+	deduplicator, err := dedup.New(time.Duration(env.DedupWindowMinutes)*time.Minute, env.DedupCacheSize, store)
+	if err != nil {
+		panic(err) // panic is justified because this means configuration is WRONG
+	}
+	return deduplicator
+}
+
 func handle(ctx context.Context, event events.SQSEvent) error {
 	lc, _ := lambdalogger.ConfigureGlobal(ctx, nil)
 	return process(lc, event)
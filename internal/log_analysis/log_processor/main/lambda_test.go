@@ -30,6 +30,7 @@ import (
 	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
 )
 
 // Replace global logger with an in-memory observer for tests.
@@ -66,3 +67,17 @@ func TestProcessOpLog(t *testing.T) {
 		t.Errorf("unknown type for sqsMessageCount: %#v", sqsMessageCount)
 	}
 }
+
+func TestNewDeduplicator(t *testing.T) {
+	assert.Nil(t, newDeduplicator(&envConfig{}))
+	assert.NotNil(t, newDeduplicator(&envConfig{DedupWindowMinutes: 60, DedupCacheSize: 10}))
+	assert.NotNil(t, newDeduplicator(&envConfig{DedupWindowMinutes: 60, DedupCacheSize: 10, DedupTable: "table"}))
+}
+
+func TestConfigureProcessorInvalidChunkSize(t *testing.T) {
+	defer func(chunkSize int) { processor.ClassificationChunkSize = chunkSize }(processor.ClassificationChunkSize)
+
+	assert.Panics(t, func() { configureProcessor(&envConfig{MaxConcurrentStreams: 4, ClassificationChunkSize: 0}) })
+	assert.Panics(t, func() { configureProcessor(&envConfig{MaxConcurrentStreams: 4, ClassificationChunkSize: -1}) })
+	assert.Equal(t, 100, processor.ClassificationChunkSize)
+}
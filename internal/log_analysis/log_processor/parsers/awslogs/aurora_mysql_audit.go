@@ -20,7 +20,6 @@ package awslogs
 
 import (
 	"encoding/csv"
-	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
@@ -69,7 +68,7 @@ func (p *AuroraMySQLAuditParser) Parse(log string) []interface{} {
 		return nil
 	}
 
-	timestampUnixMillis, err := strconv.ParseInt(record[0], 0, 64)
+	timeStamp, err := timestamp.ParseUnixMicros(record[0])
 	if err != nil {
 		return nil
 	}
@@ -78,8 +77,6 @@ func (p *AuroraMySQLAuditParser) Parse(log string) []interface{} {
 	// We are concatenating them to re-create the field
 	objectString := strings.Join(record[8:len(record)-1], ",")
 
-	timeStamp := timestamp.Unix(timestampUnixMillis/1000000, timestampUnixMillis%1000000*1000)
-
 	event := &AuroraMySQLAudit{
 		Timestamp:    &timeStamp,
 		ServerHost:   csvStringToPointer(record[1]),
@@ -109,6 +109,14 @@ type CloudTrailSessionContextWebIDFederationData struct {
 	Attributes        interface{} `json:"attributes,omitempty"`
 }
 
+// EventIdentity returns the CloudTrail eventID, which is unique per event
+func (event *CloudTrail) EventIdentity() string {
+	if event.EventID == nil {
+		return ""
+	}
+	return *event.EventID
+}
+
 // CloudTrailParser parses CloudTrail logs
 type CloudTrailParser struct{}
 
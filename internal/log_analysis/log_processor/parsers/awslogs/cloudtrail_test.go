@@ -78,3 +78,9 @@ func TestCloudTrailLogType(t *testing.T) {
 	parser := &CloudTrailParser{}
 	require.Equal(t, "AWS.CloudTrail", parser.LogType())
 }
+
+func TestCloudTrailEventIdentity(t *testing.T) {
+	require.Equal(t, "5f6e9b4c-bcf1-4e0e-9c0e-0d4e1fd3f7c1",
+		(&CloudTrail{EventID: aws.String("5f6e9b4c-bcf1-4e0e-9c0e-0d4e1fd3f7c1")}).EventIdentity())
+	require.Equal(t, "", (&CloudTrail{}).EventIdentity())
+}
@@ -19,6 +19,8 @@ package awslogs
  */
 
 import (
+	"time"
+
 	jsoniter "github.com/json-iterator/go"
 	"go.uber.org/zap"
 
@@ -59,6 +61,15 @@ type GuardDutyService struct {
 	Count          *int               `json:"count"`
 }
 
+// EventIdentity returns the finding ID and the time it was updated. GuardDuty re-publishes
+// findings with the same ID when they recur, so the update time is needed to tell them apart.
+func (event *GuardDuty) EventIdentity() string {
+	if event.ID == nil || event.UpdatedAt == nil {
+		return ""
+	}
+	return *event.ID + "@" + time.Time(*event.UpdatedAt).UTC().Format(time.RFC3339Nano)
+}
+
 // VPCFlowParser parses AWS VPC Flow Parser logs
 type GuardDutyParser struct{}
 
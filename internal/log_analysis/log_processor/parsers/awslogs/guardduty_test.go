@@ -182,3 +182,13 @@ func TestGuardDutyLogType(t *testing.T) {
 	parser := &GuardDutyParser{}
 	require.Equal(t, "AWS.GuardDuty", parser.LogType())
 }
+
+func TestGuardDutyEventIdentity(t *testing.T) {
+	updatedAt := time.Unix(1535293043, 0).In(time.UTC)
+	event := &GuardDuty{
+		ID:        aws.String("44b7c4e9781822beb75d3fbd518abf5b"),
+		UpdatedAt: (*timestamp.RFC3339)(&updatedAt),
+	}
+	require.Equal(t, "44b7c4e9781822beb75d3fbd518abf5b@2018-08-26T14:17:23Z", event.EventIdentity())
+	require.Equal(t, "", (&GuardDuty{ID: event.ID}).EventIdentity())
+}
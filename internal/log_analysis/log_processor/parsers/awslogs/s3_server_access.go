@@ -88,7 +88,7 @@ func (p *S3ServerAccessParser) Parse(log string) []interface{} {
 	// The time in the logs is represented as [06/Feb/2019:00:00:38 +0000]
 	// The CSV reader will break the above date to two different fields `[06/Feb/2019:00:00:38` and `+0000]`
 	// We concatenate these fields before trying to parse them
-	parsedTime, err := timestamp.Parse("[2/Jan/2006:15:04:05-0700]", record[2]+record[3])
+	parsedTime, err := timestamp.ParseApacheCLF(record[2] + " " + record[3])
 	if err != nil {
 		zap.L().Debug("failed to parse timestamp log as csv")
 		return nil
@@ -20,7 +20,6 @@ package awslogs
 
 import (
 	"encoding/csv"
-	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
@@ -90,18 +89,15 @@ func (p *VPCFlowParser) Parse(log string) []interface{} {
 		account = &record[1]
 	}
 
-	startTimeUnix, err := strconv.Atoi(record[10])
+	startTime, err := timestamp.ParseUnixSeconds(record[10])
 	if err != nil {
 		return nil
 	}
-	endTimeUnix, err := strconv.Atoi(record[11])
+	endTime, err := timestamp.ParseUnixSeconds(record[11])
 	if err != nil {
 		return nil
 	}
 
-	startTime := timestamp.Unix(int64(startTimeUnix), 0)
-	endTime := timestamp.Unix(int64(endTimeUnix), 0)
-
 	event := &VPCFlow{
 		Version:     csvStringToIntPointer(record[0]),
 		Account:     account,
@@ -30,5 +30,12 @@ type LogParser interface {
 	Parse(log string) []interface{}
 }
 
+// Identifiable is implemented by events that carry a unique identity within their log type.
+// The identity is used to drop duplicate events (e.g. S3 redelivery or overlapping CloudTrail trails).
+type Identifiable interface {
+	// EventIdentity returns the identity of the event, empty if it cannot be determined
+	EventIdentity() string
+}
+
 // Validator can be used to validate schemas of log fields
 var Validator = validator.New()
@@ -0,0 +1,154 @@
+package timestamp
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Layouts commonly found in logs that are not covered by RFC3339
+const (
+	apacheCLFLayout = "2/Jan/2006:15:04:05 -0700" // day is usually zero padded, accept both
+
+	syslogLayout         = time.Stamp             // RFC 3164, no year and no zone
+	syslogWithYearLayout = "Jan _2 2006 15:04:05" // common variant that adds the year
+)
+
+// ISO 8601 allows many more layouts than RFC 3339, these are the ones seen in practice
+var iso8601Layouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999",
+	"20060102T150405.999999999Z0700",
+	"20060102T150405.999999999",
+	"2006-01-02",
+}
+
+// ApacheCLF is the Apache Common Log Format timestamp e.g. [10/Oct/2000:13:55:36 -0700], the brackets are optional
+type ApacheCLF time.Time
+
+func (ts *ApacheCLF) String() string {
+	return (*time.Time)(ts).UTC().String() // ensure UTC
+}
+
+func (ts *ApacheCLF) MarshalJSON() ([]byte, error) {
+	return marshalJSON((*time.Time)(ts))
+}
+
+func (ts *ApacheCLF) UnmarshalJSON(jsonBytes []byte) error {
+	return unmarshalJSONString(jsonBytes, (*time.Time)(ts), parseApacheCLF)
+}
+
+// SyslogRFC3164 is the BSD syslog timestamp e.g. Oct 11 22:14:15, which has no year nor zone.
+// The timestamp is assumed to be UTC and within the last year.
+type SyslogRFC3164 time.Time
+
+func (ts *SyslogRFC3164) String() string {
+	return (*time.Time)(ts).UTC().String() // ensure UTC
+}
+
+func (ts *SyslogRFC3164) MarshalJSON() ([]byte, error) {
+	return marshalJSON((*time.Time)(ts))
+}
+
+func (ts *SyslogRFC3164) UnmarshalJSON(jsonBytes []byte) error {
+	return unmarshalJSONString(jsonBytes, (*time.Time)(ts), parseSyslog)
+}
+
+// ISO8601 reads the common ISO 8601 variants (basic/extended format, space or T separator, optional zone),
+// timestamps without a zone are assumed to be UTC
+type ISO8601 time.Time
+
+func (ts *ISO8601) String() string {
+	return (*time.Time)(ts).UTC().String() // ensure UTC
+}
+
+func (ts *ISO8601) MarshalJSON() ([]byte, error) {
+	return marshalJSON((*time.Time)(ts))
+}
+
+func (ts *ISO8601) UnmarshalJSON(jsonBytes []byte) error {
+	return unmarshalJSONString(jsonBytes, (*time.Time)(ts), parseISO8601)
+}
+
+// Use these functions in parsers reading non-JSON logs (e.g. CSV columns)
+
+// ParseApacheCLF parses Apache Common Log Format timestamps, the brackets are optional
+func ParseApacheCLF(value string) (RFC3339, error) {
+	t, err := parseApacheCLF(value)
+	return (RFC3339)(t), err
+}
+
+// ParseSyslog parses RFC 3164 syslog timestamps, see SyslogRFC3164
+func ParseSyslog(value string) (RFC3339, error) {
+	t, err := parseSyslog(value)
+	return (RFC3339)(t), err
+}
+
+// ParseISO8601 parses the common ISO 8601 variants, see ISO8601
+func ParseISO8601(value string) (RFC3339, error) {
+	t, err := parseISO8601(value)
+	return (RFC3339)(t), err
+}
+
+func parseApacheCLF(value string) (time.Time, error) {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	t, err := time.Parse(apacheCLFLayout, value)
+	if err != nil {
+		return t, err
+	}
+	return t.UTC(), nil
+}
+
+func parseSyslog(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(syslogWithYearLayout, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(syslogLayout, value)
+	if err != nil {
+		return t, err
+	}
+	return withCurrentYear(t, time.Now().UTC()), nil
+}
+
+// withCurrentYear sets the year of a timestamp parsed without one, assuming it is not in the future
+func withCurrentYear(t, now time.Time) time.Time {
+	t = time.Date(now.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	if t.After(now.AddDate(0, 0, 1)) { // allow some clock skew, otherwise logged last December
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t.UTC()
+}
+
+func parseISO8601(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range iso8601Layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.Errorf("invalid ISO 8601 timestamp %q", value)
+}
@@ -0,0 +1,106 @@
+package timestamp
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampApacheCLF_Unmarshal(t *testing.T) {
+	var ts ApacheCLF
+	require.NoError(t, jsoniter.Unmarshal([]byte(`"[14/Dec/2019:20:01:01 -0500]"`), &ts))
+	assert.Equal(t, (ApacheCLF)(expectedTime), ts)
+
+	require.NoError(t, jsoniter.Unmarshal([]byte(`"15/Dec/2019:01:01:01 +0000"`), &ts))
+	assert.Equal(t, (ApacheCLF)(expectedTime), ts)
+}
+
+func TestTimestampApacheCLF_Marshal(t *testing.T) {
+	ts := (ApacheCLF)(expectedTime)
+	jsonTS, err := jsoniter.Marshal(&ts)
+	require.NoError(t, err)
+	assert.Equal(t, expectedMarshalString, string(jsonTS))
+}
+
+func TestTimestampSyslogRFC3164_Unmarshal(t *testing.T) {
+	var ts SyslogRFC3164
+	require.NoError(t, jsoniter.Unmarshal([]byte(`"Dec 15 2019 01:01:01"`), &ts))
+	assert.Equal(t, (SyslogRFC3164)(expectedTime), ts)
+
+	require.NoError(t, jsoniter.Unmarshal([]byte(`"Dec 15 01:01:01"`), &ts))
+	assert.Equal(t, time.December, time.Time(ts).Month())
+	assert.Equal(t, 15, time.Time(ts).Day())
+	assert.False(t, time.Time(ts).After(time.Now().AddDate(0, 0, 1)))
+}
+
+func TestWithCurrentYear(t *testing.T) {
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	decemberNoYear := time.Date(0, 12, 31, 1, 1, 1, 0, time.UTC)
+	assert.Equal(t, time.Date(2019, 12, 31, 1, 1, 1, 0, time.UTC), withCurrentYear(decemberNoYear, now))
+	januaryNoYear := time.Date(0, 1, 1, 1, 1, 1, 0, time.UTC)
+	assert.Equal(t, time.Date(2020, 1, 1, 1, 1, 1, 0, time.UTC), withCurrentYear(januaryNoYear, now))
+}
+
+func TestTimestampISO8601_Unmarshal(t *testing.T) {
+	for _, value := range []string{
+		"2019-12-15T01:01:01Z",
+		"2019-12-15T01:01:01.000Z",
+		"2019-12-14T20:01:01-05:00",
+		"2019-12-14T20:01:01-0500",
+		"2019-12-15T01:01:01",
+		"2019-12-15 01:01:01",
+		"2019-12-15 01:01:01+00:00",
+		"20191215T010101Z",
+		"20191215T010101",
+	} {
+		var ts ISO8601
+		require.NoError(t, jsoniter.Unmarshal([]byte(`"`+value+`"`), &ts), value)
+		assert.Equal(t, (ISO8601)(expectedTime), ts, value)
+	}
+
+	var ts ISO8601
+	require.NoError(t, jsoniter.Unmarshal([]byte(`"2019-12-15"`), &ts))
+	assert.Equal(t, (ISO8601)(time.Date(2019, 12, 15, 0, 0, 0, 0, time.UTC)), ts)
+
+	assert.Error(t, jsoniter.Unmarshal([]byte(`"15/12/2019"`), &ts))
+}
+
+func TestTimestampISO8601_Marshal(t *testing.T) {
+	ts := (ISO8601)(expectedTime)
+	jsonTS, err := jsoniter.Marshal(&ts)
+	require.NoError(t, err)
+	assert.Equal(t, expectedMarshalString, string(jsonTS))
+}
+
+func TestParseApacheCLF(t *testing.T) {
+	ts, err := ParseApacheCLF("[15/Dec/2019:01:01:01 +0000]")
+	require.NoError(t, err)
+	assert.Equal(t, (RFC3339)(expectedTime), ts)
+}
+
+func TestParseISO8601(t *testing.T) {
+	ts, err := ParseISO8601("2019-12-15 01:01:01")
+	require.NoError(t, err)
+	assert.Equal(t, (RFC3339)(expectedTime), ts)
+}
@@ -19,6 +19,8 @@ package timestamp
  */
 
 import (
+	"bytes"
+	"strconv"
 	"time"
 )
 
@@ -26,6 +28,7 @@ import (
 
 // NOTE: prefix the name of all objects with Timestamp so schema generation can automatically understand these.
 // NOTE: the suffix of the names is meant to reflect the time format being read (unmarshal)
+// NOTE: all types are normalized to UTC, new types need to be added to the Glue mappings in gluecf
 
 // We want our output JSON timestamps to be: YYYY-MM-DD HH:MM:SS.fffffffff
 // https://aws.amazon.com/premiumsupport/knowledge-center/query-table-athena-timestamp-empty/
@@ -33,8 +36,12 @@ const (
 	jsonMarshalLayout = `"2006-01-02 15:04:05.000000000"`
 
 	ansicWithTZUnmarshalLayout = `"Mon Jan 2 15:04:05 2006 MST"` // similar to time.ANSIC but with MST
+
+	rfc3339WithoutTZLayout = "2006-01-02T15:04:05.999999999"
 )
 
+var jsonNull = []byte("null")
+
 // use these functions to parse all incoming dates to ensure UTC consistency
 func Parse(layout, value string) (RFC3339, error) {
 	t, err := time.Parse(layout, value)
@@ -52,11 +59,23 @@ func (ts *RFC3339) String() string {
 }
 
 func (ts *RFC3339) MarshalJSON() ([]byte, error) {
-	return []byte((*time.Time)(ts).UTC().Format(jsonMarshalLayout)), nil // ensure UTC
+	return marshalJSON((*time.Time)(ts))
 }
 
+// UnmarshalJSON reads RFC3339 timestamps, timestamps without a zone are assumed to be UTC
 func (ts *RFC3339) UnmarshalJSON(jsonBytes []byte) (err error) {
-	return (*time.Time)(ts).UnmarshalJSON(jsonBytes)
+	return unmarshalJSONString(jsonBytes, (*time.Time)(ts), parseRFC3339)
+}
+
+func parseRFC3339(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		var withoutTZErr error
+		if t, withoutTZErr = time.Parse(rfc3339WithoutTZLayout, value); withoutTZErr != nil {
+			return t, err // report the original error
+		}
+	}
+	return t.UTC(), nil
 }
 
 // Like time.ANSIC but with MST
@@ -67,7 +86,7 @@ func (ts *ANSICwithTZ) String() string {
 }
 
 func (ts *ANSICwithTZ) MarshalJSON() ([]byte, error) {
-	return []byte((*time.Time)(ts).UTC().Format(jsonMarshalLayout)), nil // ensure UTC
+	return marshalJSON((*time.Time)(ts))
 }
 
 func (ts *ANSICwithTZ) UnmarshalJSON(text []byte) (err error) {
@@ -75,6 +94,32 @@ func (ts *ANSICwithTZ) UnmarshalJSON(text []byte) (err error) {
 	if err != nil {
 		return
 	}
-	*ts = (ANSICwithTZ)(t)
+	*ts = (ANSICwithTZ)(t.UTC())
 	return
 }
+
+// shared by all types, ensures UTC and the Athena compatible layout
+func marshalJSON(t *time.Time) ([]byte, error) {
+	return []byte(t.UTC().Format(jsonMarshalLayout)), nil
+}
+
+// unmarshalJSONString reads a JSON string (or bare JSON value such as a number) and parses it with parse.
+// JSON null leaves the timestamp untouched.
+func unmarshalJSONString(jsonBytes []byte, t *time.Time, parse func(string) (time.Time, error)) error {
+	if bytes.Equal(jsonBytes, jsonNull) {
+		return nil
+	}
+	value := string(jsonBytes)
+	if len(jsonBytes) > 0 && jsonBytes[0] == '"' {
+		var err error
+		if value, err = strconv.Unquote(value); err != nil {
+			return err
+		}
+	}
+	parsed, err := parse(value)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
@@ -72,3 +72,22 @@ func TestTimestampANSICwithTZ_Unmarshal(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, (ANSICwithTZ)(expectedTime), ts)
 }
+
+func TestTimestampRFC3339_UnmarshalWithoutTZ(t *testing.T) {
+	var ts RFC3339
+	err := jsoniter.Unmarshal([]byte(`"2019-12-15T01:01:01"`), &ts)
+	assert.NoError(t, err)
+	assert.Equal(t, (RFC3339)(expectedTime), ts)
+}
+
+func TestTimestampRFC3339_UnmarshalOffset(t *testing.T) {
+	var ts RFC3339
+	err := jsoniter.Unmarshal([]byte(`"2019-12-14T20:01:01-05:00"`), &ts)
+	assert.NoError(t, err)
+	assert.Equal(t, (RFC3339)(expectedTime), ts)
+}
+
+func TestTimestampRFC3339_UnmarshalInvalid(t *testing.T) {
+	var ts RFC3339
+	assert.Error(t, jsoniter.Unmarshal([]byte(`"not a timestamp"`), &ts))
+}
@@ -0,0 +1,146 @@
+package timestamp
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Epoch timestamps, read from either a JSON number or a JSON string
+
+// UnixSeconds is seconds since the epoch, fractional seconds are allowed (e.g. 1576371661.123)
+type UnixSeconds time.Time
+
+func (ts *UnixSeconds) String() string {
+	return (*time.Time)(ts).UTC().String() // ensure UTC
+}
+
+func (ts *UnixSeconds) MarshalJSON() ([]byte, error) {
+	return marshalJSON((*time.Time)(ts))
+}
+
+func (ts *UnixSeconds) UnmarshalJSON(jsonBytes []byte) error {
+	return unmarshalJSONString(jsonBytes, (*time.Time)(ts), parseUnixSeconds)
+}
+
+// UnixMillis is milliseconds since the epoch
+type UnixMillis time.Time
+
+func (ts *UnixMillis) String() string {
+	return (*time.Time)(ts).UTC().String() // ensure UTC
+}
+
+func (ts *UnixMillis) MarshalJSON() ([]byte, error) {
+	return marshalJSON((*time.Time)(ts))
+}
+
+func (ts *UnixMillis) UnmarshalJSON(jsonBytes []byte) error {
+	return unmarshalJSONString(jsonBytes, (*time.Time)(ts), parseUnixMillis)
+}
+
+// UnixNanos is nanoseconds since the epoch
+type UnixNanos time.Time
+
+func (ts *UnixNanos) String() string {
+	return (*time.Time)(ts).UTC().String() // ensure UTC
+}
+
+func (ts *UnixNanos) MarshalJSON() ([]byte, error) {
+	return marshalJSON((*time.Time)(ts))
+}
+
+func (ts *UnixNanos) UnmarshalJSON(jsonBytes []byte) error {
+	return unmarshalJSONString(jsonBytes, (*time.Time)(ts), parseUnixNanos)
+}
+
+// Use these functions in parsers reading non-JSON logs (e.g. CSV columns)
+
+// ParseUnixSeconds parses seconds since the epoch, fractional seconds are allowed
+func ParseUnixSeconds(value string) (RFC3339, error) {
+	t, err := parseUnixSeconds(value)
+	return (RFC3339)(t), err
+}
+
+// ParseUnixMillis parses milliseconds since the epoch
+func ParseUnixMillis(value string) (RFC3339, error) {
+	t, err := parseUnixMillis(value)
+	return (RFC3339)(t), err
+}
+
+// ParseUnixMicros parses microseconds since the epoch
+func ParseUnixMicros(value string) (RFC3339, error) {
+	t, err := parseUnixMultiple(value, int64(time.Microsecond))
+	return (RFC3339)(t), err
+}
+
+// ParseUnixNanos parses nanoseconds since the epoch
+func ParseUnixNanos(value string) (RFC3339, error) {
+	t, err := parseUnixNanos(value)
+	return (RFC3339)(t), err
+}
+
+func parseUnixSeconds(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	// split the fraction to avoid losing precision in float64
+	whole, fraction := value, ""
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		whole, fraction = value[:dot], value[dot+1:]
+	}
+	sec, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid unix timestamp %q", value)
+	}
+	var nsec int64
+	if fraction != "" {
+		const nanoDigits = 9
+		if len(fraction) > nanoDigits {
+			fraction = fraction[:nanoDigits]
+		}
+		nsec, err = strconv.ParseInt(fraction+strings.Repeat("0", nanoDigits-len(fraction)), 10, 64)
+		if err != nil || nsec < 0 {
+			return time.Time{}, errors.Errorf("invalid unix timestamp %q", value)
+		}
+		if strings.HasPrefix(whole, "-") {
+			nsec = -nsec
+		}
+	}
+	return time.Unix(sec, nsec).UTC(), nil
+}
+
+func parseUnixMillis(value string) (time.Time, error) {
+	return parseUnixMultiple(value, int64(time.Millisecond))
+}
+
+func parseUnixNanos(value string) (time.Time, error) {
+	return parseUnixMultiple(value, int64(time.Nanosecond))
+}
+
+// parseUnixMultiple parses an integer count of units, each unit being unitNanos nanoseconds
+func parseUnixMultiple(value string, unitNanos int64) (time.Time, error) {
+	count, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid unix timestamp %q", value)
+	}
+	unitsPerSecond := int64(time.Second) / unitNanos
+	return time.Unix(count/unitsPerSecond, count%unitsPerSecond*unitNanos).UTC(), nil
+}
@@ -0,0 +1,99 @@
+package timestamp
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unixEvent struct {
+	Seconds *UnixSeconds `json:"seconds,omitempty"`
+	Millis  *UnixMillis  `json:"millis,omitempty"`
+	Nanos   *UnixNanos   `json:"nanos,omitempty"`
+}
+
+func TestTimestampUnix_UnmarshalNumber(t *testing.T) {
+	var event unixEvent
+	err := jsoniter.UnmarshalFromString(`{"seconds":1576371661,"millis":1576371661000,"nanos":1576371661000000000}`, &event)
+	require.NoError(t, err)
+	assert.Equal(t, (UnixSeconds)(expectedTime), *event.Seconds)
+	assert.Equal(t, (UnixMillis)(expectedTime), *event.Millis)
+	assert.Equal(t, (UnixNanos)(expectedTime), *event.Nanos)
+}
+
+func TestTimestampUnix_UnmarshalString(t *testing.T) {
+	var event unixEvent
+	err := jsoniter.UnmarshalFromString(`{"seconds":"1576371661","millis":"1576371661000","nanos":"1576371661000000000"}`, &event)
+	require.NoError(t, err)
+	assert.Equal(t, (UnixSeconds)(expectedTime), *event.Seconds)
+	assert.Equal(t, (UnixMillis)(expectedTime), *event.Millis)
+	assert.Equal(t, (UnixNanos)(expectedTime), *event.Nanos)
+}
+
+func TestTimestampUnix_UnmarshalNull(t *testing.T) {
+	var event unixEvent
+	require.NoError(t, jsoniter.UnmarshalFromString(`{"seconds":null}`, &event))
+	assert.Nil(t, event.Seconds)
+}
+
+func TestTimestampUnix_UnmarshalInvalid(t *testing.T) {
+	var event unixEvent
+	assert.Error(t, jsoniter.UnmarshalFromString(`{"millis":"yesterday"}`, &event))
+	assert.Error(t, jsoniter.UnmarshalFromString(`{"seconds":"1576371661.-5"}`, &event))
+}
+
+func TestTimestampUnix_Marshal(t *testing.T) {
+	ts := (UnixMillis)(expectedTime)
+	jsonTS, err := jsoniter.Marshal(&ts)
+	require.NoError(t, err)
+	assert.Equal(t, expectedMarshalString, string(jsonTS))
+}
+
+func TestParseUnixSecondsFraction(t *testing.T) {
+	ts, err := ParseUnixSeconds("1576371661.123456789123")
+	require.NoError(t, err)
+	assert.Equal(t, (RFC3339)(expectedTime.Add(123456789*time.Nanosecond)), ts)
+
+	ts, err = ParseUnixSeconds("1576371661.5")
+	require.NoError(t, err)
+	assert.Equal(t, (RFC3339)(expectedTime.Add(500*time.Millisecond)), ts)
+}
+
+func TestParseUnixMicros(t *testing.T) {
+	ts, err := ParseUnixMicros("1576371661000001")
+	require.NoError(t, err)
+	assert.Equal(t, (RFC3339)(expectedTime.Add(time.Microsecond)), ts)
+}
+
+func TestParseUnixMillis(t *testing.T) {
+	ts, err := ParseUnixMillis("1576371661001")
+	require.NoError(t, err)
+	assert.Equal(t, (RFC3339)(expectedTime.Add(time.Millisecond)), ts)
+}
+
+func TestParseUnixNanos(t *testing.T) {
+	ts, err := ParseUnixNanos("1576371661000000001")
+	require.NoError(t, err)
+	assert.Equal(t, (RFC3339)(expectedTime.Add(time.Nanosecond)), ts)
+}
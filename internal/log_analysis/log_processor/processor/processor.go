@@ -21,6 +21,7 @@ package processor
 import (
 	"bufio"
 	"io"
+	"runtime"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -28,7 +29,9 @@ import (
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/dedup"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/pkg/metrics"
 	"github.com/panther-labs/panther/pkg/oplog"
 )
 
@@ -36,6 +39,7 @@ const (
 	// oplog keys
 	operationName = "parse"
 	statsKey      = "stats"
+	duplicatesKey = "duplicateEventCount"
 )
 
 var (
@@ -46,6 +50,21 @@ var (
 	// to avoid using up lot of memory.
 	// see also: https://golang.org/doc/effective_go.html#channels
 	ParsedEventBufferSize = 1000
+
+	// MaxConcurrentStreams bounds the number of data streams processed at the same time. Each stream holds
+	// buffered reads and classified chunks in memory, so this bounds the memory used by a large SQS batch.
+	MaxConcurrentStreams = 4
+
+	// ClassificationWorkers is the number of goroutines classifying the lines of a single data stream.
+	// Classification is CPU bound, so there is no benefit going over the number of CPUs.
+	ClassificationWorkers = runtime.NumCPU()
+
+	// ClassificationChunkSize is the number of consecutive lines handed to a classification worker at a time.
+	// Larger chunks reduce synchronization overhead but increase the memory held per stream.
+	ClassificationChunkSize = 100
+
+	// Deduplicator, if set, drops events whose identity was already seen within its window
+	Deduplicator *dedup.Deduplicator
 )
 
 // Process orchestrates the tasks of parsing logs, classification, normalization
@@ -69,17 +88,21 @@ func process(dataStreams []*common.DataStream, destination destinations.Destinat
 		sendEventsWg.Done()
 	}()
 
+	// acquired by each stream goroutine before processing, bounds the number of streams processed at once
+	streamSlots := make(chan struct{}, maxInt(MaxConcurrentStreams, 1))
+
 	var streamProcessingWg sync.WaitGroup
 	for _, dataStream := range dataStreams {
-		processor := newProcessorFunc(dataStream)
 		streamProcessingWg.Add(1)
-		go func(p *Processor) {
-			err := p.run(parsedEventChannel)
+		go func(dataStream *common.DataStream) {
+			streamSlots <- struct{}{}
+			err := newProcessorFunc(dataStream).run(parsedEventChannel)
+			<-streamSlots // release before blocking on the error channel, which is only read below
 			if err != nil {
 				errorChannel <- err
 			}
 			streamProcessingWg.Done()
-		}(processor)
+		}(dataStream)
 	}
 
 	go func() {
@@ -100,46 +123,137 @@ func process(dataStreams []*common.DataStream, destination destinations.Destinat
 	var err error
 	for err = range errorChannel {
 	} // to ensure there are not writes to a closed channel, loop to drain
+	completeDeduplication(err)
 	return err
 }
 
-// processStream reads the data from an S3 the dataStream, parses it and writes events to the output channel
+// completeDeduplication records the identities of the written events, or forgets them if the processing failed
+// so that the retried events are not dropped as duplicates
+func completeDeduplication(processErr error) {
+	if Deduplicator == nil {
+		return
+	}
+	if processErr != nil {
+		Deduplicator.Discard()
+		return
+	}
+	// the events are written, failing now would only write them again
+	if err := Deduplicator.Commit(); err != nil {
+		zap.L().Warn("failed to record event identities", zap.Error(err))
+	}
+}
+
+// chunk is a run of consecutive lines of a data stream, classified by a single worker
+type chunk struct {
+	firstLineNum uint64 // number of lines in the stream before this chunk
+	lines        []string
+	results      []*classification.ClassifierResult
+	classified   chan struct{} // closed once results are set
+}
+
+func newChunk(firstLineNum uint64) *chunk {
+	return &chunk{
+		firstLineNum: firstLineNum,
+		lines:        make([]string, 0, ClassificationChunkSize),
+		classified:   make(chan struct{}),
+	}
+}
+
+// run reads the data from the dataStream, parses it and writes events to the output channel.
+// Chunks of lines are classified in parallel, one worker per classifier, but events are written
+// in the same order as the lines of the stream.
 func (p *Processor) run(outputChan chan *common.ParsedEvent) error {
+	pendingChunks := make(chan *chunk)                     // chunks waiting for a worker
+	orderedChunks := make(chan *chunk, len(p.classifiers)) // chunks in stream order, bounds the chunks in flight
+
+	var workersWg sync.WaitGroup
+	for _, classifier := range p.classifiers {
+		workersWg.Add(1)
+		go func(classifier classification.ClassifierAPI) {
+			for c := range pendingChunks {
+				p.classifyChunk(classifier, c)
+			}
+			workersWg.Done()
+		}(classifier)
+	}
+
+	sendDone := make(chan struct{})
+	go func() {
+		for c := range orderedChunks {
+			<-c.classified
+			for _, result := range c.results {
+				if result.LogType == nil { // unable to classify, no error, keep parsing (best effort, will be logged)
+					continue
+				}
+				p.sendEvents(result, outputChan)
+			}
+		}
+		close(sendDone)
+	}()
+
+	err := p.readChunks(pendingChunks, orderedChunks)
+	close(pendingChunks)
+	close(orderedChunks)
+	workersWg.Wait()
+	<-sendDone
+
+	p.logStats(err) // emit log line describing the processing of the file and any errors
+	return err
+}
+
+// readChunks reads lines from the dataStream and dispatches them in chunks to the workers
+func (p *Processor) readChunks(pendingChunks, orderedChunks chan<- *chunk) error {
+	dispatch := func(c *chunk) {
+		orderedChunks <- c // first, so the chunk is queued in order before a worker can pick it up
+		pendingChunks <- c
+	}
+
 	var err error
+	var lineNum uint64
 	stream := bufio.NewReader(p.input.Reader)
+	current := newChunk(lineNum)
 	for {
 		var line string
 		line, err = stream.ReadString('\n')
 		if err != nil {
 			if err == io.EOF { // we are done
 				err = nil // not really an error
-				p.processLogLine(line, outputChan)
+				current.lines = append(current.lines, line)
 			}
 			break
 		}
-		p.processLogLine(line, outputChan)
+		current.lines = append(current.lines, line)
+		lineNum++
+		if len(current.lines) >= ClassificationChunkSize {
+			dispatch(current)
+			current = newChunk(lineNum)
+		}
+	}
+	if len(current.lines) > 0 {
+		dispatch(current)
 	}
 	if err != nil {
 		err = errors.Wrap(err, "failed to ReadString()")
 	}
-	p.logStats(err) // emit log line describing the processing of the file and any errors
 	return err
 }
 
-func (p *Processor) processLogLine(line string, outputChan chan *common.ParsedEvent) {
-	classificationResult := p.classifyLogLine(line)
-	if classificationResult.LogType == nil { // unable to classify, no error, keep parsing (best effort, will be logged)
-		return
+func (p *Processor) classifyChunk(classifier classification.ClassifierAPI, c *chunk) {
+	c.results = make([]*classification.ClassifierResult, len(c.lines))
+	for i, line := range c.lines {
+		c.results[i] = p.classifyLogLine(classifier, line, c.firstLineNum+uint64(i)+1)
 	}
-	p.sendEvents(classificationResult, outputChan)
+	close(c.classified)
 }
 
-func (p *Processor) classifyLogLine(line string) *classification.ClassifierResult {
-	result := p.classifier.Classify(line)
+func (p *Processor) classifyLogLine(classifier classification.ClassifierAPI, line string,
+	lineNum uint64) *classification.ClassifierResult {
+
+	result := classifier.Classify(line)
 	if result.LogType == nil && len(result.LogLine) > 0 { // only if line is not empty do we log (often we get trailing \n's)
 		if p.input.Hints.S3 != nil { // make easy to troubleshoot but do not add log line (even partial) to avoid leaking data into CW
 			p.operation.LogWarn(errors.New("failed to classify log line"),
-				zap.Uint64("lineNum", p.classifier.Stats().LogLineCount),
+				zap.Uint64("lineNum", lineNum),
 				zap.String("bucket", p.input.Hints.S3.Bucket),
 				zap.String("key", p.input.Hints.S3.Key))
 		}
@@ -149,6 +263,10 @@ func (p *Processor) classifyLogLine(line string) *classification.ClassifierResul
 
 func (p *Processor) sendEvents(result *classification.ClassifierResult, outputChan chan *common.ParsedEvent) {
 	for _, parsedEvent := range result.Events {
+		if Deduplicator != nil && Deduplicator.IsDuplicate(*result.LogType, parsedEvent) {
+			p.duplicateEventCount++
+			continue
+		}
 		message := &common.ParsedEvent{
 			Event:   parsedEvent,
 			LogType: *result.LogType,
@@ -159,22 +277,80 @@ func (p *Processor) sendEvents(result *classification.ClassifierResult, outputCh
 
 func (p *Processor) logStats(err error) {
 	p.operation.Stop()
-	p.operation.Log(err, zap.Any(statsKey, *p.classifier.Stats()))
-	for _, parserStats := range p.classifier.ParserStats() {
-		p.operation.Log(err, zap.Any(statsKey, *parserStats))
+	stats, parserStats := p.stats()
+	p.operation.Log(err, zap.Any(statsKey, stats), zap.Uint64(duplicatesKey, p.duplicateEventCount))
+	for _, logTypeStats := range parserStats {
+		p.operation.Log(err, zap.Any(statsKey, *logTypeStats))
 	}
+	p.logMetrics(&stats, parserStats)
+}
+
+// logMetrics emits the stats as CloudWatch metrics, to graph volume per log type and alarm on classification failures
+func (p *Processor) logMetrics(stats *classification.ClassifierStats, parserStats map[string]*classification.ParserStats) {
+	common.LogMetrics(nil,
+		metrics.Metric{Name: common.MetricLogLines, Unit: metrics.UnitCount, Value: float64(stats.LogLineCount)},
+		metrics.Metric{Name: common.MetricBytesProcessed, Unit: metrics.UnitBytes, Value: float64(stats.BytesProcessedCount)},
+		metrics.Metric{Name: common.MetricClassifyTime, Unit: metrics.UnitMicroseconds,
+			Value: float64(stats.ClassifyTimeMicroseconds)},
+		metrics.Metric{Name: common.MetricSuccessfullyClassified, Unit: metrics.UnitCount,
+			Value: float64(stats.SuccessfullyClassifiedCount)},
+		metrics.Metric{Name: common.MetricClassificationFailures, Unit: metrics.UnitCount,
+			Value: float64(stats.ClassificationFailureCount)},
+		metrics.Metric{Name: common.MetricDuplicateEvents, Unit: metrics.UnitCount, Value: float64(p.duplicateEventCount)},
+	)
+	for logType, logTypeStats := range parserStats {
+		common.LogMetrics(common.LogTypeDimensions(logType),
+			metrics.Metric{Name: common.MetricLogLines, Unit: metrics.UnitCount, Value: float64(logTypeStats.LogLineCount)},
+			metrics.Metric{Name: common.MetricBytesProcessed, Unit: metrics.UnitBytes,
+				Value: float64(logTypeStats.BytesProcessedCount)},
+			metrics.Metric{Name: common.MetricEvents, Unit: metrics.UnitCount, Value: float64(logTypeStats.EventCount)},
+			metrics.Metric{Name: common.MetricParserTime, Unit: metrics.UnitMicroseconds,
+				Value: float64(logTypeStats.ParserTimeMicroseconds)},
+		)
+	}
+}
+
+// stats sums the stats of all classifiers
+func (p *Processor) stats() (stats classification.ClassifierStats, parserStats map[string]*classification.ParserStats) {
+	parserStats = make(map[string]*classification.ParserStats)
+	for _, classifier := range p.classifiers {
+		stats.Add(classifier.Stats())
+		for logType, classifierParserStats := range classifier.ParserStats() {
+			logTypeStats, found := parserStats[logType]
+			if !found {
+				logTypeStats = &classification.ParserStats{LogType: logType}
+				parserStats[logType] = logTypeStats
+			}
+			logTypeStats.Add(classifierParserStats)
+		}
+	}
+	return stats, parserStats
 }
 
 type Processor struct {
-	input      *common.DataStream
-	classifier classification.ClassifierAPI
-	operation  *oplog.Operation
+	input *common.DataStream
+	// one per classification worker, classifiers keep state and are not safe for concurrent use
+	classifiers []classification.ClassifierAPI
+	operation   *oplog.Operation
+	// events dropped by the Deduplicator
+	duplicateEventCount uint64
 }
 
 func NewProcessor(input *common.DataStream) *Processor {
+	classifiers := make([]classification.ClassifierAPI, maxInt(ClassificationWorkers, 1))
+	for i := range classifiers {
+		classifiers[i] = classification.NewClassifier()
+	}
 	return &Processor{
-		input:      input,
-		classifier: classification.NewClassifier(),
-		operation:  common.OpLogManager.Start(operationName),
+		input:       input,
+		classifiers: classifiers,
+		operation:   common.OpLogManager.Start(operationName),
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
 	}
+	return b
 }
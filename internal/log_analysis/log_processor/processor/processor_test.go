@@ -19,8 +19,12 @@ package processor
  */
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -28,13 +32,16 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/dedup"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/pkg/metrics"
 	"github.com/panther-labs/panther/pkg/oplog"
 )
 
@@ -63,7 +70,7 @@ func TestProcess(t *testing.T) {
 	dataStream := makeDataStream()
 	p := NewProcessor(dataStream)
 	mockClassifier := &testClassifier{}
-	p.classifier = mockClassifier
+	p.classifiers = []classification.ClassifierAPI{mockClassifier}
 
 	mockStats := &classification.ClassifierStats{
 		ClassifyTimeMicroseconds:    1,
@@ -98,7 +105,7 @@ func TestProcessDataStreamError(t *testing.T) {
 	dataStream := makeBadDataStream() // failure to read data, never hits classifier
 	p := NewProcessor(dataStream)
 	mockClassifier := &testClassifier{}
-	p.classifier = mockClassifier
+	p.classifiers = []classification.ClassifierAPI{mockClassifier}
 
 	// classifier never gets called
 	mockStats := &classification.ClassifierStats{}
@@ -121,6 +128,7 @@ func TestProcessDataStreamError(t *testing.T) {
 		Context: []zapcore.Field{
 			// custom
 			zap.Any(statsKey, *mockStats),
+			zap.Uint64(duplicatesKey, 0),
 
 			// error
 			zap.Error(errors.Wrap(errFailingReader, "failed to ReadString()")), // from run()
@@ -160,7 +168,7 @@ func TestProcessDestinationError(t *testing.T) {
 	dataStream := makeDataStream()
 	p := NewProcessor(dataStream)
 	mockClassifier := &testClassifier{}
-	p.classifier = mockClassifier
+	p.classifiers = []classification.ClassifierAPI{mockClassifier}
 
 	mockStats := &classification.ClassifierStats{
 		ClassifyTimeMicroseconds:    1,
@@ -200,7 +208,7 @@ func TestProcessClassifyFailure(t *testing.T) {
 	dataStream := makeDataStream()
 	p := NewProcessor(dataStream)
 	mockClassifier := &testClassifier{}
-	p.classifier = mockClassifier
+	p.classifiers = []classification.ClassifierAPI{mockClassifier}
 
 	mockStats := &classification.ClassifierStats{
 		ClassifyTimeMicroseconds:    1,
@@ -271,6 +279,7 @@ func TestProcessClassifyFailure(t *testing.T) {
 			Context: []zapcore.Field{
 				// custom
 				zap.Any(statsKey, *mockStats),
+				zap.Uint64(duplicatesKey, 0),
 
 				// standard
 				zap.String("namespace", common.OpLogNamespace),
@@ -308,6 +317,140 @@ func TestProcessClassifyFailure(t *testing.T) {
 	}
 }
 
+func TestProcessClassificationWorkersPreserveOrder(t *testing.T) {
+	defer func(chunkSize int) { ClassificationChunkSize = chunkSize }(ClassificationChunkSize)
+	ClassificationChunkSize = 7 // not a divisor of the number of lines, so the last chunk is partial
+
+	const numLines = 1000
+	lines := make([]string, numLines)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i)
+	}
+	dataStream := &common.DataStream{
+		Reader:  strings.NewReader(strings.Join(lines, "\n")),
+		LogType: &testLogType,
+	}
+
+	p := NewProcessor(dataStream)
+	p.classifiers = []classification.ClassifierAPI{
+		&echoClassifier{}, &echoClassifier{}, &echoClassifier{}, &echoClassifier{},
+	}
+	destination := &collectingDestination{}
+
+	newProcessorFunc := func(*common.DataStream) *Processor { return p }
+	require.NoError(t, process([]*common.DataStream{dataStream}, destination, newProcessorFunc))
+
+	require.Equal(t, numLines, len(destination.events))
+	for i, event := range destination.events {
+		require.Equal(t, lines[i], event.Event)
+	}
+
+	stats, parserStats := p.stats()
+	assert.Equal(t, uint64(numLines), stats.LogLineCount)
+	assert.Equal(t, uint64(numLines), parserStats[testLogType].EventCount)
+}
+
+func TestProcessMaxConcurrentStreams(t *testing.T) {
+	defer func(maxStreams int) { MaxConcurrentStreams = maxStreams }(MaxConcurrentStreams)
+	MaxConcurrentStreams = 2
+
+	const numStreams = 6
+	barrier := &streamBarrier{started: make(chan struct{}, numStreams), release: make(chan struct{})}
+	dataStreams := make([]*common.DataStream, numStreams)
+	for i := range dataStreams {
+		dataStreams[i] = &common.DataStream{
+			Reader:  &blockingReader{barrier: barrier},
+			LogType: &testLogType,
+		}
+	}
+	newProcessorFunc := func(dataStream *common.DataStream) *Processor {
+		p := NewProcessor(dataStream)
+		p.classifiers = []classification.ClassifierAPI{&echoClassifier{}}
+		return p
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- process(dataStreams, &collectingDestination{}, newProcessorFunc)
+	}()
+
+	// The first streams fill the slots and block until released
+	for i := 0; i < MaxConcurrentStreams; i++ {
+		<-barrier.started
+	}
+	assert.Equal(t, int32(MaxConcurrentStreams), atomic.LoadInt32(&barrier.active))
+	// Each stream released frees a slot for exactly one of the waiting streams
+	for i := 0; i < numStreams; i++ {
+		barrier.release <- struct{}{}
+		if i < numStreams-MaxConcurrentStreams {
+			<-barrier.started
+		}
+	}
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(MaxConcurrentStreams), atomic.LoadInt32(&barrier.maxActive))
+}
+
+func TestProcessMetrics(t *testing.T) {
+	var buffer bytes.Buffer
+	defer func(logger *metrics.Logger) { common.MetricsLogger = logger }(common.MetricsLogger)
+	common.MetricsLogger = metrics.NewLoggerWithWriter(common.MetricsNamespace, &buffer)
+
+	dataStream := makeDataStream()
+	p := NewProcessor(dataStream)
+	p.classifiers = []classification.ClassifierAPI{&echoClassifier{}}
+
+	newProcessorFunc := func(*common.DataStream) *Processor { return p }
+	require.NoError(t, process([]*common.DataStream{dataStream}, &collectingDestination{}, newProcessorFunc))
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 2) // all log types, then one per log type
+
+	all := gjson.Parse(lines[0])
+	assert.Equal(t, common.MetricsNamespace, all.Get("_aws.CloudWatchMetrics.0.Namespace").String())
+	assert.Equal(t, float64(testLogLines), all.Get(common.MetricLogLines).Float())
+	assert.True(t, all.Get(common.MetricClassificationFailures).Exists())
+
+	logType := gjson.Parse(lines[1])
+	assert.Equal(t, testLogType, logType.Get(common.MetricsLogTypeDim).String())
+	assert.Equal(t, float64(testLogEvents), logType.Get(common.MetricEvents).Float())
+}
+
+func TestCompleteDeduplication(t *testing.T) {
+	defer func(deduplicator *dedup.Deduplicator) { Deduplicator = deduplicator }(Deduplicator)
+	var err error
+	Deduplicator, err = dedup.New(time.Hour, 10, nil)
+	require.NoError(t, err)
+
+	// the events of a failed batch are processed again on retry
+	require.False(t, Deduplicator.IsDuplicate(testLogType, &identifiableEvent{id: "1"}))
+	completeDeduplication(errors.New("failure"))
+	require.False(t, Deduplicator.IsDuplicate(testLogType, &identifiableEvent{id: "1"}))
+
+	// the events of a written batch are duplicates
+	completeDeduplication(nil)
+	assert.True(t, Deduplicator.IsDuplicate(testLogType, &identifiableEvent{id: "1"}))
+}
+
+func BenchmarkProcessClassificationWorkers(b *testing.B) {
+	//nolint
+	const vpcFlowLine = "2 348372346321 eni-00184058652e5a320 52.119.169.95 172.31.20.31 443 48316 6 19 7119 1573642242 1573642284 ACCEPT OK"
+	const numLines = 10000
+	data := strings.Repeat(vpcFlowLine+"\n", numLines)
+
+	defer func(workers int) { ClassificationWorkers = workers }(ClassificationWorkers)
+	for _, workers := range []int{1, 2, 4, 8} {
+		ClassificationWorkers = workers
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				dataStream := &common.DataStream{Reader: strings.NewReader(data)}
+				err := process([]*common.DataStream{dataStream}, &collectingDestination{}, NewProcessor)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
 // deals with the error package inserting line numbers into errors
 func assertLogEqual(t *testing.T, expected, actual observer.LoggedEntry) {
 	for k, v := range expected.ContextMap() {
@@ -413,3 +556,78 @@ func mockLogger() *observer.ObservedLogs {
 	zap.ReplaceGlobals(zap.New(core))
 	return mockLog
 }
+
+// echoClassifier classifies every line as testLogType, with the line as the event
+type echoClassifier struct {
+	stats       classification.ClassifierStats
+	parserStats classification.ParserStats
+}
+
+func (c *echoClassifier) Classify(log string) *classification.ClassifierResult {
+	log = strings.TrimSpace(log)
+	c.stats.LogLineCount++
+	c.parserStats.EventCount++
+	time.Sleep(time.Duration(len(log)) * time.Microsecond) // vary the work so chunks complete out of order
+	return &classification.ClassifierResult{
+		Events:  []interface{}{log},
+		LogType: &testLogType,
+		LogLine: log,
+	}
+}
+
+func (c *echoClassifier) Stats() *classification.ClassifierStats {
+	return &c.stats
+}
+
+func (c *echoClassifier) ParserStats() map[string]*classification.ParserStats {
+	return map[string]*classification.ParserStats{testLogType: &c.parserStats}
+}
+
+type identifiableEvent struct {
+	id string
+}
+
+func (event *identifiableEvent) EventIdentity() string {
+	return event.id
+}
+
+// collectingDestination keeps all events in the order received
+type collectingDestination struct {
+	events []*common.ParsedEvent
+}
+
+func (d *collectingDestination) SendEvents(parsedEventChannel chan *common.ParsedEvent, errChan chan error) {
+	for event := range parsedEventChannel {
+		d.events = append(d.events, event)
+	}
+}
+
+// streamBarrier holds the streams being read until the test releases them
+type streamBarrier struct {
+	started, release  chan struct{}
+	active, maxActive int32
+}
+
+// blockingReader signals the barrier when its stream is read and blocks until it is released
+type blockingReader struct {
+	barrier *streamBarrier
+	done    bool
+}
+
+func (r *blockingReader) Read(b []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	r.done = true
+	active := atomic.AddInt32(&r.barrier.active, 1)
+	for {
+		maxActive := atomic.LoadInt32(&r.barrier.maxActive)
+		if active <= maxActive || atomic.CompareAndSwapInt32(&r.barrier.maxActive, maxActive, active) {
+			break
+		}
+	}
+	r.barrier.started <- struct{}{}
+	<-r.barrier.release
+	atomic.AddInt32(&r.barrier.active, -1)
+	return copy(b, testLogLine), nil
+}
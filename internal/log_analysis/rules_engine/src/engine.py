@@ -17,7 +17,7 @@
 import collections
 from datetime import datetime, timedelta
 from timeit import default_timer
-from typing import Any, Dict, List
+from typing import Any, Dict, List, NamedTuple, Optional
 
 from .analysis_api import AnalysisAPIClient
 from .logging import get_logger
@@ -26,6 +26,18 @@ from .rule import Rule, COMMON_MODULE_RULE_ID
 _CACHE_DURATION = timedelta(minutes=5)
 
 
+class EngineMatch(NamedTuple):
+    """A rule which matched an event, with the alert settings of the rule."""
+    rule_id: str
+    dedup: str
+    dedup_period_minutes: Optional[int]
+    threshold: Optional[int]
+    threshold_window_minutes: Optional[int]
+    title: str
+    alert_context: Dict[str, str]
+    event: Dict[str, Any]
+
+
 class Engine:
     """The engine that runs Python rules."""
     logger = get_logger()
@@ -51,7 +63,13 @@ class Engine:
                 del rules[index]
                 break
         for raw_rule in rules:
-            rule = Rule(raw_rule['id'], raw_rule['body'])
+            # Correlation rules match on the alerts of other rules, they are evaluated by the alert merger
+            if raw_rule.get('correlation'):
+                continue
+            rule = Rule(
+                raw_rule['id'], raw_rule['body'], raw_rule.get('dedupPeriodMinutes'), raw_rule.get('threshold'),
+                raw_rule.get('thresholdWindowMinutes')
+            )
             for log_type in raw_rule['resourceTypes']:
                 self._log_type_to_rules[log_type].append(rule)
         end = default_timer()
@@ -62,26 +80,37 @@ class Engine:
         """Retrieves all enabled rules.
 
         Returns:
-            An array of Dict['id': rule_id, 'body': rule_body]
+            An array of Dict['id': rule_id, 'body': rule_body, 'dedupPeriodMinutes': dedup_period_minutes, 'threshold': threshold,
+                'thresholdWindowMinutes': threshold_window_minutes]
         """
         return self._analysis_client.get_enabled_rules()
 
-    def analyze(self, log_type: str, event: Dict[str, Any]) -> List[str]:
+    def analyze(self, log_type: str, event: Dict[str, Any]) -> List[EngineMatch]:
         """Analyze an event by running all the rules that apply to the log type.
 
         Returns:
-            ['rule-id-1', 'rule-id-3']  # rules that matched
-
+            A match of the event for every rule which returned True
         """
         if datetime.utcnow() - self._last_update > _CACHE_DURATION:
             self.populate_rules()
 
-        matched: List[str] = []
+        matched: List[EngineMatch] = []
 
         for rule in self._log_type_to_rules[log_type]:
             result = rule.run(event)
             if result is True:
-                matched.append(rule.rule_id)
+                matched.append(
+                    EngineMatch(
+                        rule_id=rule.rule_id,
+                        dedup=rule.dedup(event),
+                        dedup_period_minutes=rule.dedup_period_minutes,
+                        threshold=rule.threshold,
+                        threshold_window_minutes=rule.threshold_window_minutes,
+                        title=rule.title(event),
+                        alert_context=rule.alert_context(event),
+                        event=event,
+                    )
+                )
             elif isinstance(result, Exception):
                 # TODO Add reporting of errors in the UI
                 self.logger.error('failed to run rule {} {}'.format(type(result).__name__, result))
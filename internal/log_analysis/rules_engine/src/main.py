@@ -22,7 +22,7 @@ from typing import Any, Dict, List
 
 import boto3
 
-from .engine import Engine
+from .engine import Engine, EngineMatch
 from .logging import get_logger
 from .sqs import send_to_sqs
 
@@ -42,14 +42,13 @@ def lambda_handler(event: Dict[str, Any], unused_context) -> None:
         logger.info("loading object from S3, bucket [{}], key [{}]".format(bucket, object_key))
         log_type_to_data[record_body['id']].append(load_contents(bucket, object_key))
 
-    # List containing tuple of (rule_id, event) for matched events
-    matched: List = []
+    # The rule matches of every event
+    matched: List[EngineMatch] = []
 
     for log_type, data_streams in log_type_to_data.items():
         for data_stream in data_streams:
             for data in data_stream:
-                for matched_rule in rules_engine.analyze(log_type, data):
-                    matched.append((matched_rule, data))
+                matched.extend(rules_engine.analyze(log_type, data))
 
     if len(matched) > 0:
         logger.info("sending {} matches".format(len(matched)))
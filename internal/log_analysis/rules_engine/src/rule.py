@@ -19,12 +19,20 @@ import sys
 import tempfile
 from importlib import util as import_util
 from pathlib import Path
-from typing import Any, Dict, Union
+from typing import Any, Dict, Optional, Union
 
 from .logging import get_logger
 
 _RULE_FOLDER = os.path.join(tempfile.gettempdir(), 'rules')
 
+# Max length of the dedup string returned by a rule, must match the alert merger validation
+MAX_DEDUP_STRING_SIZE = 1000
+
+# Max length of the title and max size of the context returned by a rule, must match the alert merger validation
+MAX_TITLE_SIZE = 1000
+MAX_CONTEXT_KEYS = 50
+MAX_CONTEXT_VALUE_SIZE = 1000
+
 # Rule with ID 'aws_globals' contains common Python logic used by other rules
 COMMON_MODULE_RULE_ID = 'aws_globals'
 
@@ -33,14 +41,27 @@ class Rule:
     """Panther rule metadata and imported module."""
     logger = get_logger()
 
-    def __init__(self, rule_id: str, rule_body: str) -> None:
+    def __init__(  # pylint: disable=too-many-arguments
+        self,
+        rule_id: str,
+        rule_body: str,
+        dedup_period_minutes: Optional[int] = None,
+        threshold: Optional[int] = None,
+        threshold_window_minutes: Optional[int] = None
+    ) -> None:
         """Import rule contents from disk.
 
         Args:
             rule_id: Unique rule identifier
             rule_body: The rule body
+            dedup_period_minutes: The period during which matches of the rule are merged into the same alert
+            threshold: The number of matches within the threshold window required before an alert is sent
+            threshold_window_minutes: The period during which matches are counted towards the threshold
         """
         self.rule_id = rule_id
+        self.dedup_period_minutes = dedup_period_minutes
+        self.threshold = threshold
+        self.threshold_window_minutes = threshold_window_minutes
 
         self._import_error = None
         try:
@@ -100,3 +121,61 @@ class Rule:
             return Exception('rule returned {}, expected bool'.format(type(matched).__name__))
 
         return matched
+
+    def dedup(self, event: Dict[str, Any]) -> str:
+        """Return the dedup string for an event matched by this rule.
+
+        Rules can optionally define a "dedup" method. Matches of the same rule with different dedup strings
+        are merged into different alerts. An empty string is returned if the method is missing or fails.
+        """
+        if self._import_error or not hasattr(self._module, 'dedup'):
+            return ''
+
+        try:
+            dedup_string = self._module.dedup(event)
+        except Exception as err:  # pylint: disable=broad-except
+            self.logger.warning('failed to compute dedup string for rule {} {} {}'.format(self.rule_id, type(err).__name__, err))
+            return ''
+
+        if not dedup_string:
+            return ''
+        return str(dedup_string)[:MAX_DEDUP_STRING_SIZE]
+
+    def title(self, event: Dict[str, Any]) -> str:
+        """Return the title of the alert triggered by an event matched by this rule.
+
+        Rules can optionally define a "title" method, the rule name is used as title if the method is missing or fails.
+        """
+        if self._import_error or not hasattr(self._module, 'title'):
+            return ''
+
+        try:
+            title = self._module.title(event)
+        except Exception as err:  # pylint: disable=broad-except
+            self.logger.warning('failed to compute title for rule {} {} {}'.format(self.rule_id, type(err).__name__, err))
+            return ''
+
+        if not title:
+            return ''
+        return str(title)[:MAX_TITLE_SIZE]
+
+    def alert_context(self, event: Dict[str, Any]) -> Dict[str, str]:
+        """Return the context of the alert triggered by an event matched by this rule, e.g. the user and source IP.
+
+        Rules can optionally define an "alert_context" method returning a dict, its values are converted to strings.
+        An empty dict is returned if the method is missing or fails.
+        """
+        if self._import_error or not hasattr(self._module, 'alert_context'):
+            return {}
+
+        try:
+            context = self._module.alert_context(event)
+        except Exception as err:  # pylint: disable=broad-except
+            self.logger.warning('failed to compute alert context for rule {} {} {}'.format(self.rule_id, type(err).__name__, err))
+            return {}
+
+        if not isinstance(context, dict):
+            self.logger.warning('alert context of rule {} is a {}, expected dict'.format(self.rule_id, type(context).__name__))
+            return {}
+        items = sorted(context.items(), key=lambda item: str(item[0]))[:MAX_CONTEXT_KEYS]
+        return {str(key)[:MAX_CONTEXT_VALUE_SIZE]: str(value)[:MAX_CONTEXT_VALUE_SIZE] for key, value in items}
@@ -21,6 +21,8 @@ from typing import List, Dict
 
 import boto3
 
+from .engine import EngineMatch
+
 # Max number of SQS messages inside an SQS batch
 _MAX_MESSAGES = 10
 # Max size of an SQS batch request
@@ -30,8 +32,8 @@ sqs_resource = boto3.resource('sqs')
 queue = sqs_resource.get_queue_by_name(QueueName=os.environ['ALERTS_QUEUE'])
 
 
-def send_to_sqs(matches: List) -> None:
-    """Send a tuple of (rule_id, event) to SQS."""
+def send_to_sqs(matches: List[EngineMatch]) -> None:
+    """Send the rule matches to SQS."""
     messages = [match_to_sqs_entry_message(i) for i in matches]
 
     current_entries: List[Dict[str, str]] = []
@@ -55,6 +57,21 @@ def send_to_sqs(matches: List) -> None:
     return
 
 
-def match_to_sqs_entry_message(match: (str, str)) -> str:
-    notification = {'ruleId': match[0], 'event': match[1], 'timestamp': datetime.utcnow().strftime('%Y-%m-%dT%H:%M:%SZ')}
+def match_to_sqs_entry_message(match: EngineMatch) -> str:
+    notification = {
+        'ruleId': match.rule_id,
+        'dedup': match.dedup,
+        'event': match.event,
+        'timestamp': datetime.utcnow().strftime('%Y-%m-%dT%H:%M:%SZ'),
+    }
+    if match.dedup_period_minutes:
+        notification['dedupPeriodMinutes'] = match.dedup_period_minutes
+    if match.threshold:
+        notification['threshold'] = match.threshold
+    if match.threshold_window_minutes:
+        notification['thresholdWindowMinutes'] = match.threshold_window_minutes
+    if match.title:
+        notification['title'] = match.title
+    if match.alert_context:
+        notification['alertContext'] = [{'key': key, 'value': value} for key, value in match.alert_context.items()]
     return json.dumps(notification)
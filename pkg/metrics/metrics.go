@@ -0,0 +1,157 @@
+// Package metrics emits CloudWatch metrics using the Embedded Metric Format (EMF).
+//
+// EMF metrics are structured log lines written to stdout: in Lambda, CloudWatch extracts the metrics
+// asynchronously, so there are no PutMetricData calls, throttling or added latency.
+// See https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+package metrics
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Units supported by CloudWatch, the ones we use
+const (
+	UnitCount        = "Count"
+	UnitBytes        = "Bytes"
+	UnitMicroseconds = "Microseconds"
+	UnitMilliseconds = "Milliseconds"
+	UnitSeconds      = "Seconds"
+	UnitPercent      = "Percent"
+	UnitNone         = "None"
+)
+
+// Dimension is a name/value pair that is part of the identity of a metric
+type Dimension struct {
+	Name  string
+	Value string
+}
+
+// Metric is a single measurement
+type Metric struct {
+	Name  string
+	Unit  string
+	Value float64
+}
+
+// Logger writes EMF log lines, it is safe for concurrent use
+type Logger struct {
+	Namespace string
+
+	mutex  sync.Mutex // serializes writes so lines are not interleaved
+	writer io.Writer
+	now    func() time.Time
+}
+
+// NewLogger returns a Logger writing to stdout, where the Lambda runtime forwards it to CloudWatch
+func NewLogger(namespace string) *Logger {
+	return NewLoggerWithWriter(namespace, os.Stdout)
+}
+
+// NewLoggerWithWriter returns a Logger writing to w
+func NewLoggerWithWriter(namespace string, w io.Writer) *Logger {
+	return &Logger{
+		Namespace: namespace,
+		writer:    w,
+		now:       time.Now,
+	}
+}
+
+// Log writes a single EMF line with all metrics, aggregated over the given dimensions.
+// Metrics with the same name are aggregated by CloudWatch, so Log can be called once per event.
+func (l *Logger) Log(dimensions []Dimension, metrics ...Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	stream := jsoniter.ConfigDefault.BorrowStream(nil)
+	defer jsoniter.ConfigDefault.ReturnStream(stream)
+
+	stream.WriteObjectStart()
+
+	// metadata describing which fields are metrics and dimensions
+	stream.WriteObjectField("_aws")
+	stream.WriteObjectStart()
+	stream.WriteObjectField("Timestamp")
+	stream.WriteInt64(l.now().UnixNano() / int64(time.Millisecond))
+	stream.WriteMore()
+	stream.WriteObjectField("CloudWatchMetrics")
+	stream.WriteArrayStart()
+	stream.WriteObjectStart()
+	stream.WriteObjectField("Namespace")
+	stream.WriteString(l.Namespace)
+	stream.WriteMore()
+	stream.WriteObjectField("Dimensions")
+	stream.WriteArrayStart()
+	stream.WriteArrayStart() // a single dimension set
+	for i, dimension := range dimensions {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		stream.WriteString(dimension.Name)
+	}
+	stream.WriteArrayEnd()
+	stream.WriteArrayEnd()
+	stream.WriteMore()
+	stream.WriteObjectField("Metrics")
+	stream.WriteArrayStart()
+	for i, metric := range metrics {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		stream.WriteObjectStart()
+		stream.WriteObjectField("Name")
+		stream.WriteString(metric.Name)
+		stream.WriteMore()
+		stream.WriteObjectField("Unit")
+		stream.WriteString(metric.Unit)
+		stream.WriteObjectEnd()
+	}
+	stream.WriteArrayEnd()
+	stream.WriteObjectEnd()
+	stream.WriteArrayEnd()
+	stream.WriteObjectEnd()
+
+	// the values are top level members
+	for _, dimension := range dimensions {
+		stream.WriteMore()
+		stream.WriteObjectField(dimension.Name)
+		stream.WriteString(dimension.Value)
+	}
+	for _, metric := range metrics {
+		stream.WriteMore()
+		stream.WriteObjectField(metric.Name)
+		stream.WriteFloat64(metric.Value)
+	}
+
+	stream.WriteObjectEnd()
+	stream.WriteRaw("\n")
+	if stream.Error != nil {
+		return stream.Error
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, err := l.writer.Write(stream.Buffer())
+	return err
+}
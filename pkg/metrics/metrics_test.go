@@ -0,0 +1,72 @@
+package metrics
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestLog(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewLoggerWithWriter("Panther", &buffer)
+	logger.now = func() time.Time { return time.Unix(1577836800, 0) }
+
+	require.NoError(t, logger.Log(
+		[]Dimension{{Name: "LogType", Value: "AWS.CloudTrail"}},
+		Metric{Name: "EventCount", Unit: UnitCount, Value: 10},
+		Metric{Name: "BytesProcessed", Unit: UnitBytes, Value: 1024},
+	))
+
+	line := buffer.String()
+	require.True(t, strings.HasSuffix(line, "}\n"))
+	require.True(t, gjson.Valid(line))
+
+	result := gjson.Parse(line)
+	assert.Equal(t, int64(1577836800000), result.Get("_aws.Timestamp").Int())
+	assert.Equal(t, "Panther", result.Get("_aws.CloudWatchMetrics.0.Namespace").String())
+	assert.Equal(t, `[["LogType"]]`, result.Get("_aws.CloudWatchMetrics.0.Dimensions").Raw)
+	assert.Equal(t, `[{"Name":"EventCount","Unit":"Count"},{"Name":"BytesProcessed","Unit":"Bytes"}]`,
+		result.Get("_aws.CloudWatchMetrics.0.Metrics").Raw)
+	assert.Equal(t, "AWS.CloudTrail", result.Get("LogType").String())
+	assert.Equal(t, float64(10), result.Get("EventCount").Float())
+	assert.Equal(t, float64(1024), result.Get("BytesProcessed").Float())
+}
+
+func TestLogNoDimensions(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewLoggerWithWriter("Panther", &buffer)
+
+	require.NoError(t, logger.Log(nil, Metric{Name: "Errors", Unit: UnitCount, Value: 1}))
+	result := gjson.Parse(buffer.String())
+	assert.Equal(t, `[[]]`, result.Get("_aws.CloudWatchMetrics.0.Dimensions").Raw)
+	assert.Equal(t, float64(1), result.Get("Errors").Float())
+}
+
+func TestLogNoMetrics(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewLoggerWithWriter("Panther", &buffer)
+
+	require.NoError(t, logger.Log([]Dimension{{Name: "LogType", Value: "AWS.CloudTrail"}}))
+	assert.Empty(t, buffer.String())
+}
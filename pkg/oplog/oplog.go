@@ -45,9 +45,7 @@ import (
 	"go.uber.org/zap"
 )
 
-/* TODO: Consider emitting CW embedded metric format also:
-https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
-*/
+// NOTE: to emit CloudWatch metrics (rather than logs) use the metrics package, which implements the embedded metric format
 
 const (
 	Success = "success"
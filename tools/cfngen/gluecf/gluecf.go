@@ -43,6 +43,30 @@ var (
 			From: reflect.TypeOf(timestamp.ANSICwithTZ{}),
 			To:   awsglue.GlueTimestampType,
 		},
+		{
+			From: reflect.TypeOf(timestamp.UnixSeconds{}),
+			To:   awsglue.GlueTimestampType,
+		},
+		{
+			From: reflect.TypeOf(timestamp.UnixMillis{}),
+			To:   awsglue.GlueTimestampType,
+		},
+		{
+			From: reflect.TypeOf(timestamp.UnixNanos{}),
+			To:   awsglue.GlueTimestampType,
+		},
+		{
+			From: reflect.TypeOf(timestamp.ApacheCLF{}),
+			To:   awsglue.GlueTimestampType,
+		},
+		{
+			From: reflect.TypeOf(timestamp.SyslogRFC3164{}),
+			To:   awsglue.GlueTimestampType,
+		},
+		{
+			From: reflect.TypeOf(timestamp.ISO8601{}),
+			To:   awsglue.GlueTimestampType,
+		},
 	}
 )
 
@@ -53,3 +53,22 @@ func TestGenerateGlueCloudFormation(t *testing.T) {
 
 	assert.Equal(t, expectedOutput, string(cf))
 }
+
+func TestTimestampGlueMappings(t *testing.T) {
+	type timestampEvent struct {
+		RFC3339     timestamp.RFC3339       `json:"rfc3339"`
+		ANSICwithTZ timestamp.ANSICwithTZ   `json:"ansicWithTZ"`
+		UnixSeconds timestamp.UnixSeconds   `json:"unixSeconds"`
+		UnixMillis  timestamp.UnixMillis    `json:"unixMillis"`
+		UnixNanos   timestamp.UnixNanos     `json:"unixNanos"`
+		ApacheCLF   timestamp.ApacheCLF     `json:"apacheCLF"`
+		Syslog      timestamp.SyslogRFC3164 `json:"syslog"`
+		ISO8601     timestamp.ISO8601       `json:"iso8601"`
+	}
+
+	cols := InferJSONColumns(&timestampEvent{}, glueMappings...)
+	require.Len(t, cols, 8)
+	for _, col := range cols {
+		assert.Equal(t, awsglue.GlueTimestampType, col.Type, col.Name)
+	}
+}
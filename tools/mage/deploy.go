@@ -123,6 +123,10 @@ func Deploy() error {
 		return err
 	}
 
+	if err := backfillAlertPartitions(awsSession); err != nil {
+		return err
+	}
+
 	// TODO - underline link
 	fmt.Printf("\nPanther URL = https://%s\n", outputs["LoadBalancerUrl"])
 	return nil
@@ -0,0 +1,107 @@
+package mage
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/magefile/mage/mg"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+)
+
+const (
+	alertsTable = "panther-log-alerts"
+
+	// The single partition of the alerts listed across rules before they were spread over several partitions
+	legacyTimePartition = "defaultPartition"
+)
+
+// Set the time partition of the alerts created before alerts were listed across rules, or written to the
+// legacy single partition, so that they are listed across rules again.
+//
+// The alert merger sets it when it merges a match into an alert, this backfills the alerts which are not
+// matched anymore.
+func backfillAlertPartitions(awsSession *session.Session) error {
+	filter := expression.AttributeNotExists(expression.Name("timePartition")).
+		Or(expression.Name("timePartition").Equal(expression.Value(legacyTimePartition)))
+	expr, err := expression.NewBuilder().
+		WithFilter(filter).
+		WithProjection(expression.NamesList(expression.Name("alertId"))).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	client := dynamodb.New(awsSession)
+	var alertIDs []*string
+	err = client.ScanPages(&dynamodb.ScanInput{
+		TableName:                 aws.String(alertsTable),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			alertIDs = append(alertIDs, item["alertId"].S)
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %v", alertsTable, err)
+	}
+
+	for _, alertID := range alertIDs {
+		if err = setAlertPartition(client, *alertID); err != nil {
+			return fmt.Errorf("failed to set the time partition of alert %s: %v", *alertID, err)
+		}
+	}
+	if mg.Verbose() || len(alertIDs) > 0 {
+		fmt.Printf("deploy: set the time partition of %d alerts\n", len(alertIDs))
+	}
+	return nil
+}
+
+func setAlertPartition(client *dynamodb.DynamoDB, alertID string) error {
+	update := expression.Set(expression.Name("timePartition"), expression.Value(models.TimePartition(alertID)))
+	// Alerts deleted since the scan are not recreated
+	condition := expression.AttributeExists(expression.Name("alertId"))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String(alertsTable),
+		Key:                       map[string]*dynamodb.AttributeValue{"alertId": {S: aws.String(alertID)}},
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil
+	}
+	return err
+}
@@ -23,6 +23,11 @@ export type ActiveSuppressCount = {
   suppressed?: Maybe<ComplianceStatusCounts>;
 };
 
+export type AddAlertCommentInput = {
+  alertId: Scalars['ID'];
+  body: Scalars['String'];
+};
+
 export type AddIntegrationAttributes = {
   awsAccountId?: Maybe<Scalars['String']>;
   integrationLabel: Scalars['String'];
@@ -35,6 +40,81 @@ export type AddIntegrationInput = {
   integrations?: Maybe<Array<Maybe<AddIntegrationAttributes>>>;
 };
 
+export type AddRoutingRuleInput = {
+  displayName: Scalars['String'];
+  priority: Scalars['Int'];
+  match: RoutingMatchInput;
+  outputIds: Array<Scalars['ID']>;
+  continue?: Maybe<Scalars['Boolean']>;
+};
+
+export type AlertActivity = {
+  __typename?: 'AlertActivity';
+  type?: Maybe<AlertActivityTypeEnum>;
+  timestamp?: Maybe<Scalars['AWSDateTime']>;
+  userId?: Maybe<Scalars['ID']>;
+  comment?: Maybe<AlertComment>;
+  change?: Maybe<AlertChange>;
+  eventCount?: Maybe<Scalars['Int']>;
+};
+
+export enum AlertActivityTypeEnum {
+  AlertCreated = 'ALERT_CREATED',
+  EventsAdded = 'EVENTS_ADDED',
+  Comment = 'COMMENT',
+  StatusChange = 'STATUS_CHANGE',
+  Assign = 'ASSIGN',
+  OutputDelivered = 'OUTPUT_DELIVERED',
+  Resend = 'RESEND',
+}
+
+export type AlertChange = {
+  __typename?: 'AlertChange';
+  action?: Maybe<Scalars['String']>;
+  userId?: Maybe<Scalars['ID']>;
+  timestamp?: Maybe<Scalars['AWSDateTime']>;
+  status?: Maybe<AlertStatusEnum>;
+  assigneeId?: Maybe<Scalars['ID']>;
+  resolution?: Maybe<Scalars['String']>;
+  outputId?: Maybe<Scalars['ID']>;
+  outputIds?: Maybe<Array<Maybe<Scalars['ID']>>>;
+};
+
+export type AlertComment = {
+  __typename?: 'AlertComment';
+  alertId: Scalars['ID'];
+  commentId: Scalars['ID'];
+  userId?: Maybe<Scalars['ID']>;
+  body?: Maybe<Scalars['String']>;
+  createdAt?: Maybe<Scalars['AWSDateTime']>;
+  lastModified?: Maybe<Scalars['AWSDateTime']>;
+};
+
+export type AlertContextField = {
+  __typename?: 'AlertContextField';
+  key: Scalars['String'];
+  value: Scalars['String'];
+};
+
+export type AlertDelivery = {
+  __typename?: 'AlertDelivery';
+  deliveryId: Scalars['ID'];
+  outputId: Scalars['ID'];
+  status: AlertDeliveryStatusEnum;
+  httpStatusCode?: Maybe<Scalars['Int']>;
+  error?: Maybe<Scalars['String']>;
+  attempt: Scalars['Int'];
+  timestamp: Scalars['AWSDateTime'];
+  resolution?: Maybe<Scalars['Boolean']>;
+};
+
+export enum AlertDeliveryStatusEnum {
+  Success = 'SUCCESS',
+  Failure = 'FAILURE',
+  PermanentFailure = 'PERMANENT_FAILURE',
+  Digested = 'DIGESTED',
+}
+
 export type AlertDetails = {
   __typename?: 'AlertDetails';
   alertId: Scalars['ID'];
@@ -42,6 +122,21 @@ export type AlertDetails = {
   creationTime?: Maybe<Scalars['AWSDateTime']>;
   lastEventMatched?: Maybe<Scalars['AWSDateTime']>;
   events?: Maybe<Array<Scalars['AWSJSON']>>;
+  eventsLastEvaluatedKey?: Maybe<Scalars['String']>;
+  eventsMatched?: Maybe<Scalars['Int']>;
+  eventLimit?: Maybe<Scalars['Int']>;
+  eventsTruncated?: Maybe<Scalars['Boolean']>;
+  dedup?: Maybe<Scalars['String']>;
+  correlatedAlertIds?: Maybe<Array<Scalars['ID']>>;
+  status?: Maybe<AlertStatusEnum>;
+  assigneeId?: Maybe<Scalars['ID']>;
+  resolution?: Maybe<AlertResolution>;
+  lastUpdatedBy?: Maybe<Scalars['ID']>;
+  lastUpdatedTime?: Maybe<Scalars['AWSDateTime']>;
+  history?: Maybe<Array<Maybe<AlertChange>>>;
+  title?: Maybe<Scalars['String']>;
+  context?: Maybe<Array<AlertContextField>>;
+  deliveries?: Maybe<Array<AlertDelivery>>;
 };
 
 export enum AlertReportFrequencyEnum {
@@ -49,6 +144,54 @@ export enum AlertReportFrequencyEnum {
   P1W = 'P1W',
 }
 
+export type AlertMetric = {
+  __typename?: 'AlertMetric';
+  key?: Maybe<Scalars['String']>;
+  alertCount?: Maybe<Scalars['Int']>;
+  eventCount?: Maybe<Scalars['Int']>;
+  resolvedCount?: Maybe<Scalars['Int']>;
+  meanTimeToResolveSeconds?: Maybe<Scalars['Float']>;
+};
+
+export type AlertMetrics = {
+  __typename?: 'AlertMetrics';
+  total?: Maybe<AlertMetric>;
+  byRule?: Maybe<Array<Maybe<AlertMetric>>>;
+  bySeverity?: Maybe<Array<Maybe<AlertMetric>>>;
+  byLogType?: Maybe<Array<Maybe<AlertMetric>>>;
+  trend?: Maybe<Array<Maybe<AlertMetric>>>;
+};
+
+export enum AlertMetricsIntervalEnum {
+  Hour = 'HOUR',
+  Day = 'DAY',
+}
+
+export type AlertResolution = {
+  __typename?: 'AlertResolution';
+  notes?: Maybe<Scalars['String']>;
+  userId?: Maybe<Scalars['ID']>;
+  timestamp?: Maybe<Scalars['AWSDateTime']>;
+};
+
+export type AlertRetention = {
+  __typename?: 'AlertRetention';
+  retentionDays?: Maybe<Scalars['Int']>;
+  severityRetentionDays?: Maybe<Array<Maybe<SeverityRetention>>>;
+};
+
+export type AlertRetentionInput = {
+  retentionDays?: Maybe<Scalars['Int']>;
+  severityRetentionDays?: Maybe<Array<Maybe<SeverityRetentionInput>>>;
+};
+
+export enum AlertStatusEnum {
+  Open = 'OPEN',
+  Triaged = 'TRIAGED',
+  Closed = 'CLOSED',
+  Resolved = 'RESOLVED',
+}
+
 export type AlertSummary = {
   __typename?: 'AlertSummary';
   alertId?: Maybe<Scalars['String']>;
@@ -56,14 +199,76 @@ export type AlertSummary = {
   eventsMatched?: Maybe<Scalars['Int']>;
   lastEventMatched?: Maybe<Scalars['AWSDateTime']>;
   ruleId?: Maybe<Scalars['String']>;
+  ruleDisplayName?: Maybe<Scalars['String']>;
+  title?: Maybe<Scalars['String']>;
+  logTypes?: Maybe<Array<Maybe<Scalars['String']>>>;
+  tags?: Maybe<Array<Maybe<Scalars['String']>>>;
   severity?: Maybe<Scalars['String']>;
+  dedup?: Maybe<Scalars['String']>;
+  status?: Maybe<AlertStatusEnum>;
+  assigneeId?: Maybe<Scalars['ID']>;
+  resolution?: Maybe<AlertResolution>;
+  lastUpdatedBy?: Maybe<Scalars['ID']>;
+  lastUpdatedTime?: Maybe<Scalars['AWSDateTime']>;
+};
+
+export type AlertTimeline = {
+  __typename?: 'AlertTimeline';
+  activities?: Maybe<Array<Maybe<AlertActivity>>>;
+  lastEvaluatedKey?: Maybe<Scalars['String']>;
 };
 
+export type AlertsExport = {
+  __typename?: 'AlertsExport';
+  jobId: Scalars['ID'];
+  status?: Maybe<AlertsExportStatusEnum>;
+  format?: Maybe<AlertsExportFormatEnum>;
+  createdAtAfter?: Maybe<Scalars['AWSDateTime']>;
+  createdAtBefore?: Maybe<Scalars['AWSDateTime']>;
+  ruleId?: Maybe<Scalars['ID']>;
+  severity?: Maybe<Array<Maybe<SeverityEnum>>>;
+  createdBy?: Maybe<Scalars['ID']>;
+  createdAt?: Maybe<Scalars['AWSDateTime']>;
+  completedAt?: Maybe<Scalars['AWSDateTime']>;
+  alertCount?: Maybe<Scalars['Int']>;
+  eventCount?: Maybe<Scalars['Int']>;
+  error?: Maybe<Scalars['String']>;
+  url?: Maybe<Scalars['String']>;
+};
+
+export enum AlertsExportFormatEnum {
+  Json = 'JSON',
+  Csv = 'CSV',
+}
+
+export enum AlertsExportStatusEnum {
+  Pending = 'PENDING',
+  Running = 'RUNNING',
+  Succeeded = 'SUCCEEDED',
+  Failed = 'FAILED',
+}
+
 export enum AnalysisTypeEnum {
   Rule = 'RULE',
   Policy = 'POLICY',
 }
 
+export type AsanaConfig = {
+  __typename?: 'AsanaConfig';
+  personalAccessToken: Scalars['String'];
+  projectGids: Array<Scalars['String']>;
+};
+
+export type AsanaConfigInput = {
+  personalAccessToken: Scalars['String'];
+  projectGids: Array<Scalars['String']>;
+};
+
+export type AssignAlertInput = {
+  alertId: Scalars['ID'];
+  assigneeId?: Maybe<Scalars['ID']>;
+};
+
 export type ComplianceItem = {
   __typename?: 'ComplianceItem';
   errorMessage?: Maybe<Scalars['String']>;
@@ -90,12 +295,26 @@ export enum ComplianceStatusEnum {
   Pass = 'PASS',
 }
 
+export type Correlation = {
+  __typename?: 'Correlation';
+  ruleIds: Array<Scalars['ID']>;
+  ordered?: Maybe<Scalars['Boolean']>;
+  windowMinutes: Scalars['Int'];
+};
+
+export type CorrelationInput = {
+  ruleIds: Array<Scalars['ID']>;
+  ordered?: Maybe<Scalars['Boolean']>;
+  windowMinutes: Scalars['Int'];
+};
+
 export type CreateOrModifyPolicyInput = {
   actionDelaySeconds?: Maybe<Scalars['Int']>;
   alertSuppressSeconds?: Maybe<Scalars['Int']>;
   autoRemediationId?: Maybe<Scalars['ID']>;
   autoRemediationParameters?: Maybe<Scalars['AWSJSON']>;
   body: Scalars['String'];
+  dedupPeriodMinutes?: Maybe<Scalars['Int']>;
   description?: Maybe<Scalars['String']>;
   displayName?: Maybe<Scalars['String']>;
   enabled: Scalars['Boolean'];
@@ -111,6 +330,8 @@ export type CreateOrModifyPolicyInput = {
 
 export type CreateOrModifyRuleInput = {
   body: Scalars['String'];
+  correlation?: Maybe<CorrelationInput>;
+  dedupPeriodMinutes?: Maybe<Scalars['Int']>;
   description?: Maybe<Scalars['String']>;
   displayName?: Maybe<Scalars['String']>;
   enabled: Scalars['Boolean'];
@@ -121,6 +342,13 @@ export type CreateOrModifyRuleInput = {
   severity: SeverityEnum;
   tags?: Maybe<Array<Maybe<Scalars['String']>>>;
   tests?: Maybe<Array<Maybe<PolicyUnitTestInput>>>;
+  threshold?: Maybe<Scalars['Int']>;
+  thresholdWindowMinutes?: Maybe<Scalars['Int']>;
+};
+
+export type DeleteAlertCommentInput = {
+  alertId: Scalars['ID'];
+  commentId: Scalars['ID'];
 };
 
 export type DeletePolicyInput = {
@@ -143,6 +371,8 @@ export type Destination = {
   outputConfig: DestinationConfig;
   verificationStatus?: Maybe<Scalars['String']>;
   defaultForSeverity: Array<Maybe<SeverityEnum>>;
+  healthStatus?: Maybe<DestinationHealthStatusEnum>;
+  consecutiveFailures?: Maybe<Scalars['Int']>;
 };
 
 export type DestinationConfig = {
@@ -156,6 +386,12 @@ export type DestinationConfig = {
   jira?: Maybe<JiraConfig>;
   opsgenie?: Maybe<OpsgenieConfig>;
   msTeams?: Maybe<MsTeamsConfig>;
+  webhook?: Maybe<WebhookConfig>;
+  serviceNow?: Maybe<ServiceNowConfig>;
+  asana?: Maybe<AsanaConfig>;
+  splunkHec?: Maybe<SplunkHecConfig>;
+  template?: Maybe<MessageTemplate>;
+  rateLimit?: Maybe<RateLimit>;
 };
 
 export type DestinationConfigInput = {
@@ -168,7 +404,19 @@ export type DestinationConfigInput = {
   jira?: Maybe<JiraConfigInput>;
   opsgenie?: Maybe<OpsgenieConfigInput>;
   msTeams?: Maybe<MsTeamsConfigInput>;
-};
+  webhook?: Maybe<WebhookConfigInput>;
+  serviceNow?: Maybe<ServiceNowConfigInput>;
+  asana?: Maybe<AsanaConfigInput>;
+  splunkHec?: Maybe<SplunkHecConfigInput>;
+  template?: Maybe<MessageTemplateInput>;
+  rateLimit?: Maybe<RateLimitInput>;
+};
+
+export enum DestinationHealthStatusEnum {
+  Healthy = 'HEALTHY',
+  Unhealthy = 'UNHEALTHY',
+  Unknown = 'UNKNOWN',
+}
 
 export type DestinationInput = {
   outputId?: Maybe<Scalars['ID']>;
@@ -178,6 +426,13 @@ export type DestinationInput = {
   defaultForSeverity: Array<Maybe<SeverityEnum>>;
 };
 
+export type DestinationTestResult = {
+  __typename?: 'DestinationTestResult';
+  success: Scalars['Boolean'];
+  message?: Maybe<Scalars['String']>;
+  httpStatusCode?: Maybe<Scalars['Int']>;
+};
+
 export enum DestinationTypeEnum {
   Slack = 'slack',
   Pagerduty = 'pagerduty',
@@ -188,6 +443,10 @@ export enum DestinationTypeEnum {
   Msteams = 'msteams',
   Sns = 'sns',
   Sqs = 'sqs',
+  Webhook = 'webhook',
+  Servicenow = 'servicenow',
+  Asana = 'asana',
+  Splunkhec = 'splunkhec',
 }
 
 export type EmailConfig = {
@@ -199,10 +458,36 @@ export type EmailConfigInput = {
   destinationAddress: Scalars['String'];
 };
 
+export type ExportAlertsInput = {
+  createdAtAfter: Scalars['AWSDateTime'];
+  createdAtBefore: Scalars['AWSDateTime'];
+  ruleId?: Maybe<Scalars['ID']>;
+  severity?: Maybe<Array<Maybe<SeverityEnum>>>;
+  format?: Maybe<AlertsExportFormatEnum>;
+};
+
 export type GetAlertInput = {
   alertId: Scalars['ID'];
   eventPageSize?: Maybe<Scalars['Int']>;
   eventPage?: Maybe<Scalars['Int']>;
+  eventsPageSize?: Maybe<Scalars['Int']>;
+  eventsExclusiveStartKey?: Maybe<Scalars['String']>;
+};
+
+export type GetAlertMetricsInput = {
+  from: Scalars['AWSDateTime'];
+  to: Scalars['AWSDateTime'];
+  interval?: Maybe<AlertMetricsIntervalEnum>;
+};
+
+export type GetAlertTimelineInput = {
+  alertId: Scalars['ID'];
+  pageSize?: Maybe<Scalars['Int']>;
+  exclusiveStartKey?: Maybe<Scalars['String']>;
+};
+
+export type GetAlertsExportInput = {
+  jobId: Scalars['ID'];
 };
 
 export type GetOrganizationResponse = {
@@ -280,8 +565,28 @@ export type JiraConfigInput = {
   assigneeID?: Maybe<Scalars['String']>;
 };
 
+export type ListAlertCommentsInput = {
+  alertId: Scalars['ID'];
+  pageSize?: Maybe<Scalars['Int']>;
+  exclusiveStartKey?: Maybe<Scalars['String']>;
+};
+
+export type ListAlertCommentsResponse = {
+  __typename?: 'ListAlertCommentsResponse';
+  comments?: Maybe<Array<Maybe<AlertComment>>>;
+  lastEvaluatedKey?: Maybe<Scalars['String']>;
+};
+
 export type ListAlertsInput = {
   ruleId?: Maybe<Scalars['ID']>;
+  severity?: Maybe<Array<Maybe<SeverityEnum>>>;
+  status?: Maybe<Array<Maybe<AlertStatusEnum>>>;
+  logTypes?: Maybe<Array<Maybe<Scalars['String']>>>;
+  tags?: Maybe<Array<Maybe<Scalars['String']>>>;
+  titleContains?: Maybe<Scalars['String']>;
+  createdAtAfter?: Maybe<Scalars['AWSDateTime']>;
+  createdAtBefore?: Maybe<Scalars['AWSDateTime']>;
+  sortDir?: Maybe<SortDirEnum>;
   pageSize?: Maybe<Scalars['Int']>;
   exclusiveStartKey?: Maybe<Scalars['String']>;
 };
@@ -385,6 +690,23 @@ export enum ListRulesSortFieldsEnum {
   Severity = 'severity',
 }
 
+export type MessageTemplate = {
+  __typename?: 'MessageTemplate';
+  title?: Maybe<Scalars['String']>;
+  body?: Maybe<Scalars['String']>;
+};
+
+export type MessageTemplateInput = {
+  title?: Maybe<Scalars['String']>;
+  body?: Maybe<Scalars['String']>;
+};
+
+export type MessageTemplatePreview = {
+  __typename?: 'MessageTemplatePreview';
+  title?: Maybe<Scalars['String']>;
+  body?: Maybe<Scalars['String']>;
+};
+
 export type MsTeamsConfig = {
   __typename?: 'MsTeamsConfig';
   webhookURL: Scalars['String'];
@@ -396,26 +718,41 @@ export type MsTeamsConfigInput = {
 
 export type Mutation = {
   __typename?: 'Mutation';
+  addAlertComment?: Maybe<AlertComment>;
   addDestination?: Maybe<Destination>;
   addIntegration?: Maybe<Integration>;
   addPolicy?: Maybe<PolicyDetails>;
+  addRoutingRule?: Maybe<RoutingRule>;
   addRule?: Maybe<RuleDetails>;
+  assignAlert?: Maybe<AlertSummary>;
+  deleteAlertComment?: Maybe<Scalars['Boolean']>;
   deleteDestination?: Maybe<Scalars['Boolean']>;
   deleteIntegration?: Maybe<Scalars['Boolean']>;
   deletePolicy?: Maybe<Scalars['Boolean']>;
+  deleteRoutingRule?: Maybe<Scalars['Boolean']>;
+  exportAlerts?: Maybe<AlertsExport>;
   remediateResource?: Maybe<Scalars['Boolean']>;
+  resendAlert?: Maybe<Scalars['Boolean']>;
   resetUserPassword?: Maybe<Scalars['Boolean']>;
   suppressPolicies?: Maybe<Scalars['Boolean']>;
+  testDestination?: Maybe<DestinationTestResult>;
   testPolicy?: Maybe<TestPolicyResponse>;
+  updateAlertComment?: Maybe<AlertComment>;
+  updateAlertStatus?: Maybe<AlertSummary>;
   updateDestination?: Maybe<Destination>;
   updateIntegration?: Maybe<Scalars['Boolean']>;
   updateOrganization?: Maybe<Scalars['Boolean']>;
   updatePolicy?: Maybe<PolicyDetails>;
+  updateRoutingRule?: Maybe<RoutingRule>;
   updateRule?: Maybe<RuleDetails>;
   updateUser?: Maybe<Scalars['Boolean']>;
   uploadPolicies?: Maybe<UploadPoliciesResponse>;
 };
 
+export type MutationAddAlertCommentArgs = {
+  input: AddAlertCommentInput;
+};
+
 export type MutationAddDestinationArgs = {
   input: DestinationInput;
 };
@@ -428,10 +765,22 @@ export type MutationAddPolicyArgs = {
   input: CreateOrModifyPolicyInput;
 };
 
+export type MutationAddRoutingRuleArgs = {
+  input: AddRoutingRuleInput;
+};
+
 export type MutationAddRuleArgs = {
   input: CreateOrModifyRuleInput;
 };
 
+export type MutationAssignAlertArgs = {
+  input: AssignAlertInput;
+};
+
+export type MutationDeleteAlertCommentArgs = {
+  input: DeleteAlertCommentInput;
+};
+
 export type MutationDeleteDestinationArgs = {
   id: Scalars['ID'];
 };
@@ -444,10 +793,22 @@ export type MutationDeletePolicyArgs = {
   input: DeletePolicyInput;
 };
 
+export type MutationDeleteRoutingRuleArgs = {
+  id: Scalars['ID'];
+};
+
+export type MutationExportAlertsArgs = {
+  input: ExportAlertsInput;
+};
+
 export type MutationRemediateResourceArgs = {
   input: RemediateResourceInput;
 };
 
+export type MutationResendAlertArgs = {
+  input: ResendAlertInput;
+};
+
 export type MutationResetUserPasswordArgs = {
   id: Scalars['ID'];
 };
@@ -456,10 +817,22 @@ export type MutationSuppressPoliciesArgs = {
   input: SuppressPoliciesInput;
 };
 
+export type MutationTestDestinationArgs = {
+  id: Scalars['ID'];
+};
+
 export type MutationTestPolicyArgs = {
   input?: Maybe<TestPolicyInput>;
 };
 
+export type MutationUpdateAlertCommentArgs = {
+  input: UpdateAlertCommentInput;
+};
+
+export type MutationUpdateAlertStatusArgs = {
+  input: UpdateAlertStatusInput;
+};
+
 export type MutationUpdateDestinationArgs = {
   input: DestinationInput;
 };
@@ -476,6 +849,10 @@ export type MutationUpdatePolicyArgs = {
   input: CreateOrModifyPolicyInput;
 };
 
+export type MutationUpdateRoutingRuleArgs = {
+  input: UpdateRoutingRuleInput;
+};
+
 export type MutationUpdateRuleArgs = {
   input: CreateOrModifyRuleInput;
 };
@@ -503,6 +880,7 @@ export type Organization = {
   displayName?: Maybe<Scalars['String']>;
   email?: Maybe<Scalars['String']>;
   alertReportFrequency?: Maybe<AlertReportFrequencyEnum>;
+  alertRetention?: Maybe<AlertRetention>;
   remediationConfig?: Maybe<RemediationConfig>;
 };
 
@@ -562,6 +940,7 @@ export type PolicyDetails = {
   body?: Maybe<Scalars['String']>;
   createdAt?: Maybe<Scalars['AWSDateTime']>;
   createdBy?: Maybe<Scalars['ID']>;
+  dedupPeriodMinutes?: Maybe<Scalars['Int']>;
   description?: Maybe<Scalars['String']>;
   displayName?: Maybe<Scalars['String']>;
   enabled?: Maybe<Scalars['Boolean']>;
@@ -618,9 +997,14 @@ export type Query = {
   __typename?: 'Query';
   alert?: Maybe<AlertDetails>;
   alerts?: Maybe<ListAlertsResponse>;
+  alertComments?: Maybe<ListAlertCommentsResponse>;
+  alertTimeline?: Maybe<AlertTimeline>;
+  alertsExport?: Maybe<AlertsExport>;
+  alertMetrics?: Maybe<AlertMetrics>;
   organization?: Maybe<GetOrganizationResponse>;
   destination?: Maybe<Destination>;
   destinations?: Maybe<Array<Maybe<Destination>>>;
+  destinationTemplatePreview?: Maybe<MessageTemplatePreview>;
   remediations?: Maybe<Scalars['AWSJSON']>;
   resource?: Maybe<ResourceDetails>;
   resources?: Maybe<ListResourcesResponse>;
@@ -632,6 +1016,7 @@ export type Query = {
   organizationStats?: Maybe<OrganizationStatsResponse>;
   rule?: Maybe<RuleDetails>;
   rules?: Maybe<ListRulesResponse>;
+  routingRules?: Maybe<Array<Maybe<RoutingRule>>>;
 };
 
 export type QueryAlertArgs = {
@@ -642,10 +1027,30 @@ export type QueryAlertsArgs = {
   input?: Maybe<ListAlertsInput>;
 };
 
+export type QueryAlertCommentsArgs = {
+  input: ListAlertCommentsInput;
+};
+
+export type QueryAlertTimelineArgs = {
+  input: GetAlertTimelineInput;
+};
+
+export type QueryAlertsExportArgs = {
+  input: GetAlertsExportInput;
+};
+
+export type QueryAlertMetricsArgs = {
+  input: GetAlertMetricsInput;
+};
+
 export type QueryDestinationArgs = {
   id: Scalars['ID'];
 };
 
+export type QueryDestinationTemplatePreviewArgs = {
+  input: MessageTemplateInput;
+};
+
 export type QueryResourceArgs = {
   input: GetResourceInput;
 };
@@ -686,6 +1091,17 @@ export type QueryRulesArgs = {
   input?: Maybe<ListRulesInput>;
 };
 
+export type RateLimit = {
+  __typename?: 'RateLimit';
+  maxAlerts: Scalars['Int'];
+  windowMinutes: Scalars['Int'];
+};
+
+export type RateLimitInput = {
+  maxAlerts: Scalars['Int'];
+  windowMinutes: Scalars['Int'];
+};
+
 export type RemediateResourceInput = {
   policyId: Scalars['ID'];
   resourceId: Scalars['ID'];
@@ -700,6 +1116,11 @@ export type RemediationConfigInput = {
   awsRemediationLambdaArn?: Maybe<Scalars['String']>;
 };
 
+export type ResendAlertInput = {
+  alertId: Scalars['ID'];
+  outputIds?: Maybe<Array<Maybe<Scalars['ID']>>>;
+};
+
 export type ResourceDetails = {
   __typename?: 'ResourceDetails';
   attributes?: Maybe<Scalars['AWSJSON']>;
@@ -738,11 +1159,48 @@ export enum RoleNameEnum {
   ReadOnly = 'ReadOnly',
 }
 
+export type RoutingMatch = {
+  __typename?: 'RoutingMatch';
+  ruleIds?: Maybe<Array<Scalars['ID']>>;
+  severities?: Maybe<Array<SeverityEnum>>;
+  tags?: Maybe<Array<Scalars['String']>>;
+  logTypes?: Maybe<Array<Scalars['String']>>;
+  alertType?: Maybe<AnalysisTypeEnum>;
+  resourceTypes?: Maybe<Array<Scalars['String']>>;
+  integrationIds?: Maybe<Array<Scalars['ID']>>;
+};
+
+export type RoutingMatchInput = {
+  ruleIds?: Maybe<Array<Scalars['ID']>>;
+  severities?: Maybe<Array<SeverityEnum>>;
+  tags?: Maybe<Array<Scalars['String']>>;
+  logTypes?: Maybe<Array<Scalars['String']>>;
+  alertType?: Maybe<AnalysisTypeEnum>;
+  resourceTypes?: Maybe<Array<Scalars['String']>>;
+  integrationIds?: Maybe<Array<Scalars['ID']>>;
+};
+
+export type RoutingRule = {
+  __typename?: 'RoutingRule';
+  routingRuleId: Scalars['ID'];
+  displayName: Scalars['String'];
+  priority: Scalars['Int'];
+  match: RoutingMatch;
+  outputIds: Array<Scalars['ID']>;
+  continue?: Maybe<Scalars['Boolean']>;
+  createdBy: Scalars['String'];
+  creationTime: Scalars['AWSDateTime'];
+  lastModifiedBy: Scalars['String'];
+  lastModifiedTime: Scalars['AWSDateTime'];
+};
+
 export type RuleDetails = {
   __typename?: 'RuleDetails';
   body?: Maybe<Scalars['String']>;
+  correlation?: Maybe<Correlation>;
   createdAt?: Maybe<Scalars['AWSDateTime']>;
   createdBy?: Maybe<Scalars['ID']>;
+  dedupPeriodMinutes?: Maybe<Scalars['Int']>;
   description?: Maybe<Scalars['String']>;
   displayName?: Maybe<Scalars['String']>;
   enabled?: Maybe<Scalars['Boolean']>;
@@ -755,6 +1213,8 @@ export type RuleDetails = {
   severity?: Maybe<SeverityEnum>;
   tags?: Maybe<Array<Maybe<Scalars['String']>>>;
   tests?: Maybe<Array<Maybe<PolicyUnitTest>>>;
+  threshold?: Maybe<Scalars['Int']>;
+  thresholdWindowMinutes?: Maybe<Scalars['Int']>;
   versionId?: Maybe<Scalars['ID']>;
 };
 
@@ -780,6 +1240,21 @@ export type ScannedResourceStats = {
   type?: Maybe<Scalars['String']>;
 };
 
+export type ServiceNowConfig = {
+  __typename?: 'ServiceNowConfig';
+  instanceURL: Scalars['String'];
+  userName: Scalars['String'];
+  password: Scalars['String'];
+  assignmentGroup?: Maybe<Scalars['String']>;
+};
+
+export type ServiceNowConfigInput = {
+  instanceURL: Scalars['String'];
+  userName: Scalars['String'];
+  password: Scalars['String'];
+  assignmentGroup?: Maybe<Scalars['String']>;
+};
+
 export enum SeverityEnum {
   Info = 'INFO',
   Low = 'LOW',
@@ -788,6 +1263,17 @@ export enum SeverityEnum {
   Critical = 'CRITICAL',
 }
 
+export type SeverityRetention = {
+  __typename?: 'SeverityRetention';
+  severity: SeverityEnum;
+  retentionDays: Scalars['Int'];
+};
+
+export type SeverityRetentionInput = {
+  severity: SeverityEnum;
+  retentionDays: Scalars['Int'];
+};
+
 export type SlackConfig = {
   __typename?: 'SlackConfig';
   webhookURL: Scalars['String'];
@@ -811,6 +1297,21 @@ export enum SortDirEnum {
   Descending = 'descending',
 }
 
+export type SplunkHecConfig = {
+  __typename?: 'SplunkHecConfig';
+  url: Scalars['String'];
+  token: Scalars['String'];
+  index?: Maybe<Scalars['String']>;
+  sourceType?: Maybe<Scalars['String']>;
+};
+
+export type SplunkHecConfigInput = {
+  url: Scalars['String'];
+  token: Scalars['String'];
+  index?: Maybe<Scalars['String']>;
+  sourceType?: Maybe<Scalars['String']>;
+};
+
 export type SqsConfig = {
   __typename?: 'SqsConfig';
   queueUrl: Scalars['String'];
@@ -840,6 +1341,18 @@ export type TestPolicyResponse = {
   testsErrored?: Maybe<Array<Maybe<PolicyUnitTestError>>>;
 };
 
+export type UpdateAlertCommentInput = {
+  alertId: Scalars['ID'];
+  commentId: Scalars['ID'];
+  body: Scalars['String'];
+};
+
+export type UpdateAlertStatusInput = {
+  alertId: Scalars['ID'];
+  status: AlertStatusEnum;
+  resolution?: Maybe<Scalars['String']>;
+};
+
 export type UpdateIntegrationInput = {
   awsAccountId?: Maybe<Scalars['String']>;
   integrationId: Scalars['String'];
@@ -850,9 +1363,19 @@ export type UpdateOrganizationInput = {
   displayName?: Maybe<Scalars['String']>;
   email?: Maybe<Scalars['String']>;
   alertReportFrequency?: Maybe<AlertReportFrequencyEnum>;
+  alertRetention?: Maybe<AlertRetentionInput>;
   remediationConfig?: Maybe<RemediationConfigInput>;
 };
 
+export type UpdateRoutingRuleInput = {
+  routingRuleId: Scalars['ID'];
+  displayName: Scalars['String'];
+  priority: Scalars['Int'];
+  match: RoutingMatchInput;
+  outputIds: Array<Scalars['ID']>;
+  continue?: Maybe<Scalars['Boolean']>;
+};
+
 export type UpdateUserInput = {
   id: Scalars['ID'];
   givenName?: Maybe<Scalars['String']>;
@@ -887,3 +1410,29 @@ export type User = {
   status?: Maybe<Scalars['String']>;
   role?: Maybe<RoleNameEnum>;
 };
+
+export type WebhookConfig = {
+  __typename?: 'WebhookConfig';
+  url: Scalars['String'];
+  headers?: Maybe<Array<WebhookHeader>>;
+  signingSecret?: Maybe<Scalars['String']>;
+  body?: Maybe<Scalars['String']>;
+};
+
+export type WebhookConfigInput = {
+  url: Scalars['String'];
+  headers?: Maybe<Array<WebhookHeaderInput>>;
+  signingSecret?: Maybe<Scalars['String']>;
+  body?: Maybe<Scalars['String']>;
+};
+
+export type WebhookHeader = {
+  __typename?: 'WebhookHeader';
+  key: Scalars['String'];
+  value: Scalars['String'];
+};
+
+export type WebhookHeaderInput = {
+  key: Scalars['String'];
+  value: Scalars['String'];
+};